@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"goExpenseTracker/config"
+	DB "goExpenseTracker/config/DB"
+	authConfig "goExpenseTracker/config/auth"
+	"goExpenseTracker/internal/repositories"
+	"goExpenseTracker/internal/services"
+)
+
+const claimUsage = `Usage: %s [flags] claim <email>
+
+Moves the data recorded before user accounts existed into the personal ledger of
+the user with the given email, and makes them the admin unless there is one.
+`
+
+// runClaim runs the claim subcommand and returns the exit code
+func runClaim(cfg *config.Config, args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, claimUsage, os.Args[0])
+		return 2
+	}
+
+	db, err := DB.Connect(cfg.Database, cfg.Server.Timezone)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to connect to database: %v\n", err)
+		return 1
+	}
+	userRepo := repositories.NewUserRepository(db)
+	twoFactorService := services.NewTwoFactorService(repositories.NewTwoFactorRepository(db), userRepo)
+	authService := services.NewAuthService(userRepo, repositories.NewLedgerRepository(db), twoFactorService, authConfig.Options(cfg.Auth))
+
+	if err := authService.ClaimLegacyData(args[0]); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to claim data: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Data recorded before user accounts existed now belongs to %s\n", args[0])
+	return 0
+}
@@ -1,68 +1,98 @@
 package config
 
 import (
-	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
-	"github.com/joho/godotenv"
+	"goExpenseTracker/config"
+
+	"github.com/glebarez/sqlite"
 	_ "github.com/lib/pq"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-func init() {
-	// Load environment variables from .env file
-	if err := godotenv.Load(); err != nil {
-		fmt.Println("No .env file found, relying on environment variables")
+// Connect opens the database of the configured driver; a Postgres session runs in
+// the given timezone
+func Connect(cfg config.Database, timezone string) (*gorm.DB, error) {
+	switch cfg.Driver {
+	case config.DriverSQLite:
+		return ConnectSQLite(cfg.Path)
+	case config.DriverMemory:
+		// Categories and expenses stay in the memory repositories; the other
+		// tables need a database, so they get an empty in-memory one
+		return ConnectSQLite(":memory:")
+	default:
+		return ConnectPostgres(cfg, timezone)
 	}
-	fmt.Println(".env found")
 }
 
-func ConnectPostgres() (*gorm.DB, error) {
-	// Support both naming conventions
-	user := os.Getenv("DB_USER")
-	if user == "" {
-		user = os.Getenv("POSTGRES_USER")
+func ConnectPostgres(cfg config.Database, timezone string) (*gorm.DB, error) {
+	// Build the DSN (Data Source Name), leaving out what is not set so the driver
+	// defaults apply, and quoting the rest so values may hold spaces
+	var settings []string
+	for _, setting := range [][2]string{
+		{"host", cfg.Host},
+		{"port", strconv.Itoa(cfg.Port)},
+		{"user", cfg.User},
+		{"password", cfg.Password.Value()},
+		{"dbname", cfg.Name},
+		{"sslmode", cfg.SSLMode},
+		{"TimeZone", timezone},
+	} {
+		if setting[1] != "" {
+			settings = append(settings, setting[0]+"="+dsnValue(setting[1]))
+		}
 	}
+	dsn := strings.Join(settings, " ")
 
-	password := os.Getenv("DB_PASSWORD")
-	if password == "" {
-		password = os.Getenv("POSTGRES_PASSWORD")
+	// Open a GORM DB connection
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		log.Printf("Error connecting to Postgres: %v", err)
+		return nil, err
 	}
 
-	dbName := os.Getenv("DB_NAME")
-	if dbName == "" {
-		dbName = os.Getenv("POSTGRES_DB")
-	}
+	log.Println("Database connected successfully!")
+	return db, nil
+}
 
-	host := os.Getenv("DB_HOST")
-	port := os.Getenv("DB_PORT")
-	sslmode := os.Getenv("DB_SSLMODE")
+// dsnValue quotes a value for a key=value connection string
+func dsnValue(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(value) + "'"
+}
 
-	if host == "" {
-		host = "localhost"
-	}
-	if port == "" {
-		port = "5432"
-	}
-	if sslmode == "" {
-		sslmode = "disable"
+// ConnectSQLite opens the SQLite database file at path, creating it if needed, or
+// an empty in-memory database for ":memory:"
+func ConnectSQLite(path string) (*gorm.DB, error) {
+	// Enforce foreign keys like Postgres, and wait for locks rather than failing
+	dsn := path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+	if path != ":memory:" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, err
+		}
+		dsn += "&_pragma=journal_mode(WAL)"
 	}
 
-	// Build the DSN (Data Source Name)
-	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=Asia/Kolkata",
-		host, user, password, dbName, port, sslmode,
-	)
-
-	// Open a GORM DB connection
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
 	if err != nil {
-		log.Printf("Error connecting to Postgres: %v", err)
+		log.Printf("Error opening SQLite database: %v", err)
 		return nil, err
 	}
 
-	log.Println("Database connected successfully!")
+	if path == ":memory:" {
+		// Every connection to :memory: opens a database of its own, so keep to one
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		sqlDB.SetMaxOpenConns(1)
+		log.Println("Database opened in memory; nothing will be kept")
+	} else {
+		log.Printf("Database opened at %s", path)
+	}
 	return db, nil
 }
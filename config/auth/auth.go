@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"crypto/rand"
+	"log"
+
+	"goExpenseTracker/config"
+	"goExpenseTracker/internal/services"
+)
+
+// Options builds the authentication settings. Without a JWT secret a random key
+// is generated, which signs everyone out whenever the server restarts.
+func Options(cfg config.Auth) services.AuthOptions {
+	options := services.AuthOptions{
+		Secret:      []byte(cfg.JWTSecret.Value()),
+		TokenTTL:    cfg.TokenTTL,
+		AllowSignup: cfg.AllowSignup,
+	}
+
+	if len(options.Secret) == 0 {
+		log.Printf("Signing access tokens with a random key; set JWT_SECRET so sessions survive a restart")
+		options.Secret = make([]byte, config.MinSecretBytes)
+		if _, err := rand.Read(options.Secret); err != nil {
+			log.Fatalf("Failed to generate a token signing key: %v", err)
+		}
+	}
+	return options
+}
@@ -0,0 +1,567 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+	_ "time/tzdata" // Timezones resolve on hosts without a zoneinfo database
+
+	"github.com/goccy/go-yaml"
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// DefaultTokenTTL is how long an access token stays valid unless auth.token_ttl says otherwise
+const DefaultTokenTTL = 24 * time.Hour
+
+// Duplicate policies selected by duplicates.policy: what happens when a new
+// expense looks like an existing one
+const (
+	DuplicatePolicyWarn   = "warn"   // Save it and list the likely duplicates
+	DuplicatePolicyReject = "reject" // Refuse it unless allow_duplicate is set
+	DuplicatePolicyOff    = "off"    // Do not look for duplicates
+)
+
+// DefaultDuplicateWindowDays is how many days apart duplicates may be dated unless configured
+const DefaultDuplicateWindowDays = 3
+
+// Database drivers selected by database.driver
+const (
+	DriverPostgres = "postgres" // The default
+	DriverSQLite   = "sqlite"   // A database file at database.path, for running locally without Postgres
+	DriverMemory   = "memory"   // Categories and expenses in process memory, the rest in in-memory SQLite; nothing is kept
+)
+
+// MinSecretBytes is the shortest JWT secret accepted for signing tokens with HS256
+const MinSecretBytes = 32
+
+// ErrUsage is returned by Load when the command line cannot be parsed; the
+// problem and the usage text have been printed already
+var ErrUsage = errors.New("invalid command line")
+
+// Config holds every setting of the server. Each one is read, from lowest to
+// highest precedence, from its default, the config file, the environment (and a
+// .env file in the working directory) and the command line.
+//
+// The key tag names the setting in the config file, nested under its section,
+// and is also its flag: database.host is set by -database.host. The env tag lists
+// the environment variables setting it, the first one set winning.
+type Config struct {
+	Server     Server     `key:"server"`
+	Swagger    Swagger    `key:"swagger"`
+	Database   Database   `key:"database"`
+	Auth       Auth       `key:"auth"`
+	OIDC       OIDC       `key:"oidc"`
+	Storage    Storage    `key:"storage"`
+	Duplicates Duplicates `key:"duplicates"`
+
+	// sources records where each setting was read from, by key
+	sources map[string]string
+}
+
+type Server struct {
+	Port int `key:"port" env:"PORT"`
+	// Timezone, an IANA name such as Europe/Berlin, is where the days of users who
+	// have not picked a timezone of their own begin and end, and the timezone of
+	// the database session. It defaults to Asia/Kolkata, which the session always
+	// used before it could be set, so upgraded deployments keep their behaviour.
+	Timezone string `key:"timezone" env:"TIMEZONE"`
+}
+
+// Location returns the server timezone, which validation has checked
+func (s Server) Location() *time.Location {
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// Swagger configures the API documentation served at /swagger
+type Swagger struct {
+	Host     string `key:"host" env:"SWAGGER_HOST"`           // Replaces the host in the spec when set
+	LocalURL string `key:"local_url" env:"SWAGGER_LOCAL_URL"` // The local development server listed in the spec
+	ProdURL  string `key:"prod_url" env:"SWAGGER_PROD_URL"`   // The production server listed in the spec
+}
+
+type Database struct {
+	Driver         string `key:"driver" env:"DB_DRIVER"`
+	Path           string `key:"path" env:"DB_PATH"` // The SQLite database file
+	Host           string `key:"host" env:"DB_HOST"`
+	Port           int    `key:"port" env:"DB_PORT"`
+	User           string `key:"user" env:"DB_USER,POSTGRES_USER"`
+	Password       Secret `key:"password" env:"DB_PASSWORD,POSTGRES_PASSWORD"`
+	Name           string `key:"name" env:"DB_NAME,POSTGRES_DB"`
+	SSLMode        string `key:"sslmode" env:"DB_SSLMODE"`
+	MigrateOnStart bool   `key:"migrate_on_start" env:"MIGRATE_ON_START"` // Apply pending migrations on start rather than refusing to start
+}
+
+type Auth struct {
+	// JWTSecret signs access tokens; without one a random key is generated, which
+	// signs everyone out whenever the server restarts
+	JWTSecret   Secret        `key:"jwt_secret" env:"JWT_SECRET"`
+	TokenTTL    time.Duration `key:"token_ttl" env:"JWT_TTL"`
+	AllowSignup bool          `key:"allow_signup" env:"ALLOW_SIGNUP"` // Whether anyone may register once the first user exists
+}
+
+// OIDC configures single sign-on, which is on once the issuer, client ID and
+// redirect URL are all set
+type OIDC struct {
+	IssuerURL     string   `key:"issuer_url" env:"OIDC_ISSUER_URL"`
+	ClientID      string   `key:"client_id" env:"OIDC_CLIENT_ID"`
+	ClientSecret  Secret   `key:"client_secret" env:"OIDC_CLIENT_SECRET"` // Left empty for public clients
+	RedirectURL   string   `key:"redirect_url" env:"OIDC_REDIRECT_URL"`   // The public URL of GET /api/v1/auth/oidc/callback
+	Scopes        []string `key:"scopes" env:"OIDC_SCOPES"`               // Requested besides openid
+	AutoProvision bool     `key:"auto_provision" env:"OIDC_AUTO_PROVISION"`
+}
+
+type Storage struct {
+	Backend            string `key:"backend" env:"STORAGE_BACKEND"` // local or s3
+	AttachmentsDir     string `key:"attachments_dir" env:"ATTACHMENTS_DIR"`
+	MaxAttachmentBytes int64  `key:"max_attachment_bytes" env:"ATTACHMENT_MAX_BYTES"`
+	S3                 S3     `key:"s3"`
+}
+
+type S3 struct {
+	Endpoint        string `key:"endpoint" env:"S3_ENDPOINT"`
+	AccessKeyID     string `key:"access_key_id" env:"S3_ACCESS_KEY_ID"`
+	SecretAccessKey Secret `key:"secret_access_key" env:"S3_SECRET_ACCESS_KEY"`
+	Bucket          string `key:"bucket" env:"S3_BUCKET"`
+	Region          string `key:"region" env:"S3_REGION"`
+	UseSSL          bool   `key:"use_ssl" env:"S3_USE_SSL"`
+}
+
+type Duplicates struct {
+	Policy     string `key:"policy" env:"DUPLICATE_POLICY"`           // warn, reject or off
+	WindowDays int    `key:"window_days" env:"DUPLICATE_WINDOW_DAYS"` // How many days apart duplicates may be dated
+}
+
+// Secret is a setting that must not be shown: printing it gives a mask instead
+// of the value. Secrets can be set from the config file and the environment but
+// not the command line, where other users could see them.
+type Secret string
+
+const secretMask = "********"
+
+// Value returns the secret itself
+func (s Secret) Value() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return secretMask
+}
+
+func (s Secret) GoString() string {
+	return strconv.Quote(s.String())
+}
+
+// defaults returns the settings used when nothing else sets them
+func defaults() *Config {
+	return &Config{
+		Server: Server{Port: 8080, Timezone: "Asia/Kolkata"},
+		Swagger: Swagger{
+			LocalURL: "http://localhost:8080/api",
+			ProdURL:  "https://goexpensetracker.onrender.com/api",
+		},
+		Database: Database{
+			Driver:  DriverPostgres,
+			Path:    "data/expenses.db",
+			Host:    "localhost",
+			Port:    5432,
+			SSLMode: "disable",
+		},
+		Auth: Auth{
+			TokenTTL:    DefaultTokenTTL,
+			AllowSignup: true,
+		},
+		OIDC: OIDC{
+			Scopes:        []string{"email", "profile"},
+			AutoProvision: true,
+		},
+		Storage: Storage{
+			Backend:            "local",
+			AttachmentsDir:     "./data/attachments",
+			MaxAttachmentBytes: 10 << 20,
+			S3: S3{
+				Endpoint: "localhost:9000",
+				Bucket:   "receipts",
+			},
+		},
+		Duplicates: Duplicates{
+			Policy:     DuplicatePolicyWarn,
+			WindowDays: DefaultDuplicateWindowDays,
+		},
+	}
+}
+
+// Load reads the configuration and validates it. args is the command line without
+// the program name; flags come first and the arguments after them are returned.
+// The config file is named by -config or CONFIG_FILE and is YAML or TOML, going
+// by its extension.
+func Load(args []string) (*Config, []string, error) {
+	cfg := defaults()
+	cfg.sources = make(map[string]string)
+	settings := cfg.settings()
+
+	// Flags are parsed first to find the config file, and applied last
+	fs := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	configFile := fs.String("config", "", "read settings from this YAML or TOML `file` (or CONFIG_FILE)")
+	flagValues := make(map[string]string)
+	for _, s := range settings {
+		if s.secret() {
+			continue
+		}
+		key := s.key
+		usage := "sets " + key
+		if len(s.env) > 0 {
+			usage = "overrides " + s.env[0]
+		}
+		set := func(value string) error {
+			flagValues[key] = value
+			return nil
+		}
+		if s.value.Kind() == reflect.Bool {
+			fs.BoolFunc(key, usage, set)
+		} else {
+			fs.Func(key, usage, set)
+		}
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s [flags] [migrate <command> | claim <email> | config]\n\nFlags:\n", fs.Name())
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, nil, err
+		}
+		return nil, nil, ErrUsage
+	}
+
+	// Values in .env fill in variables the environment leaves unset
+	if err := godotenv.Load(); err == nil {
+		log.Println("Read environment variables from .env")
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, nil, fmt.Errorf(".env: %w", err)
+	}
+
+	var errs []error
+	if *configFile == "" {
+		*configFile = os.Getenv("CONFIG_FILE")
+	}
+	if *configFile != "" {
+		if err := cfg.readFile(*configFile, settings); err != nil {
+			return nil, nil, err
+		}
+		log.Printf("Read settings from %s", *configFile)
+	}
+
+	for _, s := range settings {
+		for _, name := range s.env {
+			value, ok := os.LookupEnv(name)
+			if !s.secret() {
+				value = strings.TrimSpace(value)
+			}
+			if !ok || value == "" {
+				continue
+			}
+			if err := s.set(value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			}
+			cfg.sources[s.key] = "env " + name
+			break
+		}
+	}
+
+	for _, s := range settings {
+		if value, ok := flagValues[s.key]; ok {
+			if err := s.set(value); err != nil {
+				errs = append(errs, fmt.Errorf("-%s: %w", s.key, err))
+			}
+			cfg.sources[s.key] = "flag -" + s.key
+		}
+	}
+
+	if len(errs) == 0 {
+		errs = cfg.validate()
+	}
+	if len(errs) > 0 {
+		return nil, nil, errors.Join(errs...)
+	}
+	return cfg, fs.Args(), nil
+}
+
+// readFile applies the settings in a YAML or TOML config file
+func (c *Config) readFile(path string, settings []setting) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	values := make(map[string]any)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return fmt.Errorf("%s: unknown config file format %q, expected .yaml, .yml or .toml", path, ext)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	byKey := make(map[string]setting, len(settings))
+	for _, s := range settings {
+		byKey[s.key] = s
+	}
+	var errs []error
+	for key, value := range flatten("", values) {
+		s, ok := byKey[key]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: unknown setting %q", path, key))
+			continue
+		}
+		text, err := fileValue(value)
+		if err == nil {
+			err = s.set(text)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s: %w", path, key, err))
+			continue
+		}
+		c.sources[key] = "file " + path
+	}
+	return errors.Join(errs...)
+}
+
+// flatten turns the nested sections of a config file into values by dotted key
+func flatten(prefix string, values map[string]any) map[string]any {
+	flat := make(map[string]any)
+	for key, value := range values {
+		if section, ok := value.(map[string]any); ok {
+			for k, v := range flatten(prefix+key+".", section) {
+				flat[k] = v
+			}
+			continue
+		}
+		flat[prefix+key] = value
+	}
+	return flat
+}
+
+// fileValue writes a value from a config file as it would be given in the environment
+func fileValue(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			text, err := fileValue(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, text)
+		}
+		return strings.Join(items, ","), nil
+	case bool, int, int64, uint64, float64:
+		return fmt.Sprint(v), nil
+	default:
+		return "", fmt.Errorf("unexpected %T", value)
+	}
+}
+
+// setting is one field of Config, found through its tags
+type setting struct {
+	key   string   // The dotted key naming it in the config file and on the command line
+	env   []string // The environment variables setting it
+	value reflect.Value
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	secretType   = reflect.TypeOf(Secret(""))
+)
+
+// settings lists every setting of the configuration
+func (c *Config) settings() []setting {
+	return collect("", reflect.ValueOf(c).Elem())
+}
+
+func collect(prefix string, section reflect.Value) []setting {
+	var settings []setting
+	for i := 0; i < section.NumField(); i++ {
+		field := section.Type().Field(i)
+		key, ok := field.Tag.Lookup("key")
+		if !ok {
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct {
+			settings = append(settings, collect(prefix+key+".", section.Field(i))...)
+			continue
+		}
+		s := setting{key: prefix + key, value: section.Field(i)}
+		if env := field.Tag.Get("env"); env != "" {
+			s.env = strings.Split(env, ",")
+		}
+		settings = append(settings, s)
+	}
+	return settings
+}
+
+func (s setting) secret() bool {
+	return s.value.Type() == secretType
+}
+
+// set parses text into the setting
+func (s setting) set(text string) error {
+	switch {
+	case s.value.Type() == durationType:
+		d, err := time.ParseDuration(text)
+		if err != nil {
+			return fmt.Errorf("expected a duration such as 24h, got %q", text)
+		}
+		s.value.SetInt(int64(d))
+	case s.value.Kind() == reflect.String:
+		s.value.SetString(text)
+	case s.value.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return fmt.Errorf("expected true or false, got %q", text)
+		}
+		s.value.SetBool(b)
+	case s.value.Kind() == reflect.Int || s.value.Kind() == reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return fmt.Errorf("expected a whole number, got %q", text)
+		}
+		s.value.SetInt(n)
+	case s.value.Kind() == reflect.Slice:
+		// Lists are separated by commas or spaces
+		s.value.Set(reflect.ValueOf(strings.Fields(strings.ReplaceAll(text, ",", " "))))
+	default:
+		return fmt.Errorf("unsupported setting type %s", s.value.Type())
+	}
+	return nil
+}
+
+// validate lists every setting that is out of range or inconsistent with the others
+func (c *Config) validate() []error {
+	var errs []error
+	invalid := func(key string, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		invalid("server.port", "%d is not a TCP port", c.Server.Port)
+	}
+	if _, err := time.LoadLocation(c.Server.Timezone); err != nil || c.Server.Timezone == "" || c.Server.Timezone == "Local" {
+		invalid("server.timezone", "unknown timezone %q, expected an IANA name such as Europe/Berlin", c.Server.Timezone)
+	}
+
+	c.Database.Driver = strings.ToLower(c.Database.Driver)
+	switch c.Database.Driver {
+	case DriverPostgres:
+		if c.Database.Port < 1 || c.Database.Port > 65535 {
+			invalid("database.port", "%d is not a TCP port", c.Database.Port)
+		}
+		switch c.Database.SSLMode {
+		case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+		default:
+			invalid("database.sslmode", "unknown mode %q", c.Database.SSLMode)
+		}
+	case DriverSQLite:
+		if c.Database.Path == "" {
+			invalid("database.path", "a database file is needed with the %s driver", DriverSQLite)
+		}
+	case DriverMemory:
+	default:
+		invalid("database.driver", "unknown driver %q, expected %s, %s or %s", c.Database.Driver, DriverPostgres, DriverSQLite, DriverMemory)
+	}
+
+	if secret := c.Auth.JWTSecret.Value(); secret != "" && len(secret) < MinSecretBytes {
+		invalid("auth.jwt_secret", "must be at least %d bytes long", MinSecretBytes)
+	}
+	if c.Auth.TokenTTL <= 0 {
+		invalid("auth.token_ttl", "must be positive")
+	}
+
+	oidc := c.OIDC
+	if oidc.IssuerURL != "" || oidc.ClientID != "" || oidc.RedirectURL != "" {
+		if !absoluteURL(oidc.IssuerURL) {
+			invalid("oidc.issuer_url", "single sign-on needs the absolute URL of the provider, got %q", oidc.IssuerURL)
+		}
+		if !absoluteURL(oidc.RedirectURL) {
+			invalid("oidc.redirect_url", "single sign-on needs the absolute URL of the callback, got %q", oidc.RedirectURL)
+		}
+		if oidc.ClientID == "" {
+			invalid("oidc.client_id", "single sign-on needs the client ID registered with the provider")
+		}
+	}
+
+	switch c.Storage.Backend {
+	case "local":
+		if c.Storage.AttachmentsDir == "" {
+			invalid("storage.attachments_dir", "a directory is needed with the local backend")
+		}
+	case "s3":
+		if c.Storage.S3.Endpoint == "" || c.Storage.S3.Bucket == "" {
+			invalid("storage.s3", "the s3 backend needs an endpoint and a bucket")
+		}
+	default:
+		invalid("storage.backend", "unknown backend %q, expected local or s3", c.Storage.Backend)
+	}
+	if c.Storage.MaxAttachmentBytes <= 0 {
+		invalid("storage.max_attachment_bytes", "must be positive")
+	}
+
+	switch c.Duplicates.Policy {
+	case DuplicatePolicyWarn, DuplicatePolicyReject, DuplicatePolicyOff:
+	default:
+		invalid("duplicates.policy", "unknown policy %q, expected warn, reject or off", c.Duplicates.Policy)
+	}
+	if c.Duplicates.WindowDays < 0 {
+		invalid("duplicates.window_days", "must not be negative")
+	}
+	return errs
+}
+
+func absoluteURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// Print lists every setting with its value and where it was read from; secrets
+// are masked
+func (c *Config) Print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "SETTING\tVALUE\tSOURCE")
+	for _, s := range c.settings() {
+		source := c.sources[s.key]
+		if source == "" {
+			source = "default"
+		}
+		value := fmt.Sprint(s.value.Interface())
+		if list, ok := s.value.Interface().([]string); ok {
+			value = strings.Join(list, ",")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", s.key, value, source)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,304 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearEnv unsets every variable a setting is read from, so the tests see only
+// what they set themselves
+func clearEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("CONFIG_FILE", "")
+	for _, s := range defaults().settings() {
+		for _, name := range s.env {
+			t.Setenv(name, "")
+		}
+	}
+}
+
+// writeFile writes a config file into the test's temporary directory
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	clearEnv(t)
+	cfg, args, err := Load(nil)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(args) != 0 {
+		t.Errorf("got arguments %q, want none", args)
+	}
+	want := defaults()
+	want.sources = cfg.sources
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("got %+v\nwant %+v", cfg, want)
+	}
+	if cfg.Server.Timezone != "Asia/Kolkata" || cfg.Server.Location().String() != "Asia/Kolkata" {
+		t.Errorf("default timezone: got %q", cfg.Server.Timezone)
+	}
+}
+
+// TestExampleFileHoldsTheDefaults keeps config.example.yaml, which documents every
+// setting at its default, in step with defaults()
+func TestExampleFileHoldsTheDefaults(t *testing.T) {
+	clearEnv(t)
+	cfg, _, err := Load([]string{"-config", filepath.Join("..", "config.example.yaml")})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	want := defaults()
+	want.sources = cfg.sources
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("got %+v\nwant %+v", cfg, want)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	files := map[string]string{
+		"config.yaml": `
+server:
+  port: 9000
+database:
+  host: file-host
+  user: file-user
+  name: file-db
+auth:
+  token_ttl: 1h
+oidc:
+  scopes: [email, groups]
+`,
+		"config.toml": `
+[server]
+port = 9000
+
+[database]
+host = "file-host"
+user = "file-user"
+name = "file-db"
+
+[auth]
+token_ttl = "1h"
+
+[oidc]
+scopes = ["email", "groups"]
+`,
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			clearEnv(t)
+			t.Setenv("CONFIG_FILE", writeFile(t, name, content))
+			t.Setenv("PORT", "9100")
+			t.Setenv("DB_HOST", "env-host")
+			t.Setenv("DB_NAME", " env-db ")
+			// The first variable listed for a setting wins
+			t.Setenv("POSTGRES_DB", "other-db")
+
+			cfg, args, err := Load([]string{"-server.port", "9200", "-duplicates.policy=reject", "migrate", "up"})
+			if err != nil {
+				t.Fatalf("load: %v", err)
+			}
+			if !reflect.DeepEqual(args, []string{"migrate", "up"}) {
+				t.Errorf("got arguments %q, want [migrate up]", args)
+			}
+
+			checks := []struct {
+				key    string
+				got    any
+				want   any
+				source string
+			}{
+				{"server.port", cfg.Server.Port, 9200, "flag -server.port"},
+				{"database.host", cfg.Database.Host, "env-host", "env DB_HOST"},
+				{"database.name", cfg.Database.Name, "env-db", "env DB_NAME"},
+				{"database.user", cfg.Database.User, "file-user", "file " + os.Getenv("CONFIG_FILE")},
+				{"auth.token_ttl", cfg.Auth.TokenTTL, time.Hour, "file " + os.Getenv("CONFIG_FILE")},
+				{"oidc.scopes", cfg.OIDC.Scopes, []string{"email", "groups"}, "file " + os.Getenv("CONFIG_FILE")},
+				{"duplicates.policy", cfg.Duplicates.Policy, DuplicatePolicyReject, "flag -duplicates.policy"},
+				{"database.port", cfg.Database.Port, 5432, ""},
+			}
+			for _, c := range checks {
+				if !reflect.DeepEqual(c.got, c.want) {
+					t.Errorf("%s: got %v, want %v", c.key, c.got, c.want)
+				}
+				if source := cfg.sources[c.key]; source != c.source {
+					t.Errorf("%s: got source %q, want %q", c.key, source, c.source)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadFlagOverridesConfigFileVariable(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("CONFIG_FILE", writeFile(t, "env.yaml", "server:\n  port: 9000\n"))
+	path := writeFile(t, "flag.yaml", "server:\n  port: 9300\n")
+
+	cfg, _, err := Load([]string{"-config", path})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Server.Port != 9300 {
+		t.Errorf("got port %d, want 9300 from the file given by -config", cfg.Server.Port)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		args []string
+		env  map[string]string
+		file string // Written to config.yaml and passed with -config
+		want []string
+	}{
+		{
+			name: "port out of range",
+			args: []string{"-server.port", "70000"},
+			want: []string{"server.port: 70000 is not a TCP port"},
+		},
+		{
+			name: "unknown timezone",
+			env:  map[string]string{"TIMEZONE": "Mars/Olympus"},
+			want: []string{`server.timezone: unknown timezone "Mars/Olympus"`},
+		},
+		{
+			name: "local timezone",
+			args: []string{"-server.timezone", "Local"},
+			want: []string{"server.timezone"},
+		},
+		{
+			name: "unknown driver",
+			args: []string{"-database.driver", "mysql"},
+			want: []string{`database.driver: unknown driver "mysql"`},
+		},
+		{
+			name: "sqlite without a path",
+			args: []string{"-database.driver", "SQLite", "-database.path", ""},
+			want: []string{"database.path: a database file is needed"},
+		},
+		{
+			name: "short JWT secret",
+			env:  map[string]string{"JWT_SECRET": "too-short"},
+			want: []string{"auth.jwt_secret: must be at least 32 bytes long"},
+		},
+		{
+			name: "partial single sign-on",
+			args: []string{"-oidc.issuer_url", "accounts.example.com"},
+			want: []string{"oidc.issuer_url", "oidc.redirect_url", "oidc.client_id"},
+		},
+		{
+			name: "every problem at once",
+			args: []string{"-storage.backend", "ftp", "-duplicates.policy", "block", "-duplicates.window_days", "-1", "-auth.token_ttl", "0s"},
+			want: []string{"storage.backend", "duplicates.policy", "duplicates.window_days", "auth.token_ttl"},
+		},
+		{
+			name: "values that do not parse",
+			args: []string{"-server.port", "eighty", "-auth.allow_signup=maybe"},
+			env:  map[string]string{"JWT_TTL": "a day"},
+			want: []string{`-server.port: expected a whole number, got "eighty"`, "-auth.allow_signup: expected true or false", "JWT_TTL: expected a duration"},
+		},
+		{
+			name: "unknown setting in the file",
+			file: "server:\n  port: 8080\n  colour: blue\n",
+			want: []string{`unknown setting "server.colour"`},
+		},
+		{
+			name: "malformed file",
+			file: "server: [port\n",
+			want: []string{"config.yaml"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			clearEnv(t)
+			for name, value := range c.env {
+				t.Setenv(name, value)
+			}
+			args := c.args
+			if c.file != "" {
+				args = append([]string{"-config", writeFile(t, "config.yaml", c.file)}, args...)
+			}
+			_, _, err := Load(args)
+			if err == nil {
+				t.Fatal("got no error")
+			}
+			for _, want := range c.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+
+	t.Run("unknown file format", func(t *testing.T) {
+		clearEnv(t)
+		_, _, err := Load([]string{"-config", writeFile(t, "config.json", "{}")})
+		if err == nil || !strings.Contains(err.Error(), `unknown config file format ".json"`) {
+			t.Fatalf("got %v", err)
+		}
+	})
+}
+
+func TestSecretsAreMasked(t *testing.T) {
+	secret := Secret("correct-horse-battery-staple-0123456789")
+	for _, format := range []string{"%v", "%s", "%+v", "%#v", "%q"} {
+		if out := fmt.Sprintf(format, secret); strings.Contains(out, secret.Value()) {
+			t.Errorf("%s shows the secret: %s", format, out)
+		}
+	}
+	if out := fmt.Sprintf("%+v", Auth{JWTSecret: secret}); strings.Contains(out, secret.Value()) {
+		t.Errorf("%%+v of a section shows the secret: %s", out)
+	}
+	if out := fmt.Sprintf("%#v", Auth{JWTSecret: secret}); strings.Contains(out, secret.Value()) {
+		t.Errorf("%%#v of a section shows the secret: %s", out)
+	}
+	if Secret("").String() != "" {
+		t.Errorf("an empty secret prints as %q, want it empty", Secret("").String())
+	}
+
+	clearEnv(t)
+	t.Setenv("JWT_SECRET", secret.Value())
+	t.Setenv("DB_PASSWORD", "hunter2")
+	cfg, _, err := Load(nil)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Auth.JWTSecret.Value() != secret.Value() {
+		t.Errorf("JWT secret: got %q", cfg.Auth.JWTSecret.Value())
+	}
+
+	var out bytes.Buffer
+	cfg.Print(&out)
+	for _, value := range []string{secret.Value(), "hunter2"} {
+		if strings.Contains(out.String(), value) {
+			t.Errorf("Print shows a secret:\n%s", out.String())
+		}
+	}
+	for _, line := range []string{"auth.jwt_secret", "database.password"} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("Print leaves out %s:\n%s", line, out.String())
+		}
+	}
+	if !strings.Contains(out.String(), secretMask) || !strings.Contains(out.String(), "env JWT_SECRET") {
+		t.Errorf("Print does not show the masked secret and its source:\n%s", out.String())
+	}
+
+	// Secrets have no flag, as other users can read command lines
+	if _, _, err := Load([]string{"-auth.jwt_secret", secret.Value()}); !errors.Is(err, ErrUsage) {
+		t.Errorf("secret flag: got %v, want %v", err, ErrUsage)
+	}
+}
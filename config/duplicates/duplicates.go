@@ -0,0 +1,14 @@
+package duplicates
+
+import (
+	"goExpenseTracker/config"
+	"goExpenseTracker/internal/services"
+)
+
+// Options builds the duplicate detection settings
+func Options(cfg config.Duplicates) services.DuplicateOptions {
+	return services.DuplicateOptions{
+		Policy:     cfg.Policy,
+		WindowDays: cfg.WindowDays,
+	}
+}
@@ -0,0 +1,22 @@
+package oidc
+
+import (
+	"goExpenseTracker/config"
+	"goExpenseTracker/internal/services"
+)
+
+// Options builds the single sign-on settings, which are off unless the issuer,
+// client ID and redirect URL are set.
+//
+// Any OpenID Connect provider works, including a local mock such as
+// ghcr.io/navikt/mock-oauth2-server for trying the flow out.
+func Options(cfg config.OIDC) services.OIDCOptions {
+	return services.OIDCOptions{
+		IssuerURL:     cfg.IssuerURL,
+		ClientID:      cfg.ClientID,
+		ClientSecret:  cfg.ClientSecret.Value(),
+		RedirectURL:   cfg.RedirectURL,
+		Scopes:        cfg.Scopes,
+		AutoProvision: cfg.AutoProvision,
+	}
+}
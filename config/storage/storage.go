@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"goExpenseTracker/config"
+	blob "goExpenseTracker/internal/storage"
+)
+
+// ConnectStorage builds the attachment storage backend selected by the settings (local or s3)
+func ConnectStorage(cfg config.Storage) (blob.Storage, error) {
+	switch cfg.Backend {
+	case "local":
+		log.Printf("Storing attachments in %s", cfg.AttachmentsDir)
+		return blob.NewLocalStorage(cfg.AttachmentsDir)
+
+	case "s3":
+		s3 := blob.S3Config{
+			Endpoint:        cfg.S3.Endpoint,
+			AccessKeyID:     cfg.S3.AccessKeyID,
+			SecretAccessKey: cfg.S3.SecretAccessKey.Value(),
+			Bucket:          cfg.S3.Bucket,
+			Region:          cfg.S3.Region,
+			UseSSL:          cfg.S3.UseSSL,
+		}
+		log.Printf("Storing attachments in bucket %s at %s", s3.Bucket, s3.Endpoint)
+		return blob.NewS3Storage(context.Background(), s3)
+
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q, expected local or s3", cfg.Backend)
+	}
+}
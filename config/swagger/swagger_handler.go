@@ -3,8 +3,8 @@ package swagger
 import (
 	"encoding/json"
 	"net/http"
-	"os"
 
+	"goExpenseTracker/config"
 	"goExpenseTracker/docs"
 
 	"github.com/gin-gonic/gin"
@@ -12,7 +12,7 @@ import (
 )
 
 // CustomSwaggerHandler modifies the swagger spec to include multiple servers
-func CustomSwaggerHandler() gin.HandlerFunc {
+func CustomSwaggerHandler(cfg config.Swagger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get the swagger spec
 		swagger, err := swag.ReadDoc(docs.SwaggerInfo.InstanceName())
@@ -28,25 +28,14 @@ func CustomSwaggerHandler() gin.HandlerFunc {
 			return
 		}
 
-		// Get server URLs from environment variables with defaults
-		localURL := os.Getenv("SWAGGER_LOCAL_URL")
-		if localURL == "" {
-			localURL = "http://localhost:8080/api"
-		}
-
-		prodURL := os.Getenv("SWAGGER_PROD_URL")
-		if prodURL == "" {
-			prodURL = "https://goexpensetracker.onrender.com/api"
-		}
-
 		// Add servers array for OpenAPI-style server selection (Swagger UI 3+ supports this)
 		spec["servers"] = []map[string]interface{}{
 			{
-				"url":         localURL,
+				"url":         cfg.LocalURL,
 				"description": "Local Development Server",
 			},
 			{
-				"url":         prodURL,
+				"url":         cfg.ProdURL,
 				"description": "Production Server (Render)",
 			},
 		}
@@ -0,0 +1,77 @@
+package dto
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AccountRequestDTO is used to create or update an account (card, cash float, bank account...).
+type AccountRequestDTO struct {
+	Name           string  `json:"name" binding:"required,min=2,max=50"`
+	Type           string  `json:"type" binding:"omitempty,oneof=cash card bank other" example:"card"`
+	Currency       string  `json:"currency" binding:"required,len=3" example:"INR"`
+	OpeningBalance float64 `json:"opening_balance"`
+}
+
+// Validate performs additional business logic validation
+func (a *AccountRequestDTO) Validate() error {
+	a.Name = strings.TrimSpace(a.Name)
+	if len(a.Name) < 2 {
+		return fmt.Errorf("name must be at least 2 characters")
+	}
+
+	a.Currency = strings.ToUpper(strings.TrimSpace(a.Currency))
+	if len(a.Currency) != 3 {
+		return fmt.Errorf("currency must be a 3-letter ISO code")
+	}
+
+	if a.Type == "" {
+		a.Type = "other"
+	}
+
+	return nil
+}
+
+// AccountResponseDTO represents an account returned in API responses.
+type AccountResponseDTO struct {
+	ID             int       `json:"id"`
+	Name           string    `json:"name"`
+	Type           string    `json:"type"`
+	Currency       string    `json:"currency"`
+	OpeningBalance float64   `json:"opening_balance"`
+	Balance        float64   `json:"balance"` // Current running balance
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// AccountBalanceDTO is the balance of an account at the end of a given day.
+type AccountBalanceDTO struct {
+	AccountID      int     `json:"account_id"`
+	Currency       string  `json:"currency"`
+	OpeningBalance float64 `json:"opening_balance"`
+	Balance        float64 `json:"balance"`
+	AsOf           string  `json:"as_of,omitempty"` // Format: yyyy-mm-dd
+}
+
+// StatementEntryDTO is a single movement on an account statement.
+type StatementEntryDTO struct {
+	Date        string  `json:"date"` // Format: yyyy-mm-dd
+	Type        string  `json:"type"` // expense, refund, income, transfer_in or transfer_out
+	ReferenceID int     `json:"reference_id"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`  // Signed: negative for money leaving the account
+	Balance     float64 `json:"balance"` // Running balance after this entry
+}
+
+// AccountStatementDTO lists account movements over a date range with running balances.
+type AccountStatementDTO struct {
+	AccountID      int                 `json:"account_id"`
+	AccountName    string              `json:"account_name"`
+	Currency       string              `json:"currency"`
+	From           string              `json:"from,omitempty"`
+	To             string              `json:"to,omitempty"`
+	OpeningBalance float64             `json:"opening_balance"` // Balance before the first entry of the range
+	ClosingBalance float64             `json:"closing_balance"`
+	Entries        []StatementEntryDTO `json:"entries"`
+}
@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"time"
+)
+
+// APIKeyRequestDTO is used to create an API key.
+type APIKeyRequestDTO struct {
+	Name          string   `json:"name" binding:"required,min=1,max=100" example:"CI import job"`
+	Scopes        []string `json:"scopes" binding:"required,min=1" example:"expenses:read,expenses:write"` // See GET /v1/api-keys/scopes
+	ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1,max=3650" example:"90"`        // Never expires when omitted
+}
+
+// APIKeyResponseDTO represents an API key. The key itself is only returned when it
+// is created; the prefix tells keys apart afterwards.
+type APIKeyResponseDTO struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Key        string     `json:"key,omitempty"`
+	Prefix     string     `json:"prefix" example:"gxt_Zm9vYmFy"`
+	Scopes     []string   `json:"scopes"`
+	Status     string     `json:"status" example:"active"` // active, expired or revoked
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// APIKeyScopeDTO describes a scope an API key can be given.
+type APIKeyScopeDTO struct {
+	Name        string `json:"name" example:"expenses:write"`
+	Description string `json:"description"`
+}
@@ -0,0 +1,16 @@
+package dto
+
+import (
+	"time"
+)
+
+// AttachmentResponseDTO describes a stored receipt attachment.
+type AttachmentResponseDTO struct {
+	ID          int       `json:"id"`
+	ExpenseID   int       `json:"expense_id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,47 @@
+package dto
+
+import (
+	"time"
+)
+
+// AuditFilterDTO selects entries of the audit trail.
+type AuditFilterDTO struct {
+	EntityType string `form:"entity_type" binding:"omitempty,oneof=expense category"`
+	EntityID   int    `form:"entity_id" binding:"omitempty,min=1"`
+	Action     string `form:"action" binding:"omitempty,oneof=create update delete"`
+	ActorID    int    `form:"actor_id" binding:"omitempty,min=1"`      // User who made the change
+	RequestID  string `form:"request_id" binding:"max=64"`             // X-Request-ID of the request that made the change
+	From       string `form:"from" example:"01-01-2025" format:"date"` // First day (dd-mm-yyyy or yyyy-mm-dd)
+	To         string `form:"to" example:"31-01-2025" format:"date"`   // Last day (dd-mm-yyyy or yyyy-mm-dd)
+	Offset     int    `form:"offset" binding:"omitempty,min=0"`
+	Limit      int    `form:"limit" binding:"omitempty,min=1,max=500"` // 50 by default
+}
+
+// AuditChangeDTO is one field changed by an audited action. From is null for a
+// created entity and To for a deleted one.
+type AuditChangeDTO struct {
+	Field string      `json:"field" example:"amount"`
+	From  interface{} `json:"from" swaggertype:"object"`
+	To    interface{} `json:"to" swaggertype:"object"`
+}
+
+// AuditEntryDTO is one change to an expense or category.
+type AuditEntryDTO struct {
+	ID         int              `json:"id"`
+	EntityType string           `json:"entity_type" example:"expense"` // expense or category
+	EntityID   int              `json:"entity_id"`
+	Action     string           `json:"action" example:"update"` // create, update or delete
+	ActorID    int              `json:"actor_id"`
+	ActorEmail string           `json:"actor_email,omitempty"` // Omitted once the user is gone
+	RequestID  string           `json:"request_id,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+	Changes    []AuditChangeDTO `json:"changes"`
+}
+
+// AuditLogDTO is a page of the audit trail, newest first.
+type AuditLogDTO struct {
+	Total   int64           `json:"total"` // Entries matching the filter across all pages
+	Offset  int             `json:"offset"`
+	Limit   int             `json:"limit"`
+	Entries []AuditEntryDTO `json:"entries"`
+}
@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"time"
+)
+
+// RegisterRequestDTO is used to sign up.
+type RegisterRequestDTO struct {
+	Email    string `json:"email" binding:"required,email,max=255" example:"alex@example.com"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
+	Name     string `json:"name" binding:"max=100"`
+}
+
+// LoginRequestDTO is used to sign in.
+type LoginRequestDTO struct {
+	Email    string `json:"email" binding:"required" example:"alex@example.com"`
+	Password string `json:"password" binding:"required"`
+}
+
+// AuthTokenDTO is a signed access token, sent back as "Authorization: Bearer <token>".
+type AuthTokenDTO struct {
+	Token     string          `json:"token"`
+	TokenType string          `json:"token_type" example:"Bearer"`
+	ExpiresAt time.Time       `json:"expires_at"`
+	User      UserResponseDTO `json:"user"`
+}
+
+// UserUpdateRequestDTO changes the signed-in user's settings; fields left out stay as they are.
+type UserUpdateRequestDTO struct {
+	Timezone *string `json:"timezone" binding:"omitempty,max=64" example:"Europe/Berlin"` // IANA name; empty goes back to the server's timezone
+}
+
+// UserResponseDTO represents a user returned in API responses.
+type UserResponseDTO struct {
+	ID        int       `json:"id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name,omitempty"`
+	IsAdmin   bool      `json:"is_admin"`
+	Timezone  string    `json:"timezone,omitempty" example:"Europe/Berlin"` // Where the user's days begin and end; empty when the server's timezone applies
+	CreatedAt time.Time `json:"created_at"`
+}
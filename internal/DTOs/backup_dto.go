@@ -0,0 +1,19 @@
+package dto
+
+import "time"
+
+// RestoreResultDTO reports what a restore wrote.
+type RestoreResultDTO struct {
+	SchemaVersion int                     `json:"schema_version"`
+	CreatedAt     time.Time               `json:"created_at"` // When the backup was taken
+	Conflict      string                  `json:"conflict"`   // skip, overwrite or fail
+	Tables        []RestoreTableResultDTO `json:"tables"`
+	Attachments   int                     `json:"attachments"` // Receipt files written to storage
+}
+
+// RestoreTableResultDTO counts the rows restored into one table.
+type RestoreTableResultDTO struct {
+	Name    string `json:"name"`
+	Rows    int    `json:"rows"`    // Rows in the backup
+	Written int64  `json:"written"` // Rows inserted or overwritten; the rest were skipped
+}
@@ -39,3 +39,18 @@ type CategoryResponseDTO struct {
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
+
+// CategorySuggestionsDTO ranks the categories likely for a description, learned from past expenses.
+type CategorySuggestionsDTO struct {
+	Description string                  `json:"description"`
+	Tokens      []string                `json:"tokens"`     // Words of the description seen in past expenses
+	TrainedOn   int                     `json:"trained_on"` // Past expenses the suggestions are based on
+	Suggestions []CategorySuggestionDTO `json:"suggestions"`
+}
+
+// CategorySuggestionDTO is a suggested category with the model's confidence in it.
+type CategorySuggestionDTO struct {
+	CategoryID   int     `json:"category_id"`
+	CategoryName string  `json:"category_name"`
+	Confidence   float64 `json:"confidence" example:"0.87"` // Between 0 and 1
+}
@@ -0,0 +1,58 @@
+package dto
+
+// DuplicateExpenseDTO is an existing expense that looks like a duplicate.
+type DuplicateExpenseDTO struct {
+	ExpenseID    int     `json:"expense_id"`
+	Date         string  `json:"date"` // Format: yyyy-mm-dd
+	Kind         string  `json:"kind"`
+	Amount       float64 `json:"amount"`
+	Currency     string  `json:"currency,omitempty"`
+	Description  string  `json:"description"`
+	CategoryID   int     `json:"category_id"`
+	CategoryName string  `json:"category_name,omitempty"`
+	AccountID    int     `json:"account_id,omitempty"`
+	ExternalID   string  `json:"external_id,omitempty"`
+	Similarity   float64 `json:"similarity,omitempty" example:"0.67"` // Share of description words in common with a new expense; omitted when neither description has words to compare
+}
+
+// DuplicateConflictDTO is the 409 answer to an expense rejected as a likely duplicate.
+type DuplicateConflictDTO struct {
+	Error      string                `json:"error"`
+	Duplicates []DuplicateExpenseDTO `json:"duplicates"`
+}
+
+// DuplicateFilterDTO selects the expenses searched for duplicate clusters.
+type DuplicateFilterDTO struct {
+	ExpenseFilterDTO
+	WindowDays *int `form:"window_days" binding:"omitempty,gte=0"` // Days apart two duplicates may be; defaults to the server setting
+}
+
+// DuplicateClustersDTO lists groups of expenses suspected to be recorded more than once.
+type DuplicateClustersDTO struct {
+	WindowDays int                   `json:"window_days"`
+	Clusters   []DuplicateClusterDTO `json:"clusters"`
+}
+
+// DuplicateClusterDTO is a group of expenses of the same amount and kind, close in
+// date and with similar descriptions, in date order.
+type DuplicateClusterDTO struct {
+	Kind     string                `json:"kind"`
+	Amount   float64               `json:"amount"`
+	Currency string                `json:"currency,omitempty"`
+	Expenses []DuplicateExpenseDTO `json:"expenses"`
+}
+
+// DuplicateResolveRequestDTO settles a duplicate cluster: the duplicates are either
+// deleted, or dismissed as genuine so they are no longer reported alongside the kept expense.
+type DuplicateResolveRequestDTO struct {
+	KeepID       int    `json:"keep_id" binding:"required,min=1"`
+	DuplicateIDs []int  `json:"duplicate_ids" binding:"required,min=1,dive,min=1"`
+	Action       string `json:"action" binding:"required,oneof=delete dismiss" example:"delete"`
+}
+
+// DuplicateResolveResultDTO reports how a duplicate cluster was settled.
+type DuplicateResolveResultDTO struct {
+	KeepID       int    `json:"keep_id"`
+	Action       string `json:"action"`
+	DuplicateIDs []int  `json:"duplicate_ids"`
+}
@@ -2,36 +2,60 @@ package dto
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
 // ExpenseRequestDTO is for creating or updating an expense.
 type ExpenseRequestDTO struct {
-	CategoryID  int     `json:"category_id" binding:"min=1"`
+	CategoryID     int                      `json:"category_id" binding:"omitempty,min=1"` // Optional when splits are given or a rule assigns one
+	AccountID      int                      `json:"account_id" binding:"omitempty,min=1"`
+	Kind           string                   `json:"kind" binding:"omitempty,oneof=expense refund income" example:"expense"` // Defaults to expense
+	Amount         float64                  `json:"amount" binding:"required,gt=0"`
+	Currency       string                   `json:"currency" binding:"omitempty,len=3" example:"EUR"` // Must match the account's currency when both are set
+	Description    string                   `json:"description" binding:"max=255"`
+	Date           string                   `json:"date" binding:"required" example:"12-12-2025" format:"date"` // Format: dd-mm-yyyy or yyyy-mm-dd
+	Splits         []ExpenseSplitRequestDTO `json:"splits" binding:"omitempty,dive"`                            // Split lines must sum to amount
+	Sharing        *ExpenseSharingDTO       `json:"sharing"`                                                    // Who paid and who shares the cost
+	ExternalID     string                   `json:"external_id" binding:"max=255"`                              // Bank reference, used to skip repeat imports
+	TagIDs         []int                    `json:"tag_ids" binding:"omitempty,dive,min=1"`                     // Tags to label the expense with
+	AllowDuplicate bool                     `json:"allow_duplicate"`                                            // Save even when it looks like a duplicate of an existing expense
+}
+
+// ExpenseSplitRequestDTO assigns part of an expense to a category.
+type ExpenseSplitRequestDTO struct {
+	CategoryID  int     `json:"category_id" binding:"required,min=1"`
 	Amount      float64 `json:"amount" binding:"required,gt=0"`
 	Description string  `json:"description" binding:"max=255"`
-	Date        string  `json:"date" binding:"required" example:"12-12-2025" format:"date"` // Format: dd-mm-yyyy or yyyy-mm-dd
 }
 
-// Validate performs additional business logic validation
-func (e *ExpenseRequestDTO) Validate() error {
+// Validate performs additional business logic validation; today is the
+// requesting user's current date, in their timezone
+func (e *ExpenseRequestDTO) Validate(today time.Time) error {
+	if err := e.ValidateFields(); err != nil {
+		return err
+	}
+
+	// Check if date is not in the past
+	expenseDate, _ := parseDate(e.Date)
+	if expenseDate.Before(today) {
+		return fmt.Errorf("date cannot be in the past")
+	}
+
+	return nil
+}
+
+// ValidateFields checks the request on its own, without the date policy that only
+// applies to manually entered expenses (imports carry historical dates)
+func (e *ExpenseRequestDTO) ValidateFields() error {
 	// Check if date is provided
 	if e.Date == "" {
 		return fmt.Errorf("date is required")
 	}
 
 	// Parse the date string
-	var parsedDate time.Time
-	var err error
-
-	// Try parsing dd-mm-yyyy format
-	parsedDate, err = time.Parse("02-01-2006", e.Date)
-	if err != nil {
-		// Try ISO format as fallback
-		parsedDate, err = time.Parse("2006-01-02", e.Date)
-		if err != nil {
-			return fmt.Errorf("invalid date format, expected dd-mm-yyyy or yyyy-mm-dd")
-		}
+	if _, err := parseDate(e.Date); err != nil {
+		return err
 	}
 
 	// Check if amount is positive (already in binding, but double-check)
@@ -39,12 +63,19 @@ func (e *ExpenseRequestDTO) Validate() error {
 		return fmt.Errorf("amount must be greater than 0")
 	}
 
-	// Check if date is not in the past
-	today := time.Now().Truncate(24 * time.Hour)
-	expenseDate := parsedDate.Truncate(24 * time.Hour)
+	if len(e.Description) > 255 {
+		return fmt.Errorf("description must not exceed 255 characters")
+	}
 
-	if expenseDate.Before(today) {
-		return fmt.Errorf("date cannot be in the past")
+	e.Currency = strings.ToUpper(strings.TrimSpace(e.Currency))
+	if e.Currency != "" && len(e.Currency) != 3 {
+		return fmt.Errorf("currency must be a 3-letter ISO code")
+	}
+
+	switch e.Kind {
+	case "", "expense", "refund", "income":
+	default:
+		return fmt.Errorf("kind must be one of: expense, refund, income")
 	}
 
 	return nil
@@ -52,11 +83,17 @@ func (e *ExpenseRequestDTO) Validate() error {
 
 // ParseDate parses the date string into time.Time
 func (e *ExpenseRequestDTO) ParseDate() (time.Time, error) {
+	return parseDate(e.Date)
+}
+
+// parseDate accepts dd-mm-yyyy with yyyy-mm-dd as a fallback. Dates are kept as
+// midnight UTC whatever the user's timezone, so a date means the same day to everyone.
+func parseDate(value string) (time.Time, error) {
 	// Try parsing dd-mm-yyyy format
-	t, err := time.Parse("02-01-2006", e.Date)
+	t, err := time.Parse("02-01-2006", value)
 	if err != nil {
 		// Try ISO format as fallback
-		t, err = time.Parse("2006-01-02", e.Date)
+		t, err = time.Parse("2006-01-02", value)
 		if err != nil {
 			return time.Time{}, fmt.Errorf("invalid date format, expected dd-mm-yyyy or yyyy-mm-dd")
 		}
@@ -64,12 +101,37 @@ func (e *ExpenseRequestDTO) ParseDate() (time.Time, error) {
 	return t, nil
 }
 
+// ParseOptionalDate parses a query date, returning the zero time when empty
+func ParseOptionalDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return parseDate(value)
+}
+
 // ExpenseResponseDTO represents the expense data sent to the client.
 type ExpenseResponseDTO struct {
+	ID                 int                        `json:"id"`
+	CategoryID         int                        `json:"category_id"`
+	CategoryName       string                     `json:"category_name,omitempty"` // Category name resolved from relationship
+	AccountID          int                        `json:"account_id,omitempty"`
+	Kind               string                     `json:"kind"`
+	Amount             float64                    `json:"amount"`
+	Currency           string                     `json:"currency,omitempty"`
+	Description        string                     `json:"description"`
+	Date               string                     `json:"date"` // Format: yyyy-mm-dd
+	Splits             []ExpenseSplitResponseDTO  `json:"splits,omitempty"`
+	Sharing            *ExpenseSharingResponseDTO `json:"sharing,omitempty"`
+	ExternalID         string                     `json:"external_id,omitempty"`
+	Tags               []TagSummaryDTO            `json:"tags,omitempty"`
+	PossibleDuplicates []DuplicateExpenseDTO      `json:"possible_duplicates,omitempty"` // On creation, existing expenses this one may duplicate
+}
+
+// ExpenseSplitResponseDTO is a split line of an expense.
+type ExpenseSplitResponseDTO struct {
 	ID           int     `json:"id"`
 	CategoryID   int     `json:"category_id"`
-	CategoryName string  `json:"category_name,omitempty"` // Category name resolved from relationship
+	CategoryName string  `json:"category_name,omitempty"`
 	Amount       float64 `json:"amount"`
-	Description  string  `json:"description"`
-	Date         string  `json:"date"` // Format: yyyy-mm-dd
+	Description  string  `json:"description,omitempty"`
 }
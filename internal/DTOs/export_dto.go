@@ -0,0 +1,27 @@
+package dto
+
+// ExpenseFilterDTO selects the expenses an export or report includes. Every field is optional.
+type ExpenseFilterDTO struct {
+	Description string `form:"description"`                             // Case-insensitive substring match
+	CategoryID  int    `form:"category_id" binding:"omitempty,min=1"`   // Also matches split lines in the category
+	AccountID   int    `form:"account_id" binding:"omitempty,min=1"`    // Account the expenses were paid from
+	TagID       int    `form:"tag_id" binding:"omitempty,min=1"`        // Expenses carrying the tag
+	From        string `form:"from" example:"01-01-2025" format:"date"` // First day (dd-mm-yyyy or yyyy-mm-dd)
+	To          string `form:"to" example:"31-01-2025" format:"date"`   // Last day (dd-mm-yyyy or yyyy-mm-dd)
+}
+
+// JournalExportDTO selects expenses and describes how to post them in a plain-text
+// accounting journal.
+type JournalExportDTO struct {
+	ExpenseFilterDTO
+	Format         string   `form:"format" binding:"required,oneof=ledger hledger beancount"`
+	FundingAccount string   `form:"funding_account" example:"Assets:Bank:Checking"`      // Account every expense is paid from; defaults to each expense's own account
+	Currency       string   `form:"currency" binding:"omitempty,len=3" example:"INR"`    // Commodity for expenses without one; required by beancount
+	Accounts       []string `form:"account" example:"Groceries=Expenses:Food:Groceries"` // Category=Account mappings, repeatable
+}
+
+// ExpenseExportDTO selects expenses for a CSV, XLSX or NDJSON export.
+type ExpenseExportDTO struct {
+	ExpenseFilterDTO
+	Format string `form:"format" binding:"omitempty,oneof=csv xlsx ndjson"` // Defaults to csv
+}
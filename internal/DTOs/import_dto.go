@@ -0,0 +1,56 @@
+package dto
+
+// ImportOptionsDTO configures how an uploaded file is turned into expenses.
+// Column mapping, delimiter and header settings only apply to CSV.
+type ImportOptionsDTO struct {
+	Columns           ImportColumnsDTO `json:"columns"`
+	CategoryBy        string           `json:"category_by" example:"name"`                   // name (default) or id
+	DateFormats       []string         `json:"date_formats" example:"dd/mm/yyyy,yyyy-mm-dd"` // Tried in order
+	DecimalSeparator  string           `json:"decimal_separator" example:"."`                // "." (default) or ","
+	Delimiter         string           `json:"delimiter" example:","`                        // ",", ";", "|" or "\t"
+	HasHeader         *bool            `json:"has_header"`                                   // Defaults to true
+	DefaultCategoryID int              `json:"default_category_id"`                          // Used when a row has no category and no rule assigns one
+	AccountID         int              `json:"account_id"`                                   // Account every imported expense is paid from
+	CreateCategories  bool             `json:"create_categories"`                            // Create categories named in the file that do not exist yet
+	AllowDuplicates   bool             `json:"allow_duplicates"`                             // Import rows that look like duplicates of existing expenses
+}
+
+// ImportColumnsDTO maps expense fields to CSV columns, by header name or zero-based index.
+type ImportColumnsDTO struct {
+	Date        string `json:"date" example:"Date"`
+	Amount      string `json:"amount" example:"Amount"`
+	Description string `json:"description" example:"Description"`
+	Category    string `json:"category" example:"Category"`
+}
+
+// ImportRowResultDTO reports what happened to one row of the file.
+type ImportRowResultDTO struct {
+	Line           int                   `json:"line"`
+	Status         string                `json:"status"` // valid, imported, skipped or failed
+	Error          string                `json:"error,omitempty"`
+	Date           string                `json:"date,omitempty"` // Format: yyyy-mm-dd
+	Amount         float64               `json:"amount,omitempty"`
+	Currency       string                `json:"currency,omitempty"`
+	Description    string                `json:"description,omitempty"`
+	CategoryID     int                   `json:"category_id,omitempty"`
+	TagIDs         []int                 `json:"tag_ids,omitempty"`
+	RuleIDs        []int                 `json:"rule_ids,omitempty"` // Rules that matched the row
+	Kind           string                `json:"kind,omitempty"`
+	ExternalID     string                `json:"external_id,omitempty"`
+	Duplicates     []DuplicateExpenseDTO `json:"duplicates,omitempty"`      // Existing expenses the row may duplicate
+	DuplicateLines []int                 `json:"duplicate_lines,omitempty"` // Earlier lines of the file the row may duplicate
+	Expense        *ExpenseResponseDTO   `json:"expense,omitempty"`
+}
+
+// ImportResultDTO summarises an import or a dry run.
+type ImportResultDTO struct {
+	Format        string               `json:"format"`
+	DryRun        bool                 `json:"dry_run"`
+	Total         int                  `json:"total"`
+	Valid         int                  `json:"valid"`
+	Imported      int                  `json:"imported"`
+	Skipped       int                  `json:"skipped"`
+	Failed        int                  `json:"failed"`
+	NewCategories []string             `json:"new_categories,omitempty"` // Created by the import, or that a dry run would create
+	Rows          []ImportRowResultDTO `json:"rows"`
+}
@@ -0,0 +1,73 @@
+package dto
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LedgerRequestDTO is used to create or rename a ledger.
+type LedgerRequestDTO struct {
+	Name string `json:"name" binding:"required,min=1,max=100" example:"Household"`
+}
+
+// Validate performs additional business logic validation
+func (l *LedgerRequestDTO) Validate() error {
+	l.Name = strings.Join(strings.Fields(l.Name), " ")
+	if l.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	return nil
+}
+
+// LedgerResponseDTO represents a ledger returned in API responses, with the role
+// the signed-in user has in it.
+type LedgerResponseDTO struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Personal  bool      `json:"personal"`
+	Role      string    `json:"role" example:"owner"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LedgerMemberRequestDTO is used to change the role of a member.
+type LedgerMemberRequestDTO struct {
+	Role string `json:"role" binding:"required,oneof=owner editor viewer" example:"editor"`
+}
+
+// LedgerMemberResponseDTO represents a member of a ledger.
+type LedgerMemberResponseDTO struct {
+	UserID   int       `json:"user_id"`
+	Email    string    `json:"email"`
+	Name     string    `json:"name,omitempty"`
+	Role     string    `json:"role" example:"editor"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// LedgerInviteRequestDTO is used to invite someone to a ledger.
+type LedgerInviteRequestDTO struct {
+	Role           string `json:"role" binding:"required,oneof=editor viewer" example:"editor"`
+	Email          string `json:"email" binding:"omitempty,email,max=255" example:"sam@example.com"` // Only this user may accept; anyone holding the token when empty
+	ExpiresInHours int    `json:"expires_in_hours" binding:"omitempty,min=1,max=720" example:"168"`  // Defaults to 7 days
+}
+
+// LedgerInviteResponseDTO represents an invitation. The token is only returned
+// when the invitation is created.
+type LedgerInviteResponseDTO struct {
+	ID         int        `json:"id"`
+	LedgerID   int        `json:"ledger_id"`
+	Token      string     `json:"token,omitempty"`
+	Role       string     `json:"role"`
+	Email      string     `json:"email,omitempty"`
+	Status     string     `json:"status" example:"pending"` // pending, accepted or expired
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedBy *int       `json:"accepted_by,omitempty"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// LedgerInviteAcceptDTO is used to join a ledger with an invitation token.
+type LedgerInviteAcceptDTO struct {
+	Token string `json:"token" binding:"required"`
+}
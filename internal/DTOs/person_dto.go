@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PersonRequestDTO is used to create or update a participant of shared expenses.
+type PersonRequestDTO struct {
+	Name  string `json:"name" binding:"required,min=2,max=50"`
+	Email string `json:"email" binding:"omitempty,email,max=255"`
+}
+
+// Validate performs additional business logic validation
+func (p *PersonRequestDTO) Validate() error {
+	p.Name = strings.TrimSpace(p.Name)
+	if len(p.Name) < 2 {
+		return fmt.Errorf("name must be at least 2 characters")
+	}
+	p.Email = strings.TrimSpace(p.Email)
+	return nil
+}
+
+// PersonResponseDTO represents a participant returned in API responses.
+type PersonResponseDTO struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
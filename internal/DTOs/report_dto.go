@@ -0,0 +1,25 @@
+package dto
+
+// CategoryTotalDTO is the spending attributed to one category.
+type CategoryTotalDTO struct {
+	CategoryID   int     `json:"category_id"`
+	CategoryName string  `json:"category_name,omitempty"`
+	Total        float64 `json:"total"`
+	Count        int     `json:"count"` // Number of expenses or split lines counted
+}
+
+// CategoryReportDTO breaks spending over a period down by category.
+type CategoryReportDTO struct {
+	From       string             `json:"from,omitempty"` // Format: yyyy-mm-dd
+	To         string             `json:"to,omitempty"`   // Format: yyyy-mm-dd
+	Total      float64            `json:"total"`
+	Categories []CategoryTotalDTO `json:"categories"`
+}
+
+// ExpenseReportPDFDTO selects the expenses of a PDF expense report and how it is printed.
+type ExpenseReportPDFDTO struct {
+	ExpenseFilterDTO
+	Title    string   `form:"title" binding:"max=100" example:"Travel expenses Q1"` // Defaults to "Expense report"
+	Receipts bool     `form:"receipts"`                                             // Append thumbnails of the receipts
+	SignOff  []string `form:"sign_off" example:"Approved by"`                       // Roles given a signature line, repeatable; defaults to Prepared by and Approved by
+}
@@ -0,0 +1,97 @@
+package dto
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// RuleRequestDTO is used to create, update or test a categorisation rule. At least
+// one condition (pattern, amount range or account) and one action (category, tags
+// or description) are required.
+type RuleRequestDTO struct {
+	Name           string   `json:"name" binding:"required,min=2,max=100" example:"Uber rides"`
+	Priority       int      `json:"priority"`                                                               // Lower runs first; defaults to 0
+	Enabled        *bool    `json:"enabled"`                                                                // Defaults to true
+	MatchType      string   `json:"match_type" binding:"omitempty,oneof=contains regex" example:"contains"` // Defaults to contains
+	Pattern        string   `json:"pattern" binding:"max=255" example:"uber"`                               // Matched against the description, ignoring case
+	MinAmount      *float64 `json:"min_amount" binding:"omitempty,gte=0"`
+	MaxAmount      *float64 `json:"max_amount" binding:"omitempty,gte=0"`
+	AccountID      int      `json:"account_id" binding:"omitempty,min=1"` // Only expenses paid from this account
+	CategoryID     int      `json:"category_id" binding:"omitempty,min=1"`
+	TagIDs         []int    `json:"tag_ids" binding:"omitempty,dive,min=1"`
+	SetDescription string   `json:"set_description" binding:"max=255" example:"Uber"` // Regex rules may refer to groups as $1 or ${name}
+}
+
+// Validate performs additional business logic validation
+func (r *RuleRequestDTO) Validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+	if len(r.Name) < 2 {
+		return fmt.Errorf("name must be at least 2 characters")
+	}
+	r.Pattern = strings.TrimSpace(r.Pattern)
+	r.SetDescription = strings.TrimSpace(r.SetDescription)
+
+	if r.MatchType == "" {
+		r.MatchType = "contains"
+	}
+	if r.MatchType == "regex" {
+		if r.Pattern == "" {
+			return fmt.Errorf("pattern is required for regex rules")
+		}
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return fmt.Errorf("pattern is not a valid regular expression: %v", err)
+		}
+	}
+
+	if r.MinAmount != nil && r.MaxAmount != nil && *r.MaxAmount < *r.MinAmount {
+		return fmt.Errorf("max_amount must not be less than min_amount")
+	}
+	if r.Pattern == "" && r.MinAmount == nil && r.MaxAmount == nil && r.AccountID == 0 {
+		return fmt.Errorf("a rule needs a pattern, an amount range or an account to match on")
+	}
+	if r.CategoryID == 0 && len(r.TagIDs) == 0 && r.SetDescription == "" {
+		return fmt.Errorf("a rule needs a category_id, tag_ids or set_description to apply")
+	}
+	return nil
+}
+
+// RuleResponseDTO represents a rule returned in API responses.
+type RuleResponseDTO struct {
+	ID             int             `json:"id"`
+	Name           string          `json:"name"`
+	Priority       int             `json:"priority"`
+	Enabled        bool            `json:"enabled"`
+	MatchType      string          `json:"match_type"`
+	Pattern        string          `json:"pattern,omitempty"`
+	MinAmount      *float64        `json:"min_amount,omitempty"`
+	MaxAmount      *float64        `json:"max_amount,omitempty"`
+	AccountID      int             `json:"account_id,omitempty"`
+	CategoryID     int             `json:"category_id,omitempty"`
+	CategoryName   string          `json:"category_name,omitempty"`
+	Tags           []TagSummaryDTO `json:"tags,omitempty"`
+	SetDescription string          `json:"set_description,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// RuleTestResultDTO lists the existing expenses a rule would match.
+type RuleTestResultDTO struct {
+	Checked int            `json:"checked"` // Expenses the rule was tried against
+	Matched int            `json:"matched"`
+	Matches []RuleMatchDTO `json:"matches"` // Up to limit, in date order
+}
+
+// RuleMatchDTO is an existing expense matched by a rule and what the rule would make of it.
+type RuleMatchDTO struct {
+	ExpenseID       int     `json:"expense_id"`
+	Date            string  `json:"date"` // Format: yyyy-mm-dd
+	Amount          float64 `json:"amount"`
+	Description     string  `json:"description"`
+	CategoryID      int     `json:"category_id"`
+	CategoryName    string  `json:"category_name,omitempty"`
+	NewCategoryID   int     `json:"new_category_id,omitempty"`
+	NewCategoryName string  `json:"new_category_name,omitempty"`
+	NewDescription  string  `json:"new_description,omitempty"` // Set when the rule rewrites the description
+}
@@ -0,0 +1,87 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+)
+
+// ExpenseSharingDTO describes who paid a shared expense and how it is divided.
+type ExpenseSharingDTO struct {
+	PaidByID     int                     `json:"paid_by_id" binding:"required,min=1"`
+	Mode         string                  `json:"mode" binding:"required,oneof=equal percentage exact" example:"equal"`
+	Participants []ExpenseParticipantDTO `json:"participants" binding:"required,min=1,dive"`
+}
+
+// ExpenseParticipantDTO is one person sharing an expense.
+type ExpenseParticipantDTO struct {
+	PersonID int     `json:"person_id" binding:"required,min=1"`
+	Share    float64 `json:"share" binding:"gte=0"` // Percentage or exact amount; ignored for equal splits
+}
+
+// ExpenseSharingResponseDTO is the resolved division of a shared expense.
+type ExpenseSharingResponseDTO struct {
+	PaidByID   int                       `json:"paid_by_id"`
+	PaidByName string                    `json:"paid_by_name,omitempty"`
+	Mode       string                    `json:"mode"`
+	Shares     []ExpenseShareResponseDTO `json:"shares"`
+}
+
+// ExpenseShareResponseDTO is the amount one participant owes for a shared expense.
+type ExpenseShareResponseDTO struct {
+	PersonID   int     `json:"person_id"`
+	PersonName string  `json:"person_name,omitempty"`
+	Share      float64 `json:"share,omitempty"` // Percentage or exact amount as entered
+	Amount     float64 `json:"amount"`
+}
+
+// PersonBalanceDTO is where a person stands across all shared expenses and settlements.
+type PersonBalanceDTO struct {
+	PersonID   int     `json:"person_id"`
+	PersonName string  `json:"person_name"`
+	Paid       float64 `json:"paid"`    // Total paid for shared expenses
+	Owed       float64 `json:"owed"`    // Total of their shares
+	Balance    float64 `json:"balance"` // Positive: is owed money, negative: owes money
+}
+
+// SettlementRequestDTO records a repayment between two people.
+type SettlementRequestDTO struct {
+	FromPersonID int     `json:"from_person_id" binding:"required,min=1"`
+	ToPersonID   int     `json:"to_person_id" binding:"required,min=1"`
+	Amount       float64 `json:"amount" binding:"required,gt=0"`
+	Note         string  `json:"note" binding:"max=255"`
+	Date         string  `json:"date" example:"12-12-2025" format:"date"` // Format: dd-mm-yyyy or yyyy-mm-dd, defaults to today
+}
+
+// Validate performs additional business logic validation
+func (s *SettlementRequestDTO) Validate() error {
+	if s.FromPersonID == s.ToPersonID {
+		return fmt.Errorf("a person cannot settle with themselves")
+	}
+	if s.Date != "" {
+		if _, err := parseDate(s.Date); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseDate parses the date string into time.Time, defaulting to today, the
+// requesting user's current date
+func (s *SettlementRequestDTO) ParseDate(today time.Time) (time.Time, error) {
+	if s.Date == "" {
+		return today, nil
+	}
+	return parseDate(s.Date)
+}
+
+// SettlementResponseDTO is a recorded or suggested repayment.
+type SettlementResponseDTO struct {
+	ID             int     `json:"id,omitempty"`
+	FromPersonID   int     `json:"from_person_id"`
+	FromPersonName string  `json:"from_person_name,omitempty"`
+	ToPersonID     int     `json:"to_person_id"`
+	ToPersonName   string  `json:"to_person_name,omitempty"`
+	Amount         float64 `json:"amount"`
+	Note           string  `json:"note,omitempty"`
+	Date           string  `json:"date,omitempty"` // Format: yyyy-mm-dd
+}
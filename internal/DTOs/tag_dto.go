@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TagRequestDTO is used to create or rename a tag.
+type TagRequestDTO struct {
+	Name string `json:"name" binding:"required,min=1,max=50" example:"offsite-2025"`
+}
+
+// Validate performs additional business logic validation
+func (t *TagRequestDTO) Validate() error {
+	t.Name = strings.Join(strings.Fields(t.Name), " ")
+	if t.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(t.Name) > 50 {
+		return fmt.Errorf("name must not exceed 50 characters")
+	}
+	return nil
+}
+
+// TagResponseDTO represents a tag returned in API responses.
+type TagResponseDTO struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TagSummaryDTO is a tag as listed on an expense or rule.
+type TagSummaryDTO struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
@@ -0,0 +1,44 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+)
+
+// TransferRequestDTO moves money between two accounts.
+type TransferRequestDTO struct {
+	FromAccountID int     `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int     `json:"to_account_id" binding:"required,min=1"`
+	Amount        float64 `json:"amount" binding:"required,gt=0"`
+	Description   string  `json:"description" binding:"max=255"`
+	Date          string  `json:"date" binding:"required" example:"12-12-2025" format:"date"` // Format: dd-mm-yyyy or yyyy-mm-dd
+}
+
+// Validate performs additional business logic validation
+func (t *TransferRequestDTO) Validate() error {
+	if t.FromAccountID == t.ToAccountID {
+		return fmt.Errorf("cannot transfer to the same account")
+	}
+	if t.Amount <= 0 {
+		return fmt.Errorf("amount must be greater than 0")
+	}
+	if _, err := parseDate(t.Date); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ParseDate parses the date string into time.Time
+func (t *TransferRequestDTO) ParseDate() (time.Time, error) {
+	return parseDate(t.Date)
+}
+
+// TransferResponseDTO represents a transfer returned in API responses.
+type TransferResponseDTO struct {
+	ID            int     `json:"id"`
+	FromAccountID int     `json:"from_account_id"`
+	ToAccountID   int     `json:"to_account_id"`
+	Amount        float64 `json:"amount"`
+	Description   string  `json:"description"`
+	Date          string  `json:"date"` // Format: yyyy-mm-dd
+}
@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"time"
+)
+
+// TwoFactorStatusDTO tells whether sign-ins of the user ask for a TOTP code.
+type TwoFactorStatusDTO struct {
+	Enabled                bool       `json:"enabled"`
+	Pending                bool       `json:"pending"` // Enrolment started but not confirmed with a code yet
+	ConfirmedAt            *time.Time `json:"confirmed_at,omitempty"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+}
+
+// TwoFactorEnrollmentDTO is a new TOTP secret, to be added to an authenticator app
+// by scanning a QR code of the provisioning URI or typing in the secret.
+type TwoFactorEnrollmentDTO struct {
+	Secret          string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"` // Base32
+	ProvisioningURI string `json:"provisioning_uri" example:"otpauth://totp/goExpenseTracker:alex@example.com?algorithm=SHA1&digits=6&issuer=goExpenseTracker&period=30&secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	Digits          int    `json:"digits" example:"6"`
+	Period          int    `json:"period" example:"30"` // Seconds each code is valid for
+}
+
+// TwoFactorCodeRequestDTO carries a code from the authenticator app, or a recovery
+// code where one is accepted.
+type TwoFactorCodeRequestDTO struct {
+	Code string `json:"code" binding:"required,max=32" example:"123456"`
+}
+
+// TwoFactorLoginRequestDTO finishes a sign-in that asked for a second factor.
+type TwoFactorLoginRequestDTO struct {
+	Challenge string `json:"challenge" binding:"required"`
+	Code      string `json:"code" binding:"required,max=32" example:"123456"` // TOTP code or recovery code
+}
+
+// TwoFactorChallengeDTO is the 401 answer to a correct password, or a sign-in
+// through an identity provider, of a user with two-factor sign-in: the challenge
+// is sent with a code to POST /v1/auth/2fa/verify.
+type TwoFactorChallengeDTO struct {
+	Error             string    `json:"error"`
+	TwoFactorRequired bool      `json:"two_factor_required" example:"true"`
+	Challenge         string    `json:"challenge"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+// RecoveryCodesDTO lists fresh recovery codes, each of which signs in once in place
+// of a TOTP code. They are shown only this once.
+type RecoveryCodesDTO struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"k7f2m-q9x4c,3hv8w-pn6rt"`
+}
@@ -0,0 +1,149 @@
+package exporters
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Journal formats understood by WriteJournal
+const (
+	Ledger    = "ledger"
+	HLedger   = "hledger"
+	Beancount = "beancount"
+)
+
+// JournalTransaction is one dated entry of a plain-text accounting journal
+type JournalTransaction struct {
+	Date        time.Time
+	Description string
+	Postings    []JournalPosting // Must sum to zero per currency
+}
+
+// JournalPosting moves an amount into (positive) or out of (negative) an account
+type JournalPosting struct {
+	Account  string
+	Amount   float64
+	Currency string // Commodity; ledger and hledger allow it to be empty
+	Comment  string
+}
+
+// WriteJournal renders the transactions as a ledger, hledger or beancount journal.
+// Beancount needs every account opened before use, so an open directive is
+// written for each account on the day of its first transaction.
+func WriteJournal(w io.Writer, format string, transactions []JournalTransaction) error {
+	out := bufio.NewWriter(w)
+
+	if format == Beancount {
+		writeOpenDirectives(out, transactions)
+	}
+
+	for i, txn := range transactions {
+		if i > 0 || format == Beancount {
+			out.WriteString("\n")
+		}
+
+		description := strings.Join(strings.Fields(txn.Description), " ")
+		switch format {
+		case Beancount:
+			fmt.Fprintf(out, "%s * %s\n", txn.Date.Format("2006-01-02"), strconv.Quote(description))
+		case HLedger:
+			fmt.Fprintf(out, "%s %s\n", txn.Date.Format("2006-01-02"), description)
+		default:
+			fmt.Fprintf(out, "%s %s\n", txn.Date.Format("2006/01/02"), description)
+		}
+
+		for _, posting := range txn.Postings {
+			amount := strconv.FormatFloat(posting.Amount, 'f', 2, 64)
+			if posting.Currency != "" {
+				amount += " " + posting.Currency
+			}
+			// Two spaces end an account name in ledger and hledger
+			line := fmt.Sprintf("    %-40s  %12s", posting.Account, amount)
+			if posting.Comment != "" {
+				line += "  ; " + strings.Join(strings.Fields(posting.Comment), " ")
+			}
+			out.WriteString(line + "\n")
+		}
+	}
+
+	return out.Flush()
+}
+
+// writeOpenDirectives opens each account on the date it is first used
+func writeOpenDirectives(out *bufio.Writer, transactions []JournalTransaction) {
+	opened := make(map[string]time.Time)
+	for _, txn := range transactions {
+		for _, posting := range txn.Postings {
+			if first, ok := opened[posting.Account]; !ok || txn.Date.Before(first) {
+				opened[posting.Account] = txn.Date
+			}
+		}
+	}
+
+	accounts := make([]string, 0, len(opened))
+	for account := range opened {
+		accounts = append(accounts, account)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		if !opened[accounts[i]].Equal(opened[accounts[j]]) {
+			return opened[accounts[i]].Before(opened[accounts[j]])
+		}
+		return accounts[i] < accounts[j]
+	})
+
+	for _, account := range accounts {
+		fmt.Fprintf(out, "%s open %s\n", opened[account].Format("2006-01-02"), account)
+	}
+}
+
+// JournalAccount cleans an account path such as "Expenses:Food & Drink" for the
+// given format. Ledger and hledger accept most characters but not runs of spaces;
+// beancount components must start with a capital letter or digit and contain
+// only letters, digits and dashes.
+func JournalAccount(format string, path string) string {
+	components := strings.Split(path, ":")
+	cleaned := make([]string, 0, len(components))
+	for _, component := range components {
+		component = strings.Join(strings.Fields(component), " ")
+		if format == Beancount {
+			component = beancountComponent(component)
+		}
+		if component != "" {
+			cleaned = append(cleaned, component)
+		}
+	}
+	return strings.Join(cleaned, ":")
+}
+
+func beancountComponent(component string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range component {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+
+	result := strings.TrimRight(b.String(), "-")
+	if result == "" {
+		return ""
+	}
+	runes := []rune(result)
+	if !unicode.IsDigit(runes[0]) {
+		runes[0] = unicode.ToUpper(runes[0])
+	}
+	if !unicode.IsUpper(runes[0]) && !unicode.IsDigit(runes[0]) {
+		return "X" + string(runes)
+	}
+	return string(runes)
+}
@@ -0,0 +1,106 @@
+package exporters
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files under testdata")
+
+// checkGolden compares an export with its golden file under testdata, rewriting
+// the file instead when the tests run with -update
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+// sampleJournal is a split expense, a plain expense and a refund, out of date
+// order, with accounts cleaned for the format
+func sampleJournal(format string) []JournalTransaction {
+	account := func(path string) string { return JournalAccount(format, path) }
+	return []JournalTransaction{
+		{
+			Date:        day(2025, 3, 2),
+			Description: "Groceries  at\nthe market",
+			Postings: []JournalPosting{
+				{Account: account("Expenses:Food & Drink"), Amount: 42.5, Currency: "EUR"},
+				{Account: account("Assets:Checking"), Amount: -42.5, Currency: "EUR"},
+			},
+		},
+		{
+			Date:        day(2025, 3, 1),
+			Description: `Dinner "Chez Paul"`,
+			Postings: []JournalPosting{
+				{Account: account("Expenses:Food & Drink"), Amount: 20.1, Currency: "EUR", Comment: "main\ncourse"},
+				{Account: account("Expenses:gifts"), Amount: 9.9, Currency: "EUR", Comment: "flowers"},
+				{Account: account("Liabilities:Credit  card"), Amount: -30, Currency: "EUR"},
+			},
+		},
+		{
+			Date:        day(2025, 3, 3),
+			Description: "Refund",
+			Postings: []JournalPosting{
+				{Account: account("Assets:Checking"), Amount: 12, Currency: "EUR"},
+				{Account: account("Income:Refunds"), Amount: -12, Currency: "EUR"},
+			},
+		},
+	}
+}
+
+func TestWriteJournal(t *testing.T) {
+	for _, format := range []string{Ledger, HLedger, Beancount} {
+		t.Run(format, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := WriteJournal(&out, format, sampleJournal(format)); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			checkGolden(t, "journal."+format+".golden", out.Bytes())
+		})
+	}
+}
+
+func TestJournalAccount(t *testing.T) {
+	cases := []struct {
+		format string
+		path   string
+		want   string
+	}{
+		{Ledger, "Expenses:Food & Drink", "Expenses:Food & Drink"},
+		{Ledger, "Expenses:  Food   and drink ", "Expenses:Food and drink"},
+		{Ledger, "Expenses::Travel", "Expenses:Travel"},
+		{HLedger, "Liabilities:Credit  card", "Liabilities:Credit card"},
+		{Beancount, "Expenses:Food & Drink", "Expenses:Food-Drink"},
+		{Beancount, "Expenses:gifts", "Expenses:Gifts"},
+		{Beancount, "Expenses:2025 trips", "Expenses:2025-trips"},
+		{Beancount, "Expenses:Café", "Expenses:Café"},
+		{Beancount, "Expenses:ébène", "Expenses:Ébène"},
+		{Beancount, "Expenses:& more", "Expenses:More"},
+		{Beancount, "Expenses:!?", "Expenses"},
+	}
+	for _, c := range cases {
+		if got := JournalAccount(c.format, c.path); got != c.want {
+			t.Errorf("JournalAccount(%q, %q) = %q, want %q", c.format, c.path, got, c.want)
+		}
+	}
+}
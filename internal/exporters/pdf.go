@@ -0,0 +1,383 @@
+package exporters
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // Receipt decoders for thumbnails
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// ExpenseReport is everything printed in a PDF expense report
+type ExpenseReport struct {
+	Title       string
+	Period      string   // Human-readable date range
+	Selection   []string // Filters applied, e.g. "Category: Travel"
+	GeneratedAt time.Time
+	Sections    []ReportSection // One per category, in print order
+	Total       float64
+	Receipts    []ReportReceipt // Printed after the totals when not empty
+	SignOff     []string        // Roles given a signature line, e.g. "Approved by"
+}
+
+// ReportSection lists the lines attributed to one category
+type ReportSection struct {
+	Category string
+	Lines    []ReportLine
+	Subtotal float64
+}
+
+// ReportLine is an expense, or one split line of it, within a section
+type ReportLine struct {
+	ExpenseID   int
+	Date        time.Time
+	Description string
+	Tags        string
+	Amount      float64 // Negative for refunds
+	Currency    string
+}
+
+// ReportReceipt is a receipt of a reported expense. Thumbnail holds a JPEG
+// preview; receipts that cannot be previewed (PDFs, WebP) only get a caption.
+type ReportReceipt struct {
+	ExpenseID int
+	Date      time.Time
+	FileName  string
+	Thumbnail []byte
+}
+
+// Page layout in millimetres (A4 portrait)
+const (
+	pdfMargin       = 15.0
+	pdfContentWidth = 210 - 2*pdfMargin
+	pdfRowHeight    = 6.0
+	pdfThumbWidth   = 56.0
+	pdfThumbHeight  = 64.0
+	pdfThumbColumns = 3
+)
+
+// pdfColumns are the widths of the date, description, tags and amount columns
+var pdfColumns = [4]float64{22, 94, 36, 28}
+
+// WriteExpenseReport renders the report as a PDF: a summary of subtotals, the
+// lines of each category, the grand total, signature lines and finally the
+// receipt thumbnails. Text is printed with the core Helvetica font, so characters
+// outside Windows-1252 are replaced.
+func WriteExpenseReport(w io.Writer, report ExpenseReport) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin+5)
+	pdf.SetTitle(report.Title, true)
+	pdf.SetCreator("goExpenseTracker", true)
+	pdf.AliasNbPages("")
+
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	r := &pdfReport{pdf: pdf, tr: tr}
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pdfMargin)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetTextColor(110, 110, 110)
+		pdf.CellFormat(pdfContentWidth/2, 5, tr(report.Title), "", 0, "L", false, 0, "")
+		pdf.CellFormat(pdfContentWidth/2, 5, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	})
+
+	pdf.AddPage()
+	r.header(report)
+	r.summary(report)
+	for _, section := range report.Sections {
+		r.section(section)
+	}
+	r.total(report.Total)
+	r.signOff(report.SignOff)
+	if len(report.Receipts) > 0 {
+		r.receipts(report.Receipts)
+	}
+
+	return pdf.Output(w)
+}
+
+type pdfReport struct {
+	pdf *fpdf.Fpdf
+	tr  func(string) string
+}
+
+func (r *pdfReport) header(report ExpenseReport) {
+	pdf := r.pdf
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(pdfContentWidth, 9, r.tr(report.Title), "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(pdfContentWidth, 5, r.tr("Period: "+report.Period), "", 1, "L", false, 0, "")
+	for _, line := range report.Selection {
+		pdf.CellFormat(pdfContentWidth, 5, r.tr(line), "", 1, "L", false, 0, "")
+	}
+	pdf.SetTextColor(110, 110, 110)
+	pdf.CellFormat(pdfContentWidth, 5, "Generated "+report.GeneratedAt.Format("02 Jan 2006 15:04 MST"), "", 1, "L", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Ln(4)
+}
+
+// summary lists the subtotal of each category ahead of the detail
+func (r *pdfReport) summary(report ExpenseReport) {
+	pdf := r.pdf
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(pdfContentWidth, 7, "Summary", "", 1, "L", false, 0, "")
+
+	labelWidth := pdfContentWidth - 2*pdfColumns[3]
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetFillColor(230, 230, 230)
+	pdf.CellFormat(labelWidth, pdfRowHeight, "Category", "B", 0, "L", true, 0, "")
+	pdf.CellFormat(pdfColumns[3], pdfRowHeight, "Lines", "B", 0, "R", true, 0, "")
+	pdf.CellFormat(pdfColumns[3], pdfRowHeight, "Subtotal", "B", 1, "R", true, 0, "")
+
+	pdf.SetFont("Helvetica", "", 9)
+	if len(report.Sections) == 0 {
+		pdf.CellFormat(pdfContentWidth, pdfRowHeight, "No expenses match this selection.", "", 1, "L", false, 0, "")
+	}
+	for _, section := range report.Sections {
+		pdf.CellFormat(labelWidth, pdfRowHeight, r.fit(section.Category, labelWidth), "", 0, "L", false, 0, "")
+		pdf.CellFormat(pdfColumns[3], pdfRowHeight, fmt.Sprint(len(section.Lines)), "", 0, "R", false, 0, "")
+		pdf.CellFormat(pdfColumns[3], pdfRowHeight, formatAmount(section.Subtotal), "", 1, "R", false, 0, "")
+	}
+
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.CellFormat(labelWidth+pdfColumns[3], pdfRowHeight, "Total", "T", 0, "L", false, 0, "")
+	pdf.CellFormat(pdfColumns[3], pdfRowHeight, formatAmount(report.Total), "T", 1, "R", false, 0, "")
+	pdf.Ln(6)
+}
+
+// section prints a category's lines, repeating the column headings when the
+// table continues on a new page
+func (r *pdfReport) section(section ReportSection) {
+	pdf := r.pdf
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottom := pdf.GetMargins()
+	limit := pageHeight - bottom - 10
+
+	// Keep the heading with at least a couple of lines
+	if pdf.GetY()+7+3*pdfRowHeight > limit {
+		pdf.AddPage()
+	}
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(pdfContentWidth, 7, r.tr(section.Category), "", 1, "L", false, 0, "")
+	r.columnHeadings()
+
+	pdf.SetFont("Helvetica", "", 9)
+	for _, line := range section.Lines {
+		if pdf.GetY()+pdfRowHeight > limit {
+			pdf.AddPage()
+			r.columnHeadings()
+			pdf.SetFont("Helvetica", "", 9)
+		}
+		amount := formatAmount(line.Amount)
+		if line.Currency != "" {
+			amount += " " + line.Currency
+		}
+		pdf.CellFormat(pdfColumns[0], pdfRowHeight, line.Date.Format("2006-01-02"), "", 0, "L", false, 0, "")
+		pdf.CellFormat(pdfColumns[1], pdfRowHeight, r.fit(line.Description, pdfColumns[1]), "", 0, "L", false, 0, "")
+		pdf.CellFormat(pdfColumns[2], pdfRowHeight, r.fit(line.Tags, pdfColumns[2]), "", 0, "L", false, 0, "")
+		pdf.CellFormat(pdfColumns[3], pdfRowHeight, amount, "", 1, "R", false, 0, "")
+	}
+
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.CellFormat(pdfContentWidth-pdfColumns[3], pdfRowHeight, r.tr("Subtotal "+section.Category), "T", 0, "R", false, 0, "")
+	pdf.CellFormat(pdfColumns[3], pdfRowHeight, formatAmount(section.Subtotal), "T", 1, "R", false, 0, "")
+	pdf.Ln(4)
+}
+
+func (r *pdfReport) columnHeadings() {
+	pdf := r.pdf
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetFillColor(230, 230, 230)
+	for i, heading := range []string{"Date", "Description", "Tags", "Amount"} {
+		align := "L"
+		if i == 3 {
+			align = "R"
+		}
+		pdf.CellFormat(pdfColumns[i], pdfRowHeight, heading, "B", 0, align, true, 0, "")
+	}
+	pdf.Ln(-1)
+}
+
+func (r *pdfReport) total(total float64) {
+	pdf := r.pdf
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(pdfContentWidth-pdfColumns[3], 8, "Grand total", "TB", 0, "R", false, 0, "")
+	pdf.CellFormat(pdfColumns[3], 8, formatAmount(total), "TB", 1, "R", false, 0, "")
+	pdf.Ln(10)
+}
+
+// signOff prints a name, signature and date line for each role, all on one page
+func (r *pdfReport) signOff(roles []string) {
+	if len(roles) == 0 {
+		return
+	}
+	pdf := r.pdf
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottom := pdf.GetMargins()
+	if pdf.GetY()+float64(len(roles))*16 > pageHeight-bottom-10 {
+		pdf.AddPage()
+	}
+
+	pdf.SetFont("Helvetica", "", 9)
+	for _, role := range roles {
+		y := pdf.GetY() + 8
+		pdf.SetXY(pdfMargin, y)
+		pdf.CellFormat(30, 5, r.fit(role, 30), "", 0, "L", false, 0, "")
+		for _, field := range []struct {
+			label string
+			x     float64
+			width float64
+		}{{"Name", 45, 50}, {"Signature", 100, 50}, {"Date", 155, 25}} {
+			pdf.Line(field.x, y+5, field.x+field.width, y+5)
+			pdf.SetXY(field.x, y+5)
+			pdf.SetTextColor(110, 110, 110)
+			pdf.CellFormat(field.width, 4, field.label, "", 0, "L", false, 0, "")
+			pdf.SetTextColor(0, 0, 0)
+		}
+		pdf.SetXY(pdfMargin, y+8)
+	}
+}
+
+// receipts lays the receipt thumbnails out in a grid on new pages
+func (r *pdfReport) receipts(receipts []ReportReceipt) {
+	pdf := r.pdf
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(pdfContentWidth, 7, "Receipts", "", 1, "L", false, 0, "")
+	pdf.Ln(2)
+
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottom := pdf.GetMargins()
+	gap := (pdfContentWidth - pdfThumbColumns*pdfThumbWidth) / (pdfThumbColumns - 1)
+	top := pdf.GetY()
+
+	for i, receipt := range receipts {
+		column := i % pdfThumbColumns
+		if column == 0 && i > 0 {
+			top += pdfThumbHeight + 12
+		}
+		if top+pdfThumbHeight+10 > pageHeight-bottom {
+			pdf.AddPage()
+			top = pdf.GetY()
+		}
+		x := pdfMargin + float64(column)*(pdfThumbWidth+gap)
+
+		pdf.SetDrawColor(200, 200, 200)
+		pdf.Rect(x, top, pdfThumbWidth, pdfThumbHeight, "D")
+		pdf.SetDrawColor(0, 0, 0)
+		if !r.thumbnail(fmt.Sprintf("receipt-%d", i), receipt.Thumbnail, x, top) {
+			pdf.SetXY(x, top+pdfThumbHeight/2-3)
+			pdf.SetFont("Helvetica", "I", 8)
+			pdf.SetTextColor(110, 110, 110)
+			pdf.CellFormat(pdfThumbWidth, 6, "No preview", "", 0, "C", false, 0, "")
+			pdf.SetTextColor(0, 0, 0)
+		}
+
+		pdf.SetFont("Helvetica", "", 7)
+		pdf.SetXY(x, top+pdfThumbHeight+1)
+		caption := fmt.Sprintf("#%d  %s", receipt.ExpenseID, receipt.Date.Format("2006-01-02"))
+		pdf.CellFormat(pdfThumbWidth, 4, caption, "", 2, "L", false, 0, "")
+		pdf.CellFormat(pdfThumbWidth, 4, r.fit(receipt.FileName, pdfThumbWidth), "", 0, "L", false, 0, "")
+	}
+}
+
+// thumbnail draws a JPEG centred in its box, scaled to fit, and reports whether it could
+func (r *pdfReport) thumbnail(name string, data []byte, x, y float64) bool {
+	if len(data) == 0 {
+		return false
+	}
+	pdf := r.pdf
+	options := fpdf.ImageOptions{ImageType: "JPG"}
+	info := pdf.RegisterImageOptionsReader(name, options, bytes.NewReader(data))
+	if pdf.Err() {
+		// A broken image must not cost the whole report
+		pdf.ClearError()
+		return false
+	}
+
+	width, height := info.Width(), info.Height()
+	scale := min((pdfThumbWidth-4)/width, (pdfThumbHeight-4)/height)
+	width, height = width*scale, height*scale
+	pdf.ImageOptions(name, x+(pdfThumbWidth-width)/2, y+(pdfThumbHeight-height)/2, width, height, false, options, 0, "")
+	return true
+}
+
+// fit translates text for the core font and shortens it with an ellipsis to fit the width
+func (r *pdfReport) fit(text string, width float64) string {
+	text = r.tr(strings.Join(strings.Fields(text), " "))
+	width -= 2 // Cell padding
+	if r.pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	for len(text) > 0 && r.pdf.GetStringWidth(text+"...") > width {
+		text = text[:len(text)-1]
+	}
+	return text + "..."
+}
+
+// thumbnailSize is the longest side, in pixels, of a receipt preview
+const thumbnailSize = 480
+
+// ReceiptThumbnail decodes a JPEG, PNG or GIF receipt and returns a downscaled
+// JPEG preview, keeping the report small however large the scans are
+func ReceiptThumbnail(r io.Reader) ([]byte, error) {
+	src, _, err := image.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return nil, fmt.Errorf("image is empty")
+	}
+	if longest := max(width, height); longest > thumbnailSize {
+		width = max(1, width*thumbnailSize/longest)
+		height = max(1, height*thumbnailSize/longest)
+	}
+
+	// Average the source pixels behind each thumbnail pixel
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*bounds.Dy()/height)
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := max(x0+1, bounds.Min.X+(x+1)*bounds.Dx()/width)
+
+			var red, green, blue, count uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					// Composite transparent pixels onto white
+					red += uint64(cr + 0xffff - ca)
+					green += uint64(cg + 0xffff - ca)
+					blue += uint64(cb + 0xffff - ca)
+					count++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(red / count),
+				G: uint16(green / count),
+				B: uint16(blue / count),
+				A: 0xffff,
+			})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
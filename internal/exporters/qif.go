@@ -0,0 +1,72 @@
+package exporters
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QIFTransaction is one expense as written to a QIF file
+type QIFTransaction struct {
+	Date     time.Time
+	Amount   float64 // Negative for money going out
+	Payee    string
+	Category string
+	Splits   []QIFSplit
+}
+
+// QIFSplit is one split line of a QIF transaction
+type QIFSplit struct {
+	Category string
+	Amount   float64 // Same sign as the transaction amount
+	Memo     string
+}
+
+// WriteQIF writes the transactions as a single !Type section, for example Bank or CCard.
+// Dates use the month-first mm/dd/yyyy form every QIF reader accepts.
+func WriteQIF(w io.Writer, accountType string, transactions []QIFTransaction) error {
+	out := bufio.NewWriter(w)
+
+	out.WriteString("!Type:" + accountType + "\n")
+	for _, txn := range transactions {
+		out.WriteString("D" + txn.Date.Format("01/02/2006") + "\n")
+		out.WriteString("T" + qifAmount(txn.Amount) + "\n")
+		if txn.Payee != "" {
+			out.WriteString("P" + qifText(txn.Payee) + "\n")
+		}
+		if txn.Category != "" {
+			out.WriteString("L" + qifCategory(txn.Category) + "\n")
+		}
+		for _, split := range txn.Splits {
+			out.WriteString("S" + qifCategory(split.Category) + "\n")
+			if split.Memo != "" {
+				out.WriteString("E" + qifText(split.Memo) + "\n")
+			}
+			out.WriteString("$" + qifAmount(split.Amount) + "\n")
+		}
+		out.WriteString("^\n")
+	}
+
+	return out.Flush()
+}
+
+func qifAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// qifText keeps a value on one line
+func qifText(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// qifCategory escapes characters QIF gives a meaning in category fields:
+// "/" starts a class and a name in brackets is a transfer account
+func qifCategory(name string) string {
+	name = strings.ReplaceAll(qifText(name), "/", "-")
+	if strings.HasPrefix(name, "[") {
+		name = strings.TrimRight(strings.TrimLeft(name, "["), "]")
+	}
+	return name
+}
@@ -0,0 +1,47 @@
+package exporters
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteQIF(t *testing.T) {
+	transactions := []QIFTransaction{
+		{
+			Date:     day(2025, 3, 2),
+			Amount:   -42.5,
+			Payee:    "Corner  shop\nhigh street",
+			Category: "Food",
+		},
+		{
+			Date:   day(2025, 3, 14),
+			Amount: -30,
+			Payee:  "Garden centre",
+			Splits: []QIFSplit{
+				{Category: "Home/Garden", Amount: -20.1, Memo: "seeds\nand bulbs"},
+				{Category: "[Gifts]", Amount: -9.9},
+			},
+		},
+		{
+			Date:     day(2025, 3, 20),
+			Amount:   12,
+			Category: "Refunds",
+		},
+	}
+
+	var out bytes.Buffer
+	if err := WriteQIF(&out, "Bank", transactions); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	checkGolden(t, "expenses.qif.golden", out.Bytes())
+}
+
+func TestWriteQIFEmpty(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteQIF(&out, "CCard", nil); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if got, want := out.String(), "!Type:CCard\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
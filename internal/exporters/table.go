@@ -0,0 +1,243 @@
+package exporters
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Tabular export formats understood by NewRowWriter
+const (
+	CSV    = "csv"
+	XLSX   = "xlsx"
+	NDJSON = "ndjson"
+)
+
+// ExpenseRow is one expense as written to a CSV, XLSX or NDJSON export
+type ExpenseRow struct {
+	ID          int        `json:"id"`
+	Date        time.Time  `json:"-"`
+	Kind        string     `json:"kind"`
+	Amount      float64    `json:"amount"`
+	Currency    string     `json:"currency,omitempty"`
+	CategoryID  int        `json:"category_id"`
+	Category    string     `json:"category"`
+	AccountID   int        `json:"account_id,omitempty"`
+	Account     string     `json:"account,omitempty"`
+	Description string     `json:"description"`
+	ExternalID  string     `json:"external_id,omitempty"`
+	Splits      []SplitRow `json:"splits,omitempty"`
+}
+
+// SplitRow is a split line of an exported expense
+type SplitRow struct {
+	CategoryID  int     `json:"category_id"`
+	Category    string  `json:"category"`
+	Amount      float64 `json:"amount"`
+	Description string  `json:"description,omitempty"`
+}
+
+// expenseColumns is the header row of the CSV and XLSX exports
+var expenseColumns = []string{
+	"id", "date", "kind", "amount", "currency", "category_id", "category",
+	"account_id", "account", "description", "external_id", "splits",
+}
+
+// RowWriter writes expenses one at a time; Close completes the file
+type RowWriter interface {
+	Write(row ExpenseRow) error
+	Close() error
+}
+
+// NewRowWriter creates the writer for a tabular export format
+func NewRowWriter(format string, w io.Writer) (RowWriter, error) {
+	switch format {
+	case XLSX:
+		return newXLSXWriter(w)
+	case NDJSON:
+		return &ndjsonWriter{out: bufio.NewWriter(w)}, nil
+	default:
+		out := csv.NewWriter(w)
+		if err := out.Write(expenseColumns); err != nil {
+			return nil, err
+		}
+		return &csvWriter{out: out}, nil
+	}
+}
+
+// splitSummary renders split lines as "Category 20.10; Category 9.90" for flat formats
+func splitSummary(splits []SplitRow) string {
+	parts := make([]string, 0, len(splits))
+	for _, split := range splits {
+		parts = append(parts, split.Category+" "+formatAmount(split.Amount))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// optionalID leaves unset references blank instead of writing 0
+func optionalID(id int) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.Itoa(id)
+}
+
+type csvWriter struct {
+	out *csv.Writer
+}
+
+func (w *csvWriter) Write(row ExpenseRow) error {
+	return w.out.Write([]string{
+		strconv.Itoa(row.ID),
+		row.Date.Format("2006-01-02"),
+		row.Kind,
+		formatAmount(row.Amount),
+		row.Currency,
+		strconv.Itoa(row.CategoryID),
+		row.Category,
+		optionalID(row.AccountID),
+		row.Account,
+		row.Description,
+		row.ExternalID,
+		splitSummary(row.Splits),
+	})
+}
+
+func (w *csvWriter) Close() error {
+	w.out.Flush()
+	return w.out.Error()
+}
+
+type ndjsonWriter struct {
+	out *bufio.Writer
+}
+
+// ndjsonRow writes the date as yyyy-mm-dd like the rest of the API
+type ndjsonRow struct {
+	ExpenseRow
+	Date string `json:"date"`
+}
+
+func (w *ndjsonWriter) Write(row ExpenseRow) error {
+	line, err := json.Marshal(ndjsonRow{ExpenseRow: row, Date: row.Date.Format("2006-01-02")})
+	if err != nil {
+		return err
+	}
+	if _, err := w.out.Write(line); err != nil {
+		return err
+	}
+	return w.out.WriteByte('\n')
+}
+
+func (w *ndjsonWriter) Close() error {
+	return w.out.Flush()
+}
+
+// xlsxWriter fills a single sheet through excelize's stream writer, which spills
+// rows to a temporary file instead of keeping the whole sheet in memory
+type xlsxWriter struct {
+	out         io.Writer
+	file        *excelize.File
+	sheet       *excelize.StreamWriter
+	row         int
+	dateStyle   int
+	amountStyle int
+}
+
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+	sheet, err := file.NewStreamWriter("Sheet1")
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	dateFormat := "yyyy-mm-dd"
+	dateStyle, err := file.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	amountStyle, err := file.NewStyle(&excelize.Style{NumFmt: 4}) // #,##0.00
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	headerStyle, err := file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	// Widths must be set before the first row is written
+	widths := map[int]float64{2: 12, 7: 20, 9: 20, 10: 40, 12: 40}
+	for col, width := range widths {
+		if err := sheet.SetColWidth(col, col, width); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+
+	header := make([]interface{}, 0, len(expenseColumns))
+	for _, name := range expenseColumns {
+		header = append(header, excelize.Cell{StyleID: headerStyle, Value: name})
+	}
+	if err := sheet.SetRow("A1", header, excelize.RowOpts{}); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &xlsxWriter{
+		out:         w,
+		file:        file,
+		sheet:       sheet,
+		row:         1,
+		dateStyle:   dateStyle,
+		amountStyle: amountStyle,
+	}, nil
+}
+
+func (w *xlsxWriter) Write(row ExpenseRow) error {
+	w.row++
+	cell, err := excelize.CoordinatesToCellName(1, w.row)
+	if err != nil {
+		return err
+	}
+
+	var accountID interface{}
+	if row.AccountID != 0 {
+		accountID = row.AccountID
+	}
+	return w.sheet.SetRow(cell, []interface{}{
+		row.ID,
+		excelize.Cell{StyleID: w.dateStyle, Value: row.Date},
+		row.Kind,
+		excelize.Cell{StyleID: w.amountStyle, Value: row.Amount},
+		row.Currency,
+		row.CategoryID,
+		row.Category,
+		accountID,
+		row.Account,
+		row.Description,
+		row.ExternalID,
+		splitSummary(row.Splits),
+	})
+}
+
+func (w *xlsxWriter) Close() error {
+	defer w.file.Close()
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+	return w.file.Write(w.out)
+}
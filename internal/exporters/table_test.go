@@ -0,0 +1,127 @@
+package exporters
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// sampleRows covers the values a flat export has to quote or type: separators,
+// quotes and line breaks in text, leading zeros, splits and a missing account
+func sampleRows() []ExpenseRow {
+	return []ExpenseRow{
+		{
+			ID:          1,
+			Date:        day(2025, 3, 2),
+			Kind:        "expense",
+			Amount:      1234.5,
+			Currency:    "EUR",
+			CategoryID:  3,
+			Category:    "Food, drink",
+			AccountID:   2,
+			Account:     "Credit card",
+			Description: `Dinner at "Chez Paul"`,
+			ExternalID:  "007",
+			Splits: []SplitRow{
+				{CategoryID: 3, Category: "Food, drink", Amount: 1200},
+				{CategoryID: 4, Category: "Tips", Amount: 34.5},
+			},
+		},
+		{
+			ID:          2,
+			Date:        day(2025, 3, 3),
+			Kind:        "refund",
+			Amount:      5,
+			CategoryID:  3,
+			Category:    "Food, drink",
+			Description: "Two lines\nof notes",
+		},
+	}
+}
+
+// writeRows exports the rows in the given format
+func writeRows(t *testing.T, format string, rows []ExpenseRow) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	writer, err := NewRowWriter(format, &out)
+	if err != nil {
+		t.Fatalf("new %s writer: %v", format, err)
+	}
+	for _, row := range rows {
+		if err := writer.Write(row); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	return out.Bytes()
+}
+
+func TestCSVExport(t *testing.T) {
+	checkGolden(t, "expenses.csv.golden", writeRows(t, CSV, sampleRows()))
+}
+
+func TestNDJSONExport(t *testing.T) {
+	checkGolden(t, "expenses.ndjson.golden", writeRows(t, NDJSON, sampleRows()))
+}
+
+// TestXLSXExport lists the type, number format and stored value of every cell,
+// so numbers and dates are checked to be numbers rather than text
+func TestXLSXExport(t *testing.T) {
+	file, err := excelize.OpenReader(bytes.NewReader(writeRows(t, XLSX, sampleRows())))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer file.Close()
+
+	var cells strings.Builder
+	for row := 1; row <= len(sampleRows())+1; row++ {
+		for col := 1; col <= len(expenseColumns); col++ {
+			name, _ := excelize.CoordinatesToCellName(col, row)
+			fmt.Fprintf(&cells, "%s\t%s\n", name, describeCell(t, file, name))
+		}
+	}
+	checkGolden(t, "expenses.xlsx.golden", []byte(cells.String()))
+}
+
+// describeCell renders a cell as "type format value"; a number format only shows
+// when the cell has one
+func describeCell(t *testing.T, file *excelize.File, name string) string {
+	t.Helper()
+	value, err := file.GetCellValue("Sheet1", name, excelize.Options{RawCellValue: true})
+	if err != nil {
+		t.Fatalf("value of %s: %v", name, err)
+	}
+	cellType, err := file.GetCellType("Sheet1", name)
+	if err != nil {
+		t.Fatalf("type of %s: %v", name, err)
+	}
+
+	kind := "number"
+	switch {
+	case cellType == excelize.CellTypeInlineString || cellType == excelize.CellTypeSharedString:
+		kind = "text"
+	case value == "":
+		return "empty"
+	}
+
+	styleID, err := file.GetCellStyle("Sheet1", name)
+	if err != nil {
+		t.Fatalf("style of %s: %v", name, err)
+	}
+	style, err := file.GetStyle(styleID)
+	if err != nil {
+		t.Fatalf("style %d: %v", styleID, err)
+	}
+	switch {
+	case style.CustomNumFmt != nil:
+		kind += " " + *style.CustomNumFmt
+	case style.NumFmt != 0:
+		kind += fmt.Sprintf(" numFmt(%d)", style.NumFmt)
+	}
+	return fmt.Sprintf("%s\t%q", kind, value)
+}
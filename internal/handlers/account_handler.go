@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccountHandler struct {
+	AccountService services.AccountService
+}
+
+// NewAccountHandler creates a new AccountHandler
+func NewAccountHandler(service services.AccountService) *AccountHandler {
+	return &AccountHandler{
+		AccountService: service,
+	}
+}
+
+// CreateAccount godoc
+// @Summary      Create a new account
+// @Description  Create a payment account (card, cash float, bank account) with an opening balance and currency
+// @Tags         accounts
+// @Accept       json
+// @Produce      json
+// @Param        account  body      dto.AccountRequestDTO  true  "Account Data"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      201      {object}  dto.AccountResponseDTO
+// @Failure      400      {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/accounts [post]
+func (h *AccountHandler) CreateAccount(c *gin.Context) {
+	var req dto.AccountRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	createdAccount, err := h.AccountService.Create(currentAccess(c), req)
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, createdAccount)
+}
+
+// GetAllAccounts godoc
+// @Summary      Get all accounts
+// @Description  Retrieve accounts with their current running balances
+// @Tags         accounts
+// @Produce      json
+// @Param        name    query  string  false  "Filter by account name (partial match)"
+// @Param        offset  query  int     false  "Offset for pagination" default(0)
+// @Param        limit   query  int     false  "Limit for pagination" default(10)
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200  {array}   dto.AccountResponseDTO
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/accounts [get]
+func (h *AccountHandler) GetAllAccounts(c *gin.Context) {
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	name := c.DefaultQuery("name", "")
+
+	accounts, err := h.AccountService.GetAll(currentAccess(c), offset, limit, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, accounts)
+}
+
+// GetAccountByID godoc
+// @Summary      Get account by ID
+// @Description  Retrieve account details and current balance by its ID
+// @Tags         accounts
+// @Produce      json
+// @Param        id   path      int  true  "Account ID"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200  {object}  dto.AccountResponseDTO
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/accounts/{id} [get]
+func (h *AccountHandler) GetAccountByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID"})
+		return
+	}
+
+	account, err := h.AccountService.GetByID(currentAccess(c), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+// UpdateAccount godoc
+// @Summary      Update account
+// @Description  Modify account name, type, currency or opening balance by its ID
+// @Tags         accounts
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                    true  "Account ID"
+// @Param        account  body      dto.AccountRequestDTO  true  "Updated Account Data"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200      {object}  dto.AccountResponseDTO
+// @Failure      400      {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/accounts/{id} [put]
+func (h *AccountHandler) UpdateAccount(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID"})
+		return
+	}
+
+	var req dto.AccountRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updatedAccount, err := h.AccountService.Update(currentAccess(c), id, req)
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedAccount)
+}
+
+// DeleteAccount godoc
+// @Summary      Delete account
+// @Description  Remove an account that has no expenses or transfers
+// @Tags         accounts
+// @Param        id   path  int  true  "Account ID"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      204  "No Content"
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/accounts/{id} [delete]
+func (h *AccountHandler) DeleteAccount(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID"})
+		return
+	}
+
+	if err := h.AccountService.Delete(currentAccess(c), id); err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetAccountBalance godoc
+// @Summary      Get account balance
+// @Description  Running balance of an account, optionally as of the end of a given day
+// @Tags         accounts
+// @Produce      json
+// @Param        id     path   int     true   "Account ID"
+// @Param        as_of  query  string  false  "Balance at the end of this day (dd-mm-yyyy or yyyy-mm-dd)"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200  {object}  dto.AccountBalanceDTO
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/accounts/{id}/balance [get]
+func (h *AccountHandler) GetAccountBalance(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID"})
+		return
+	}
+
+	asOf, err := dto.ParseOptionalDate(c.Query("as_of"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "as_of: " + err.Error()})
+		return
+	}
+
+	balance, err := h.AccountService.Balance(currentAccess(c), id, asOf)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, balance)
+}
+
+// GetAccountStatement godoc
+// @Summary      Get account statement
+// @Description  Expenses and transfers on an account over a date range, each with the running balance
+// @Tags         accounts
+// @Produce      json
+// @Param        id    path   int     true   "Account ID"
+// @Param        from  query  string  false  "First day of the statement (dd-mm-yyyy or yyyy-mm-dd)"
+// @Param        to    query  string  false  "Last day of the statement (dd-mm-yyyy or yyyy-mm-dd)"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200  {object}  dto.AccountStatementDTO
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/accounts/{id}/statement [get]
+func (h *AccountHandler) GetAccountStatement(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID"})
+		return
+	}
+
+	from, err := dto.ParseOptionalDate(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from: " + err.Error()})
+		return
+	}
+	to, err := dto.ParseOptionalDate(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to: " + err.Error()})
+		return
+	}
+
+	statement, err := h.AccountService.Statement(currentAccess(c), id, from, to)
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, statement)
+}
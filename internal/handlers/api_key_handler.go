@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	APIKeyService services.APIKeyService
+}
+
+// NewAPIKeyHandler creates a new APIKeyHandler
+func NewAPIKeyHandler(service services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		APIKeyService: service,
+	}
+}
+
+// CreateAPIKey godoc
+// @Summary      Create an API key
+// @Description  Issue a key for scripts and CI jobs, sent as "Authorization: Bearer <key>" like an access token. It acts as the signed-in user in any of their ledgers, limited to its scopes, and cannot manage keys, ledgers or backups. The key is returned only in this response.
+// @Tags         api-keys
+// @Accept       json
+// @Produce      json
+// @Param        key  body      dto.APIKeyRequestDTO  true  "Key name and scopes"
+// @Success      201  {object}  dto.APIKeyResponseDTO
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req dto.APIKeyRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+
+	key, err := h.APIKeyService.Create(currentUserID(c), req)
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+// GetAllAPIKeys godoc
+// @Summary      Get all API keys
+// @Description  List the signed-in user's API keys, newest first, with when each was last used; revoked and expired keys are included
+// @Tags         api-keys
+// @Produce      json
+// @Success      200  {array}   dto.APIKeyResponseDTO
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/api-keys [get]
+func (h *APIKeyHandler) GetAllAPIKeys(c *gin.Context) {
+	keys, err := h.APIKeyService.GetAll(currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+// GetAPIKeyScopes godoc
+// @Summary      Get API key scopes
+// @Description  List the scopes an API key can be given. Reads need a read scope and changes the matching write scope, which includes reading.
+// @Tags         api-keys
+// @Produce      json
+// @Success      200  {array}   dto.APIKeyScopeDTO
+// @Security     BearerAuth
+// @Router       /v1/api-keys/scopes [get]
+func (h *APIKeyHandler) GetAPIKeyScopes(c *gin.Context) {
+	c.JSON(http.StatusOK, services.APIKeyScopes)
+}
+
+// RevokeAPIKey godoc
+// @Summary      Revoke an API key
+// @Description  Stop a key working straight away; it stays listed as revoked
+// @Tags         api-keys
+// @Param        id   path  int  true  "API key ID"
+// @Success      204
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+		return
+	}
+
+	if err := h.APIKeyService.Revoke(currentUserID(c), id); err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"goExpenseTracker/internal/services"
+	"goExpenseTracker/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AttachmentHandler struct {
+	AttachmentService services.AttachmentService
+	MaxBytes          int64
+}
+
+// NewAttachmentHandler creates a new AttachmentHandler accepting uploads up to maxBytes
+func NewAttachmentHandler(service services.AttachmentService, maxBytes int64) *AttachmentHandler {
+	return &AttachmentHandler{
+		AttachmentService: service,
+		MaxBytes:          maxBytes,
+	}
+}
+
+// UploadAttachment godoc
+// @Summary      Upload a receipt
+// @Description  Attach a receipt image (JPEG, PNG, GIF, WebP) or PDF to an expense; the type is sniffed from the content
+// @Tags         attachments
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        id    path      int   true  "Expense ID"
+// @Param        file  formData  file  true  "Receipt file"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      201   {object}  dto.AttachmentResponseDTO
+// @Failure      400   {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      413   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/expenses/{id}/attachments [post]
+func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	expenseID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid expense ID"})
+		return
+	}
+
+	// Leave room for the multipart envelope around the file itself
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.MaxBytes+1<<20)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("file exceeds the %d byte limit", h.MaxBytes)})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	if fileHeader.Size > h.MaxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("file exceeds the %d byte limit", h.MaxBytes)})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "could not read uploaded file"})
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.AttachmentService.Upload(currentAccess(c), expenseID, fileHeader.Filename, file, fileHeader.Size)
+	if err != nil {
+		status := serviceErrorStatus(err, http.StatusInternalServerError)
+		if err.Error() == "expense not found" {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// GetAttachments godoc
+// @Summary      List receipts
+// @Description  List the attachments of an expense
+// @Tags         attachments
+// @Produce      json
+// @Param        id   path      int  true  "Expense ID"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200  {array}   dto.AttachmentResponseDTO
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/expenses/{id}/attachments [get]
+func (h *AttachmentHandler) GetAttachments(c *gin.Context) {
+	expenseID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid expense ID"})
+		return
+	}
+
+	attachments, err := h.AttachmentService.List(currentAccess(c), expenseID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, attachments)
+}
+
+// DownloadAttachment godoc
+// @Summary      Download a receipt
+// @Description  Download the content of an attachment
+// @Tags         attachments
+// @Produce      octet-stream
+// @Param        id             path  int  true  "Expense ID"
+// @Param        attachment_id  path  int  true  "Attachment ID"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200  {file}    file
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/expenses/{id}/attachments/{attachment_id} [get]
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	expenseID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid expense ID"})
+		return
+	}
+	attachmentID, err := strconv.Atoi(c.Param("attachment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID"})
+		return
+	}
+
+	attachment, reader, err := h.AttachmentService.Open(currentAccess(c), expenseID, attachmentID)
+	if err != nil {
+		status := http.StatusNotFound
+		if !errors.Is(err, storage.ErrNotFound) && err.Error() != "attachment not found" {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	defer reader.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, reader, map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=%q", attachment.FileName),
+		"ETag":                `"` + attachment.SHA256 + `"`,
+	})
+}
+
+// DeleteAttachment godoc
+// @Summary      Delete a receipt
+// @Description  Remove an attachment and its stored content
+// @Tags         attachments
+// @Param        id             path  int  true  "Expense ID"
+// @Param        attachment_id  path  int  true  "Attachment ID"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      204  "No Content"
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/expenses/{id}/attachments/{attachment_id} [delete]
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	expenseID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid expense ID"})
+		return
+	}
+	attachmentID, err := strconv.Atoi(c.Param("attachment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID"})
+		return
+	}
+
+	if err := h.AttachmentService.Delete(currentAccess(c), expenseID, attachmentID); err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
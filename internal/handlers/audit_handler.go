@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	AuditService services.AuditService
+}
+
+// NewAuditHandler creates a new AuditHandler
+func NewAuditHandler(service services.AuditService) *AuditHandler {
+	return &AuditHandler{
+		AuditService: service,
+	}
+}
+
+// GetAuditLog godoc
+// @Summary      Search the audit trail
+// @Description  Page through the changes made to the ledger's expenses and categories, newest first. Every create, update and delete is recorded with who made it, when, the X-Request-ID of the request and the fields it changed; entries cannot be edited or removed.
+// @Tags         audit
+// @Produce      json
+// @Param        entity_type  query     string  false  "expense or category"
+// @Param        entity_id    query     int     false  "ID of the expense or category"
+// @Param        action       query     string  false  "create, update or delete"
+// @Param        actor_id     query     int     false  "User who made the change"
+// @Param        request_id   query     string  false  "X-Request-ID of the request that made the change"
+// @Param        from         query     string  false  "First day (dd-mm-yyyy or yyyy-mm-dd)"
+// @Param        to           query     string  false  "Last day (dd-mm-yyyy or yyyy-mm-dd)"
+// @Param        offset       query     int     false  "Offset for pagination" default(0)
+// @Param        limit        query     int     false  "Limit for pagination (at most 500)" default(50)
+// @Param        X-Ledger-ID  header    int     false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200          {object}  dto.AuditLogDTO
+// @Failure      400          {object}  map[string]string
+// @Failure      500          {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/audit [get]
+func (h *AuditHandler) GetAuditLog(c *gin.Context) {
+	var filter dto.AuditFilterDTO
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+
+	log, err := h.AuditService.Search(currentAccess(c), filter)
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, log)
+}
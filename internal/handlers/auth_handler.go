@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	dto "goExpenseTracker/internal/DTOs"
+	Logger "goExpenseTracker/internal/middlewears"
+	"goExpenseTracker/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthHandler struct {
+	AuthService services.AuthService
+}
+
+// NewAuthHandler creates a new AuthHandler
+func NewAuthHandler(service services.AuthService) *AuthHandler {
+	return &AuthHandler{
+		AuthService: service,
+	}
+}
+
+// currentUserID is the signed-in user, set by the auth middleware
+func currentUserID(c *gin.Context) int {
+	return c.GetInt(Logger.UserIDKey)
+}
+
+// currentAccess is the signed-in user's access to the ledger of the request, set by
+// the ledger middleware
+func currentAccess(c *gin.Context) services.Access {
+	access, _ := c.Get(Logger.AccessKey)
+	resolved, _ := access.(services.Access)
+	return resolved
+}
+
+// signInFailed answers a sign-in that did not issue a token, with the challenge
+// when it only lacks a second factor
+func signInFailed(c *gin.Context, err error, fallback int) {
+	var challenge *services.TwoFactorRequiredError
+	if errors.As(err, &challenge) {
+		c.JSON(http.StatusUnauthorized, dto.TwoFactorChallengeDTO{
+			Error:             err.Error(),
+			TwoFactorRequired: true,
+			Challenge:         challenge.Challenge,
+			ExpiresAt:         challenge.ExpiresAt,
+		})
+		return
+	}
+	c.JSON(serviceErrorStatus(err, fallback), gin.H{"error": err.Error()})
+}
+
+// Register godoc
+// @Summary      Sign up
+// @Description  Create a user account with a personal ledger and sign in. The first account becomes the admin, unless the server holds data recorded before accounts existed; that data and the admin role then go to the account named to the claim command. Once a user exists, further sign-ups can be turned off with ALLOW_SIGNUP=false.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        account  body      dto.RegisterRequestDTO  true  "Account details"
+// @Success      201      {object}  dto.AuthTokenDTO
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /v1/auth/register [post]
+func (h *AuthHandler) Register(c *gin.Context) {
+	var req dto.RegisterRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+
+	token, err := h.AuthService.Register(req)
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, token)
+}
+
+// Login godoc
+// @Summary      Sign in
+// @Description  Exchange an email and password for an access token, sent on every other request as "Authorization: Bearer <token>". Users with two-factor sign-in get a 401 with two_factor_required and a challenge instead, to send with a code to POST /v1/auth/2fa/verify.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        credentials  body      dto.LoginRequestDTO  true  "Email and password"
+// @Success      200          {object}  dto.AuthTokenDTO
+// @Failure      400          {object}  map[string]string
+// @Failure      401          {object}  dto.TwoFactorChallengeDTO
+// @Failure      500          {object}  map[string]string
+// @Router       /v1/auth/login [post]
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req dto.LoginRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+
+	token, err := h.AuthService.Login(req)
+	if err != nil {
+		signInFailed(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+// VerifyTwoFactor godoc
+// @Summary      Finish a two-factor sign-in
+// @Description  Exchange the challenge of a sign-in that asked for a second factor, and a code from the authenticator app or an unused recovery code, for an access token. The challenge is valid for 5 minutes and each code works once; after 5 wrong codes the account's codes are refused for 15 minutes.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        verification  body      dto.TwoFactorLoginRequestDTO  true  "Challenge and code"
+// @Success      200           {object}  dto.AuthTokenDTO
+// @Failure      400           {object}  map[string]string
+// @Failure      401           {object}  map[string]string
+// @Failure      500           {object}  map[string]string
+// @Router       /v1/auth/2fa/verify [post]
+func (h *AuthHandler) VerifyTwoFactor(c *gin.Context) {
+	var req dto.TwoFactorLoginRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+
+	token, err := h.AuthService.VerifyTwoFactor(req)
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+// GetCurrentUser godoc
+// @Summary      Get the signed-in user
+// @Tags         auth
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  dto.UserResponseDTO
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /v1/auth/me [get]
+func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
+	user, err := h.AuthService.Me(currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// UpdateCurrentUser godoc
+// @Summary      Update the signed-in user
+// @Description  Change the signed-in user's settings. The timezone decides where their days begin and end: which date is today for the rule that expenses cannot be dated in the past, the default date of settlements and the days of audit trail filters. Users without one get the server's timezone.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        user  body      dto.UserUpdateRequestDTO  true  "Settings to change"
+// @Security     BearerAuth
+// @Success      200  {object}  dto.UserResponseDTO
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /v1/auth/me [patch]
+func (h *AuthHandler) UpdateCurrentUser(c *gin.Context) {
+	var req dto.UserUpdateRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+
+	user, err := h.AuthService.UpdateMe(currentUserID(c), req)
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"goExpenseTracker/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxRestoreBytes caps the size of an uploaded backup archive
+const maxRestoreBytes = 2 << 30
+
+type BackupHandler struct {
+	BackupService services.BackupService
+}
+
+// NewBackupHandler creates a new BackupHandler
+func NewBackupHandler(service services.BackupService) *BackupHandler {
+	return &BackupHandler{
+		BackupService: service,
+	}
+}
+
+// DownloadBackup godoc
+// @Summary      Download a full backup
+// @Description  Download every table (users, categories, accounts, people, expenses with their split lines and shares, transfers, settlements and attachments) of every user and the receipt files as one zip archive. Admins only. The archive records its schema version so a restore can check compatibility.
+// @Tags         backup
+// @Produce      application/zip
+// @Success      200  {file}    file
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/backup [get]
+func (h *BackupHandler) DownloadBackup(c *gin.Context) {
+	fileName := "backup-" + time.Now().UTC().Format("20060102-150405") + ".zip"
+	out := &downloadWriter{c: c, contentType: "application/zip", fileName: fileName}
+	if err := h.BackupService.Backup(currentUserID(c), out); err != nil {
+		if !out.started {
+			c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+			return
+		}
+		_ = c.Error(err)
+		c.Abort()
+	}
+}
+
+// RestoreBackup godoc
+// @Summary      Restore a backup
+// @Description  Restore an archive made by GET /v1/backup into this instance, keeping IDs and timestamps. Everything is restored in one transaction. Rows whose ID already exists are skipped, overwritten, or make the whole restore fail (the default). Admins only; rows of backups made before user accounts existed go to the admin restoring them.
+// @Tags         backup
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file      formData  file    true   "Backup archive"
+// @Param        conflict  formData  string  false  "What to do with rows that already exist" Enums(skip, overwrite, fail) default(fail)
+// @Success      200  {object}  dto.RestoreResultDTO
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/restore [post]
+func (h *BackupHandler) RestoreBackup(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxRestoreBytes)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "could not read uploaded file"})
+		return
+	}
+	defer file.Close()
+
+	result, err := h.BackupService.Restore(currentUserID(c), file, fileHeader.Size, c.PostForm("conflict"))
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -51,9 +51,12 @@ func formatCategoryValidationError(err error) string {
 // @Accept       json
 // @Produce      json
 // @Param        category  body      dto.CategoryRequestDTO  true  "Category Data"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
 // @Success      201       {object}  dto.CategoryResponseDTO
 // @Failure      400       {object}  map[string]string
+// @Failure      403  {object}  map[string]string
 // @Failure      500       {object}  map[string]string
+// @Security     BearerAuth
 // @Router       /v1/categories [post]
 func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 	var req dto.CategoryRequestDTO
@@ -62,9 +65,9 @@ func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 		return
 	}
 
-	createdCategory, err := h.CategoryService.Create(req)
+	createdCategory, err := h.CategoryService.Create(currentAccess(c), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -79,15 +82,17 @@ func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 // @Param        name    query  string  false  "Filter by category name (partial match)"
 // @Param        offset  query  int     false  "Offset for pagination" default(0)
 // @Param        limit   query  int     false  "Limit for pagination" default(10)
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
 // @Success      200  {array}   dto.CategoryResponseDTO
 // @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
 // @Router       /v1/categories [get]
 func (h *CategoryHandler) GetAllCategorys(c *gin.Context) {
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 	name := c.DefaultQuery("name", "")
 
-	categories, err := h.CategoryService.GetAll(offset, limit, name)
+	categories, err := h.CategoryService.GetAll(currentAccess(c), offset, limit, name)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -101,9 +106,11 @@ func (h *CategoryHandler) GetAllCategorys(c *gin.Context) {
 // @Tags         categories
 // @Produce      json
 // @Param        id   path      int  true  "Category ID"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
 // @Success      200  {object}  dto.CategoryResponseDTO
 // @Failure      400  {object}  map[string]string
 // @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
 // @Router       /v1/categories/{id} [get]
 func (h *CategoryHandler) GetCategoryByID(c *gin.Context) {
 	idParam := c.Param("id")
@@ -113,7 +120,7 @@ func (h *CategoryHandler) GetCategoryByID(c *gin.Context) {
 		return
 	}
 
-	category, err := h.CategoryService.GetByID(id)
+	category, err := h.CategoryService.GetByID(currentAccess(c), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -130,9 +137,12 @@ func (h *CategoryHandler) GetCategoryByID(c *gin.Context) {
 // @Produce      json
 // @Param        id        path      int                   true  "Category ID"
 // @Param        category  body      dto.CategoryRequestDTO true  "Updated Category Data"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
 // @Success      200       {object}  dto.CategoryResponseDTO
 // @Failure      400       {object}  map[string]string
+// @Failure      403  {object}  map[string]string
 // @Failure      500       {object}  map[string]string
+// @Security     BearerAuth
 // @Router       /v1/categories/{id} [put]
 func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
 	idParam := c.Param("id")
@@ -148,9 +158,9 @@ func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
 		return
 	}
 
-	updatedCategory, err := h.CategoryService.Update(id, req)
+	updatedCategory, err := h.CategoryService.Update(currentAccess(c), id, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -162,9 +172,12 @@ func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
 // @Description  Remove category by its ID
 // @Tags         categories
 // @Param        id   path  int  true  "Category ID"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
 // @Success      204  "No Content"
 // @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
 // @Router       /v1/categories/{id} [delete]
 func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 	idParam := c.Param("id")
@@ -174,9 +187,9 @@ func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 		return
 	}
 
-	err = h.CategoryService.Delete(id)
+	err = h.CategoryService.Delete(currentAccess(c), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -33,14 +34,16 @@ func formatValidationError(err error) string {
 			case "required":
 				return field + " is required"
 			case "min":
-				if e.Field() == "CategoryID" {
-					return "category_id must be greater than 0"
+				if strings.HasSuffix(e.Field(), "ID") {
+					return field + " must be greater than 0"
 				}
 				return field + " must be at least " + e.Param()
 			case "gt":
 				return field + " must be greater than 0"
 			case "max":
 				return field + " must not exceed " + e.Param() + " characters"
+			case "oneof":
+				return field + " must be one of: " + strings.ReplaceAll(e.Param(), " ", ", ")
 			default:
 				return field + " is invalid"
 			}
@@ -51,14 +54,18 @@ func formatValidationError(err error) string {
 
 // CreateExpense godoc
 // @Summary      Create a new expense
-// @Description  Add a new expense entry including amount, category, and description
+// @Description  Add a new expense entry including amount, category, and description. Without a category_id or split lines, the categorisation rules pick the category and may add tags or clean up the description. Existing expenses of the same kind and amount, dated close by and with a similar description, are listed as possible_duplicates, or make the request fail with 409 when the server rejects duplicates; allow_duplicate skips the check.
 // @Tags         expenses
 // @Accept       json
 // @Produce      json
 // @Param        expense  body      dto.ExpenseRequestDTO  true  "Expense Data"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
 // @Success      201       {object}  dto.ExpenseResponseDTO
 // @Failure      400       {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      409       {object}  dto.DuplicateConflictDTO
 // @Failure      500       {object}  map[string]string
+// @Security     BearerAuth
 // @Router       /v1/expenses [post]
 func (h *ExpenseHandler) CreateExpense(c *gin.Context) {
 	var req dto.ExpenseRequestDTO
@@ -68,14 +75,19 @@ func (h *ExpenseHandler) CreateExpense(c *gin.Context) {
 	}
 
 	// Additional validation
-	if err := req.Validate(); err != nil {
+	if err := req.Validate(currentAccess(c).Today()); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	createdExpense, err := h.ExpenseService.Create(req)
+	createdExpense, err := h.ExpenseService.Create(currentAccess(c), req)
+	var duplicateErr *services.DuplicateError
+	if errors.As(err, &duplicateErr) {
+		c.JSON(http.StatusConflict, dto.DuplicateConflictDTO{Error: err.Error(), Duplicates: duplicateErr.Duplicates})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -87,12 +99,14 @@ func (h *ExpenseHandler) CreateExpense(c *gin.Context) {
 // @Description  Retrieve all recorded expenses with pagination and filtering
 // @Tags         expenses
 // @Param        description  query  string  false  "Filter by description (partial match)"
-// @Param        category_id  query  int     false  "Filter by category ID"
+// @Param        category_id  query  int     false  "Filter by category ID (split lines included)"
 // @Produce      json
 // @Param        offset       query  int     false  "Offset for pagination" default(0)
 // @Param        limit        query  int     false  "Limit for pagination" default(10)
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
 // @Success      200  {array}   dto.ExpenseResponseDTO
 // @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
 // @Router       /v1/expenses [get]
 func (h *ExpenseHandler) GetAllExpenses(c *gin.Context) {
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
@@ -100,7 +114,7 @@ func (h *ExpenseHandler) GetAllExpenses(c *gin.Context) {
 	descriptionFilter := c.DefaultQuery("description", "")
 	categoryID, _ := strconv.Atoi(c.DefaultQuery("category_id", "0"))
 
-	expenses, err := h.ExpenseService.GetAll(offset, limit, descriptionFilter, categoryID)
+	expenses, err := h.ExpenseService.GetAll(currentAccess(c), offset, limit, descriptionFilter, categoryID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -114,9 +128,11 @@ func (h *ExpenseHandler) GetAllExpenses(c *gin.Context) {
 // @Tags         expenses
 // @Produce      json
 // @Param        id   path      int  true  "Expense ID"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
 // @Success      200  {object}  dto.ExpenseResponseDTO
 // @Failure      400  {object}  map[string]string
 // @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
 // @Router       /v1/expenses/{id} [get]
 func (h *ExpenseHandler) GetExpenseByID(c *gin.Context) {
 	idParam := c.Param("id")
@@ -126,7 +142,7 @@ func (h *ExpenseHandler) GetExpenseByID(c *gin.Context) {
 		return
 	}
 
-	expense, err := h.ExpenseService.GetByID(id)
+	expense, err := h.ExpenseService.GetByID(currentAccess(c), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -143,9 +159,12 @@ func (h *ExpenseHandler) GetExpenseByID(c *gin.Context) {
 // @Produce      json
 // @Param        id        path      int                    true  "Expense ID"
 // @Param        expense   body      dto.ExpenseRequestDTO  true  "Updated Expense Data"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
 // @Success      200       {object}  dto.ExpenseResponseDTO
 // @Failure      400       {object}  map[string]string
+// @Failure      403  {object}  map[string]string
 // @Failure      500       {object}  map[string]string
+// @Security     BearerAuth
 // @Router       /v1/expenses/{id} [put]
 func (h *ExpenseHandler) UpdateExpense(c *gin.Context) {
 	idParam := c.Param("id")
@@ -162,14 +181,14 @@ func (h *ExpenseHandler) UpdateExpense(c *gin.Context) {
 	}
 
 	// Additional validation
-	if err := req.Validate(); err != nil {
+	if err := req.Validate(currentAccess(c).Today()); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	updatedExpense, err := h.ExpenseService.Update(id, req)
+	updatedExpense, err := h.ExpenseService.Update(currentAccess(c), id, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -181,9 +200,12 @@ func (h *ExpenseHandler) UpdateExpense(c *gin.Context) {
 // @Description  Remove a specific expense entry by ID
 // @Tags         expenses
 // @Param        id   path  int  true  "Expense ID"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
 // @Success      204  "No Content"
 // @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
 // @Router       /v1/expenses/{id} [delete]
 func (h *ExpenseHandler) DeleteExpense(c *gin.Context) {
 	idParam := c.Param("id")
@@ -193,11 +215,103 @@ func (h *ExpenseHandler) DeleteExpense(c *gin.Context) {
 		return
 	}
 
-	err = h.ExpenseService.Delete(id)
+	err = h.ExpenseService.Delete(currentAccess(c), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
+
+// GetExpenseHistory godoc
+// @Summary      Get the change history of an expense
+// @Description  List every recorded change to an expense, oldest first: who made it, when, in which request (X-Request-ID) and each field's old and new value. Deleted expenses keep their history.
+// @Tags         expenses
+// @Produce      json
+// @Param        id           path      int  true   "Expense ID"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200          {array}   dto.AuditEntryDTO
+// @Failure      400          {object}  map[string]string
+// @Failure      404          {object}  map[string]string
+// @Failure      500          {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/expenses/{id}/history [get]
+func (h *ExpenseHandler) GetExpenseHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid expense ID"})
+		return
+	}
+
+	history, err := h.ExpenseService.History(currentAccess(c), id)
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
+
+// GetDuplicateClusters godoc
+// @Summary      List suspected duplicate expenses
+// @Description  Group expenses that look like the same expense recorded twice: same kind and amount, dated at most window_days apart, with similar descriptions and no conflicting account, currency or bank reference. Pairs dismissed as genuine are not grouped again.
+// @Tags         expenses
+// @Produce      json
+// @Param        window_days  query  int     false  "Days apart duplicates may be dated; defaults to the server setting"
+// @Param        description  query  string  false  "Filter by description (case-insensitive)"
+// @Param        category_id  query  int     false  "Filter by category ID, including split lines"
+// @Param        account_id   query  int     false  "Filter by account ID"
+// @Param        tag_id       query  int     false  "Filter by tag ID"
+// @Param        from         query  string  false  "First day (dd-mm-yyyy or yyyy-mm-dd)"
+// @Param        to           query  string  false  "Last day (dd-mm-yyyy or yyyy-mm-dd)"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200  {object}  dto.DuplicateClustersDTO
+// @Failure      400  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/expenses/duplicates [get]
+func (h *ExpenseHandler) GetDuplicateClusters(c *gin.Context) {
+	var filter dto.DuplicateFilterDTO
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+
+	clusters, err := h.ExpenseService.DuplicateClusters(currentAccess(c), filter)
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, clusters)
+}
+
+// ResolveDuplicates godoc
+// @Summary      Resolve suspected duplicates
+// @Description  Settle a duplicate cluster around the expense to keep: delete removes the duplicates along with their receipts, dismiss marks them as genuine so they are no longer reported with the kept expense
+// @Tags         expenses
+// @Accept       json
+// @Produce      json
+// @Param        resolution  body      dto.DuplicateResolveRequestDTO  true  "Expense to keep and its duplicates"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200         {object}  dto.DuplicateResolveResultDTO
+// @Failure      400         {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500         {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/expenses/duplicates/resolve [post]
+func (h *ExpenseHandler) ResolveDuplicates(c *gin.Context) {
+	var req dto.DuplicateResolveRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+
+	result, err := h.ExpenseService.ResolveDuplicates(currentAccess(c), req)
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportHandler struct {
+	ExportService services.ExportService
+}
+
+// NewExportHandler creates a new ExportHandler
+func NewExportHandler(service services.ExportService) *ExportHandler {
+	return &ExportHandler{
+		ExportService: service,
+	}
+}
+
+// ExportQIF godoc
+// @Summary      Export expenses as QIF
+// @Description  Download the filtered expenses as a QIF file. Expenses are written as payments and refunds or income as deposits; split expenses keep their split lines.
+// @Tags         exports
+// @Produce      application/qif
+// @Param        description  query  string  false  "Filter by description (case-insensitive)"
+// @Param        category_id  query  int     false  "Filter by category ID, including split lines"
+// @Param        account_id   query  int     false  "Filter by account ID"
+// @Param        tag_id       query  int     false  "Filter by tag ID"
+// @Param        from         query  string  false  "First day (dd-mm-yyyy or yyyy-mm-dd)"
+// @Param        to           query  string  false  "Last day (dd-mm-yyyy or yyyy-mm-dd)"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200  {file}    file
+// @Failure      400  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/exports/qif [get]
+func (h *ExportHandler) ExportQIF(c *gin.Context) {
+	var filter dto.ExpenseFilterDTO
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := h.ExportService.QIF(currentAccess(c), &buf, filter); err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="expenses.qif"`)
+	c.Data(http.StatusOK, "application/qif", buf.Bytes())
+}
+
+// ExportJournal godoc
+// @Summary      Export expenses as a plain-text accounting journal
+// @Description  Download the filtered expenses as a ledger, hledger or beancount journal. Each expense posts to the account of its category (Expenses:<Category>, Income:<Category> for income) unless mapped with account=Category=Account:Path, and is balanced against the funding account. Without funding_account each expense is balanced against the account it was paid from (Liabilities for cards), or Assets:Bank.
+// @Tags         exports
+// @Produce      plain
+// @Param        format           query  string    true   "Journal format" Enums(ledger, hledger, beancount)
+// @Param        funding_account  query  string    false  "Account every expense is paid from, e.g. Assets:Bank:Checking"
+// @Param        currency         query  string    false  "Commodity for expenses without a currency"
+// @Param        account          query  []string  false  "Category to account mapping, e.g. Groceries=Expenses:Food:Groceries" collectionFormat(multi)
+// @Param        description      query  string    false  "Filter by description (case-insensitive)"
+// @Param        category_id      query  int       false  "Filter by category ID, including split lines"
+// @Param        account_id       query  int       false  "Filter by account ID"
+// @Param        tag_id           query  int       false  "Filter by tag ID"
+// @Param        from             query  string    false  "First day (dd-mm-yyyy or yyyy-mm-dd)"
+// @Param        to               query  string    false  "Last day (dd-mm-yyyy or yyyy-mm-dd)"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200  {string}  string
+// @Failure      400  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/exports/journal [get]
+func (h *ExportHandler) ExportJournal(c *gin.Context) {
+	var req dto.JournalExportDTO
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := h.ExportService.Journal(currentAccess(c), &buf, req); err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	extension := map[string]string{"ledger": "ledger", "hledger": "journal", "beancount": "beancount"}[req.Format]
+	c.Header("Content-Disposition", `attachment; filename="expenses.`+extension+`"`)
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", buf.Bytes())
+}
+
+// exportContentTypes maps each streaming export format to its content type and file extension
+var exportContentTypes = map[string][2]string{
+	"csv":    {"text/csv; charset=utf-8", "csv"},
+	"xlsx":   {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "xlsx"},
+	"ndjson": {"application/x-ndjson", "ndjson"},
+}
+
+// ExportExpenses godoc
+// @Summary      Export expenses as CSV, XLSX or NDJSON
+// @Description  Stream the filtered expenses as a download. Rows are read in batches and written as they arrive, so large exports do not need to fit in memory. XLSX files have a header row and typed date and amount cells; NDJSON has one expense object per line.
+// @Tags         exports
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce      application/x-ndjson
+// @Param        format       query  string  false  "Export format" Enums(csv, xlsx, ndjson) default(csv)
+// @Param        description  query  string  false  "Filter by description (case-insensitive)"
+// @Param        category_id  query  int     false  "Filter by category ID, including split lines"
+// @Param        account_id   query  int     false  "Filter by account ID"
+// @Param        tag_id       query  int     false  "Filter by tag ID"
+// @Param        from         query  string  false  "First day (dd-mm-yyyy or yyyy-mm-dd)"
+// @Param        to           query  string  false  "Last day (dd-mm-yyyy or yyyy-mm-dd)"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200  {file}    file
+// @Failure      400  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/exports/expenses [get]
+func (h *ExportHandler) ExportExpenses(c *gin.Context) {
+	var req dto.ExpenseExportDTO
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+	if req.Format == "" {
+		req.Format = "csv"
+	}
+
+	content := exportContentTypes[req.Format]
+	out := &downloadWriter{c: c, contentType: content[0], fileName: "expenses." + content[1]}
+	if err := h.ExportService.Expenses(currentAccess(c), out, req); err != nil {
+		if !out.started {
+			c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+			return
+		}
+		// Part of the file is already on its way; all that is left is to cut it short
+		_ = c.Error(err)
+		c.Abort()
+	}
+}
+
+// downloadWriter sends the download headers with the first byte of the file, so
+// an error found before anything was written can still be answered with JSON
+type downloadWriter struct {
+	c           *gin.Context
+	contentType string
+	fileName    string
+	started     bool
+}
+
+func (w *downloadWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		w.c.Header("Content-Type", w.contentType)
+		w.c.Header("Content-Disposition", `attachment; filename="`+w.fileName+`"`)
+		w.c.Status(http.StatusOK)
+	}
+	return w.c.Writer.Write(p)
+}
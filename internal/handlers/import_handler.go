@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportBytes caps the size of uploaded statement files
+const maxImportBytes = 20 << 20
+
+type ImportHandler struct {
+	ImportService services.ImportService
+}
+
+// NewImportHandler creates a new ImportHandler
+func NewImportHandler(service services.ImportService) *ImportHandler {
+	return &ImportHandler{
+		ImportService: service,
+	}
+}
+
+// ImportExpenses godoc
+// @Summary      Import expenses from a file
+// @Description  Upload a file to turn its rows into expenses. CSV is mapped with the column options; OFX/QFX statements turn debits into expenses and credits into refunds or income, skipping transactions whose FITID was already imported. camt.053 and MT940 statements are dated on the value date, keep the currency, describe each entry by counterparty and remittance information, and skip entries whose bank reference was already imported. QIF files may carry split lines. Rows without a category go through the categorisation rules before default_category_id applies. Rows that look like existing expenses carry the suspected duplicates, or are skipped when the server rejects duplicates, unless allow_duplicates is set. With create_categories set, categories named in the file that do not exist are created. Without a format the file extension decides, falling back to csv. With dry_run=true every row is validated and reported without saving anything; otherwise all valid rows are created in one transaction. The options field is a JSON object with the column mapping and parsing settings.
+// @Tags         imports
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file     formData  file    true   "File to import"
+// @Param        format   formData  string  false  "File format" Enums(csv, ofx, qfx, qif, camt053, mt940)
+// @Param        dry_run  formData  bool    false  "Validate only" default(false)
+// @Param        options  formData  string  false  "dto.ImportOptionsDTO as JSON, e.g. {\"columns\":{\"date\":\"Date\",\"amount\":\"Amount\"},\"date_formats\":[\"dd/mm/yyyy\"]}"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200  {object}  dto.ImportResultDTO
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/imports [post]
+func (h *ImportHandler) ImportExpenses(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+
+	var opts dto.ImportOptionsDTO
+	if raw := c.PostForm("options"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &opts); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "options must be valid JSON: " + err.Error()})
+			return
+		}
+	}
+
+	dryRun, err := strconv.ParseBool(c.DefaultPostForm("dry_run", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dry_run must be true or false"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "could not read uploaded file"})
+		return
+	}
+	defer file.Close()
+
+	result, err := h.ImportService.Import(currentAccess(c), importFormat(c.PostForm("format"), fileHeader.Filename), file, opts, dryRun)
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// importFormat picks the explicit format, or else guesses it from the file extension
+func importFormat(format string, fileName string) string {
+	if format != "" {
+		return strings.ToLower(format)
+	}
+	switch ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(fileName), ".")); ext {
+	case "ofx", "qfx", "qif", "mt940":
+		return ext
+	case "xml":
+		return "camt053"
+	case "sta":
+		return "mt940"
+	default:
+		return "csv"
+	}
+}
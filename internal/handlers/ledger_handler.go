@@ -0,0 +1,393 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LedgerHandler struct {
+	LedgerService services.LedgerService
+}
+
+// NewLedgerHandler creates a new LedgerHandler
+func NewLedgerHandler(service services.LedgerService) *LedgerHandler {
+	return &LedgerHandler{
+		LedgerService: service,
+	}
+}
+
+// CreateLedger godoc
+// @Summary      Create a new ledger
+// @Description  Create a ledger to share with a household or team; the creator becomes its owner. Other requests work on a ledger when its ID is sent in the X-Ledger-ID header.
+// @Tags         ledgers
+// @Accept       json
+// @Produce      json
+// @Param        ledger  body      dto.LedgerRequestDTO  true  "Ledger Data"
+// @Success      201     {object}  dto.LedgerResponseDTO
+// @Failure      400     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/ledgers [post]
+func (h *LedgerHandler) CreateLedger(c *gin.Context) {
+	var req dto.LedgerRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ledger, err := h.LedgerService.Create(currentUserID(c), req)
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, ledger)
+}
+
+// GetAllLedgers godoc
+// @Summary      Get all ledgers
+// @Description  List the ledgers the signed-in user is a member of, with their role in each; the personal ledger comes first
+// @Tags         ledgers
+// @Produce      json
+// @Success      200  {array}   dto.LedgerResponseDTO
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/ledgers [get]
+func (h *LedgerHandler) GetAllLedgers(c *gin.Context) {
+	ledgers, err := h.LedgerService.GetAll(currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, ledgers)
+}
+
+// GetLedgerByID godoc
+// @Summary      Get ledger by ID
+// @Description  Retrieve a ledger the signed-in user is a member of
+// @Tags         ledgers
+// @Produce      json
+// @Param        id   path      int  true  "Ledger ID"
+// @Success      200  {object}  dto.LedgerResponseDTO
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/ledgers/{id} [get]
+func (h *LedgerHandler) GetLedgerByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ledger ID"})
+		return
+	}
+
+	ledger, err := h.LedgerService.GetByID(currentUserID(c), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ledger)
+}
+
+// UpdateLedger godoc
+// @Summary      Rename ledger
+// @Description  Change a ledger's name; only owners may
+// @Tags         ledgers
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int                   true  "Ledger ID"
+// @Param        ledger  body      dto.LedgerRequestDTO  true  "Updated Ledger Data"
+// @Success      200     {object}  dto.LedgerResponseDTO
+// @Failure      400     {object}  map[string]string
+// @Failure      403     {object}  map[string]string
+// @Failure      404     {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/ledgers/{id} [put]
+func (h *LedgerHandler) UpdateLedger(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ledger ID"})
+		return
+	}
+
+	var req dto.LedgerRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ledger, err := h.LedgerService.Update(currentUserID(c), id, req)
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ledger)
+}
+
+// DeleteLedger godoc
+// @Summary      Delete ledger
+// @Description  Delete an empty ledger with its members and invitations; only owners may. Personal ledgers cannot be deleted.
+// @Tags         ledgers
+// @Param        id   path  int  true  "Ledger ID"
+// @Success      204
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/ledgers/{id} [delete]
+func (h *LedgerHandler) DeleteLedger(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ledger ID"})
+		return
+	}
+
+	if err := h.LedgerService.Delete(currentUserID(c), id); err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetLedgerMembers godoc
+// @Summary      Get ledger members
+// @Description  List the members of a ledger with their roles
+// @Tags         ledgers
+// @Produce      json
+// @Param        id   path      int  true  "Ledger ID"
+// @Success      200  {array}   dto.LedgerMemberResponseDTO
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/ledgers/{id}/members [get]
+func (h *LedgerHandler) GetLedgerMembers(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ledger ID"})
+		return
+	}
+
+	members, err := h.LedgerService.GetMembers(currentUserID(c), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// UpdateLedgerMember godoc
+// @Summary      Change a member's role
+// @Description  Make a member an owner, editor or viewer; only owners may. A ledger always keeps at least one owner.
+// @Tags         ledgers
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                         true  "Ledger ID"
+// @Param        user_id  path      int                         true  "User ID of the member"
+// @Param        member   body      dto.LedgerMemberRequestDTO  true  "New role"
+// @Success      200      {object}  dto.LedgerMemberResponseDTO
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/ledgers/{id}/members/{user_id} [put]
+func (h *LedgerHandler) UpdateLedgerMember(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ledger ID"})
+		return
+	}
+	memberID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req dto.LedgerMemberRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+
+	member, err := h.LedgerService.UpdateMember(currentUserID(c), id, memberID, req)
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// RemoveLedgerMember godoc
+// @Summary      Remove a member
+// @Description  Take a user out of a ledger. Owners may remove anyone; every member may remove themselves to leave. The last owner cannot leave.
+// @Tags         ledgers
+// @Param        id       path  int  true  "Ledger ID"
+// @Param        user_id  path  int  true  "User ID of the member"
+// @Success      204
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/ledgers/{id}/members/{user_id} [delete]
+func (h *LedgerHandler) RemoveLedgerMember(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ledger ID"})
+		return
+	}
+	memberID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := h.LedgerService.RemoveMember(currentUserID(c), id, memberID); err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CreateLedgerInvite godoc
+// @Summary      Invite someone to a ledger
+// @Description  Issue a single-use invitation token granting the editor or viewer role; only owners may. The token is returned only in this response, and can be restricted to one email address.
+// @Tags         ledgers
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int                         true  "Ledger ID"
+// @Param        invite  body      dto.LedgerInviteRequestDTO  true  "Invitation"
+// @Success      201     {object}  dto.LedgerInviteResponseDTO
+// @Failure      400     {object}  map[string]string
+// @Failure      403     {object}  map[string]string
+// @Failure      404     {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/ledgers/{id}/invites [post]
+func (h *LedgerHandler) CreateLedgerInvite(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ledger ID"})
+		return
+	}
+
+	var req dto.LedgerInviteRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+
+	invite, err := h.LedgerService.CreateInvite(currentUserID(c), id, req)
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, invite)
+}
+
+// GetLedgerInvites godoc
+// @Summary      Get ledger invitations
+// @Description  List the invitations of a ledger, newest first, with whether each is pending, accepted or expired; only owners may
+// @Tags         ledgers
+// @Produce      json
+// @Param        id   path      int  true  "Ledger ID"
+// @Success      200  {array}   dto.LedgerInviteResponseDTO
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/ledgers/{id}/invites [get]
+func (h *LedgerHandler) GetLedgerInvites(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ledger ID"})
+		return
+	}
+
+	invites, err := h.LedgerService.GetInvites(currentUserID(c), id)
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, invites)
+}
+
+// RevokeLedgerInvite godoc
+// @Summary      Revoke an invitation
+// @Description  Delete an invitation so its token can no longer be used; only owners may
+// @Tags         ledgers
+// @Param        id         path  int  true  "Ledger ID"
+// @Param        invite_id  path  int  true  "Invitation ID"
+// @Success      204
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/ledgers/{id}/invites/{invite_id} [delete]
+func (h *LedgerHandler) RevokeLedgerInvite(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ledger ID"})
+		return
+	}
+	inviteID, err := strconv.Atoi(c.Param("invite_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+		return
+	}
+
+	if err := h.LedgerService.RevokeInvite(currentUserID(c), id, inviteID); err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AcceptLedgerInvite godoc
+// @Summary      Accept an invitation
+// @Description  Join the ledger of an invitation token with the role it grants. Tokens work once and until they expire.
+// @Tags         ledgers
+// @Accept       json
+// @Produce      json
+// @Param        invite  body      dto.LedgerInviteAcceptDTO  true  "Invitation token"
+// @Success      200     {object}  dto.LedgerResponseDTO
+// @Failure      400     {object}  map[string]string
+// @Failure      403     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/invites/accept [post]
+func (h *LedgerHandler) AcceptLedgerInvite(c *gin.Context) {
+	var req dto.LedgerInviteAcceptDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+
+	ledger, err := h.LedgerService.AcceptInvite(currentUserID(c), req)
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ledger)
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"goExpenseTracker/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OIDCHandler struct {
+	OIDCService services.OIDCService
+}
+
+// NewOIDCHandler creates a new OIDCHandler
+func NewOIDCHandler(service services.OIDCService) *OIDCHandler {
+	return &OIDCHandler{
+		OIDCService: service,
+	}
+}
+
+// BeginOIDCLogin godoc
+// @Summary      Sign in with the identity provider
+// @Description  Start single sign-on: redirects to the configured OpenID Connect provider using the authorization code flow with PKCE. The provider sends the user back to the callback, which answers with an access token. Users signing in for the first time get an account unless OIDC_AUTO_PROVISION=false.
+// @Tags         auth
+// @Success      302
+// @Failure      404  {object}  map[string]string
+// @Failure      502  {object}  map[string]string
+// @Router       /v1/auth/oidc/login [get]
+func (h *OIDCHandler) BeginOIDCLogin(c *gin.Context) {
+	url, err := h.OIDCService.Begin(c.Request.Context())
+	if err != nil {
+		status := http.StatusBadGateway
+		if errors.Is(err, services.ErrOIDCDisabled) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Redirect(http.StatusFound, url)
+}
+
+// CompleteOIDCLogin godoc
+// @Summary      Single sign-on callback
+// @Description  Where the identity provider sends the user back. The authorization code is redeemed with the PKCE verifier, the ID token checked against the provider's signing keys, and the user it names signed in. Users with two-factor sign-in get a 401 with a challenge, as from POST /v1/auth/login.
+// @Tags         auth
+// @Produce      json
+// @Param        code               query     string  false  "Authorization code"
+// @Param        state              query     string  true   "State issued when the sign-in started"
+// @Param        error              query     string  false  "Error reported by the identity provider"
+// @Param        error_description  query     string  false  "Details of the error"
+// @Success      200                {object}  dto.AuthTokenDTO
+// @Failure      400                {object}  map[string]string
+// @Failure      401                {object}  dto.TwoFactorChallengeDTO
+// @Failure      403                {object}  map[string]string
+// @Failure      404                {object}  map[string]string
+// @Failure      502                {object}  map[string]string
+// @Router       /v1/auth/oidc/callback [get]
+func (h *OIDCHandler) CompleteOIDCLogin(c *gin.Context) {
+	if providerErr := c.Query("error"); providerErr != "" {
+		message := "the identity provider refused the sign-in: " + providerErr
+		if description := c.Query("error_description"); description != "" {
+			message += " (" + description + ")"
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": message})
+		return
+	}
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code and state are required"})
+		return
+	}
+
+	token, err := h.OIDCService.Complete(c.Request.Context(), state, code)
+	if err != nil {
+		if errors.Is(err, services.ErrOIDCDisabled) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		signInFailed(c, err, http.StatusBadGateway)
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
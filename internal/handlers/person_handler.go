@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PersonHandler struct {
+	PersonService services.PersonService
+}
+
+// NewPersonHandler creates a new PersonHandler
+func NewPersonHandler(service services.PersonService) *PersonHandler {
+	return &PersonHandler{
+		PersonService: service,
+	}
+}
+
+// CreatePerson godoc
+// @Summary      Create a new person
+// @Description  Add someone who can pay for or share expenses
+// @Tags         people
+// @Accept       json
+// @Produce      json
+// @Param        person  body      dto.PersonRequestDTO  true  "Person Data"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      201     {object}  dto.PersonResponseDTO
+// @Failure      400     {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/people [post]
+func (h *PersonHandler) CreatePerson(c *gin.Context) {
+	var req dto.PersonRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	person, err := h.PersonService.Create(currentAccess(c), req)
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, person)
+}
+
+// GetAllPeople godoc
+// @Summary      Get all people
+// @Description  Retrieve people with pagination and filtering
+// @Tags         people
+// @Produce      json
+// @Param        name    query  string  false  "Filter by name (partial match)"
+// @Param        offset  query  int     false  "Offset for pagination" default(0)
+// @Param        limit   query  int     false  "Limit for pagination" default(10)
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200  {array}   dto.PersonResponseDTO
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/people [get]
+func (h *PersonHandler) GetAllPeople(c *gin.Context) {
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	name := c.DefaultQuery("name", "")
+
+	people, err := h.PersonService.GetAll(currentAccess(c), offset, limit, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, people)
+}
+
+// GetPersonByID godoc
+// @Summary      Get person by ID
+// @Description  Retrieve a person by their ID
+// @Tags         people
+// @Produce      json
+// @Param        id   path      int  true  "Person ID"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200  {object}  dto.PersonResponseDTO
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/people/{id} [get]
+func (h *PersonHandler) GetPersonByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid person ID"})
+		return
+	}
+
+	person, err := h.PersonService.GetByID(currentAccess(c), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, person)
+}
+
+// UpdatePerson godoc
+// @Summary      Update person
+// @Description  Modify a person's name or email
+// @Tags         people
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int                   true  "Person ID"
+// @Param        person  body      dto.PersonRequestDTO  true  "Updated Person Data"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200     {object}  dto.PersonResponseDTO
+// @Failure      400     {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/people/{id} [put]
+func (h *PersonHandler) UpdatePerson(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid person ID"})
+		return
+	}
+
+	var req dto.PersonRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	person, err := h.PersonService.Update(currentAccess(c), id, req)
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, person)
+}
+
+// DeletePerson godoc
+// @Summary      Delete person
+// @Description  Remove a person who has no shared expenses or settlements
+// @Tags         people
+// @Param        id   path  int  true  "Person ID"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      204  "No Content"
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/people/{id} [delete]
+func (h *PersonHandler) DeletePerson(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid person ID"})
+		return
+	}
+
+	if err := h.PersonService.Delete(currentAccess(c), id); err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
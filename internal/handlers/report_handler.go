@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportHandler struct {
+	ReportService services.ReportService
+}
+
+// NewReportHandler creates a new ReportHandler
+func NewReportHandler(service services.ReportService) *ReportHandler {
+	return &ReportHandler{
+		ReportService: service,
+	}
+}
+
+// GetCategoryReport godoc
+// @Summary      Spending by category
+// @Description  Total spending per category over a date range; split expenses count towards each split line's category
+// @Tags         reports
+// @Produce      json
+// @Param        from  query  string  false  "First day (dd-mm-yyyy or yyyy-mm-dd)"
+// @Param        to    query  string  false  "Last day (dd-mm-yyyy or yyyy-mm-dd)"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200  {object}  dto.CategoryReportDTO
+// @Failure      400  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/reports/categories [get]
+func (h *ReportHandler) GetCategoryReport(c *gin.Context) {
+	from, err := dto.ParseOptionalDate(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from: " + err.Error()})
+		return
+	}
+	to, err := dto.ParseOptionalDate(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to: " + err.Error()})
+		return
+	}
+
+	report, err := h.ReportService.CategoryTotals(currentAccess(c), from, to)
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetExpensePDFReport godoc
+// @Summary      Expense report as PDF
+// @Description  Render the selected expenses as a PDF for sign-off: a summary of category subtotals, the lines of each category with its subtotal, the grand total and signature lines. Split expenses count towards each split line's category; refunds reduce the totals and income is left out. With receipts=true, thumbnails of JPEG, PNG and GIF receipts are appended; other receipts are listed without a preview.
+// @Tags         reports
+// @Produce      application/pdf
+// @Param        from         query  string    false  "First day (dd-mm-yyyy or yyyy-mm-dd)"
+// @Param        to           query  string    false  "Last day (dd-mm-yyyy or yyyy-mm-dd)"
+// @Param        category_id  query  int       false  "Only lines in this category, including split lines"
+// @Param        tag_id       query  int       false  "Only expenses carrying this tag"
+// @Param        account_id   query  int       false  "Only expenses paid from this account"
+// @Param        description  query  string    false  "Filter by description (case-insensitive)"
+// @Param        title        query  string    false  "Report title" default(Expense report)
+// @Param        receipts     query  bool      false  "Append receipt thumbnails" default(false)
+// @Param        sign_off     query  []string  false  "Role given a signature line; defaults to Prepared by and Approved by, a single empty value prints none" collectionFormat(multi)
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200  {file}    file
+// @Failure      400  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/reports/expenses/pdf [get]
+func (h *ReportHandler) GetExpensePDFReport(c *gin.Context) {
+	var req dto.ExpenseReportPDFDTO
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := h.ReportService.ExpensePDF(currentAccess(c), &buf, req); err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="expense-report.pdf"`)
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RuleHandler struct {
+	RuleService services.RuleService
+}
+
+// NewRuleHandler creates a new RuleHandler
+func NewRuleHandler(service services.RuleService) *RuleHandler {
+	return &RuleHandler{
+		RuleService: service,
+	}
+}
+
+// CreateRule godoc
+// @Summary      Create a categorisation rule
+// @Description  Add a rule applied to expenses created or imported without a category. Conditions (pattern, amount range, account) must all hold; matching rules run in priority order, every one adds its tags and the first to set a category or description wins.
+// @Tags         rules
+// @Accept       json
+// @Produce      json
+// @Param        rule  body      dto.RuleRequestDTO  true  "Rule Data"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      201   {object}  dto.RuleResponseDTO
+// @Failure      400   {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/rules [post]
+func (h *RuleHandler) CreateRule(c *gin.Context) {
+	var req dto.RuleRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.RuleService.Create(currentAccess(c), req)
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// GetAllRules godoc
+// @Summary      Get all rules
+// @Description  Retrieve rules in the order they run, with pagination
+// @Tags         rules
+// @Produce      json
+// @Param        offset  query  int  false  "Offset for pagination" default(0)
+// @Param        limit   query  int  false  "Limit for pagination" default(10)
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200  {array}   dto.RuleResponseDTO
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/rules [get]
+func (h *RuleHandler) GetAllRules(c *gin.Context) {
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	rules, err := h.RuleService.GetAll(currentAccess(c), offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// GetRuleByID godoc
+// @Summary      Get rule by ID
+// @Description  Retrieve a rule by its ID
+// @Tags         rules
+// @Produce      json
+// @Param        id   path      int  true  "Rule ID"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200  {object}  dto.RuleResponseDTO
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/rules/{id} [get]
+func (h *RuleHandler) GetRuleByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+
+	rule, err := h.RuleService.GetByID(currentAccess(c), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// UpdateRule godoc
+// @Summary      Update rule
+// @Description  Replace a rule's conditions and actions
+// @Tags         rules
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int                 true  "Rule ID"
+// @Param        rule  body      dto.RuleRequestDTO  true  "Updated Rule Data"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200   {object}  dto.RuleResponseDTO
+// @Failure      400   {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/rules/{id} [put]
+func (h *RuleHandler) UpdateRule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+
+	var req dto.RuleRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.RuleService.Update(currentAccess(c), id, req)
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteRule godoc
+// @Summary      Delete rule
+// @Description  Remove a rule; expenses it already categorised keep their category
+// @Tags         rules
+// @Param        id   path  int  true  "Rule ID"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      204  "No Content"
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/rules/{id} [delete]
+func (h *RuleHandler) DeleteRule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+
+	if err := h.RuleService.Delete(currentAccess(c), id); err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// TestRule godoc
+// @Summary      Test a rule against existing expenses
+// @Description  Run a rule without saving it against every existing expense, whatever its category, and list the matches with the category and description the rule would give them
+// @Tags         rules
+// @Accept       json
+// @Produce      json
+// @Param        rule   body      dto.RuleRequestDTO  true   "Rule to test"
+// @Param        limit  query     int                 false  "Maximum number of matches listed" default(20)
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200    {object}  dto.RuleTestResultDTO
+// @Failure      400    {object}  map[string]string
+// @Failure      500    {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/rules/test [post]
+func (h *RuleHandler) TestRule(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a non-negative number"})
+		return
+	}
+
+	var req dto.RuleRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.RuleService.Test(currentAccess(c), req, limit)
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SharingHandler struct {
+	SharingService services.SharingService
+}
+
+// NewSharingHandler creates a new SharingHandler
+func NewSharingHandler(service services.SharingService) *SharingHandler {
+	return &SharingHandler{
+		SharingService: service,
+	}
+}
+
+// GetBalances godoc
+// @Summary      Shared expense balances
+// @Description  Per-person totals paid and owed across all shared expenses, net of recorded settlements
+// @Tags         sharing
+// @Produce      json
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200  {array}   dto.PersonBalanceDTO
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/shared/balances [get]
+func (h *SharingHandler) GetBalances(c *gin.Context) {
+	balances, err := h.SharingService.Balances(currentAccess(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, balances)
+}
+
+// GetSettleUp godoc
+// @Summary      Suggested settle-up transfers
+// @Description  A small set of transfers that brings every shared balance back to zero
+// @Tags         sharing
+// @Produce      json
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200  {array}   dto.SettlementResponseDTO
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/shared/settle-up [get]
+func (h *SharingHandler) GetSettleUp(c *gin.Context) {
+	transfers, err := h.SharingService.SettleUp(currentAccess(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, transfers)
+}
+
+// CreateSettlement godoc
+// @Summary      Record a settlement
+// @Description  Record money paid back from one person to another
+// @Tags         sharing
+// @Accept       json
+// @Produce      json
+// @Param        settlement  body      dto.SettlementRequestDTO  true  "Settlement Data"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      201         {object}  dto.SettlementResponseDTO
+// @Failure      400         {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500         {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/settlements [post]
+func (h *SharingHandler) CreateSettlement(c *gin.Context) {
+	var req dto.SettlementRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settlement, err := h.SharingService.CreateSettlement(currentAccess(c), req)
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, settlement)
+}
+
+// GetAllSettlements godoc
+// @Summary      Get all settlements
+// @Description  Retrieve recorded settlements with pagination, optionally involving one person
+// @Tags         sharing
+// @Produce      json
+// @Param        person_id  query  int  false  "Only settlements paid or received by this person"
+// @Param        offset     query  int  false  "Offset for pagination" default(0)
+// @Param        limit      query  int  false  "Limit for pagination" default(10)
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200  {array}   dto.SettlementResponseDTO
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/settlements [get]
+func (h *SharingHandler) GetAllSettlements(c *gin.Context) {
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	personID, _ := strconv.Atoi(c.DefaultQuery("person_id", "0"))
+
+	settlements, err := h.SharingService.GetSettlements(currentAccess(c), offset, limit, personID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, settlements)
+}
+
+// DeleteSettlement godoc
+// @Summary      Delete settlement
+// @Description  Remove a recorded settlement by its ID
+// @Tags         sharing
+// @Param        id   path  int  true  "Settlement ID"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      204  "No Content"
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/settlements/{id} [delete]
+func (h *SharingHandler) DeleteSettlement(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid settlement ID"})
+		return
+	}
+
+	if err := h.SharingService.DeleteSettlement(currentAccess(c), id); err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"goExpenseTracker/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SuggestionHandler struct {
+	SuggestionService services.SuggestionService
+}
+
+// NewSuggestionHandler creates a new SuggestionHandler
+func NewSuggestionHandler(service services.SuggestionService) *SuggestionHandler {
+	return &SuggestionHandler{
+		SuggestionService: service,
+	}
+}
+
+// SuggestCategory godoc
+// @Summary      Suggest categories for a description
+// @Description  Rank the categories likely for an expense description using a naive Bayes model learned from the descriptions and categories of past expenses. The model follows expenses as they are created, recategorised and deleted. Words never seen before are ignored; a description with no known word gets no suggestions.
+// @Tags         categories
+// @Produce      json
+// @Param        description  query     string  true   "Expense description"
+// @Param        limit        query     int     false  "Maximum number of suggestions" default(3)
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200          {object}  dto.CategorySuggestionsDTO
+// @Failure      400          {object}  map[string]string
+// @Failure      500          {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/categories/suggest [get]
+func (h *SuggestionHandler) SuggestCategory(c *gin.Context) {
+	description := strings.TrimSpace(c.Query("description"))
+	if description == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "description is required"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "3"))
+	if err != nil || limit < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive number"})
+		return
+	}
+
+	result, err := h.SuggestionService.Suggest(currentAccess(c), description, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TransferHandler struct {
+	TransferService services.TransferService
+}
+
+// NewTransferHandler creates a new TransferHandler
+func NewTransferHandler(service services.TransferService) *TransferHandler {
+	return &TransferHandler{
+		TransferService: service,
+	}
+}
+
+// CreateTransfer godoc
+// @Summary      Transfer between accounts
+// @Description  Move money from one account to another account with the same currency
+// @Tags         transfers
+// @Accept       json
+// @Produce      json
+// @Param        transfer  body      dto.TransferRequestDTO  true  "Transfer Data"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      201       {object}  dto.TransferResponseDTO
+// @Failure      400       {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/transfers [post]
+func (h *TransferHandler) CreateTransfer(c *gin.Context) {
+	var req dto.TransferRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	transfer, err := h.TransferService.Create(currentAccess(c), req)
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, transfer)
+}
+
+// GetAllTransfers godoc
+// @Summary      Get all transfers
+// @Description  Retrieve transfers with pagination, optionally for a single account
+// @Tags         transfers
+// @Produce      json
+// @Param        account_id  query  int  false  "Only transfers from or to this account"
+// @Param        offset      query  int  false  "Offset for pagination" default(0)
+// @Param        limit       query  int  false  "Limit for pagination" default(10)
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200  {array}   dto.TransferResponseDTO
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/transfers [get]
+func (h *TransferHandler) GetAllTransfers(c *gin.Context) {
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	accountID, _ := strconv.Atoi(c.DefaultQuery("account_id", "0"))
+
+	transfers, err := h.TransferService.GetAll(currentAccess(c), offset, limit, accountID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, transfers)
+}
+
+// GetTransferByID godoc
+// @Summary      Get transfer by ID
+// @Description  Retrieve a single transfer by its ID
+// @Tags         transfers
+// @Produce      json
+// @Param        id   path      int  true  "Transfer ID"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      200  {object}  dto.TransferResponseDTO
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/transfers/{id} [get]
+func (h *TransferHandler) GetTransferByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transfer ID"})
+		return
+	}
+
+	transfer, err := h.TransferService.GetByID(currentAccess(c), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+// DeleteTransfer godoc
+// @Summary      Delete transfer
+// @Description  Remove a transfer by its ID
+// @Tags         transfers
+// @Param        id   path  int  true  "Transfer ID"
+// @Param        X-Ledger-ID  header    int  false  "Ledger to work on; defaults to the personal ledger"
+// @Success      204  "No Content"
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/transfers/{id} [delete]
+func (h *TransferHandler) DeleteTransfer(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transfer ID"})
+		return
+	}
+
+	if err := h.TransferService.Delete(currentAccess(c), id); err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorHandler struct {
+	TwoFactorService services.TwoFactorService
+}
+
+// NewTwoFactorHandler creates a new TwoFactorHandler
+func NewTwoFactorHandler(service services.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		TwoFactorService: service,
+	}
+}
+
+// GetTwoFactorStatus godoc
+// @Summary      Get two-factor sign-in status
+// @Description  Tell whether sign-ins of the signed-in user ask for a TOTP code, and how many recovery codes are left
+// @Tags         two-factor
+// @Produce      json
+// @Success      200  {object}  dto.TwoFactorStatusDTO
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/auth/2fa [get]
+func (h *TwoFactorHandler) GetTwoFactorStatus(c *gin.Context) {
+	status, err := h.TwoFactorService.Status(currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// EnrollTwoFactor godoc
+// @Summary      Start two-factor enrolment
+// @Description  Create a TOTP secret for the signed-in user. Show the provisioning URI as a QR code for an authenticator app to scan, or the secret to type in, then confirm with a first code. Until then sign-ins are unchanged, and enrolling again replaces the secret.
+// @Tags         two-factor
+// @Produce      json
+// @Success      201  {object}  dto.TwoFactorEnrollmentDTO
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/auth/2fa/enroll [post]
+func (h *TwoFactorHandler) EnrollTwoFactor(c *gin.Context) {
+	enrollment, err := h.TwoFactorService.Enroll(currentUserID(c))
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, enrollment)
+}
+
+// ConfirmTwoFactor godoc
+// @Summary      Confirm two-factor enrolment
+// @Description  Turn two-factor sign-in on with a first code from the authenticator app. The response holds the recovery codes, each of which signs in once in place of a code; they are shown only this once.
+// @Tags         two-factor
+// @Accept       json
+// @Produce      json
+// @Param        code  body      dto.TwoFactorCodeRequestDTO  true  "Code from the authenticator app"
+// @Success      200   {object}  dto.RecoveryCodesDTO
+// @Failure      400   {object}  map[string]string
+// @Failure      403   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/auth/2fa/confirm [post]
+func (h *TwoFactorHandler) ConfirmTwoFactor(c *gin.Context) {
+	var req dto.TwoFactorCodeRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+
+	codes, err := h.TwoFactorService.Confirm(currentUserID(c), req.Code)
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, codes)
+}
+
+// DisableTwoFactor godoc
+// @Summary      Turn two-factor sign-in off
+// @Description  Stop asking for a second factor at sign-in, given a current code or a recovery code. An enrolment not confirmed yet is cancelled without one.
+// @Tags         two-factor
+// @Accept       json
+// @Param        code  body  dto.TwoFactorCodeRequestDTO  true  "TOTP code or recovery code"
+// @Success      204
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/auth/2fa/disable [post]
+func (h *TwoFactorHandler) DisableTwoFactor(c *gin.Context) {
+	var req dto.TwoFactorCodeRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+
+	if err := h.TwoFactorService.Disable(currentUserID(c), req.Code); err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary      Replace recovery codes
+// @Description  Issue new recovery codes, given a current code or a recovery code; every earlier recovery code stops working. They are shown only this once.
+// @Tags         two-factor
+// @Accept       json
+// @Produce      json
+// @Param        code  body      dto.TwoFactorCodeRequestDTO  true  "TOTP code or recovery code"
+// @Success      200   {object}  dto.RecoveryCodesDTO
+// @Failure      400   {object}  map[string]string
+// @Failure      403   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/auth/2fa/recovery-codes [post]
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req dto.TwoFactorCodeRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": formatBindingError(err)})
+		return
+	}
+
+	codes, err := h.TwoFactorService.RegenerateRecoveryCodes(currentUserID(c), req.Code)
+	if err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, codes)
+}
+
+// ResetTwoFactor godoc
+// @Summary      Reset a user's two-factor sign-in
+// @Description  Turn two-factor sign-in off for a user who lost their authenticator app and recovery codes, so they can sign in with their password alone and enrol again. Admins only.
+// @Tags         two-factor
+// @Param        id   path  int  true  "User ID"
+// @Success      204
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /v1/users/{id}/2fa [delete]
+func (h *TwoFactorHandler) ResetTwoFactor(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := h.TwoFactorService.Reset(currentUserID(c), id); err != nil {
+		c.JSON(serviceErrorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
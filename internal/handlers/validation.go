@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"goExpenseTracker/internal/services"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// snakeField turns a struct field name such as FromAccountID into from_account_id
+func snakeField(e validator.FieldError) string {
+	name := strings.Replace(e.Field(), "ID", "Id", -1)
+
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// formatBindingError converts validation errors of the newer request DTOs into user-friendly messages
+func formatBindingError(err error) string {
+	if validationErrs, ok := err.(validator.ValidationErrors); ok {
+		for _, e := range validationErrs {
+			field := snakeField(e)
+
+			switch e.Tag() {
+			case "required":
+				return field + " is required"
+			case "min":
+				if e.Kind().String() == "string" {
+					return field + " must be at least " + e.Param() + " characters"
+				}
+				return field + " must be at least " + e.Param()
+			case "max":
+				return field + " must not exceed " + e.Param() + " characters"
+			case "len":
+				return field + " must be exactly " + e.Param() + " characters"
+			case "gt":
+				return field + " must be greater than " + e.Param()
+			case "gte":
+				return field + " must be at least " + e.Param()
+			case "oneof":
+				return field + " must be one of: " + strings.ReplaceAll(e.Param(), " ", ", ")
+			default:
+				return field + " is invalid"
+			}
+		}
+	}
+	return err.Error()
+}
+
+// serviceErrorStatus answers 400 for invalid input reported by a service, 401 and 403
+// for failed sign-ins and refused requests, and the fallback otherwise
+func serviceErrorStatus(err error, fallback int) int {
+	var (
+		validationErr *services.ValidationError
+		authErr       *services.AuthError
+		forbiddenErr  *services.ForbiddenError
+	)
+	switch {
+	case errors.As(err, &validationErr):
+		return http.StatusBadRequest
+	case errors.As(err, &authErr):
+		return http.StatusUnauthorized
+	case errors.As(err, &forbiddenErr):
+		return http.StatusForbidden
+	}
+	return fallback
+}
@@ -0,0 +1,224 @@
+package importers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// camtDocument is the part of an ISO 20022 camt.053 (bank to customer statement)
+// message the importer reads. Element names are matched without their namespace,
+// so every camt.053.001.xx version decodes the same way.
+type camtDocument struct {
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	IBAN     string      `xml:"Acct>Id>IBAN"`
+	OtherID  string      `xml:"Acct>Id>Othr>Id"`
+	Currency string      `xml:"Acct>Ccy"`
+	Entries  []camtEntry `xml:"Ntry"`
+}
+
+type camtEntry struct {
+	Reference      string          `xml:"NtryRef"`
+	Amount         camtAmount      `xml:"Amt"`
+	CreditDebit    string          `xml:"CdtDbtInd"`
+	Reversal       bool            `xml:"RvslInd"`
+	Status         camtStatus      `xml:"Sts"`
+	BookingDate    camtDate        `xml:"BookgDt"`
+	ValueDate      camtDate        `xml:"ValDt"`
+	ServicerRef    string          `xml:"AcctSvcrRef"`
+	SubFamily      string          `xml:"BkTxCd>Domn>Fmly>SubFmlyCd"`
+	Details        []camtTxDetails `xml:"NtryDtls>TxDtls"`
+	AdditionalInfo string          `xml:"AddtlNtryInf"`
+}
+
+// camtStatus is a plain code in camt.053.001.02 to .07 and a Cd element from .08 on
+type camtStatus struct {
+	Value string `xml:",chardata"`
+	Code  string `xml:"Cd"`
+}
+
+type camtTxDetails struct {
+	ServicerRef   string      `xml:"Refs>AcctSvcrRef"`
+	EndToEndID    string      `xml:"Refs>EndToEndId"`
+	TxID          string      `xml:"Refs>TxId"`
+	Amount        *camtAmount `xml:"Amt"`
+	CreditorName  string      `xml:"RltdPties>Cdtr>Nm"`
+	CreditorParty string      `xml:"RltdPties>Cdtr>Pty>Nm"`
+	DebtorName    string      `xml:"RltdPties>Dbtr>Nm"`
+	DebtorParty   string      `xml:"RltdPties>Dbtr>Pty>Nm"`
+	Unstructured  []string    `xml:"RmtInf>Ustrd"`
+	CreditorRef   string      `xml:"RmtInf>Strd>CdtrRefInf>Ref"`
+	Additional    string      `xml:"AddtlTxInf"`
+}
+
+type camtAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+// camtIncomeSubFamilies are bank transaction codes of credits that are earnings
+var camtIncomeSubFamilies = map[string]bool{
+	"INTR": true, // Interest
+	"DVDN": true, // Dividend
+	"SALA": true, // Salary
+}
+
+// ParseCamt053 reads the booked entries of a camt.053 statement. Each entry becomes
+// a record dated on its value date, unless it bundles several transactions with
+// their own amounts, in which case each transaction becomes a record. Pending
+// entries are listed as skipped; the bank's reference is the external ID.
+func ParseCamt053(r io.Reader) ([]Record, error) {
+	var doc camtDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Statements) == 0 {
+		return nil, fmt.Errorf("no BkToCstmrStmt/Stmt element found")
+	}
+
+	var records []Record
+	for _, stmt := range doc.Statements {
+		account := stmt.IBAN
+		if account == "" {
+			account = stmt.OtherID
+		}
+
+		for _, entry := range stmt.Entries {
+			details := entry.Details
+			split := len(details) > 1
+			for _, detail := range details {
+				split = split && detail.Amount != nil
+			}
+			if !split {
+				var detail camtTxDetails
+				if len(details) > 0 {
+					detail = details[0]
+				}
+				details = []camtTxDetails{detail}
+			}
+
+			for _, detail := range details {
+				record := camtRecord(len(records)+1, account, stmt.Currency, entry, detail, split)
+				records = append(records, record)
+			}
+		}
+	}
+	return records, nil
+}
+
+// camtRecord maps an entry, or one transaction of a batch entry, onto a record
+func camtRecord(number int, account string, currency string, entry camtEntry, detail camtTxDetails, split bool) Record {
+	record := Record{Line: number}
+
+	// The entry's own reference identifies it unless it is split into transactions
+	reference := firstNonEmpty(entry.ServicerRef, entry.Reference, detail.ServicerRef, detail.TxID, detail.EndToEndID)
+	if split {
+		reference = firstNonEmpty(detail.ServicerRef, detail.TxID, detail.EndToEndID)
+	}
+	if reference == "" {
+		record.Err = fmt.Errorf("entry has no bank reference")
+		return record
+	}
+	record.ExternalID = "camt:" + account + ":" + reference
+
+	status := strings.ToUpper(firstNonEmpty(entry.Status.Code, entry.Status.Value))
+	if status != "" && status != "BOOK" {
+		record.SkipReason = "entry is not booked (status " + status + ")"
+	}
+
+	date, err := parseCamtDate(entry.ValueDate)
+	if err != nil || date.IsZero() {
+		date, err = parseCamtDate(entry.BookingDate)
+	}
+	if err != nil {
+		record.Err = err
+		return record
+	}
+	if date.IsZero() {
+		record.Err = fmt.Errorf("entry has no value or booking date")
+		return record
+	}
+	record.Date = date
+
+	amount := entry.Amount
+	if split {
+		amount = *detail.Amount
+	}
+	value, err := ParseAmount(amount.Value, ".")
+	if err != nil {
+		record.Err = err
+		return record
+	}
+	if value == 0 {
+		record.Err = fmt.Errorf("entry amount is zero")
+		return record
+	}
+	record.Amount = value
+	record.Currency = strings.ToUpper(firstNonEmpty(amount.Currency, currency))
+
+	// A reversed debit gives money back, a reversed credit takes it away again
+	debit := strings.EqualFold(entry.CreditDebit, "DBIT") != entry.Reversal
+	switch {
+	case debit:
+		record.Kind = "expense"
+	case camtIncomeSubFamilies[strings.ToUpper(entry.SubFamily)]:
+		record.Kind = "income"
+	default:
+		record.Kind = "refund"
+	}
+
+	// The counterparty is whoever is on the other side of the booking
+	counterparty := firstNonEmpty(detail.CreditorName, detail.CreditorParty)
+	if !strings.EqualFold(entry.CreditDebit, "DBIT") {
+		counterparty = firstNonEmpty(detail.DebtorName, detail.DebtorParty)
+	}
+	remittance := strings.Join(detail.Unstructured, " ")
+	if remittance == "" {
+		remittance = detail.CreditorRef
+	}
+	record.Description = joinDescription(counterparty, remittance)
+	if record.Description == "" {
+		record.Description = joinDescription(entry.AdditionalInfo, detail.Additional)
+	}
+
+	return record
+}
+
+// parseCamtDate reads an ISODate or ISODateTime, returning the zero time when neither is set
+func parseCamtDate(value camtDate) (time.Time, error) {
+	raw := strings.TrimSpace(value.Date)
+	if raw == "" {
+		raw = strings.TrimSpace(value.DateTime)
+	}
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if len(raw) < 10 {
+		return time.Time{}, fmt.Errorf("invalid date %q", raw)
+	}
+	t, err := time.Parse("2006-01-02", raw[:10])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", raw)
+	}
+	return t, nil
+}
+
+// firstNonEmpty returns the first value that is not blank
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,177 @@
+package importers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// CSVColumns names the column holding each field: a header name when the file has
+// a header row, otherwise (or when numeric) a zero-based column index
+type CSVColumns struct {
+	Date        string
+	Amount      string
+	Description string
+	Category    string
+}
+
+// CSVOptions controls how a spreadsheet export is read
+type CSVOptions struct {
+	Columns          CSVColumns
+	CategoryByID     bool     // Category column holds IDs rather than names
+	DateFormats      []string // Patterns such as dd/mm/yyyy, tried in order
+	DecimalSeparator string   // "." or ","
+	Delimiter        rune
+	HasHeader        bool
+}
+
+// ParseCSV reads every row into a Record. Problems with a single row are reported on
+// that record; an error is only returned when the file as a whole cannot be read.
+func ParseCSV(r io.Reader, opts CSVOptions) ([]Record, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = opts.Delimiter
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var header []string
+	if opts.HasHeader {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("the file is empty")
+		}
+		if err != nil {
+			return nil, err
+		}
+		header = row
+		if len(header) > 0 {
+			header[0] = strings.TrimPrefix(header[0], "\ufeff")
+		}
+	}
+
+	date, err := columnIndex(header, opts.Columns.Date, true)
+	if err != nil {
+		return nil, fmt.Errorf("date column: %w", err)
+	}
+	amount, err := columnIndex(header, opts.Columns.Amount, true)
+	if err != nil {
+		return nil, fmt.Errorf("amount column: %w", err)
+	}
+	description, err := columnIndex(header, opts.Columns.Description, false)
+	if err != nil {
+		return nil, fmt.Errorf("description column: %w", err)
+	}
+	category, err := columnIndex(header, opts.Columns.Category, false)
+	if err != nil {
+		return nil, fmt.Errorf("category column: %w", err)
+	}
+
+	records := make([]Record, 0)
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				records = append(records, Record{Line: parseErr.Line, Err: parseErr.Err})
+				continue
+			}
+			return nil, err
+		}
+		if isBlank(row) {
+			continue
+		}
+
+		records = append(records, parseCSVRow(row, line, opts, date, amount, description, category))
+	}
+
+	return records, nil
+}
+
+func parseCSVRow(row []string, line int, opts CSVOptions, date, amount, description, category int) Record {
+	record := Record{Line: line}
+	field := func(index int) string {
+		if index < 0 || index >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[index])
+	}
+
+	parsedDate, err := ParseDate(field(date), opts.DateFormats)
+	if err != nil {
+		record.Err = err
+		return record
+	}
+	record.Date = parsedDate
+
+	parsedAmount, err := ParseAmount(field(amount), opts.DecimalSeparator)
+	if err != nil {
+		record.Err = err
+		return record
+	}
+	// Bank exports often write money going out as negative numbers
+	record.Amount = math.Abs(parsedAmount)
+
+	record.Description = field(description)
+
+	if value := field(category); value != "" {
+		if opts.CategoryByID {
+			id, err := strconv.Atoi(value)
+			if err != nil || id < 1 {
+				record.Err = fmt.Errorf("invalid category ID %q", value)
+				return record
+			}
+			record.CategoryID = id
+		} else {
+			record.CategoryName = value
+		}
+	}
+
+	return record
+}
+
+// columnIndex resolves a column reference against the header row
+func columnIndex(header []string, column string, required bool) (int, error) {
+	column = strings.TrimSpace(column)
+	if column == "" {
+		if required {
+			return -1, fmt.Errorf("mapping is required")
+		}
+		return -1, nil
+	}
+
+	if index, err := strconv.Atoi(column); err == nil {
+		if index < 0 {
+			return -1, fmt.Errorf("index must not be negative")
+		}
+		return index, nil
+	}
+
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), column) {
+			return i, nil
+		}
+	}
+	if header == nil {
+		return -1, fmt.Errorf("%q must be a column index when the file has no header row", column)
+	}
+	if !required {
+		return -1, nil
+	}
+	return -1, fmt.Errorf("no column named %q in the header row", column)
+}
+
+func isBlank(row []string) bool {
+	for _, value := range row {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
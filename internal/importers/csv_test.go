@@ -0,0 +1,153 @@
+package importers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// openSample opens a file under testdata, closing it when the test ends
+func openSample(t *testing.T, name string) *os.File {
+	t.Helper()
+	file, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("open %s: %v", name, err)
+	}
+	t.Cleanup(func() { file.Close() })
+	return file
+}
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+// wantRecord is what a test expects of one parsed record; Err is a substring of
+// the record's error, empty when it should have none
+type wantRecord struct {
+	Line        int
+	Date        time.Time
+	Amount      float64
+	Kind        string
+	Currency    string
+	Description string
+	Category    string
+	ExternalID  string
+	SkipReason  string
+	Err         string
+}
+
+func checkRecords(t *testing.T, got []Record, want []wantRecord) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d records, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		g := got[i]
+		if w.Err != "" {
+			if g.Err == nil || !strings.Contains(g.Err.Error(), w.Err) {
+				t.Errorf("record %d: got error %v, want one containing %q", i, g.Err, w.Err)
+			}
+			continue
+		}
+		if g.Err != nil {
+			t.Errorf("record %d: unexpected error %v", i, g.Err)
+			continue
+		}
+		if w.Line != 0 && g.Line != w.Line {
+			t.Errorf("record %d: got line %d, want %d", i, g.Line, w.Line)
+		}
+		if !g.Date.Equal(w.Date) || g.Amount != w.Amount || g.Kind != w.Kind || g.Currency != w.Currency ||
+			g.Description != w.Description || g.CategoryName != w.Category || g.ExternalID != w.ExternalID ||
+			g.SkipReason != w.SkipReason {
+			t.Errorf("record %d:\n got %+v\nwant %+v", i, g, w)
+		}
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	named := CSVColumns{Date: "date", Amount: "amount", Description: "description", Category: "category"}
+	cases := []struct {
+		name    string
+		file    string
+		opts    CSVOptions
+		want    []wantRecord
+		wantErr string
+	}{
+		{
+			name: "decimal point, negative and bracketed amounts",
+			file: "bank_dot.csv",
+			opts: CSVOptions{Columns: named, DateFormats: []string{"dd-mm-yyyy"}, DecimalSeparator: ".", Delimiter: ',', HasHeader: true},
+			want: []wantRecord{
+				{Line: 2, Date: day(2025, 1, 15), Amount: 12.5, Description: "Coffee beans", Category: "Groceries"},
+				{Line: 3, Date: day(2025, 1, 16), Amount: 1234, Description: "Laptop", Category: "Electronics"},
+				{Err: `invalid amount "abc"`},
+				{Err: "invalid date"},
+				{Line: 7, Date: day(2025, 1, 18), Amount: 7.25, Description: "Parking"},
+			},
+		},
+		{
+			name: "decimal comma with semicolons",
+			file: "bank_comma.csv",
+			opts: CSVOptions{
+				Columns:     CSVColumns{Date: "Datum", Amount: "Betrag", Description: "Text", Category: "Kategorie"},
+				DateFormats: []string{"dd-mm-yyyy", "dd.mm.yyyy"}, DecimalSeparator: ",", Delimiter: ';', HasHeader: true,
+			},
+			want: []wantRecord{
+				{Line: 2, Date: day(2025, 1, 15), Amount: 12.5, Description: "Kaffee", Category: "Lebensmittel"},
+				{Line: 3, Date: day(2025, 1, 16), Amount: 1234, Description: "Laptop", Category: "Technik"},
+				{Err: `invalid amount "12-5"`},
+			},
+		},
+		{
+			name: "no header, columns by index and categories by ID",
+			file: "no_header.csv",
+			opts: CSVOptions{
+				Columns:     CSVColumns{Date: "0", Amount: "1", Description: "2", Category: "3"},
+				DateFormats: []string{"yyyy-mm-dd"}, DecimalSeparator: ".", Delimiter: ',', CategoryByID: true,
+			},
+			want: []wantRecord{
+				{Line: 1, Date: day(2025, 2, 1), Amount: 12, Description: "Taxi"},
+				{Err: `invalid category ID "x"`},
+			},
+		},
+		{
+			name: "unterminated quote",
+			file: "broken_quote.csv",
+			opts: CSVOptions{Columns: named, DateFormats: []string{"dd-mm-yyyy"}, DecimalSeparator: ".", Delimiter: ',', HasHeader: true},
+			want: []wantRecord{{Err: "quote"}},
+		},
+		{
+			name:    "missing mapped column",
+			file:    "bank_dot.csv",
+			opts:    CSVOptions{Columns: CSVColumns{Date: "posted", Amount: "amount"}, DateFormats: []string{"dd-mm-yyyy"}, DecimalSeparator: ".", Delimiter: ',', HasHeader: true},
+			wantErr: `date column: no column named "posted"`,
+		},
+		{
+			name:    "column name without a header row",
+			file:    "no_header.csv",
+			opts:    CSVOptions{Columns: CSVColumns{Date: "date", Amount: "1"}, DateFormats: []string{"yyyy-mm-dd"}, DecimalSeparator: ".", Delimiter: ','},
+			wantErr: "must be a column index",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			records, err := ParseCSV(openSample(t, c.file), c.opts)
+			if c.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, c.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			checkRecords(t, records, c.want)
+		})
+	}
+
+	if _, err := ParseCSV(strings.NewReader(""), CSVOptions{Columns: named, Delimiter: ',', HasHeader: true}); err == nil {
+		t.Error("an empty file with a header expected: got no error")
+	}
+}
@@ -0,0 +1,146 @@
+package importers
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record is one transaction read from an imported file, before it becomes an expense
+type Record struct {
+	Line         int // Line (CSV) or entry number in the source file
+	Date         time.Time
+	Amount       float64
+	Currency     string // ISO 4217 code when the file states one
+	Description  string
+	CategoryID   int    // Category given by ID in the file
+	CategoryName string // Category given by name in the file
+	Kind         string // expense, refund or income; empty means expense
+	ExternalID   string // Bank reference used to skip repeat imports
+	Splits       []SplitRecord
+	SkipReason   string // Set for entries the file lists but that should not be imported
+	Err          error  // Set when the row could not be read
+}
+
+// SplitRecord is one split line of an imported transaction
+type SplitRecord struct {
+	CategoryName string
+	Amount       float64
+	Description  string
+}
+
+// dateTokens maps the date patterns users write (dd/mm/yyyy) onto Go layouts
+var dateTokens = strings.NewReplacer(
+	"yyyy", "2006",
+	"yy", "06",
+	"MMM", "Jan",
+	"mm", "01",
+	"MM", "01",
+	"dd", "02",
+	"DD", "02",
+)
+
+// DateLayout converts a pattern such as dd/mm/yyyy into a Go time layout
+func DateLayout(pattern string) string {
+	return dateTokens.Replace(pattern)
+}
+
+// ParseDate tries each pattern in order and reports all of them when none matches
+func ParseDate(value string, patterns []string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, pattern := range patterns {
+		if t, err := time.Parse(DateLayout(pattern), value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q, expected one of: %s", value, strings.Join(patterns, ", "))
+}
+
+// currencyAffix matches a currency symbol or ISO code at either end of an amount
+var currencyAffix = regexp.MustCompile(`^(?:\p{Sc}|[A-Z]{3})|(?:\p{Sc}|[A-Z]{3})$`)
+
+// ParseAmount reads an amount written with the given decimal separator ("." or ","),
+// ignoring thousands separators, spaces and a currency symbol or code at either end
+func ParseAmount(value string, decimalSeparator string) (float64, error) {
+	thousands := ","
+	if decimalSeparator == "," {
+		thousands = "."
+	}
+
+	// Accounting notation: (12.50) is a negative amount, as is 12.50- on some statements
+	trimmed := trimCurrency(value)
+	negative := strings.HasPrefix(trimmed, "(") && strings.HasSuffix(trimmed, ")")
+	if negative {
+		trimmed = trimCurrency(trimmed[1 : len(trimmed)-1])
+	}
+
+	invalid := false
+	cleaned := strings.Map(func(r rune) rune {
+		switch {
+		case r >= '0' && r <= '9', r == '-', r == '+':
+			return r
+		case string(r) == decimalSeparator:
+			return '.'
+		case string(r) == thousands, r == ' ', r == '\'', r == '\u00a0':
+			return -1
+		default:
+			invalid = true
+			return -1
+		}
+	}, trimmed)
+	if invalid {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+
+	if negative {
+		cleaned = "-" + cleaned
+	} else if len(cleaned) > 1 && strings.HasSuffix(cleaned, "-") && !strings.ContainsAny(cleaned[:len(cleaned)-1], "+-") {
+		cleaned = "-" + strings.TrimSuffix(cleaned, "-")
+	}
+
+	// The whole number must parse, so 12-5 or 1.2.3 are refused rather than cut short
+	amount, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	return amount, nil
+}
+
+// trimCurrency strips spaces and one currency symbol or code from each end of an amount
+func trimCurrency(value string) string {
+	return strings.TrimSpace(currencyAffix.ReplaceAllString(strings.TrimSpace(value), ""))
+}
+
+// joinDescription joins the non-empty parts with " - ", leaving out repeats,
+// and shortens the result to the 255 characters a description may hold
+func joinDescription(parts ...string) string {
+	kept := make([]string, 0, len(parts))
+	for _, part := range parts {
+		part = strings.Join(strings.Fields(part), " ")
+		if part == "" {
+			continue
+		}
+		duplicate := false
+		for _, k := range kept {
+			if strings.EqualFold(k, part) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			kept = append(kept, part)
+		}
+	}
+	return truncate(strings.Join(kept, " - "), 255)
+}
+
+// truncate shortens a string to at most n runes
+func truncate(value string, n int) string {
+	runes := []rune(value)
+	if len(runes) <= n {
+		return value
+	}
+	return string(runes[:n])
+}
@@ -0,0 +1,51 @@
+package importers
+
+import "testing"
+
+func TestParseAmount(t *testing.T) {
+	cases := []struct {
+		value     string
+		separator string
+		want      float64
+		wantErr   bool
+	}{
+		{"12.50", ".", 12.5, false},
+		{"-12.50", ".", -12.5, false},
+		{"+7", ".", 7, false},
+		{"1,234.56", ".", 1234.56, false},
+		{"1.234,56", ",", 1234.56, false},
+		{"12,5", ",", 12.5, false},
+		{"1 234,56", ",", 1234.56, false},
+		{"1'234.56", ".", 1234.56, false},
+		{"$ 9.99", ".", 9.99, false},
+		{"9,99 €", ",", 9.99, false},
+		{"EUR -3.10", ".", -3.1, false},
+		{"(12.50)", ".", -12.5, false},
+		{"12.50-", ".", -12.5, false},
+		{"", ".", 0, true},
+		{"abc", ".", 0, true},
+		{"12-5", ".", 0, true},
+		{"1.2.3", ".", 0, true},
+		{"12,50", ".", 1250, false}, // A comma is a thousands separator here
+		{"--5", ".", 0, true},
+		{"5+", ".", 0, true},
+		{"-", ".", 0, true},
+		{"12x34", ".", 0, true},
+		{"1e5", ".", 0, true},
+		{"12.5O", ".", 0, true},
+		{"(€12.50)", ".", -12.5, false},
+		{"USD1,000.00", ".", 1000, false},
+	}
+	for _, c := range cases {
+		got, err := ParseAmount(c.value, c.separator)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("ParseAmount(%q, %q) = %v, want an error", c.value, c.separator, got)
+			}
+			continue
+		}
+		if err != nil || got != c.want {
+			t.Errorf("ParseAmount(%q, %q) = %v, %v, want %v", c.value, c.separator, got, err, c.want)
+		}
+	}
+}
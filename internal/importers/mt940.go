@@ -0,0 +1,191 @@
+package importers
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// mt940Tag matches the start of a field such as :61: or :60F:
+var mt940Tag = regexp.MustCompile(`^:([0-9]{2}[A-Z]?):`)
+
+// mt940Statement matches the :61: statement line: value date, optional entry date,
+// debit/credit mark, optional funds code, amount, transaction type and references
+var mt940Statement = regexp.MustCompile(`^(\d{6})(\d{4})?(RC|RD|C|D)([A-Z])?(\d+,\d*)([NFS][A-Z0-9]{3})([^/]*?)(?://(.*))?$`)
+
+// mt940Subfield matches the ?NN subfields of structured :86: information (German banks)
+var mt940Subfield = regexp.MustCompile(`\?(\d{2})`)
+
+// mt940Field is one :tag: field with its continuation lines
+type mt940Field struct {
+	tag   string
+	value string
+}
+
+// ParseMT940 reads the statement lines (:61:) of a SWIFT MT940 file together with
+// the information to account owner (:86:) that follows each of them. Records are
+// dated on the value date and use the bank reference after // for deduplication,
+// falling back to the customer reference.
+func ParseMT940(r io.Reader) ([]Record, error) {
+	fields, err := mt940Fields(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		records  []Record
+		account  string
+		currency string
+		current  *Record
+	)
+	for _, field := range fields {
+		switch field.tag {
+		case "25":
+			account = strings.TrimSpace(field.value)
+		case "60F", "60M":
+			// D/C mark, date, then the statement currency
+			if len(field.value) >= 10 {
+				currency = strings.ToUpper(field.value[7:10])
+			}
+		case "61":
+			record := mt940Record(len(records)+1, account, currency, field.value)
+			records = append(records, record)
+			current = &records[len(records)-1]
+		case "86":
+			if current != nil && current.Err == nil {
+				if description := mt940Information(field.value); description != "" {
+					current.Description = description
+				}
+			}
+			current = nil
+		default:
+			current = nil
+		}
+	}
+
+	if account == "" && len(records) == 0 {
+		return nil, fmt.Errorf("no :25: or :61: field found")
+	}
+	return records, nil
+}
+
+// mt940Fields splits the file into fields, dropping the SWIFT block wrapper
+func mt940Fields(r io.Reader) ([]mt940Field, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var fields []mt940Field
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed == "-" || trimmed == "-}" || strings.HasPrefix(trimmed, "{1:") {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "{4:") {
+			line = strings.TrimPrefix(trimmed, "{4:")
+			if line == "" {
+				continue
+			}
+		}
+
+		if match := mt940Tag.FindStringSubmatch(line); match != nil {
+			fields = append(fields, mt940Field{tag: match[1], value: line[len(match[0]):]})
+			continue
+		}
+		if len(fields) > 0 {
+			fields[len(fields)-1].value += "\n" + line
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// mt940Record maps a :61: statement line onto a record
+func mt940Record(number int, account string, currency string, value string) Record {
+	record := Record{Line: number, Currency: currency}
+
+	// The first line holds the fixed part; a second line may carry supplementary details
+	lines := strings.SplitN(value, "\n", 2)
+	match := mt940Statement.FindStringSubmatch(strings.TrimSpace(lines[0]))
+	if match == nil {
+		record.Err = fmt.Errorf("invalid :61: statement line %q", truncate(lines[0], 40))
+		return record
+	}
+
+	date, err := time.Parse("060102", match[1])
+	if err != nil {
+		record.Err = fmt.Errorf("invalid value date %q", match[1])
+		return record
+	}
+	record.Date = date
+
+	amount, err := ParseAmount(match[5], ",")
+	if err != nil {
+		record.Err = err
+		return record
+	}
+	if amount == 0 {
+		record.Err = fmt.Errorf("statement line amount is zero")
+		return record
+	}
+	record.Amount = amount
+
+	// Debits and reversed credits take money out of the account
+	switch match[3] {
+	case "D", "RC":
+		record.Kind = "expense"
+	default:
+		record.Kind = "refund"
+	}
+
+	customerRef := strings.TrimSpace(match[7])
+	bankRef := strings.TrimSpace(match[8])
+	reference := bankRef
+	if reference == "" && !strings.EqualFold(customerRef, "NONREF") {
+		reference = customerRef
+	}
+	if reference == "" {
+		record.Err = fmt.Errorf("statement line has no bank reference")
+		return record
+	}
+	record.ExternalID = "mt940:" + account + ":" + reference
+
+	if len(lines) > 1 {
+		record.Description = joinDescription(lines[1])
+	}
+	return record
+}
+
+// mt940Information turns :86: information into a description of counterparty and
+// remittance information. Structured ?NN subfields are decoded; other layouts are
+// kept as free text.
+func mt940Information(value string) string {
+	value = strings.ReplaceAll(value, "\n", "")
+	if !mt940Subfield.MatchString(value) {
+		return joinDescription(value)
+	}
+
+	var counterparty, remittance []string
+	indexes := mt940Subfield.FindAllStringSubmatchIndex(value, -1)
+	for i, index := range indexes {
+		end := len(value)
+		if i+1 < len(indexes) {
+			end = indexes[i+1][0]
+		}
+		code := value[index[2]:index[3]]
+		text := value[index[1]:end]
+
+		switch {
+		case code >= "20" && code <= "29", code >= "60" && code <= "63":
+			remittance = append(remittance, text)
+		case code == "32" || code == "33":
+			counterparty = append(counterparty, text)
+		}
+	}
+	return joinDescription(strings.Join(counterparty, ""), strings.Join(remittance, ""))
+}
@@ -0,0 +1,167 @@
+package importers
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+)
+
+// incomeTypes are OFX credit transaction types that are earnings rather than refunds
+var incomeTypes = map[string]bool{
+	"INT":       true,
+	"DIV":       true,
+	"DEP":       true,
+	"DIRECTDEP": true,
+}
+
+// ParseOFX reads the statement transactions of an OFX or QFX file. Both OFX 1.x,
+// where leaf elements are not closed (SGML), and OFX 2.x (XML) are accepted.
+// Debits become expenses and credits become refunds or income; each record carries
+// the account and FITID as its external ID so repeat imports can be recognised.
+func ParseOFX(r io.Reader) ([]Record, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Skip the OFX 1.x header block or the XML prolog
+	start := bytes.Index(bytes.ToUpper(data), []byte("<OFX>"))
+	if start < 0 {
+		return nil, fmt.Errorf("no <OFX> element found")
+	}
+	body := string(data[start:])
+
+	var (
+		records   []Record
+		accountID string
+		currency  string
+		openTag   string            // Last opened element still waiting for its value
+		txn       map[string]string // Fields of the STMTTRN being read, nil outside one
+	)
+
+	for len(body) > 0 {
+		lt := strings.IndexByte(body, '<')
+		if lt < 0 {
+			break
+		}
+
+		// Text before the tag is the value of the element opened last
+		if value := strings.TrimSpace(body[:lt]); value != "" && openTag != "" {
+			value = html.UnescapeString(value)
+			if txn != nil {
+				txn[openTag] = value
+			} else if openTag == "ACCTID" && accountID == "" {
+				accountID = value
+			} else if openTag == "CURDEF" {
+				currency = strings.ToUpper(value)
+			}
+			openTag = ""
+		}
+
+		gt := strings.IndexByte(body[lt:], '>')
+		if gt < 0 {
+			return nil, fmt.Errorf("unterminated tag near %q", truncate(body[lt:], 20))
+		}
+		tag := strings.ToUpper(strings.TrimSpace(body[lt+1 : lt+gt]))
+		body = body[lt+gt+1:]
+
+		switch {
+		case tag == "" || tag[0] == '?' || tag[0] == '!':
+			continue
+		case tag[0] == '/':
+			name := tag[1:]
+			if name == "STMTTRN" && txn != nil {
+				record := ofxRecord(len(records)+1, accountID, txn)
+				record.Currency = currency
+				records = append(records, record)
+				txn = nil
+			} else if name == "STMTRS" || name == "CCSTMTRS" {
+				// The next statement names its own account and currency
+				accountID = ""
+				currency = ""
+			}
+			openTag = ""
+		default:
+			// Self-closing XML elements carry no value
+			name := strings.TrimSuffix(tag, "/")
+			if name == "STMTTRN" {
+				txn = make(map[string]string)
+			}
+			openTag = name
+			if strings.HasSuffix(tag, "/") {
+				openTag = ""
+			}
+		}
+	}
+
+	if txn != nil {
+		return nil, fmt.Errorf("unterminated STMTTRN element")
+	}
+	return records, nil
+}
+
+// ofxRecord maps the fields of one STMTTRN element onto a record
+func ofxRecord(number int, accountID string, txn map[string]string) Record {
+	record := Record{Line: number}
+
+	if txn["FITID"] == "" {
+		record.Err = fmt.Errorf("transaction has no FITID")
+		return record
+	}
+	record.ExternalID = "ofx:" + accountID + ":" + txn["FITID"]
+
+	date, err := parseOFXDate(txn["DTPOSTED"])
+	if err != nil {
+		record.Err = err
+		return record
+	}
+	record.Date = date
+
+	// A few banks write amounts with a decimal comma
+	separator := "."
+	if strings.Contains(txn["TRNAMT"], ",") && !strings.Contains(txn["TRNAMT"], ".") {
+		separator = ","
+	}
+	amount, err := ParseAmount(txn["TRNAMT"], separator)
+	if err != nil {
+		record.Err = err
+		return record
+	}
+	if amount == 0 {
+		record.Err = fmt.Errorf("transaction amount is zero")
+		return record
+	}
+
+	switch {
+	case amount < 0:
+		record.Kind = "expense"
+		amount = -amount
+	case incomeTypes[strings.ToUpper(txn["TRNTYPE"])]:
+		record.Kind = "income"
+	default:
+		record.Kind = "refund"
+	}
+	record.Amount = amount
+
+	record.Description = joinDescription(txn["NAME"], txn["MEMO"])
+	if record.Description == "" {
+		record.Description = txn["TRNTYPE"]
+	}
+
+	return record
+}
+
+// parseOFXDate reads the date part of an OFX datetime such as 20250115120000.000[-5:EST]
+func parseOFXDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid DTPOSTED %q", value)
+	}
+	t, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid DTPOSTED %q", value)
+	}
+	return t, nil
+}
@@ -0,0 +1,53 @@
+package importers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseOFX(t *testing.T) {
+	cases := []struct {
+		name    string
+		file    string
+		want    []wantRecord
+		wantErr string
+	}{
+		{
+			name: "SGML with decimal comma, credits and bad transactions",
+			file: "statement_sgml.ofx",
+			want: []wantRecord{
+				{Line: 1, Date: day(2025, 1, 15), Amount: 12.5, Kind: "expense", Currency: "USD", Description: "Coffee & Co - Card 1234", ExternalID: "ofx:1234:T1"},
+				{Line: 2, Date: day(2025, 1, 16), Amount: 20, Kind: "refund", Currency: "USD", Description: "Shop refund", ExternalID: "ofx:1234:T2"},
+				{Line: 3, Date: day(2025, 1, 31), Amount: 0.42, Kind: "income", Currency: "USD", Description: "INT", ExternalID: "ofx:1234:T3"},
+				{Err: "no FITID"},
+				{Err: "invalid DTPOSTED"},
+				{Err: "amount is zero"},
+			},
+		},
+		{
+			name: "XML with thousands separators and empty elements",
+			file: "statement_xml.qfx",
+			want: []wantRecord{
+				{Line: 1, Date: day(2025, 2, 3), Amount: 1234.56, Kind: "expense", Currency: "EUR", Description: "Airline", ExternalID: "ofx:9876:X1"},
+				{Line: 2, Date: day(2025, 2, 4), Amount: 100, Kind: "income", Currency: "EUR", Description: "DEP", ExternalID: "ofx:9876:X2"},
+			},
+		},
+		{name: "unterminated transaction", file: "unterminated.ofx", wantErr: "unterminated STMTTRN"},
+		{name: "not an OFX file", file: "not_ofx.ofx", wantErr: "no <OFX> element"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			records, err := ParseOFX(openSample(t, c.file))
+			if c.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, c.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			checkRecords(t, records, c.want)
+		})
+	}
+}
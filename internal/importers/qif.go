@@ -0,0 +1,237 @@
+package importers
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// QIFOptions configures how QIF dates and amounts are read
+type QIFOptions struct {
+	DateFormats      []string // Patterns such as mm/dd/yyyy; empty means US month-first dates
+	DecimalSeparator string   // "." or ","
+}
+
+// qifTransactionTypes are the !Type sections that hold bank-style transactions
+var qifTransactionTypes = map[string]bool{
+	"BANK":  true,
+	"CASH":  true,
+	"CCARD": true,
+	"OTH A": true,
+	"OTH L": true,
+}
+
+// qifDateLayouts are tried when no date formats are configured. Quicken writes
+// month-first dates, with an apostrophe before two-digit years after 2000.
+var qifDateLayouts = []string{"1/2/2006", "1/2/06", "2006-01-02", "1-2-2006", "1.2.2006"}
+
+// ParseQIF reads the transactions of a QIF file. Only bank, cash, credit card and
+// asset/liability sections are imported; category lists, account lists and
+// investment sections are skipped. Negative amounts are expenses and positive
+// amounts refunds. Split lines (S/E/$) become the record's splits.
+func ParseQIF(r io.Reader, opts QIFOptions) ([]Record, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var (
+		records   []Record
+		section   string
+		entry     qifEntry
+		entryLine int
+		lineNo    int
+	)
+
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if lineNo == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if line[0] == '!' {
+			header := strings.ToUpper(strings.TrimSpace(line[1:]))
+			switch {
+			case strings.HasPrefix(header, "TYPE:"):
+				section = strings.TrimSpace(strings.TrimPrefix(header, "TYPE:"))
+			case strings.HasPrefix(header, "OPTION:") || strings.HasPrefix(header, "CLEAR:"):
+				// Switches for Quicken itself
+			default:
+				section = header
+			}
+			entry = qifEntry{}
+			continue
+		}
+
+		if line[0] == '^' {
+			if qifTransactionTypes[section] && !entry.empty() {
+				records = append(records, entry.record(entryLine, opts))
+			}
+			entry = qifEntry{}
+			continue
+		}
+
+		if entry.empty() {
+			entryLine = lineNo
+		}
+		entry.add(line[0], line[1:])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// Some exporters leave out the final ^
+	if qifTransactionTypes[section] && !entry.empty() {
+		records = append(records, entry.record(entryLine, opts))
+	}
+	if section == "" {
+		return nil, fmt.Errorf("no !Type header found")
+	}
+	return records, nil
+}
+
+// qifEntry collects the fields of one transaction up to its ^ terminator
+type qifEntry struct {
+	fields map[byte]string
+	splits []qifSplit
+}
+
+// qifSplit is one S/E/$ group of a split transaction
+type qifSplit struct {
+	category string
+	memo     string
+	amount   string
+}
+
+func (e *qifEntry) empty() bool {
+	return len(e.fields) == 0 && len(e.splits) == 0
+}
+
+func (e *qifEntry) add(code byte, value string) {
+	value = strings.TrimSpace(value)
+	switch code {
+	case 'S':
+		e.splits = append(e.splits, qifSplit{category: value})
+	case 'E', '$':
+		if len(e.splits) == 0 {
+			e.splits = append(e.splits, qifSplit{})
+		}
+		last := &e.splits[len(e.splits)-1]
+		if code == 'E' {
+			last.memo = value
+		} else {
+			last.amount = value
+		}
+	default:
+		if e.fields == nil {
+			e.fields = make(map[byte]string)
+		}
+		e.fields[code] = value
+	}
+}
+
+// record maps the collected fields onto a record
+func (e *qifEntry) record(line int, opts QIFOptions) Record {
+	record := Record{Line: line}
+
+	date, err := parseQIFDate(e.fields['D'], opts.DateFormats)
+	if err != nil {
+		record.Err = err
+		return record
+	}
+	record.Date = date
+
+	// T and U hold the same amount; U is the newer, wider field
+	raw := e.fields['T']
+	if raw == "" {
+		raw = e.fields['U']
+	}
+	amount, err := ParseAmount(raw, decimalSeparator(opts))
+	if err != nil {
+		record.Err = err
+		return record
+	}
+	if amount == 0 {
+		record.Err = fmt.Errorf("transaction amount is zero")
+		return record
+	}
+	record.Kind = "expense"
+	if amount > 0 {
+		record.Kind = "refund"
+	}
+	record.Amount = math.Abs(amount)
+
+	record.Description = joinDescription(e.fields['P'], e.fields['M'])
+
+	category, err := qifCategory(e.fields['L'])
+	if err != nil {
+		record.Err = err
+		return record
+	}
+	record.CategoryName = category
+
+	for i, split := range e.splits {
+		splitCategory, err := qifCategory(split.category)
+		if err != nil {
+			record.Err = fmt.Errorf("split %d: %w", i+1, err)
+			return record
+		}
+		splitAmount, err := ParseAmount(split.amount, decimalSeparator(opts))
+		if err != nil {
+			record.Err = fmt.Errorf("split %d: %w", i+1, err)
+			return record
+		}
+		record.Splits = append(record.Splits, SplitRecord{
+			CategoryName: splitCategory,
+			Amount:       math.Abs(splitAmount),
+			Description:  truncate(split.memo, 255),
+		})
+	}
+
+	// A single split line is just the transaction's category
+	if len(record.Splits) == 1 {
+		if record.CategoryName == "" {
+			record.CategoryName = record.Splits[0].CategoryName
+		}
+		record.Splits = nil
+	}
+
+	return record
+}
+
+// qifCategory reads an L or S field: Category:Subcategory/Class, or [Account] for transfers
+func qifCategory(value string) (string, error) {
+	if strings.HasPrefix(value, "[") {
+		return "", fmt.Errorf("transfer to %s is not an expense", value)
+	}
+	if i := strings.Index(value, "/"); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value), nil
+}
+
+// parseQIFDate reads a QIF date, accepting the apostrophe Quicken writes before two-digit years
+func parseQIFDate(value string, patterns []string) (time.Time, error) {
+	normalized := strings.ReplaceAll(strings.ReplaceAll(value, "'", "/"), " ", "")
+	if len(patterns) > 0 {
+		return ParseDate(normalized, patterns)
+	}
+	for _, layout := range qifDateLayouts {
+		if t, err := time.Parse(layout, normalized); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+func decimalSeparator(opts QIFOptions) string {
+	if opts.DecimalSeparator == "" {
+		return "."
+	}
+	return opts.DecimalSeparator
+}
@@ -0,0 +1,69 @@
+package importers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseQIF(t *testing.T) {
+	cases := []struct {
+		name    string
+		file    string
+		opts    QIFOptions
+		want    []wantRecord
+		splits  map[int][]SplitRecord // Expected splits by record index
+		wantErr string
+	}{
+		{
+			name: "Quicken dates, splits, transfers and skipped sections",
+			file: "bank.qif",
+			want: []wantRecord{
+				{Line: 8, Date: day(2025, 1, 15), Amount: 12.5, Kind: "expense", Description: "Coffee beans - Card 1234", Category: "Groceries:Coffee"},
+				{Line: 14, Date: day(2025, 1, 16), Amount: 1234, Kind: "refund", Description: "Laptop return", Category: "Electronics"},
+				{Line: 19, Date: day(2025, 1, 17), Amount: 60, Kind: "expense", Description: "Supermarket"},
+				{Line: 28, Date: day(2025, 1, 18), Amount: 30, Kind: "expense", Description: "Single split", Category: "Pets"},
+				{Err: "transfer to [Savings]"},
+				{Err: "invalid date"},
+				{Err: `split 1: invalid amount "1-0"`},
+			},
+			splits: map[int][]SplitRecord{
+				2: {
+					{CategoryName: "Groceries", Amount: 45, Description: "Food"},
+					{CategoryName: "Household", Amount: 15},
+				},
+			},
+		},
+		{
+			name: "decimal comma without a final terminator",
+			file: "bank_comma.qif",
+			opts: QIFOptions{DateFormats: []string{"dd.mm.yyyy"}, DecimalSeparator: ","},
+			want: []wantRecord{
+				{Line: 2, Date: day(2025, 1, 15), Amount: 1234.56, Kind: "expense", Description: "Flug"},
+				{Err: "amount is zero"},
+				{Err: `invalid amount "-12,5,0"`},
+			},
+		},
+		{name: "no type header", file: "no_type.qif", wantErr: "no !Type header"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			records, err := ParseQIF(openSample(t, c.file), c.opts)
+			if c.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, c.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			checkRecords(t, records, c.want)
+			for i, record := range records {
+				if !reflect.DeepEqual(record.Splits, c.splits[i]) {
+					t.Errorf("record %d: got splits %+v, want %+v", i, record.Splits, c.splits[i])
+				}
+			}
+		})
+	}
+}
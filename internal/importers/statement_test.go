@@ -0,0 +1,89 @@
+package importers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCamt053(t *testing.T) {
+	const iban = "camt:DE89370400440532013000:"
+	cases := []struct {
+		name    string
+		file    string
+		want    []wantRecord
+		wantErr string
+	}{
+		{
+			name: "entries, batches, reversals and pending entries",
+			file: "statement.camt053.xml",
+			want: []wantRecord{
+				{Line: 1, Date: day(2025, 1, 15), Amount: 12.5, Kind: "expense", Currency: "EUR", Description: "Coffee Shop - Beans order 7", ExternalID: iban + "REF1"},
+				{Line: 2, Date: day(2025, 1, 31), Amount: 2500, Kind: "income", Currency: "EUR", Description: "Employer", ExternalID: iban + "REF2"},
+				{Line: 3, Date: day(2025, 1, 20), Amount: 40, Kind: "refund", Currency: "EUR", Description: "Card payment reversed", ExternalID: iban + "REF3"},
+				{Line: 4, Date: day(2025, 1, 21), Amount: 10, Kind: "expense", Currency: "EUR", Description: "Utility", ExternalID: iban + "B1"},
+				{Line: 5, Date: day(2025, 1, 21), Amount: 20, Kind: "expense", Currency: "USD", Description: "Streaming", ExternalID: iban + "B2"},
+				{Line: 6, Date: day(2025, 1, 22), Amount: 5, Kind: "expense", Currency: "EUR", Description: "Pending card payment", ExternalID: iban + "REF5", SkipReason: "entry is not booked (status PDNG)"},
+				{Err: "no bank reference"},
+				{Err: `invalid amount "5.0.0"`},
+				{Err: `invalid date "24.01.2025"`},
+			},
+		},
+		{name: "no statement", file: "no_statement.camt053.xml", wantErr: "no BkToCstmrStmt/Stmt element"},
+		{name: "truncated XML", file: "truncated.camt053.xml", wantErr: "EOF"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			records, err := ParseCamt053(openSample(t, c.file))
+			if c.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, c.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			checkRecords(t, records, c.want)
+		})
+	}
+}
+
+func TestParseMT940(t *testing.T) {
+	const account = "mt940:10020030/1234567:"
+	cases := []struct {
+		name    string
+		file    string
+		want    []wantRecord
+		wantErr string
+	}{
+		{
+			name: "debits, credits, reversals and structured information",
+			file: "statement.mt940",
+			want: []wantRecord{
+				{Line: 1, Date: day(2025, 1, 15), Amount: 12.5, Kind: "expense", Currency: "EUR", Description: "Coffee Shop - Beans order 7", ExternalID: account + "BANKREF1"},
+				{Line: 2, Date: day(2025, 1, 16), Amount: 1234, Kind: "refund", Currency: "EUR", Description: "Employer payroll", ExternalID: account + "CUST2"},
+				{Line: 3, Date: day(2025, 1, 17), Amount: 40, Kind: "expense", Currency: "EUR", ExternalID: account + "BANKREF3"},
+				{Err: "amount is zero"},
+				{Err: "no bank reference"},
+				{Err: "invalid :61: statement line"},
+				{Err: `invalid value date "251319"`},
+			},
+		},
+		{name: "no statement lines", file: "no_lines.mt940", wantErr: "no :25: or :61: field"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			records, err := ParseMT940(openSample(t, c.file))
+			if c.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, c.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			checkRecords(t, records, c.want)
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package Logger_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/handlers"
+	Logger "goExpenseTracker/internal/middlewears"
+	"goExpenseTracker/internal/migrations"
+	"goExpenseTracker/internal/models"
+	"goExpenseTracker/internal/repositories"
+	"goExpenseTracker/internal/routes"
+	"goExpenseTracker/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// accessTest is a router guarded the way main guards it, over a test database
+// holding Alice's personal ledger, shared with Bob as a viewer, and Carol, who has
+// no part in it
+type accessTest struct {
+	router   *gin.Engine
+	ledgerID string
+
+	alice, bob, carol string // Access tokens
+	readKey, writeKey string // Alice's API keys for the people routes
+}
+
+func newAccessTest(t *testing.T) *accessTest {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")+"?_pragma=foreign_keys(1)"),
+		&gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	runner, err := migrations.New(db)
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if _, err := runner.Up(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	userRepo := repositories.NewUserRepository(db)
+	ledgerRepo := repositories.NewLedgerRepository(db)
+	authService := services.NewAuthService(userRepo, ledgerRepo, nil, services.AuthOptions{
+		Secret:      []byte("access test secret"),
+		AllowSignup: true,
+	})
+	apiKeyService := services.NewAPIKeyService(repositories.NewAPIKeyRepository(db), userRepo)
+	ledgerService := services.NewLedgerService(ledgerRepo, userRepo, time.UTC)
+	personService := services.NewPersonService(repositories.NewPersonRepository(db), repositories.NewExpenseRepository(db))
+
+	register := func(email string) dto.AuthTokenDTO {
+		token, err := authService.Register(dto.RegisterRequestDTO{Email: email, Password: "correct horse battery"})
+		if err != nil {
+			t.Fatalf("register %s: %v", email, err)
+		}
+		return token
+	}
+	alice, bob, carol := register("alice@example.com"), register("bob@example.com"), register("carol@example.com")
+
+	ledger, err := ledgerRepo.GetPersonal(alice.User.ID)
+	if err != nil {
+		t.Fatalf("personal ledger: %v", err)
+	}
+	invite, err := ledgerService.CreateInvite(alice.User.ID, ledger.ID, dto.LedgerInviteRequestDTO{Role: models.RoleViewer})
+	if err != nil {
+		t.Fatalf("invite: %v", err)
+	}
+	if _, err := ledgerService.AcceptInvite(bob.User.ID, dto.LedgerInviteAcceptDTO{Token: invite.Token}); err != nil {
+		t.Fatalf("accept invite: %v", err)
+	}
+
+	newKey := func(scopes ...string) string {
+		key, err := apiKeyService.Create(alice.User.ID, dto.APIKeyRequestDTO{Name: "test", Scopes: scopes})
+		if err != nil {
+			t.Fatalf("create API key: %v", err)
+		}
+		return key.Key
+	}
+
+	requireAuth := Logger.Auth(authService, apiKeyService)
+	router := gin.New()
+	account := router.Group("/api", requireAuth, Logger.RequireSession())
+	routes.SetupAPIKeyRoutes(account, handlers.NewAPIKeyHandler(apiKeyService))
+	api := router.Group("/api", requireAuth, Logger.Ledger(ledgerService))
+	sharing := api.Group("", Logger.RequireScope(services.ScopeSharingRead, services.ScopeSharingWrite))
+	routes.SetupPersonRoutes(sharing, handlers.NewPersonHandler(personService))
+
+	return &accessTest{
+		router:   router,
+		ledgerID: strconv.Itoa(ledger.ID),
+		alice:    alice.Token,
+		bob:      bob.Token,
+		carol:    carol.Token,
+		readKey:  newKey(services.ScopeSharingRead),
+		writeKey: newKey(services.ScopeSharingRead, services.ScopeSharingWrite),
+	}
+}
+
+// do sends a request with the token and ledger header, when set, and returns the status
+func (a *accessTest) do(method, path, token, ledgerID string) int {
+	body := ""
+	if method == http.MethodPost {
+		body = `{"name": "Sam"}`
+	}
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if ledgerID != "" {
+		req.Header.Set(Logger.LedgerHeader, ledgerID)
+	}
+	w := httptest.NewRecorder()
+	a.router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestAccess(t *testing.T) {
+	a := newAccessTest(t)
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		ledger string
+		want   int
+	}{
+		{"no token", http.MethodGet, "/api/v1/people", "", "", http.StatusUnauthorized},
+		{"unknown token", http.MethodGet, "/api/v1/people", "not-a-token", "", http.StatusUnauthorized},
+		{"unknown API key", http.MethodGet, "/api/v1/people", services.APIKeyPrefix + "nope", "", http.StatusUnauthorized},
+
+		{"owner reads", http.MethodGet, "/api/v1/people", a.alice, a.ledgerID, http.StatusOK},
+		{"owner writes", http.MethodPost, "/api/v1/people", a.alice, a.ledgerID, http.StatusCreated},
+		{"viewer reads", http.MethodGet, "/api/v1/people", a.bob, a.ledgerID, http.StatusOK},
+		{"viewer cannot write", http.MethodPost, "/api/v1/people", a.bob, a.ledgerID, http.StatusForbidden},
+
+		{"non-member reads another ledger", http.MethodGet, "/api/v1/people", a.carol, a.ledgerID, http.StatusForbidden},
+		{"non-member writes another ledger", http.MethodPost, "/api/v1/people", a.carol, a.ledgerID, http.StatusForbidden},
+		{"non-member keeps their own ledger", http.MethodGet, "/api/v1/people", a.carol, "", http.StatusOK},
+		{"malformed ledger header", http.MethodGet, "/api/v1/people", a.alice, "first", http.StatusBadRequest},
+
+		{"read key reads", http.MethodGet, "/api/v1/people", a.readKey, a.ledgerID, http.StatusOK},
+		{"read key cannot write", http.MethodPost, "/api/v1/people", a.readKey, a.ledgerID, http.StatusForbidden},
+		{"write key writes", http.MethodPost, "/api/v1/people", a.writeKey, a.ledgerID, http.StatusCreated},
+
+		{"session lists API keys", http.MethodGet, "/api/v1/api-keys", a.alice, "", http.StatusOK},
+		{"API key cannot list API keys", http.MethodGet, "/api/v1/api-keys", a.writeKey, "", http.StatusForbidden},
+		{"API key cannot create API keys", http.MethodPost, "/api/v1/api-keys", a.writeKey, "", http.StatusForbidden},
+	}
+	for _, c := range cases {
+		if got := a.do(c.method, c.path, c.token, c.ledger); got != c.want {
+			t.Errorf("%s: %s %s = %d, want %d", c.name, c.method, c.path, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package Logger
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"goExpenseTracker/internal/models"
+	"goExpenseTracker/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserIDKey is the context key holding the ID of the signed-in user
+const UserIDKey = "userID"
+
+// UserKey is the context key holding the signed-in user, as loaded when checking the token
+const UserKey = "user"
+
+// Auth rejects requests without a valid "Authorization: Bearer <token>" header with
+// 401 and otherwise stores the user the token was issued to under UserKey, and
+// their ID under UserIDKey. The
+// token is either an access token or an API key, whose scopes go under ScopesKey.
+func Auth(authService services.AuthService, apiKeyService services.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scheme, token, found := strings.Cut(c.GetHeader("Authorization"), " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+			c.Header("WWW-Authenticate", `Bearer realm="api"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
+			return
+		}
+
+		token = strings.TrimSpace(token)
+		var (
+			user   models.User
+			scopes []string
+			err    error
+		)
+		if strings.HasPrefix(token, services.APIKeyPrefix) {
+			user, scopes, err = apiKeyService.Authenticate(token)
+		} else {
+			user, err = authService.Authenticate(token)
+		}
+		if err != nil {
+			var authErr *services.AuthError
+			if !errors.As(err, &authErr) {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			c.Header("WWW-Authenticate", `Bearer realm="api", error="invalid_token"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Set(UserKey, user)
+		c.Set(UserIDKey, user.ID)
+		if scopes != nil {
+			c.Set(ScopesKey, scopes)
+		}
+		c.Next()
+	}
+}
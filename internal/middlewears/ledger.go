@@ -0,0 +1,54 @@
+package Logger
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"goExpenseTracker/internal/models"
+	"goExpenseTracker/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LedgerHeader selects the ledger a request works on; without it the signed-in
+// user's personal ledger is used
+const LedgerHeader = "X-Ledger-ID"
+
+// AccessKey is the context key holding the services.Access of the request
+const AccessKey = "access"
+
+// Ledger checks that the signed-in user is a member of the ledger named by the
+// X-Ledger-ID header and stores their access under AccessKey, along with the
+// request ID for audit entries. It must run after Auth.
+func Ledger(ledgerService services.LedgerService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ledgerID := 0
+		if header := c.GetHeader(LedgerHeader); header != "" {
+			id, err := strconv.Atoi(header)
+			if err != nil || id < 1 {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": LedgerHeader + " must be a positive integer"})
+				return
+			}
+			ledgerID = id
+		}
+
+		user, _ := c.Get(UserKey)
+		signedIn, _ := user.(models.User)
+		access, err := ledgerService.Resolve(signedIn, ledgerID)
+		if err != nil {
+			var forbiddenErr *services.ForbiddenError
+			if errors.As(err, &forbiddenErr) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		access.RequestID = c.GetString(RequestIDKey)
+		c.Header(LedgerHeader, strconv.Itoa(access.LedgerID))
+		c.Set(AccessKey, access)
+		c.Next()
+	}
+}
@@ -4,36 +4,111 @@ import (
 	"bytes"
 	"io"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-// bodyLogWriter helps capture and store the response body
+// SkipBodiesKey is the context key set on routes whose bodies are kept out of the log
+const SkipBodiesKey = "skipBodies"
+
+// maxLoggedBody is how much of a request or response body is kept for the log
+const maxLoggedBody = 64 << 10
+
+// SkipBodies keeps the request and response bodies of a route out of the log and
+// out of memory, for routes carrying secrets or files such as backups
+func SkipBodies() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(SkipBodiesKey, true)
+		c.Next()
+	}
+}
+
+// bodyLog holds the start of a body for the log
+type bodyLog struct {
+	buf       bytes.Buffer
+	truncated bool
+	skipped   bool
+}
+
+func (b *bodyLog) keep(p []byte) {
+	room := maxLoggedBody - b.buf.Len()
+	if len(p) > room {
+		p = p[:max(room, 0)]
+		b.truncated = true
+	}
+	b.buf.Write(p)
+}
+
+func (b *bodyLog) String() string {
+	switch {
+	case b.skipped:
+		return "[not logged]"
+	case b.truncated:
+		return b.buf.String() + "... (truncated)"
+	}
+	return b.buf.String()
+}
+
+// bodyLogReader keeps what the handler reads of the request body, so the body is
+// never read ahead of the handler and its own size limits apply
+type bodyLogReader struct {
+	io.ReadCloser
+	c   *gin.Context
+	log *bodyLog
+}
+
+func (r *bodyLogReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if r.c.GetBool(SkipBodiesKey) {
+		r.log.skipped = true
+	} else {
+		r.log.keep(p[:n])
+	}
+	return n, err
+}
+
+// bodyLogWriter keeps JSON response bodies as they are written. Files and exports
+// go straight to the client, so streamed downloads are never held in memory.
 type bodyLogWriter struct {
 	gin.ResponseWriter
-	body *bytes.Buffer
+	c   *gin.Context
+	log *bodyLog
 }
 
-func (w bodyLogWriter) Write(b []byte) (int, error) {
-	w.body.Write(b)
+func (w *bodyLogWriter) Write(b []byte) (int, error) {
+	if w.c.GetBool(SkipBodiesKey) || !jsonContent(w.Header().Get("Content-Type")) {
+		w.log.skipped = true
+	} else {
+		w.log.keep(b)
+	}
 	return w.ResponseWriter.Write(b)
 }
 
+// jsonContent reports whether a content type is JSON. NDJSON exports are streams
+// of records rather than a single document, and are not.
+func jsonContent(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
 func Logger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 
-		// Capture request body
-		var reqBody []byte
-		if c.Request.Body != nil {
-			reqBody, _ = io.ReadAll(c.Request.Body)
-			c.Request.Body = io.NopCloser(bytes.NewBuffer(reqBody))
+		// Capture JSON request bodies as the handler reads them; uploads are left alone
+		requestBody := &bodyLog{}
+		if contentType := c.GetHeader("Content-Type"); contentType != "" && !jsonContent(contentType) {
+			requestBody.skipped = true
+		} else if c.Request.Body != nil {
+			c.Request.Body = &bodyLogReader{ReadCloser: c.Request.Body, c: c, log: requestBody}
 		}
 
 		// Wrap the response writer
-		blw := &bodyLogWriter{body: bytes.NewBufferString(""), ResponseWriter: c.Writer}
-		c.Writer = blw
+		responseBody := &bodyLog{}
+		c.Writer = &bodyLogWriter{ResponseWriter: c.Writer, c: c, log: responseBody}
 
 		// Process request
 		c.Next()
@@ -42,15 +117,27 @@ func Logger() gin.HandlerFunc {
 		latency := time.Since(start)
 		clientIP := c.ClientIP()
 		statusCode := c.Writer.Status()
-		responseBody := blw.body.String()
+		reqBody, respBody := requestBody.String(), responseBody.String()
+
+		// Sign-up and sign-in carry passwords one way and access tokens the other,
+		// and new API keys are returned in the clear
+		if strings.Contains(c.Request.URL.Path, "/auth/") || strings.Contains(c.Request.URL.Path, "/api-keys") {
+			reqBody = "[redacted]"
+			respBody = "[redacted]"
+		}
+		if c.GetBool(SkipBodiesKey) {
+			reqBody = "[not logged]"
+			respBody = "[not logged]"
+		}
 
 		log.Printf("\n---- Request Log ----\n")
 		log.Printf("Client IP: %s", clientIP)
+		log.Printf("Request ID: %s", c.GetString(RequestIDKey))
 		log.Printf("Path: %s | Method: %s", c.Request.URL.Path, c.Request.Method)
-		log.Printf("Request Body: %s", string(reqBody))
+		log.Printf("Request Body: %s", reqBody)
 		log.Printf("Status Code: %d", statusCode)
 		log.Printf("Latency: %v", latency)
-		log.Printf("Response: %s\n", responseBody)
+		log.Printf("Response: %s\n", respBody)
 		log.Println("----------------------")
 	}
 }
@@ -0,0 +1,196 @@
+package Logger
+
+import (
+	"bytes"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// captureLog sends log output to a buffer for the rest of the test
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	return &buf
+}
+
+// loggedBody returns how much of the response the Logger holds, from inside a handler
+func loggedBody(c *gin.Context) int {
+	return c.Writer.(*bodyLogWriter).log.buf.Len()
+}
+
+func TestLoggerKeepsJSONBodies(t *testing.T) {
+	logs := captureLog(t)
+	router := gin.New()
+	router.Use(Logger())
+	router.POST("/api/v1/categories", func(c *gin.Context) {
+		var req map[string]string
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"id": 7, "name": req["name"]})
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/categories", strings.NewReader(`{"name":"Groceries"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("got status %d", w.Code)
+	}
+	for _, want := range []string{`Request Body: {"name":"Groceries"}`, `Response: {"id":7,"name":"Groceries"}`} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("log lacks %q:\n%s", want, logs.String())
+		}
+	}
+}
+
+func TestLoggerTruncatesLongBodies(t *testing.T) {
+	logs := captureLog(t)
+	router := gin.New()
+	router.Use(Logger())
+	long := strings.Repeat("x", 2*maxLoggedBody)
+	router.GET("/api/v1/expenses", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"description": long})
+		if held := loggedBody(c); held != maxLoggedBody {
+			t.Errorf("logger holds %d bytes, want %d", held, maxLoggedBody)
+		}
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/expenses", nil))
+	if !strings.Contains(logs.String(), "... (truncated)") {
+		t.Error("long body is not marked as truncated")
+	}
+	if len(w.Body.String()) < len(long) {
+		t.Error("the client got a truncated response")
+	}
+}
+
+func TestSkipBodies(t *testing.T) {
+	logs := captureLog(t)
+	router := gin.New()
+	router.Use(Logger())
+	router.POST("/api/v1/restore", SkipBodies(), func(c *gin.Context) {
+		// The handler's own limit applies: nothing was read ahead of it
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, 10)
+		buf := make([]byte, 64)
+		n, _ := c.Request.Body.Read(buf)
+		c.Data(http.StatusOK, "application/json", append([]byte(`{"read":"`), append(buf[:n], '"', '}')...))
+		if held := loggedBody(c); held != 0 {
+			t.Errorf("logger holds %d bytes of a skipped body", held)
+		}
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/restore", strings.NewReader(`{"password_hash":"secret-hash"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	if strings.Contains(logs.String(), "secret-hash") || strings.Contains(logs.String(), "password_hash") {
+		t.Errorf("log shows a skipped body:\n%s", logs.String())
+	}
+	if !strings.Contains(logs.String(), "Request Body: [not logged]") || !strings.Contains(logs.String(), "Response: [not logged]") {
+		t.Errorf("log does not say the bodies were left out:\n%s", logs.String())
+	}
+}
+
+func TestLoggerDoesNotBufferExports(t *testing.T) {
+	cases := []struct {
+		name        string
+		contentType string
+	}{
+		{"csv", "text/csv; charset=utf-8"},
+		{"ndjson", "application/x-ndjson"},
+		{"xlsx", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+		{"pdf", "application/pdf"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			logs := captureLog(t)
+			router := gin.New()
+			router.Use(Logger())
+			row := []byte(`{"id":1,"description":"exported row"}` + "\n")
+			router.GET("/api/v1/exports/expenses", func(ctx *gin.Context) {
+				ctx.Header("Content-Type", c.contentType)
+				for i := 0; i < 10000; i++ {
+					ctx.Writer.Write(row)
+					if held := loggedBody(ctx); held != 0 {
+						t.Fatalf("logger holds %d bytes after row %d", held, i+1)
+					}
+				}
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/exports/expenses", nil))
+			if w.Body.Len() != 10000*len(row) {
+				t.Fatalf("client got %d bytes, want %d", w.Body.Len(), 10000*len(row))
+			}
+			if strings.Contains(logs.String(), "exported row") || !strings.Contains(logs.String(), "Response: [not logged]") {
+				t.Errorf("log shows the export:\n%.500s", logs.String())
+			}
+		})
+	}
+}
+
+func TestJSONContent(t *testing.T) {
+	for contentType, want := range map[string]bool{
+		"application/json":                  true,
+		"application/json; charset=utf-8":   true,
+		"application/problem+json":          true,
+		"application/x-ndjson":              false,
+		"text/csv":                          false,
+		"":                                  false,
+		"multipart/form-data; boundary=abc": false,
+	} {
+		if got := jsonContent(contentType); got != want {
+			t.Errorf("%q: got %v, want %v", contentType, got, want)
+		}
+	}
+}
+
+func TestLoggerLeavesUploadsToTheHandler(t *testing.T) {
+	logs := captureLog(t)
+	router := gin.New()
+	router.Use(Logger())
+	receipt := strings.Repeat("\x89PNG receipt bytes ", 4096)
+	router.POST("/api/v1/expenses/1/attachments", func(c *gin.Context) {
+		// The handler's limit is the first to see the upload
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, 1024)
+		if _, err := c.FormFile("file"); err == nil {
+			t.Error("upload over the limit was read")
+		}
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "too large"})
+	})
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile("file", "receipt.png")
+	part.Write([]byte(receipt))
+	form.Close()
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/expenses/1/attachments", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("got status %d", w.Code)
+	}
+	if strings.Contains(logs.String(), "receipt bytes") || !strings.Contains(logs.String(), "Request Body: [not logged]") {
+		t.Errorf("log shows the upload:\n%.500s", logs.String())
+	}
+}
@@ -0,0 +1,50 @@
+package Logger
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the ID of a request, taken from the client or a proxy in
+// front of the server when it sends one
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDKey is the context key holding the ID of the request
+const RequestIDKey = "requestID"
+
+// maxRequestIDLength bounds the IDs accepted from clients, as they are logged and stored
+const maxRequestIDLength = 64
+
+// RequestID gives every request an ID, sent back in the X-Request-ID header, that
+// ties its log lines and audit entries together. A well-formed ID sent by the
+// client is kept; otherwise a random one is made up.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			buf := make([]byte, 16)
+			_, _ = rand.Read(buf)
+			id = hex.EncodeToString(buf)
+		}
+		c.Set(RequestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// validRequestID accepts IDs of letters, digits, dots, dashes and underscores
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
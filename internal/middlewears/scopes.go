@@ -0,0 +1,49 @@
+package Logger
+
+import (
+	"net/http"
+
+	"goExpenseTracker/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScopesKey is the context key holding the scopes of the API key a request was made
+// with; it is unset for users signed in with a password
+const ScopesKey = "scopes"
+
+// RequireScope limits API keys to the routes their scopes allow: reads (GET and
+// HEAD) need the read scope and everything else the write scope. Users signed in
+// with a password are not limited. It must run after Auth.
+func RequireScope(read, write string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scopes, ok := c.Get(ScopesKey)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		required := write
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			required = read
+		}
+		granted, _ := scopes.([]string)
+		if !services.HasScope(granted, required) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API key lacks the " + required + " scope"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireSession keeps API keys off routes meant for people, such as managing
+// keys and ledgers. It must run after Auth.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get(ScopesKey); ok {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API keys cannot be used here; sign in with a password"})
+			return
+		}
+		c.Next()
+	}
+}
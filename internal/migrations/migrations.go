@@ -0,0 +1,301 @@
+// Package migrations versions the database schema. Each migration is a pair of
+// SQL files, NNNN_name.up.sql and NNNN_name.down.sql, embedded per database
+// dialect; the versions applied to a database are recorded in schema_migrations.
+package migrations
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+// lockKey identifies the advisory lock held by a Postgres migration runner
+const lockKey int64 = 0x676f45787054726b // "goExpTrk"
+
+// fileName matches migration files: version, name and direction
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// ErrPending is returned by Check when the database is behind the migrations
+var ErrPending = errors.New("database has pending migrations")
+
+// Migration is one versioned change to the schema
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string // Empty when the migration cannot be rolled back
+}
+
+// MigrationStatus is a migration together with when it was applied, if it was
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+	Unknown   bool // Applied to the database but not part of this build
+}
+
+// appliedMigration is a row of schema_migrations
+type appliedMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (appliedMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Runner applies and rolls back the migrations of a database
+type Runner struct {
+	db         *gorm.DB
+	dialect    string
+	migrations []Migration
+}
+
+// New loads the migrations for the dialect of the database
+func New(db *gorm.DB) (*Runner, error) {
+	dialect := db.Dialector.Name()
+	migrations, err := load(files, dialect)
+	if err != nil {
+		return nil, err
+	}
+	return &Runner{db: db, dialect: dialect, migrations: migrations}, nil
+}
+
+// load reads the migrations of a dialect, ordered by version
+func load(fsys fs.FS, dialect string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dialect)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for %s databases", dialect)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration %s/%s: name must be NNNN_name.up.sql or NNNN_name.down.sql", dialect, entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(fsys, path.Join(dialect, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d is named both %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Status lists every migration of this build with when it was applied, followed
+// by any the database has applied that this build does not know
+func (r *Runner) Status() ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := r.db.Connection(func(conn *gorm.DB) error {
+		applied, err := r.applied(conn)
+		if err != nil {
+			return err
+		}
+		statuses = r.statuses(applied)
+		return nil
+	})
+	return statuses, err
+}
+
+// Check reports ErrPending unless every migration of this build is applied
+func (r *Runner) Check() error {
+	statuses, err := r.Status()
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			return fmt.Errorf("%w, starting with %d_%s", ErrPending, status.Version, status.Name)
+		}
+	}
+	return nil
+}
+
+// Up applies the pending migrations in order and returns how many it applied.
+// Each migration runs in its own transaction with its schema_migrations row.
+func (r *Runner) Up() (int, error) {
+	count := 0
+	err := r.locked(func(conn *gorm.DB) error {
+		applied, err := r.applied(conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range r.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Up).Error; err != nil {
+					return err
+				}
+				return tx.Create(&appliedMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			log.Printf("Applied migration %d_%s", migration.Version, migration.Name)
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Down rolls back the last steps applied migrations, newest first, and returns
+// how many it rolled back
+func (r *Runner) Down(steps int) (int, error) {
+	count := 0
+	err := r.locked(func(conn *gorm.DB) error {
+		applied, err := r.applied(conn)
+		if err != nil {
+			return err
+		}
+		// Migrations applied by a newer build have to be rolled back by that build first
+		for _, status := range r.statuses(applied) {
+			if status.Unknown {
+				return fmt.Errorf("migration %d_%s is not part of this build; roll it back with the build that applied it", status.Version, status.Name)
+			}
+		}
+		for i := len(r.migrations) - 1; i >= 0 && count < steps; i-- {
+			migration := r.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d_%s cannot be rolled back", migration.Version, migration.Name)
+			}
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Down).Error; err != nil {
+					return err
+				}
+				return tx.Delete(&appliedMigration{}, migration.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("roll back migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			log.Printf("Rolled back migration %d_%s", migration.Version, migration.Name)
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// locked runs fn on a single connection holding the migration lock, so runners
+// started together by several instances apply each migration once. A SQLite
+// database belongs to a single instance and takes no lock.
+func (r *Runner) locked(fn func(conn *gorm.DB) error) error {
+	return r.db.Connection(func(conn *gorm.DB) error {
+		if r.dialect == "postgres" {
+			// Advisory locks belong to the session, which is why the connection is pinned
+			var acquired bool
+			if err := conn.Raw("SELECT pg_try_advisory_lock(?)", lockKey).Scan(&acquired).Error; err != nil {
+				return err
+			}
+			if !acquired {
+				log.Println("Waiting for another migration runner to finish...")
+				if err := conn.Exec("SELECT pg_advisory_lock(?)", lockKey).Error; err != nil {
+					return err
+				}
+			}
+			defer conn.Exec("SELECT pg_advisory_unlock(?)", lockKey)
+		}
+		if err := createTable(conn); err != nil {
+			return err
+		}
+		return fn(conn)
+	})
+}
+
+// createTable creates schema_migrations on first use, with the column types of
+// the dialect
+func createTable(conn *gorm.DB) error {
+	if conn.Migrator().HasTable(&appliedMigration{}) {
+		return nil
+	}
+	return conn.Migrator().CreateTable(&appliedMigration{})
+}
+
+// applied reads schema_migrations; a database without it has nothing applied
+func (r *Runner) applied(conn *gorm.DB) (map[int]appliedMigration, error) {
+	applied := make(map[int]appliedMigration)
+	if !conn.Migrator().HasTable(&appliedMigration{}) {
+		return applied, nil
+	}
+
+	var rows []appliedMigration
+	if err := conn.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+func (r *Runner) statuses(applied map[int]appliedMigration) []MigrationStatus {
+	statuses := make([]MigrationStatus, 0, len(r.migrations))
+	known := make(map[int]bool, len(r.migrations))
+	for _, migration := range r.migrations {
+		known[migration.Version] = true
+		status := MigrationStatus{Migration: migration}
+		if row, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &row.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	versions := make([]int, 0, len(applied))
+	for version := range applied {
+		if !known[version] {
+			versions = append(versions, version)
+		}
+	}
+	sort.Ints(versions)
+	for _, version := range versions {
+		row := applied[version]
+		statuses = append(statuses, MigrationStatus{
+			Migration: Migration{Version: row.Version, Name: row.Name},
+			AppliedAt: &row.AppliedAt,
+			Unknown:   true,
+		})
+	}
+	return statuses
+}
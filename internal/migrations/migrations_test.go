@@ -0,0 +1,282 @@
+package migrations
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// The SQLite tests always run. The Postgres tests, which also bring a schema left
+// by AutoMigrate under the baseline, run when TEST_POSTGRES_DSN points at a
+// database whose public schema they may drop.
+
+func quietGorm() *gorm.Config {
+	return &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+}
+
+func openSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")+"?_pragma=foreign_keys(1)"), quietGorm())
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	closeOnCleanup(t, db)
+	return db
+}
+
+// openPostgres connects to TEST_POSTGRES_DSN and empties its public schema
+func openPostgres(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), quietGorm())
+	if err != nil {
+		t.Fatalf("open postgres: %v", err)
+	}
+	closeOnCleanup(t, db)
+	if err := db.Exec("DROP SCHEMA public CASCADE; CREATE SCHEMA public").Error; err != nil {
+		t.Fatalf("empty postgres schema: %v", err)
+	}
+	return db
+}
+
+func closeOnCleanup(t *testing.T, db *gorm.DB) {
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+}
+
+func newRunner(t *testing.T, db *gorm.DB) *Runner {
+	t.Helper()
+	runner, err := New(db)
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if len(runner.migrations) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+	return runner
+}
+
+// checkUpIsIdempotent applies every migration to the database, then checks a
+// second run applies none and leaves nothing pending
+func checkUpIsIdempotent(t *testing.T, runner *Runner) {
+	t.Helper()
+	applied, err := runner.Up()
+	if err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if applied != len(runner.migrations) {
+		t.Fatalf("up applied %d migrations, want %d", applied, len(runner.migrations))
+	}
+	if err := runner.Check(); err != nil {
+		t.Fatalf("check after up: %v", err)
+	}
+
+	again, err := runner.Up()
+	if err != nil {
+		t.Fatalf("second up: %v", err)
+	}
+	if again != 0 {
+		t.Fatalf("second up applied %d migrations, want 0", again)
+	}
+	statuses, err := runner.Status()
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if len(statuses) != len(runner.migrations) {
+		t.Fatalf("got %d statuses, want %d", len(statuses), len(runner.migrations))
+	}
+	for _, status := range statuses {
+		if status.AppliedAt == nil || status.Unknown {
+			t.Errorf("migration %d_%s: applied %v, unknown %v", status.Version, status.Name, status.AppliedAt, status.Unknown)
+		}
+	}
+}
+
+// checkDownAndUpAgain rolls every migration back and applies them again
+func checkDownAndUpAgain(t *testing.T, runner *Runner) {
+	t.Helper()
+	rolledBack, err := runner.Down(len(runner.migrations))
+	if err != nil {
+		t.Fatalf("down: %v", err)
+	}
+	if rolledBack != len(runner.migrations) {
+		t.Fatalf("down rolled back %d migrations, want %d", rolledBack, len(runner.migrations))
+	}
+	if err := runner.Check(); err == nil || !strings.Contains(err.Error(), ErrPending.Error()) {
+		t.Fatalf("check after down: got %v, want %v", err, ErrPending)
+	}
+	if runner.db.Migrator().HasTable("users") {
+		t.Error("users table is left after rolling everything back")
+	}
+	checkUpIsIdempotent(t, runner)
+}
+
+func TestSQLiteUpIsIdempotent(t *testing.T) {
+	db := openSQLite(t)
+	runner := newRunner(t, db)
+	checkUpIsIdempotent(t, runner)
+
+	// A new runner over the same database, as at the next start, has nothing to do
+	if applied, err := newRunner(t, db).Up(); err != nil || applied != 0 {
+		t.Fatalf("up from a new runner: applied %d, %v", applied, err)
+	}
+}
+
+func TestSQLiteDownAndUpAgain(t *testing.T) {
+	runner := newRunner(t, openSQLite(t))
+	if _, err := runner.Up(); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	checkDownAndUpAgain(t, runner)
+}
+
+func TestSQLiteDownRefusesUnknownMigrations(t *testing.T) {
+	db := openSQLite(t)
+	runner := newRunner(t, db)
+	if _, err := runner.Up(); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	// A migration applied by a newer build
+	if err := db.Create(&appliedMigration{Version: 9999, Name: "from_the_future"}).Error; err != nil {
+		t.Fatalf("record migration: %v", err)
+	}
+
+	statuses, err := runner.Status()
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if last := statuses[len(statuses)-1]; !last.Unknown || last.Version != 9999 {
+		t.Errorf("last status: got %+v, want the unknown migration 9999", last)
+	}
+	if _, err := runner.Down(1); err == nil || !strings.Contains(err.Error(), "not part of this build") {
+		t.Fatalf("down: got %v, want it refused", err)
+	}
+}
+
+func TestPostgresUpIsIdempotent(t *testing.T) {
+	runner := newRunner(t, openPostgres(t))
+	checkUpIsIdempotent(t, runner)
+	checkDownAndUpAgain(t, runner)
+}
+
+// TestPostgresBaselineOnExistingSchema brings a database AutoMigrate set up at an
+// earlier release, with data and without schema_migrations, under the baseline
+func TestPostgresBaselineOnExistingSchema(t *testing.T) {
+	db := openPostgres(t)
+	err := db.Exec(`
+		CREATE TABLE "users" ("id" bigserial PRIMARY KEY, "email" text, "password_hash" text, "created_at" timestamptz);
+		CREATE UNIQUE INDEX "idx_users_email" ON "users" ("email");
+		CREATE TABLE "categories" ("id" bigserial PRIMARY KEY, "user_id" bigint, "name" text);
+		INSERT INTO "users" ("email", "password_hash") VALUES ('ada@example.com', 'hash');
+		INSERT INTO "categories" ("user_id", "name") VALUES (1, 'Groceries');
+	`).Error
+	if err != nil {
+		t.Fatalf("create existing schema: %v", err)
+	}
+
+	runner := newRunner(t, db)
+	checkUpIsIdempotent(t, runner)
+
+	var email string
+	if err := db.Raw(`SELECT "email" FROM "users" WHERE "id" = 1`).Scan(&email).Error; err != nil || email != "ada@example.com" {
+		t.Fatalf("existing user: got %q, %v", email, err)
+	}
+	for _, column := range []string{"name", "is_admin", "timezone"} {
+		if !db.Migrator().HasColumn("users", column) {
+			t.Errorf("users.%s was not added", column)
+		}
+	}
+	// Data recorded before ledgers existed moves to its user's personal ledger
+	var ledgerID int
+	err = db.Raw(`SELECT c."ledger_id" FROM "categories" c JOIN "ledgers" l ON l."id" = c."ledger_id" WHERE l."personal" AND l."created_by" = 1`).
+		Scan(&ledgerID).Error
+	if err != nil || ledgerID == 0 {
+		t.Fatalf("existing category: got ledger %d, %v; want the user's personal ledger", ledgerID, err)
+	}
+
+	// The baseline itself runs again without error over the schema it created
+	if err := db.Exec(runner.migrations[0].Up).Error; err != nil {
+		t.Fatalf("baseline over its own schema: %v", err)
+	}
+	var ledgers int64
+	if err := db.Table("ledgers").Count(&ledgers).Error; err != nil || ledgers != 1 {
+		t.Fatalf("ledgers after running the baseline again: got %d, %v; want 1", ledgers, err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	cases := []struct {
+		name     string
+		files    fstest.MapFS
+		versions []int
+		wantErr  string
+	}{
+		{
+			name: "ordered by version, down optional",
+			files: fstest.MapFS{
+				"sqlite/0010_later.up.sql":    {Data: []byte("SELECT 10")},
+				"sqlite/0002_second.up.sql":   {Data: []byte("SELECT 2")},
+				"sqlite/0002_second.down.sql": {Data: []byte("SELECT -2")},
+			},
+			versions: []int{2, 10},
+		},
+		{
+			name:    "badly named file",
+			files:   fstest.MapFS{"sqlite/0001_first.sql": {Data: []byte("SELECT 1")}},
+			wantErr: "name must be NNNN_name",
+		},
+		{
+			name:    "down without up",
+			files:   fstest.MapFS{"sqlite/0001_first.down.sql": {Data: []byte("SELECT 1")}},
+			wantErr: "has no up file",
+		},
+		{
+			name: "one version with two names",
+			files: fstest.MapFS{
+				"sqlite/0001_first.up.sql":   {Data: []byte("SELECT 1")},
+				"sqlite/0001_other.down.sql": {Data: []byte("SELECT 1")},
+			},
+			wantErr: "named both",
+		},
+		{
+			name:    "unknown dialect",
+			files:   fstest.MapFS{"postgres/0001_first.up.sql": {Data: []byte("SELECT 1")}},
+			wantErr: "no migrations for sqlite",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			migrations, err := load(c.files, "sqlite")
+			if c.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, c.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("load: %v", err)
+			}
+			if len(migrations) != len(c.versions) {
+				t.Fatalf("got %d migrations, want %d", len(migrations), len(c.versions))
+			}
+			for i, version := range c.versions {
+				if migrations[i].Version != version {
+					t.Errorf("migration %d: got version %d, want %d", i, migrations[i].Version, version)
+				}
+			}
+		})
+	}
+}
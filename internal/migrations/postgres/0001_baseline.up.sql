@@ -0,0 +1,313 @@
+-- Baseline: the schema as AutoMigrate left it before migrations were versioned.
+-- Every statement is idempotent, so it creates a fresh database and brings one
+-- that AutoMigrate set up (at any earlier release) to the same state.
+
+CREATE TABLE IF NOT EXISTS "users" ("id" bigserial PRIMARY KEY);
+ALTER TABLE "users"
+    ADD COLUMN IF NOT EXISTS "email" text,
+    ADD COLUMN IF NOT EXISTS "name" text,
+    ADD COLUMN IF NOT EXISTS "password_hash" text,
+    ADD COLUMN IF NOT EXISTS "is_admin" boolean,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_email" ON "users" ("email");
+
+CREATE TABLE IF NOT EXISTS "ledgers" ("id" bigserial PRIMARY KEY);
+ALTER TABLE "ledgers"
+    ADD COLUMN IF NOT EXISTS "name" text,
+    ADD COLUMN IF NOT EXISTS "personal" boolean,
+    ADD COLUMN IF NOT EXISTS "created_by" bigint,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_ledgers_created_by" ON "ledgers" ("created_by");
+
+CREATE TABLE IF NOT EXISTS "ledger_members" ("id" bigserial PRIMARY KEY);
+ALTER TABLE "ledger_members"
+    ADD COLUMN IF NOT EXISTS "ledger_id" bigint,
+    ADD COLUMN IF NOT EXISTS "user_id" bigint,
+    ADD COLUMN IF NOT EXISTS "role" text,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_ledger_members_user_id" ON "ledger_members" ("user_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_ledger_member" ON "ledger_members" ("ledger_id","user_id");
+
+CREATE TABLE IF NOT EXISTS "ledger_invites" ("id" bigserial PRIMARY KEY);
+ALTER TABLE "ledger_invites"
+    ADD COLUMN IF NOT EXISTS "ledger_id" bigint,
+    ADD COLUMN IF NOT EXISTS "token_hash" text,
+    ADD COLUMN IF NOT EXISTS "role" text,
+    ADD COLUMN IF NOT EXISTS "email" text,
+    ADD COLUMN IF NOT EXISTS "created_by" bigint,
+    ADD COLUMN IF NOT EXISTS "expires_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "accepted_by" bigint,
+    ADD COLUMN IF NOT EXISTS "accepted_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_ledger_invites_token_hash" ON "ledger_invites" ("token_hash");
+CREATE INDEX IF NOT EXISTS "idx_ledger_invites_ledger_id" ON "ledger_invites" ("ledger_id");
+
+CREATE TABLE IF NOT EXISTS "api_keys" ("id" bigserial PRIMARY KEY);
+ALTER TABLE "api_keys"
+    ADD COLUMN IF NOT EXISTS "user_id" bigint,
+    ADD COLUMN IF NOT EXISTS "name" text,
+    ADD COLUMN IF NOT EXISTS "prefix" text,
+    ADD COLUMN IF NOT EXISTS "key_hash" text,
+    ADD COLUMN IF NOT EXISTS "scopes" text,
+    ADD COLUMN IF NOT EXISTS "expires_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "last_used_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "revoked_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_api_keys_key_hash" ON "api_keys" ("key_hash");
+CREATE INDEX IF NOT EXISTS "idx_api_keys_user_id" ON "api_keys" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "user_identities" ("id" bigserial PRIMARY KEY);
+ALTER TABLE "user_identities"
+    ADD COLUMN IF NOT EXISTS "user_id" bigint,
+    ADD COLUMN IF NOT EXISTS "issuer" text,
+    ADD COLUMN IF NOT EXISTS "subject" text,
+    ADD COLUMN IF NOT EXISTS "email" text,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "last_login_at" timestamptz;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_identity_subject" ON "user_identities" ("issuer","subject");
+CREATE INDEX IF NOT EXISTS "idx_user_identities_user_id" ON "user_identities" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "two_factors" (
+    "user_id" bigint,
+    "secret" text,
+    "confirmed_at" timestamptz,
+    "last_used_step" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("user_id")
+);
+
+CREATE TABLE IF NOT EXISTS "recovery_codes" ("id" bigserial PRIMARY KEY);
+ALTER TABLE "recovery_codes"
+    ADD COLUMN IF NOT EXISTS "user_id" bigint,
+    ADD COLUMN IF NOT EXISTS "code_hash" text,
+    ADD COLUMN IF NOT EXISTS "used_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_recovery_codes_user_id" ON "recovery_codes" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "categories" ("id" bigserial PRIMARY KEY);
+ALTER TABLE "categories"
+    ADD COLUMN IF NOT EXISTS "ledger_id" bigint,
+    ADD COLUMN IF NOT EXISTS "user_id" bigint,
+    ADD COLUMN IF NOT EXISTS "name" text,
+    ADD COLUMN IF NOT EXISTS "description" text,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_categories_user_id" ON "categories" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_categories_ledger_id" ON "categories" ("ledger_id");
+
+CREATE TABLE IF NOT EXISTS "accounts" ("id" bigserial PRIMARY KEY);
+ALTER TABLE "accounts"
+    ADD COLUMN IF NOT EXISTS "ledger_id" bigint,
+    ADD COLUMN IF NOT EXISTS "user_id" bigint,
+    ADD COLUMN IF NOT EXISTS "name" text,
+    ADD COLUMN IF NOT EXISTS "type" text,
+    ADD COLUMN IF NOT EXISTS "currency" text,
+    ADD COLUMN IF NOT EXISTS "opening_balance" decimal,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_accounts_user_id" ON "accounts" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_accounts_ledger_id" ON "accounts" ("ledger_id");
+
+CREATE TABLE IF NOT EXISTS "people" ("id" bigserial PRIMARY KEY);
+ALTER TABLE "people"
+    ADD COLUMN IF NOT EXISTS "ledger_id" bigint,
+    ADD COLUMN IF NOT EXISTS "user_id" bigint,
+    ADD COLUMN IF NOT EXISTS "name" text,
+    ADD COLUMN IF NOT EXISTS "email" text,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_people_user_id" ON "people" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_people_ledger_id" ON "people" ("ledger_id");
+
+CREATE TABLE IF NOT EXISTS "tags" ("id" bigserial PRIMARY KEY);
+ALTER TABLE "tags"
+    ADD COLUMN IF NOT EXISTS "ledger_id" bigint,
+    ADD COLUMN IF NOT EXISTS "user_id" bigint,
+    ADD COLUMN IF NOT EXISTS "name" text,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_tags_user_id" ON "tags" ("user_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_tags_ledger_name" ON "tags" ("ledger_id","name");
+-- Tag names used to be unique across the instance, then per user; they are now unique per ledger
+DROP INDEX IF EXISTS "idx_tags_name";
+DROP INDEX IF EXISTS "idx_tags_user_name";
+
+CREATE TABLE IF NOT EXISTS "expenses" ("id" bigserial PRIMARY KEY);
+ALTER TABLE "expenses"
+    ADD COLUMN IF NOT EXISTS "ledger_id" bigint,
+    ADD COLUMN IF NOT EXISTS "user_id" bigint,
+    ADD COLUMN IF NOT EXISTS "category_id" bigint,
+    ADD COLUMN IF NOT EXISTS "account_id" bigint,
+    ADD COLUMN IF NOT EXISTS "paid_by_id" bigint,
+    ADD COLUMN IF NOT EXISTS "share_mode" text,
+    ADD COLUMN IF NOT EXISTS "kind" text DEFAULT 'expense',
+    ADD COLUMN IF NOT EXISTS "amount" decimal,
+    ADD COLUMN IF NOT EXISTS "currency" text,
+    ADD COLUMN IF NOT EXISTS "description" text,
+    ADD COLUMN IF NOT EXISTS "external_id" text,
+    ADD COLUMN IF NOT EXISTS "date" timestamptz,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_expenses_external_id" ON "expenses" ("external_id");
+CREATE INDEX IF NOT EXISTS "idx_expenses_user_id" ON "expenses" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_expenses_ledger_id" ON "expenses" ("ledger_id");
+
+CREATE TABLE IF NOT EXISTS "expense_tags" (
+    "expense_id" bigint,
+    "tag_id" bigint,
+    PRIMARY KEY ("expense_id","tag_id"),
+    CONSTRAINT "fk_expense_tags_expense" FOREIGN KEY ("expense_id") REFERENCES "expenses"("id"),
+    CONSTRAINT "fk_expense_tags_tag" FOREIGN KEY ("tag_id") REFERENCES "tags"("id")
+);
+
+CREATE TABLE IF NOT EXISTS "expense_splits" ("id" bigserial PRIMARY KEY);
+ALTER TABLE "expense_splits"
+    ADD COLUMN IF NOT EXISTS "expense_id" bigint CONSTRAINT "fk_expenses_splits" REFERENCES "expenses"("id"),
+    ADD COLUMN IF NOT EXISTS "category_id" bigint,
+    ADD COLUMN IF NOT EXISTS "amount" decimal,
+    ADD COLUMN IF NOT EXISTS "description" text,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+
+CREATE TABLE IF NOT EXISTS "expense_shares" ("id" bigserial PRIMARY KEY);
+ALTER TABLE "expense_shares"
+    ADD COLUMN IF NOT EXISTS "expense_id" bigint CONSTRAINT "fk_expenses_shares" REFERENCES "expenses"("id"),
+    ADD COLUMN IF NOT EXISTS "person_id" bigint,
+    ADD COLUMN IF NOT EXISTS "value" decimal,
+    ADD COLUMN IF NOT EXISTS "amount" decimal,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+
+CREATE TABLE IF NOT EXISTS "transfers" ("id" bigserial PRIMARY KEY);
+ALTER TABLE "transfers"
+    ADD COLUMN IF NOT EXISTS "ledger_id" bigint,
+    ADD COLUMN IF NOT EXISTS "user_id" bigint,
+    ADD COLUMN IF NOT EXISTS "from_account_id" bigint,
+    ADD COLUMN IF NOT EXISTS "to_account_id" bigint,
+    ADD COLUMN IF NOT EXISTS "amount" decimal,
+    ADD COLUMN IF NOT EXISTS "description" text,
+    ADD COLUMN IF NOT EXISTS "date" timestamptz,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_transfers_user_id" ON "transfers" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_transfers_ledger_id" ON "transfers" ("ledger_id");
+
+CREATE TABLE IF NOT EXISTS "settlements" ("id" bigserial PRIMARY KEY);
+ALTER TABLE "settlements"
+    ADD COLUMN IF NOT EXISTS "ledger_id" bigint,
+    ADD COLUMN IF NOT EXISTS "user_id" bigint,
+    ADD COLUMN IF NOT EXISTS "from_person_id" bigint,
+    ADD COLUMN IF NOT EXISTS "to_person_id" bigint,
+    ADD COLUMN IF NOT EXISTS "amount" decimal,
+    ADD COLUMN IF NOT EXISTS "note" text,
+    ADD COLUMN IF NOT EXISTS "date" timestamptz,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_settlements_user_id" ON "settlements" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_settlements_ledger_id" ON "settlements" ("ledger_id");
+
+CREATE TABLE IF NOT EXISTS "attachments" ("id" bigserial PRIMARY KEY);
+ALTER TABLE "attachments"
+    ADD COLUMN IF NOT EXISTS "ledger_id" bigint,
+    ADD COLUMN IF NOT EXISTS "user_id" bigint,
+    ADD COLUMN IF NOT EXISTS "expense_id" bigint,
+    ADD COLUMN IF NOT EXISTS "file_name" text,
+    ADD COLUMN IF NOT EXISTS "content_type" text,
+    ADD COLUMN IF NOT EXISTS "size" bigint,
+    ADD COLUMN IF NOT EXISTS "sha256" text,
+    ADD COLUMN IF NOT EXISTS "storage_key" text,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_attachments_user_id" ON "attachments" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_attachments_ledger_id" ON "attachments" ("ledger_id");
+
+CREATE TABLE IF NOT EXISTS "rules" ("id" bigserial PRIMARY KEY);
+ALTER TABLE "rules"
+    ADD COLUMN IF NOT EXISTS "ledger_id" bigint,
+    ADD COLUMN IF NOT EXISTS "user_id" bigint,
+    ADD COLUMN IF NOT EXISTS "name" text,
+    ADD COLUMN IF NOT EXISTS "priority" bigint,
+    ADD COLUMN IF NOT EXISTS "enabled" boolean,
+    ADD COLUMN IF NOT EXISTS "match_type" text,
+    ADD COLUMN IF NOT EXISTS "pattern" text,
+    ADD COLUMN IF NOT EXISTS "min_amount" decimal,
+    ADD COLUMN IF NOT EXISTS "max_amount" decimal,
+    ADD COLUMN IF NOT EXISTS "account_id" bigint,
+    ADD COLUMN IF NOT EXISTS "category_id" bigint,
+    ADD COLUMN IF NOT EXISTS "set_description" text,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_rules_user_id" ON "rules" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_rules_ledger_id" ON "rules" ("ledger_id");
+
+CREATE TABLE IF NOT EXISTS "rule_tags" (
+    "rule_id" bigint,
+    "tag_id" bigint,
+    PRIMARY KEY ("rule_id","tag_id"),
+    CONSTRAINT "fk_rule_tags_rule" FOREIGN KEY ("rule_id") REFERENCES "rules"("id"),
+    CONSTRAINT "fk_rule_tags_tag" FOREIGN KEY ("tag_id") REFERENCES "tags"("id")
+);
+
+CREATE TABLE IF NOT EXISTS "duplicate_dismissals" ("id" bigserial PRIMARY KEY);
+ALTER TABLE "duplicate_dismissals"
+    ADD COLUMN IF NOT EXISTS "ledger_id" bigint,
+    ADD COLUMN IF NOT EXISTS "user_id" bigint,
+    ADD COLUMN IF NOT EXISTS "expense_id" bigint,
+    ADD COLUMN IF NOT EXISTS "other_id" bigint,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_duplicate_dismissals_other_id" ON "duplicate_dismissals" ("other_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_duplicate_dismissal_pair" ON "duplicate_dismissals" ("expense_id","other_id");
+CREATE INDEX IF NOT EXISTS "idx_duplicate_dismissals_user_id" ON "duplicate_dismissals" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_duplicate_dismissals_ledger_id" ON "duplicate_dismissals" ("ledger_id");
+
+CREATE TABLE IF NOT EXISTS "audit_entries" ("id" bigserial PRIMARY KEY);
+ALTER TABLE "audit_entries"
+    ADD COLUMN IF NOT EXISTS "ledger_id" bigint,
+    ADD COLUMN IF NOT EXISTS "entity_type" text,
+    ADD COLUMN IF NOT EXISTS "entity_id" bigint,
+    ADD COLUMN IF NOT EXISTS "action" text,
+    ADD COLUMN IF NOT EXISTS "actor_id" bigint,
+    ADD COLUMN IF NOT EXISTS "request_id" text,
+    ADD COLUMN IF NOT EXISTS "changes" text,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_audit_entries_created_at" ON "audit_entries" ("created_at");
+CREATE INDEX IF NOT EXISTS "idx_audit_entries_request_id" ON "audit_entries" ("request_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_entries_actor_id" ON "audit_entries" ("actor_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_entity" ON "audit_entries" ("entity_type","entity_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_entries_ledger_id" ON "audit_entries" ("ledger_id");
+
+-- Data recorded before ledgers existed moves to the personal ledger of its user
+INSERT INTO "ledgers" ("name", "personal", "created_by", "created_at", "updated_at")
+SELECT 'Personal', true, u."id", now(), now()
+FROM "users" u
+WHERE NOT EXISTS (SELECT 1 FROM "ledgers" l WHERE l."personal" AND l."created_by" = u."id")
+ORDER BY u."id";
+
+INSERT INTO "ledger_members" ("ledger_id", "user_id", "role", "created_at", "updated_at")
+SELECT l."id", l."created_by", 'owner', l."created_at", l."created_at"
+FROM "ledgers" l
+WHERE l."personal"
+  AND NOT EXISTS (SELECT 1 FROM "ledger_members" m WHERE m."ledger_id" = l."id" AND m."user_id" = l."created_by");
+
+UPDATE "categories" SET "ledger_id" = (SELECT l."id" FROM "ledgers" l WHERE l."personal" AND l."created_by" = "categories"."user_id")
+WHERE ("ledger_id" IS NULL OR "ledger_id" = 0) AND "user_id" > 0;
+UPDATE "accounts" SET "ledger_id" = (SELECT l."id" FROM "ledgers" l WHERE l."personal" AND l."created_by" = "accounts"."user_id")
+WHERE ("ledger_id" IS NULL OR "ledger_id" = 0) AND "user_id" > 0;
+UPDATE "people" SET "ledger_id" = (SELECT l."id" FROM "ledgers" l WHERE l."personal" AND l."created_by" = "people"."user_id")
+WHERE ("ledger_id" IS NULL OR "ledger_id" = 0) AND "user_id" > 0;
+UPDATE "tags" SET "ledger_id" = (SELECT l."id" FROM "ledgers" l WHERE l."personal" AND l."created_by" = "tags"."user_id")
+WHERE ("ledger_id" IS NULL OR "ledger_id" = 0) AND "user_id" > 0;
+UPDATE "rules" SET "ledger_id" = (SELECT l."id" FROM "ledgers" l WHERE l."personal" AND l."created_by" = "rules"."user_id")
+WHERE ("ledger_id" IS NULL OR "ledger_id" = 0) AND "user_id" > 0;
+UPDATE "expenses" SET "ledger_id" = (SELECT l."id" FROM "ledgers" l WHERE l."personal" AND l."created_by" = "expenses"."user_id")
+WHERE ("ledger_id" IS NULL OR "ledger_id" = 0) AND "user_id" > 0;
+UPDATE "transfers" SET "ledger_id" = (SELECT l."id" FROM "ledgers" l WHERE l."personal" AND l."created_by" = "transfers"."user_id")
+WHERE ("ledger_id" IS NULL OR "ledger_id" = 0) AND "user_id" > 0;
+UPDATE "settlements" SET "ledger_id" = (SELECT l."id" FROM "ledgers" l WHERE l."personal" AND l."created_by" = "settlements"."user_id")
+WHERE ("ledger_id" IS NULL OR "ledger_id" = 0) AND "user_id" > 0;
+UPDATE "attachments" SET "ledger_id" = (SELECT l."id" FROM "ledgers" l WHERE l."personal" AND l."created_by" = "attachments"."user_id")
+WHERE ("ledger_id" IS NULL OR "ledger_id" = 0) AND "user_id" > 0;
+UPDATE "duplicate_dismissals" SET "ledger_id" = (SELECT l."id" FROM "ledgers" l WHERE l."personal" AND l."created_by" = "duplicate_dismissals"."user_id")
+WHERE ("ledger_id" IS NULL OR "ledger_id" = 0) AND "user_id" > 0;
@@ -0,0 +1,24 @@
+-- Rolling back the baseline drops every table and the data in it
+DROP TABLE IF EXISTS `audit_entries`;
+DROP TABLE IF EXISTS `duplicate_dismissals`;
+DROP TABLE IF EXISTS `rule_tags`;
+DROP TABLE IF EXISTS `rules`;
+DROP TABLE IF EXISTS `attachments`;
+DROP TABLE IF EXISTS `settlements`;
+DROP TABLE IF EXISTS `transfers`;
+DROP TABLE IF EXISTS `expense_shares`;
+DROP TABLE IF EXISTS `expense_splits`;
+DROP TABLE IF EXISTS `expense_tags`;
+DROP TABLE IF EXISTS `expenses`;
+DROP TABLE IF EXISTS `tags`;
+DROP TABLE IF EXISTS `people`;
+DROP TABLE IF EXISTS `accounts`;
+DROP TABLE IF EXISTS `categories`;
+DROP TABLE IF EXISTS `recovery_codes`;
+DROP TABLE IF EXISTS `two_factors`;
+DROP TABLE IF EXISTS `user_identities`;
+DROP TABLE IF EXISTS `api_keys`;
+DROP TABLE IF EXISTS `ledger_invites`;
+DROP TABLE IF EXISTS `ledger_members`;
+DROP TABLE IF EXISTS `ledgers`;
+DROP TABLE IF EXISTS `users`;
@@ -0,0 +1,65 @@
+-- Baseline: the schema of the first release with SQLite support, as AutoMigrate
+-- created it at the time
+
+CREATE TABLE `users` (`id` integer PRIMARY KEY AUTOINCREMENT,`email` text,`name` text,`password_hash` text,`is_admin` numeric,`created_at` datetime,`updated_at` datetime);
+CREATE UNIQUE INDEX `idx_users_email` ON `users`(`email`);
+CREATE TABLE `ledgers` (`id` integer PRIMARY KEY AUTOINCREMENT,`name` text,`personal` numeric,`created_by` integer,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX `idx_ledgers_created_by` ON `ledgers`(`created_by`);
+CREATE TABLE `ledger_members` (`id` integer PRIMARY KEY AUTOINCREMENT,`ledger_id` integer,`user_id` integer,`role` text,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX `idx_ledger_members_user_id` ON `ledger_members`(`user_id`);
+CREATE UNIQUE INDEX `idx_ledger_member` ON `ledger_members`(`ledger_id`,`user_id`);
+CREATE TABLE `ledger_invites` (`id` integer PRIMARY KEY AUTOINCREMENT,`ledger_id` integer,`token_hash` text,`role` text,`email` text,`created_by` integer,`expires_at` datetime,`accepted_by` integer,`accepted_at` datetime,`created_at` datetime);
+CREATE UNIQUE INDEX `idx_ledger_invites_token_hash` ON `ledger_invites`(`token_hash`);
+CREATE INDEX `idx_ledger_invites_ledger_id` ON `ledger_invites`(`ledger_id`);
+CREATE TABLE `api_keys` (`id` integer PRIMARY KEY AUTOINCREMENT,`user_id` integer,`name` text,`prefix` text,`key_hash` text,`scopes` text,`expires_at` datetime,`last_used_at` datetime,`revoked_at` datetime,`created_at` datetime);
+CREATE UNIQUE INDEX `idx_api_keys_key_hash` ON `api_keys`(`key_hash`);
+CREATE INDEX `idx_api_keys_user_id` ON `api_keys`(`user_id`);
+CREATE TABLE `user_identities` (`id` integer PRIMARY KEY AUTOINCREMENT,`user_id` integer,`issuer` text,`subject` text,`email` text,`created_at` datetime,`last_login_at` datetime);
+CREATE UNIQUE INDEX `idx_identity_subject` ON `user_identities`(`issuer`,`subject`);
+CREATE INDEX `idx_user_identities_user_id` ON `user_identities`(`user_id`);
+CREATE TABLE `two_factors` (`user_id` integer,`secret` text,`confirmed_at` datetime,`last_used_step` integer,`created_at` datetime,PRIMARY KEY (`user_id`));
+CREATE TABLE `recovery_codes` (`id` integer PRIMARY KEY AUTOINCREMENT,`user_id` integer,`code_hash` text,`used_at` datetime,`created_at` datetime);
+CREATE INDEX `idx_recovery_codes_user_id` ON `recovery_codes`(`user_id`);
+CREATE TABLE `categories` (`id` integer PRIMARY KEY AUTOINCREMENT,`ledger_id` integer,`user_id` integer,`name` text,`description` text,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX `idx_categories_user_id` ON `categories`(`user_id`);
+CREATE INDEX `idx_categories_ledger_id` ON `categories`(`ledger_id`);
+CREATE TABLE `accounts` (`id` integer PRIMARY KEY AUTOINCREMENT,`ledger_id` integer,`user_id` integer,`name` text,`type` text,`currency` text,`opening_balance` real,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX `idx_accounts_user_id` ON `accounts`(`user_id`);
+CREATE INDEX `idx_accounts_ledger_id` ON `accounts`(`ledger_id`);
+CREATE TABLE `people` (`id` integer PRIMARY KEY AUTOINCREMENT,`ledger_id` integer,`user_id` integer,`name` text,`email` text,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX `idx_people_user_id` ON `people`(`user_id`);
+CREATE INDEX `idx_people_ledger_id` ON `people`(`ledger_id`);
+CREATE TABLE `tags` (`id` integer PRIMARY KEY AUTOINCREMENT,`ledger_id` integer,`user_id` integer,`name` text,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX `idx_tags_user_id` ON `tags`(`user_id`);
+CREATE UNIQUE INDEX `idx_tags_ledger_name` ON `tags`(`ledger_id`,`name`);
+CREATE TABLE `expenses` (`id` integer PRIMARY KEY AUTOINCREMENT,`ledger_id` integer,`user_id` integer,`category_id` integer,`account_id` integer,`paid_by_id` integer,`share_mode` text,`kind` text DEFAULT "expense",`amount` real,`currency` text,`description` text,`external_id` text,`date` datetime,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX `idx_expenses_external_id` ON `expenses`(`external_id`);
+CREATE INDEX `idx_expenses_user_id` ON `expenses`(`user_id`);
+CREATE INDEX `idx_expenses_ledger_id` ON `expenses`(`ledger_id`);
+CREATE TABLE `expense_tags` (`expense_id` integer,`tag_id` integer,PRIMARY KEY (`expense_id`,`tag_id`),CONSTRAINT `fk_expense_tags_expense` FOREIGN KEY (`expense_id`) REFERENCES `expenses`(`id`),CONSTRAINT `fk_expense_tags_tag` FOREIGN KEY (`tag_id`) REFERENCES `tags`(`id`));
+CREATE TABLE `expense_splits` (`id` integer PRIMARY KEY AUTOINCREMENT,`expense_id` integer,`category_id` integer,`amount` real,`description` text,`created_at` datetime,`updated_at` datetime,CONSTRAINT `fk_expenses_splits` FOREIGN KEY (`expense_id`) REFERENCES `expenses`(`id`));
+CREATE TABLE `expense_shares` (`id` integer PRIMARY KEY AUTOINCREMENT,`expense_id` integer,`person_id` integer,`value` real,`amount` real,`created_at` datetime,`updated_at` datetime,CONSTRAINT `fk_expenses_shares` FOREIGN KEY (`expense_id`) REFERENCES `expenses`(`id`));
+CREATE TABLE `transfers` (`id` integer PRIMARY KEY AUTOINCREMENT,`ledger_id` integer,`user_id` integer,`from_account_id` integer,`to_account_id` integer,`amount` real,`description` text,`date` datetime,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX `idx_transfers_user_id` ON `transfers`(`user_id`);
+CREATE INDEX `idx_transfers_ledger_id` ON `transfers`(`ledger_id`);
+CREATE TABLE `settlements` (`id` integer PRIMARY KEY AUTOINCREMENT,`ledger_id` integer,`user_id` integer,`from_person_id` integer,`to_person_id` integer,`amount` real,`note` text,`date` datetime,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX `idx_settlements_user_id` ON `settlements`(`user_id`);
+CREATE INDEX `idx_settlements_ledger_id` ON `settlements`(`ledger_id`);
+CREATE TABLE `attachments` (`id` integer PRIMARY KEY AUTOINCREMENT,`ledger_id` integer,`user_id` integer,`expense_id` integer,`file_name` text,`content_type` text,`size` integer,`sha256` text,`storage_key` text,`created_at` datetime);
+CREATE INDEX `idx_attachments_user_id` ON `attachments`(`user_id`);
+CREATE INDEX `idx_attachments_ledger_id` ON `attachments`(`ledger_id`);
+CREATE TABLE `rules` (`id` integer PRIMARY KEY AUTOINCREMENT,`ledger_id` integer,`user_id` integer,`name` text,`priority` integer,`enabled` numeric,`match_type` text,`pattern` text,`min_amount` real,`max_amount` real,`account_id` integer,`category_id` integer,`set_description` text,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX `idx_rules_user_id` ON `rules`(`user_id`);
+CREATE INDEX `idx_rules_ledger_id` ON `rules`(`ledger_id`);
+CREATE TABLE `rule_tags` (`rule_id` integer,`tag_id` integer,PRIMARY KEY (`rule_id`,`tag_id`),CONSTRAINT `fk_rule_tags_rule` FOREIGN KEY (`rule_id`) REFERENCES `rules`(`id`),CONSTRAINT `fk_rule_tags_tag` FOREIGN KEY (`tag_id`) REFERENCES `tags`(`id`));
+CREATE TABLE `duplicate_dismissals` (`id` integer PRIMARY KEY AUTOINCREMENT,`ledger_id` integer,`user_id` integer,`expense_id` integer,`other_id` integer,`created_at` datetime);
+CREATE INDEX `idx_duplicate_dismissals_other_id` ON `duplicate_dismissals`(`other_id`);
+CREATE UNIQUE INDEX `idx_duplicate_dismissal_pair` ON `duplicate_dismissals`(`expense_id`,`other_id`);
+CREATE INDEX `idx_duplicate_dismissals_user_id` ON `duplicate_dismissals`(`user_id`);
+CREATE INDEX `idx_duplicate_dismissals_ledger_id` ON `duplicate_dismissals`(`ledger_id`);
+CREATE TABLE `audit_entries` (`id` integer PRIMARY KEY AUTOINCREMENT,`ledger_id` integer,`entity_type` text,`entity_id` integer,`action` text,`actor_id` integer,`request_id` text,`changes` text,`created_at` datetime);
+CREATE INDEX `idx_audit_entries_created_at` ON `audit_entries`(`created_at`);
+CREATE INDEX `idx_audit_entries_request_id` ON `audit_entries`(`request_id`);
+CREATE INDEX `idx_audit_entries_actor_id` ON `audit_entries`(`actor_id`);
+CREATE INDEX `idx_audit_entity` ON `audit_entries`(`entity_type`,`entity_id`);
+CREATE INDEX `idx_audit_entries_ledger_id` ON `audit_entries`(`ledger_id`);
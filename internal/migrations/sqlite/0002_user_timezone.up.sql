@@ -0,0 +1,3 @@
+-- The timezone a user's days are counted in, as an IANA name such as Europe/Berlin;
+-- empty until they pick one, when the server's timezone applies
+ALTER TABLE `users` ADD COLUMN `timezone` text NOT NULL DEFAULT '';
@@ -0,0 +1,4 @@
+-- The first user to sign up becomes the admin; allowing a single admin lets two
+-- simultaneous first sign-ups fail one of them rather than both winning. An
+-- instance that already has several admins must demote all but one first.
+CREATE UNIQUE INDEX `idx_users_single_admin` ON `users` (`is_admin`) WHERE `is_admin`;
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+type Account struct {
+	ID             int       `json:"id" db:"id"`
+	LedgerID       int       `json:"ledger_id" db:"ledger_id" gorm:"index"`
+	UserID         int       `json:"user_id" db:"user_id" gorm:"index"` // User who recorded it
+	Name           string    `json:"name" db:"name"`
+	Type           string    `json:"type" db:"type"`
+	Currency       string    `json:"currency" db:"currency"`
+	OpeningBalance float64   `json:"opening_balance" db:"opening_balance"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// APIKey lets a script or CI job call the API as the user who created it, limited
+// to its scopes. Only a hash of the key is stored.
+type APIKey struct {
+	ID         int        `json:"id" db:"id"`
+	UserID     int        `json:"user_id" db:"user_id" gorm:"index"`
+	Name       string     `json:"name" db:"name"`
+	Prefix     string     `json:"prefix" db:"prefix"`                   // Start of the key, to tell keys apart
+	KeyHash    string     `json:"-" db:"key_hash" gorm:"uniqueIndex"`   // Hex SHA-256 of the key
+	Scopes     string     `json:"scopes" db:"scopes"`                   // Space-separated, such as "expenses:read expenses:write"
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" db:"expires_at"` // Never expires when nil
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
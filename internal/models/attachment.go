@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// Attachment is a receipt image or PDF stored for an expense
+type Attachment struct {
+	ID          int       `json:"id" db:"id"`
+	LedgerID    int       `json:"ledger_id" db:"ledger_id" gorm:"index"`
+	UserID      int       `json:"user_id" db:"user_id" gorm:"index"` // User who recorded it
+	ExpenseID   int       `json:"expense_id" db:"expense_id"`
+	FileName    string    `json:"file_name" db:"file_name"`
+	ContentType string    `json:"content_type" db:"content_type"` // Sniffed from the content, not taken from the client
+	Size        int64     `json:"size" db:"size"`
+	SHA256      string    `json:"sha256" db:"sha256"`
+	StorageKey  string    `json:"-" db:"storage_key"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+// Audited entity types
+const (
+	AuditEntityExpense  = "expense"
+	AuditEntityCategory = "category"
+)
+
+// Audited actions
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// AuditEntry records one change to an expense or category: who made it, when,
+// in which request and which fields it changed. Entries are never changed once
+// written; they go only with their ledger.
+type AuditEntry struct {
+	ID         int       `json:"id" db:"id"`
+	LedgerID   int       `json:"ledger_id" db:"ledger_id" gorm:"index"`
+	EntityType string    `json:"entity_type" db:"entity_type" gorm:"index:idx_audit_entity"` // expense or category
+	EntityID   int       `json:"entity_id" db:"entity_id" gorm:"index:idx_audit_entity"`
+	Action     string    `json:"action" db:"action"` // create, update or delete
+	ActorID    int       `json:"actor_id" db:"actor_id" gorm:"index"`
+	RequestID  string    `json:"request_id,omitempty" db:"request_id" gorm:"index"`
+	Changes    string    `json:"changes" db:"changes"` // JSON list of {"field", "from", "to"}
+	CreatedAt  time.Time `json:"created_at" db:"created_at" gorm:"index"`
+}
@@ -6,6 +6,8 @@ import (
 
 type Category struct {
 	ID          int       `json:"id" db:"id"`
+	LedgerID    int       `json:"ledger_id" db:"ledger_id" gorm:"index"`
+	UserID      int       `json:"user_id" db:"user_id" gorm:"index"` // User who recorded it
 	Name        string    `json:"name" db:"name"`
 	Description string    `json:"description,omitempty" db:"description"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+// DuplicateDismissal records that two expenses looking alike were reviewed and are
+// both genuine, so they are no longer reported as duplicates. ExpenseID is always
+// the lower of the two IDs.
+type DuplicateDismissal struct {
+	ID        int       `json:"id" db:"id"`
+	LedgerID  int       `json:"ledger_id" db:"ledger_id" gorm:"index"`
+	UserID    int       `json:"user_id" db:"user_id" gorm:"index"` // User who recorded it
+	ExpenseID int       `json:"expense_id" db:"expense_id" gorm:"uniqueIndex:idx_duplicate_dismissal_pair"`
+	OtherID   int       `json:"other_id" db:"other_id" gorm:"uniqueIndex:idx_duplicate_dismissal_pair;index"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
@@ -4,12 +4,31 @@ import (
 	"time"
 )
 
+// Expense kinds: money going out, money coming back for a purchase, and earnings
+const (
+	KindExpense = "expense"
+	KindRefund  = "refund"
+	KindIncome  = "income"
+)
+
 type Expense struct {
 	ID          int       `json:"id" db:"id"`
+	LedgerID    int       `json:"ledger_id" db:"ledger_id" gorm:"index"`
+	UserID      int       `json:"user_id" db:"user_id" gorm:"index"` // User who recorded it
 	CategoryID  int       `json:"category_id" db:"category_id"`
+	AccountID   *int      `json:"account_id,omitempty" db:"account_id"`
+	PaidByID    *int      `json:"paid_by_id,omitempty" db:"paid_by_id"`  // Person who paid a shared expense
+	ShareMode   string    `json:"share_mode,omitempty" db:"share_mode"`  // equal, percentage or exact
+	Kind        string    `json:"kind" db:"kind" gorm:"default:expense"` // expense, refund or income
 	Amount      float64   `json:"amount" db:"amount"`
+	Currency    string    `json:"currency,omitempty" db:"currency"` // ISO 4217 code; empty means the account's currency
 	Description string    `json:"description" db:"description"`
+	ExternalID  string    `json:"external_id,omitempty" db:"external_id" gorm:"index"` // Bank reference of imported transactions
 	Date        time.Time `json:"date" db:"date"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+
+	Splits []ExpenseSplit `json:"splits,omitempty" gorm:"foreignKey:ExpenseID"`
+	Shares []ExpenseShare `json:"shares,omitempty" gorm:"foreignKey:ExpenseID"`
+	Tags   []Tag          `json:"tags,omitempty" gorm:"many2many:expense_tags"`
 }
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// ExpenseShare is one participant's part of a shared expense
+type ExpenseShare struct {
+	ID        int       `json:"id" db:"id"`
+	ExpenseID int       `json:"expense_id" db:"expense_id"`
+	PersonID  int       `json:"person_id" db:"person_id"`
+	Value     float64   `json:"value" db:"value"`   // Percentage or exact amount as entered, depending on the share mode
+	Amount    float64   `json:"amount" db:"amount"` // Resolved amount owed by the participant
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
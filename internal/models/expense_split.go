@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// ExpenseSplit attributes part of an expense's amount to a category
+type ExpenseSplit struct {
+	ID          int       `json:"id" db:"id"`
+	ExpenseID   int       `json:"expense_id" db:"expense_id"`
+	CategoryID  int       `json:"category_id" db:"category_id"`
+	Amount      float64   `json:"amount" db:"amount"`
+	Description string    `json:"description" db:"description"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
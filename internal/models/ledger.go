@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+)
+
+// Ledger roles, from most to least privileged
+const (
+	RoleOwner  = "owner"  // Manages members and invitations as well as the data
+	RoleEditor = "editor" // Records and changes data
+	RoleViewer = "viewer" // Reads data only
+)
+
+// PersonalLedgerName names the ledger every user starts with
+const PersonalLedgerName = "Personal"
+
+// Ledger is a workspace owning categories, expenses and the rest of the data. Every
+// user has a personal ledger and may share others with a household or team.
+type Ledger struct {
+	ID        int       `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Personal  bool      `json:"personal" db:"personal"`                  // Created with the user; cannot be deleted
+	CreatedBy int       `json:"created_by" db:"created_by" gorm:"index"` // User who created the ledger
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// LedgerMember gives a user a role in a ledger
+type LedgerMember struct {
+	ID        int       `json:"id" db:"id"`
+	LedgerID  int       `json:"ledger_id" db:"ledger_id" gorm:"uniqueIndex:idx_ledger_member"`
+	UserID    int       `json:"user_id" db:"user_id" gorm:"uniqueIndex:idx_ledger_member;index"`
+	Role      string    `json:"role" db:"role"` // owner, editor or viewer
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// LedgerInvite lets whoever holds its token join a ledger with a role, once.
+// Only a hash of the token is stored.
+type LedgerInvite struct {
+	ID         int        `json:"id" db:"id"`
+	LedgerID   int        `json:"ledger_id" db:"ledger_id" gorm:"index"`
+	TokenHash  string     `json:"-" db:"token_hash" gorm:"uniqueIndex"` // Hex SHA-256 of the token
+	Role       string     `json:"role" db:"role"`                       // editor or viewer
+	Email      string     `json:"email,omitempty" db:"email"`           // When set, only this user may accept
+	CreatedBy  int        `json:"created_by" db:"created_by"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	AcceptedBy *int       `json:"accepted_by,omitempty" db:"accepted_by"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty" db:"accepted_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
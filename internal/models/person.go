@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// Person is someone who takes part in shared expenses
+type Person struct {
+	ID        int       `json:"id" db:"id"`
+	LedgerID  int       `json:"ledger_id" db:"ledger_id" gorm:"index"`
+	UserID    int       `json:"user_id" db:"user_id" gorm:"index"` // User who recorded it
+	Name      string    `json:"name" db:"name"`
+	Email     string    `json:"email,omitempty" db:"email"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
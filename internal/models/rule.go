@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// Rule match types for the description pattern
+const (
+	MatchContains = "contains"
+	MatchRegex    = "regex"
+)
+
+// Rule categorises expenses entered or imported without a category. Every condition
+// that is set must hold; the actions of matching rules are applied in priority order.
+type Rule struct {
+	ID        int    `json:"id" db:"id"`
+	LedgerID  int    `json:"ledger_id" db:"ledger_id" gorm:"index"`
+	UserID    int    `json:"user_id" db:"user_id" gorm:"index"` // User who recorded it
+	Name      string `json:"name" db:"name"`
+	Priority  int    `json:"priority" db:"priority"` // Lower runs first
+	Enabled   bool   `json:"enabled" db:"enabled"`
+	MatchType string `json:"match_type" db:"match_type"` // contains or regex
+
+	// Conditions
+	Pattern   string   `json:"pattern,omitempty" db:"pattern"` // Matched against the description, ignoring case
+	MinAmount *float64 `json:"min_amount,omitempty" db:"min_amount"`
+	MaxAmount *float64 `json:"max_amount,omitempty" db:"max_amount"`
+	AccountID *int     `json:"account_id,omitempty" db:"account_id"`
+
+	// Actions
+	CategoryID     *int   `json:"category_id,omitempty" db:"category_id"`
+	SetDescription string `json:"set_description,omitempty" db:"set_description"` // Regex rules may refer to groups as $1 or ${name}
+	Tags           []Tag  `json:"tags,omitempty" gorm:"many2many:rule_tags"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// Settlement records money paid back from one person to another
+type Settlement struct {
+	ID           int       `json:"id" db:"id"`
+	LedgerID     int       `json:"ledger_id" db:"ledger_id" gorm:"index"`
+	UserID       int       `json:"user_id" db:"user_id" gorm:"index"` // User who recorded it
+	FromPersonID int       `json:"from_person_id" db:"from_person_id"`
+	ToPersonID   int       `json:"to_person_id" db:"to_person_id"`
+	Amount       float64   `json:"amount" db:"amount"`
+	Note         string    `json:"note" db:"note"`
+	Date         time.Time `json:"date" db:"date"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+)
+
+// Tag is a free-form label attached to expenses across categories, such as a trip or a project
+type Tag struct {
+	ID        int       `json:"id" db:"id"`
+	LedgerID  int       `json:"ledger_id" db:"ledger_id" gorm:"uniqueIndex:idx_tags_ledger_name"`
+	UserID    int       `json:"user_id" db:"user_id" gorm:"index"`                      // User who recorded it
+	Name      string    `json:"name" db:"name" gorm:"uniqueIndex:idx_tags_ledger_name"` // Unique per ledger
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
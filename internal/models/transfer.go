@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+type Transfer struct {
+	ID            int       `json:"id" db:"id"`
+	LedgerID      int       `json:"ledger_id" db:"ledger_id" gorm:"index"`
+	UserID        int       `json:"user_id" db:"user_id" gorm:"index"` // User who recorded it
+	FromAccountID int       `json:"from_account_id" db:"from_account_id"`
+	ToAccountID   int       `json:"to_account_id" db:"to_account_id"`
+	Amount        float64   `json:"amount" db:"amount"`
+	Description   string    `json:"description" db:"description"`
+	Date          time.Time `json:"date" db:"date"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
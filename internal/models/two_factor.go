@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// TwoFactor holds a user's TOTP secret. Once confirmed with a first code, every
+// sign-in asks for a code from the user's authenticator app.
+type TwoFactor struct {
+	UserID       int        `json:"user_id" db:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	Secret       string     `json:"-" db:"secret"`                            // Base32, as given to authenticator apps
+	ConfirmedAt  *time.Time `json:"confirmed_at,omitempty" db:"confirmed_at"` // Nil while enrolment is pending
+	LastUsedStep int64      `json:"-" db:"last_used_step"`                    // Time step of the last code accepted, which cannot be used again
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// RecoveryCode signs a user in once in place of a TOTP code, for when their
+// authenticator app is lost. Only a hash of the code is stored.
+type RecoveryCode struct {
+	ID        int        `json:"id" db:"id"`
+	UserID    int        `json:"user_id" db:"user_id" gorm:"index"`
+	CodeHash  string     `json:"-" db:"code_hash"` // Hex SHA-256 of the code without its hyphen
+	UsedAt    *time.Time `json:"used_at,omitempty" db:"used_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
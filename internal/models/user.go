@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// User is someone who signs in, with a password or through an identity provider,
+// and works in the ledgers they are a member of
+type User struct {
+	ID           int       `json:"id" db:"id"`
+	Email        string    `json:"email" db:"email" gorm:"uniqueIndex"` // Stored lower-cased
+	Name         string    `json:"name,omitempty" db:"name"`
+	PasswordHash string    `json:"-" db:"password_hash"`                                        // Empty for users who only sign in through an identity provider
+	IsAdmin      bool      `json:"is_admin" db:"is_admin"`                                      // May back up and restore the whole instance
+	Timezone     string    `json:"timezone,omitempty" db:"timezone" gorm:"not null;default:''"` // IANA name; empty means the server's timezone
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+// UserIdentity links a user to their account at an external identity provider,
+// which signs them in through OpenID Connect
+type UserIdentity struct {
+	ID          int       `json:"id" db:"id"`
+	UserID      int       `json:"user_id" db:"user_id" gorm:"index"`
+	Issuer      string    `json:"issuer" db:"issuer" gorm:"uniqueIndex:idx_identity_subject"`   // Identity provider URL
+	Subject     string    `json:"subject" db:"subject" gorm:"uniqueIndex:idx_identity_subject"` // User ID at the provider
+	Email       string    `json:"email" db:"email"`                                             // As last reported by the provider
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	LastLoginAt time.Time `json:"last_login_at" db:"last_login_at"`
+}
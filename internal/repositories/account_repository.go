@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"goExpenseTracker/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type AccountRepository interface {
+	Create(account *models.Account) error
+	GetAll(ledgerID int, offset int, limit int, nameFilter string) ([]models.Account, error)
+	GetByID(ledgerID int, id uint) (*models.Account, error)
+	Update(account *models.Account) error
+	Delete(ledgerID int, id uint) error
+	HasTransfers(ledgerID int, id uint) (bool, error)
+}
+
+type accountRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountRepository(db *gorm.DB) AccountRepository {
+	return &accountRepository{db: db}
+}
+
+func (r *accountRepository) Create(account *models.Account) error {
+	return r.db.Create(account).Error
+}
+
+// GetAll fetches accounts with pagination and optional filters
+func (r *accountRepository) GetAll(ledgerID int, offset int, limit int, nameFilter string) ([]models.Account, error) {
+	var accounts []models.Account
+
+	query := r.db.Model(&models.Account{}).Scopes(inLedger(ledgerID))
+
+	if nameFilter != "" {
+		query = query.Scopes(containsFold("name", nameFilter))
+	}
+
+	if limit > 0 {
+		query = query.Offset(offset).Limit(limit)
+	}
+
+	err := query.Order("id").Find(&accounts).Error
+	return accounts, err
+}
+
+func (r *accountRepository) GetByID(ledgerID int, id uint) (*models.Account, error) {
+	var account models.Account
+	err := r.db.Scopes(inLedger(ledgerID)).First(&account, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *accountRepository) Update(account *models.Account) error {
+	return r.db.Save(account).Error
+}
+
+func (r *accountRepository) Delete(ledgerID int, id uint) error {
+	return r.db.Scopes(inLedger(ledgerID)).Delete(&models.Account{}, id).Error
+}
+
+// HasTransfers reports whether any transfer moves money into or out of the
+// account; expenses paid from it are checked through ExpenseRepository.UsesAccount
+func (r *accountRepository) HasTransfers(ledgerID int, id uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Transfer{}).Scopes(inLedger(ledgerID)).
+		Where("from_account_id = ? OR to_account_id = ?", id, id).
+		Count(&count).Error
+	return count > 0, err
+}
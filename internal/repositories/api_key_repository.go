@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"time"
+
+	"goExpenseTracker/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	Create(key *models.APIKey) error
+	GetAll(userID int) ([]models.APIKey, error)
+	GetByID(userID int, id uint) (*models.APIKey, error)
+	GetByHash(keyHash string) (*models.APIKey, error)
+	Revoke(userID int, id uint, at time.Time) error
+	TouchLastUsed(id int, at time.Time) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) Create(key *models.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+// GetAll lists the user's keys, newest first, revoked ones included
+func (r *apiKeyRepository) GetAll(userID int) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := r.db.Where("user_id = ?", userID).Order("id DESC").Find(&keys).Error
+	return keys, err
+}
+
+func (r *apiKeyRepository) GetByID(userID int, id uint) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.db.Where("user_id = ?", userID).First(&key, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) GetByHash(keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.db.Where("key_hash = ?", keyHash).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// Revoke marks the key revoked unless it already is, keeping it listed
+func (r *apiKeyRepository) Revoke(userID int, id uint, at time.Time) error {
+	return r.db.Model(&models.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", at).Error
+}
+
+func (r *apiKeyRepository) TouchLastUsed(id int, at time.Time) error {
+	return r.db.Model(&models.APIKey{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
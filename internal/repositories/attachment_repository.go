@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"goExpenseTracker/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type AttachmentRepository interface {
+	Create(attachment *models.Attachment) error
+	GetByExpense(ledgerID int, expenseID int) ([]models.Attachment, error)
+	GetByID(ledgerID int, id uint) (*models.Attachment, error)
+	Delete(ledgerID int, id uint) error
+}
+
+type attachmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepository(db *gorm.DB) AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+func (r *attachmentRepository) Create(attachment *models.Attachment) error {
+	return r.db.Create(attachment).Error
+}
+
+// GetByExpense lists the attachments of an expense in upload order
+func (r *attachmentRepository) GetByExpense(ledgerID int, expenseID int) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	err := r.db.Scopes(inLedger(ledgerID)).Where("expense_id = ?", expenseID).Order("id").Find(&attachments).Error
+	return attachments, err
+}
+
+func (r *attachmentRepository) GetByID(ledgerID int, id uint) (*models.Attachment, error) {
+	var attachment models.Attachment
+	err := r.db.Scopes(inLedger(ledgerID)).First(&attachment, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *attachmentRepository) Delete(ledgerID int, id uint) error {
+	return r.db.Scopes(inLedger(ledgerID)).Delete(&models.Attachment{}, id).Error
+}
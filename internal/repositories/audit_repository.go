@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"time"
+
+	"goExpenseTracker/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// AuditFilter narrows a search of the audit trail; zero values match everything
+type AuditFilter struct {
+	EntityType string
+	EntityID   int
+	Action     string
+	ActorID    int
+	RequestID  string
+	From       time.Time // Inclusive
+	To         time.Time // Exclusive
+}
+
+// AuditRepository appends to the audit trail and reads it; entries cannot be
+// changed or removed through it
+type AuditRepository interface {
+	Create(entry *models.AuditEntry) error
+	GetForEntity(ledgerID int, entityType string, entityID int) ([]models.AuditEntry, error)
+	Search(ledgerID int, filter AuditFilter, offset, limit int) ([]models.AuditEntry, int64, error)
+}
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) Create(entry *models.AuditEntry) error {
+	return r.db.Create(entry).Error
+}
+
+// GetForEntity lists the changes to an expense or category, oldest first
+func (r *auditRepository) GetForEntity(ledgerID int, entityType string, entityID int) ([]models.AuditEntry, error) {
+	var entries []models.AuditEntry
+	err := r.db.Scopes(inLedger(ledgerID)).
+		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("id ASC").
+		Find(&entries).Error
+	return entries, err
+}
+
+// Search lists the entries matching the filter, newest first, with how many
+// match in all
+func (r *auditRepository) Search(ledgerID int, filter AuditFilter, offset, limit int) ([]models.AuditEntry, int64, error) {
+	query := r.db.Model(&models.AuditEntry{}).Scopes(inLedger(ledgerID))
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID > 0 {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.ActorID > 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	// SQLite compares times as text, which only orders them when they share an
+	// offset, so bounds are given in UTC like the entries are recorded
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From.UTC())
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To.UTC())
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var entries []models.AuditEntry
+	err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&entries).Error
+	return entries, total, err
+}
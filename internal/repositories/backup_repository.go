@@ -0,0 +1,227 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Conflict policies for rows whose primary key already exists during a restore
+const (
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+	ConflictFail      = "fail"
+)
+
+// BackupRepository reads and writes whole tables as column → value rows, so a
+// backup covers every column without a model per table
+type BackupRepository interface {
+	Snapshot(fn func(r TableReader) error) error
+	Restore(fn func(w TableWriter) error) error
+}
+
+// TableReader reads tables within a backup transaction
+type TableReader interface {
+	// DumpTable hands every row of the table to fn, ordered by primary key when it has one
+	DumpTable(table string, fn func(row map[string]interface{}) error) error
+}
+
+// TableWriter inserts rows within a restore transaction
+type TableWriter interface {
+	// Write inserts rows decoded from JSON, converting values to the column types
+	// of the table, and returns how many rows were written
+	Write(table string, rows []map[string]interface{}, policy string) (int64, error)
+}
+
+type backupRepository struct {
+	db *gorm.DB
+}
+
+func NewBackupRepository(db *gorm.DB) BackupRepository {
+	return &backupRepository{db: db}
+}
+
+// Snapshot runs fn in a read-only transaction, so every table it reads shows the
+// database as of the same moment. Postgres needs repeatable read for that; a
+// SQLite transaction sees one state of the file throughout.
+func (r *backupRepository) Snapshot(fn func(r TableReader) error) error {
+	opts := &sql.TxOptions{ReadOnly: true}
+	if r.db.Dialector.Name() == "postgres" {
+		opts.Isolation = sql.LevelRepeatableRead
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&tableReader{tx: tx})
+	}, opts)
+}
+
+type tableReader struct {
+	tx *gorm.DB
+}
+
+func (r *tableReader) DumpTable(table string, fn func(row map[string]interface{}) error) error {
+	columns, err := r.tx.Migrator().ColumnTypes(table)
+	if err != nil {
+		return err
+	}
+
+	query := r.tx.Table(table)
+	for _, column := range columns {
+		if primary, ok := column.PrimaryKey(); ok && primary {
+			query = query.Order(column.Name())
+		}
+	}
+
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		row := make(map[string]interface{})
+		if err := r.tx.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Restore runs fn in a transaction and, on Postgres, moves each table's id
+// sequence past the restored IDs so new rows do not collide with them
+func (r *backupRepository) Restore(fn func(w TableWriter) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		writer := &tableWriter{tx: tx, columns: make(map[string]map[string]gorm.ColumnType)}
+		if err := fn(writer); err != nil {
+			return err
+		}
+
+		if tx.Dialector.Name() != "postgres" {
+			return nil
+		}
+		for table, columns := range writer.columns {
+			if _, ok := columns["id"]; !ok {
+				continue
+			}
+			// setval ignores tables whose id has no sequence, as it is strict on NULL
+			err := tx.Exec(
+				"SELECT setval(pg_get_serial_sequence(?, 'id'), COALESCE(MAX(id), 1), MAX(id) IS NOT NULL) FROM "+tx.Statement.Quote(table),
+				table,
+			).Error
+			if err != nil {
+				return fmt.Errorf("reset id sequence of %s: %w", table, err)
+			}
+		}
+		return nil
+	})
+}
+
+type tableWriter struct {
+	tx      *gorm.DB
+	columns map[string]map[string]gorm.ColumnType
+}
+
+func (w *tableWriter) Write(table string, rows []map[string]interface{}, policy string) (int64, error) {
+	columns, err := w.tableColumns(table)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	var primaryKeys []clause.Column
+	isPrimary := make(map[string]bool)
+	for name, column := range columns {
+		if primary, ok := column.PrimaryKey(); ok && primary {
+			primaryKeys = append(primaryKeys, clause.Column{Name: name})
+			isPrimary[name] = true
+		}
+	}
+
+	for _, row := range rows {
+		for name, value := range row {
+			column, ok := columns[name]
+			if !ok {
+				return 0, fmt.Errorf("table %s has no column %s", table, name)
+			}
+			converted, err := convertValue(column.DatabaseTypeName(), value)
+			if err != nil {
+				return 0, fmt.Errorf("table %s, column %s: %w", table, name, err)
+			}
+			row[name] = converted
+		}
+	}
+
+	query := w.tx.Table(table)
+	switch policy {
+	case ConflictSkip:
+		query = query.Clauses(clause.OnConflict{Columns: primaryKeys, DoNothing: true})
+	case ConflictOverwrite:
+		var updates []string
+		for name := range rows[0] {
+			if !isPrimary[name] {
+				updates = append(updates, name)
+			}
+		}
+		query = query.Clauses(clause.OnConflict{Columns: primaryKeys, DoUpdates: clause.AssignmentColumns(updates)})
+	}
+
+	result := query.Create(&rows)
+	return result.RowsAffected, result.Error
+}
+
+// tableColumns caches the column types of a table for the rest of the restore
+func (w *tableWriter) tableColumns(table string) (map[string]gorm.ColumnType, error) {
+	if columns, ok := w.columns[table]; ok {
+		return columns, nil
+	}
+	if !w.tx.Migrator().HasTable(table) {
+		return nil, fmt.Errorf("table %s does not exist", table)
+	}
+	types, err := w.tx.Migrator().ColumnTypes(table)
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]gorm.ColumnType, len(types))
+	for _, column := range types {
+		columns[column.Name()] = column
+	}
+	w.columns[table] = columns
+	return columns, nil
+}
+
+// convertValue turns a value decoded from JSON (with UseNumber) into the Go type
+// the column expects: JSON has no integers, times or byte strings of its own
+func convertValue(databaseType string, value interface{}) (interface{}, error) {
+	kind := strings.ToUpper(databaseType)
+	switch v := value.(type) {
+	case json.Number:
+		if strings.Contains(kind, "INT") || strings.Contains(kind, "SERIAL") {
+			return v.Int64()
+		}
+		return v.Float64()
+	case string:
+		switch {
+		case strings.Contains(kind, "TIME") || strings.Contains(kind, "DATE"):
+			if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+				return t, nil
+			}
+			return v, nil
+		case strings.Contains(kind, "BYTEA") || strings.Contains(kind, "BLOB"):
+			return base64.StdEncoding.DecodeString(v)
+		}
+		return v, nil
+	default:
+		return value, nil
+	}
+}
@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"database/sql/driver"
+	"strings"
+
+	sqlite "github.com/glebarez/go-sqlite"
+	"gorm.io/gorm"
+)
+
+// SQLite folds the case of ASCII letters only, so on SQLite the conditions below
+// call these Go functions instead, which fold case the way Postgres does and are
+// shared with the in-memory repositories
+func init() {
+	sqlite.MustRegisterDeterministicScalarFunction("ilike", 2, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		value, ok := args[0].(string)
+		pattern, ok2 := args[1].(string)
+		if !ok || !ok2 {
+			return nil, nil
+		}
+		if matchLikeFold(value, pattern) {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	})
+	sqlite.MustRegisterDeterministicScalarFunction("fold", 1, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		value, ok := args[0].(string)
+		if !ok {
+			return args[0], nil
+		}
+		return strings.ToLower(value), nil
+	})
+}
+
+// containsFold matches rows whose column contains filter regardless of case, as
+// Postgres ILIKE '%filter%' does: % and _ in the filter are wildcards and a
+// backslash makes the next character literal
+func containsFold(column, filter string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		pattern := "%" + filter + "%"
+		if db.Dialector.Name() == "sqlite" {
+			return db.Where("ilike("+column+", ?)", pattern)
+		}
+		return db.Where(column+" ILIKE ?", pattern)
+	}
+}
+
+// equalFold matches rows whose column equals value regardless of case
+func equalFold(column, value string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if db.Dialector.Name() == "sqlite" {
+			return db.Where("fold("+column+") = fold(?)", value)
+		}
+		return db.Where("LOWER("+column+") = LOWER(?)", value)
+	}
+}
+
+// sameFold reports whether two strings are equal regardless of case, as
+// equalFold matches them
+func sameFold(a, b string) bool {
+	return strings.ToLower(a) == strings.ToLower(b)
+}
+
+// likeToken is one element of a LIKE pattern: a literal character, _ for any
+// single character or % for any run of characters
+type likeToken struct {
+	wildcard rune
+	literal  rune
+}
+
+// matchLikeFold reports whether value matches the LIKE pattern regardless of case
+func matchLikeFold(value, pattern string) bool {
+	tokens := parseLike(strings.ToLower(pattern))
+	text := []rune(strings.ToLower(value))
+
+	// Match greedily, going back to the last % when the rest does not match
+	t, p := 0, 0
+	star, starText := -1, 0
+	for t < len(text) {
+		switch {
+		case p < len(tokens) && tokens[p].wildcard == '%':
+			star, starText = p, t
+			p++
+		case p < len(tokens) && (tokens[p].wildcard == '_' || (tokens[p].wildcard == 0 && tokens[p].literal == text[t])):
+			p++
+			t++
+		case star >= 0:
+			starText++
+			p, t = star+1, starText
+		default:
+			return false
+		}
+	}
+	for p < len(tokens) && tokens[p].wildcard == '%' {
+		p++
+	}
+	return p == len(tokens)
+}
+
+func parseLike(pattern string) []likeToken {
+	runes := []rune(pattern)
+	tokens := make([]likeToken, 0, len(runes))
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == '\\' && i+1 < len(runes):
+			i++
+			tokens = append(tokens, likeToken{literal: runes[i]})
+		case r == '%' || r == '_':
+			tokens = append(tokens, likeToken{wildcard: r})
+		default:
+			tokens = append(tokens, likeToken{literal: r})
+		}
+	}
+	return tokens
+}
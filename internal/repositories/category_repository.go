@@ -8,10 +8,11 @@ import (
 
 type CategoryRepository interface {
 	Create(category *models.Category) error
-	GetAll(offset int, limit int, nameFilter string) ([]models.Category, error)
-	GetByID(id uint) (*models.Category, error)
+	GetAll(ledgerID int, offset int, limit int, nameFilter string) ([]models.Category, error)
+	GetByID(ledgerID int, id uint) (*models.Category, error)
+	GetByName(ledgerID int, name string) (*models.Category, error)
 	Update(category *models.Category) error
-	Delete(id uint) error
+	Delete(ledgerID int, id uint) error
 }
 
 type categoryRepository struct {
@@ -27,26 +28,37 @@ func (r *categoryRepository) Create(category *models.Category) error {
 }
 
 // GetAll fetches categories with pagination and optional filters
-func (r *categoryRepository) GetAll(offset int, limit int, nameFilter string) ([]models.Category, error) {
+func (r *categoryRepository) GetAll(ledgerID int, offset int, limit int, nameFilter string) ([]models.Category, error) {
 	var categories []models.Category
 
-	query := r.db.Model(&models.Category{})
+	query := r.db.Model(&models.Category{}).Scopes(inLedger(ledgerID))
 
 	if nameFilter != "" {
-		query = query.Where("name ILIKE ?", "%"+nameFilter+"%")
+		query = query.Scopes(containsFold("name", nameFilter))
 	}
 
 	if limit > 0 {
 		query = query.Offset(offset).Limit(limit)
 	}
 
-	err := query.Find(&categories).Error
+	// Pages follow creation order, so they neither overlap nor skip rows
+	err := query.Order("id").Find(&categories).Error
 	return categories, err
 }
 
-func (r *categoryRepository) GetByID(id uint) (*models.Category, error) {
+func (r *categoryRepository) GetByID(ledgerID int, id uint) (*models.Category, error) {
 	var category models.Category
-	err := r.db.First(&category, id).Error
+	err := r.db.Scopes(inLedger(ledgerID)).First(&category, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// GetByName finds a category by its name, ignoring case
+func (r *categoryRepository) GetByName(ledgerID int, name string) (*models.Category, error) {
+	var category models.Category
+	err := r.db.Scopes(inLedger(ledgerID)).Scopes(equalFold("name", name)).First(&category).Error
 	if err != nil {
 		return nil, err
 	}
@@ -57,6 +69,6 @@ func (r *categoryRepository) Update(category *models.Category) error {
 	return r.db.Save(category).Error
 }
 
-func (r *categoryRepository) Delete(id uint) error {
-	return r.db.Delete(&models.Category{}, id).Error
+func (r *categoryRepository) Delete(ledgerID int, id uint) error {
+	return r.db.Scopes(inLedger(ledgerID)).Delete(&models.Category{}, id).Error
 }
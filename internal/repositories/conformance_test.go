@@ -0,0 +1,831 @@
+package repositories
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"goExpenseTracker/internal/migrations"
+	"goExpenseTracker/internal/models"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// The conformance suite runs every storage backend of CategoryRepository and
+// ExpenseRepository through the same cases. The in-memory and SQLite backends
+// always run; Postgres runs when TEST_POSTGRES_DSN points at a database the
+// suite may empty, created with a UTF-8 locale other than C so that ILIKE folds
+// more than ASCII.
+
+const (
+	ledgerA = 1
+	ledgerB = 2
+)
+
+// backendRepos are the repositories of one backend, over empty storage
+type backendRepos struct {
+	categories CategoryRepository
+	expenses   ExpenseRepository
+	// newTag stores a tag that expenses can be linked to
+	newTag func(t *testing.T, ledgerID int, name string) models.Tag
+}
+
+type backend struct {
+	name string
+	open func(t *testing.T) backendRepos
+}
+
+func backends(t *testing.T) []backend {
+	list := []backend{
+		{name: "memory", open: openMemory},
+		{name: "sqlite", open: func(t *testing.T) backendRepos {
+			db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")+"?_pragma=foreign_keys(1)"), quietGorm())
+			if err != nil {
+				t.Fatalf("open sqlite: %v", err)
+			}
+			return openDatabase(t, db)
+		}},
+	}
+	if dsn := os.Getenv("TEST_POSTGRES_DSN"); dsn != "" {
+		list = append(list, backend{name: "postgres", open: func(t *testing.T) backendRepos {
+			db, err := gorm.Open(postgres.Open(dsn), quietGorm())
+			if err != nil {
+				t.Fatalf("open postgres: %v", err)
+			}
+			repos := openDatabase(t, db)
+			err = db.Exec("TRUNCATE expense_tags, expense_splits, expense_shares, expenses, tags, categories RESTART IDENTITY CASCADE").Error
+			if err != nil {
+				t.Fatalf("empty postgres tables: %v", err)
+			}
+			return repos
+		}})
+	} else {
+		t.Log("TEST_POSTGRES_DSN is not set; skipping the postgres backend")
+	}
+	return list
+}
+
+func quietGorm() *gorm.Config {
+	return &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+}
+
+func openMemory(t *testing.T) backendRepos {
+	lastTagID := 0
+	categories := NewMemoryCategoryRepository()
+	return backendRepos{
+		categories: categories,
+		expenses:   NewMemoryExpenseRepository(categories),
+		newTag: func(t *testing.T, ledgerID int, name string) models.Tag {
+			lastTagID++
+			return models.Tag{ID: lastTagID, LedgerID: ledgerID, Name: name}
+		},
+	}
+}
+
+func openDatabase(t *testing.T, db *gorm.DB) backendRepos {
+	runner, err := migrations.New(db)
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if _, err := runner.Up(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	tags := NewTagRepository(db)
+	return backendRepos{
+		categories: NewCategoryRepository(db),
+		expenses:   NewExpenseRepository(db),
+		newTag: func(t *testing.T, ledgerID int, name string) models.Tag {
+			tag := models.Tag{LedgerID: ledgerID, Name: name}
+			if err := tags.Create(&tag); err != nil {
+				t.Fatalf("create tag: %v", err)
+			}
+			return tag
+		},
+	}
+}
+
+// eachBackend runs the test against fresh storage of every backend
+func eachBackend(t *testing.T, test func(t *testing.T, repos backendRepos)) {
+	for _, b := range backends(t) {
+		t.Run(b.name, func(t *testing.T) {
+			test(t, b.open(t))
+		})
+	}
+}
+
+// likeCases are the ILIKE semantics every backend must share: a case-insensitive
+// substring match, Unicode included, in which % and _ are wildcards unless
+// escaped with a backslash
+var likeNames = []string{"Groceries", "GROCERY store", "Café Crème", "100% Organic", "snake_case", "Travel"}
+
+var likeCases = []struct {
+	filter string
+	want   []string
+}{
+	{"", likeNames},
+	{"groc", []string{"Groceries", "GROCERY store"}},
+	{"STORE", []string{"GROCERY store"}},
+	{"CAFÉ", []string{"Café Crème"}},
+	{"crème", []string{"Café Crème"}},
+	{"cafe", nil},
+	{"gr_cer", []string{"Groceries", "GROCERY store"}},
+	{"g%y", []string{"GROCERY store"}},
+	{"0%o", []string{"100% Organic"}},
+	{`0\%`, []string{"100% Organic"}},
+	{`e\_c`, []string{"snake_case"}},
+	{"e_c", []string{"snake_case"}},
+	{"xyz", nil},
+}
+
+func date(day int) time.Time {
+	return time.Date(2025, time.March, day, 0, 0, 0, 0, time.UTC)
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func mustCreateCategory(t *testing.T, repo CategoryRepository, ledgerID int, name string) models.Category {
+	t.Helper()
+	category := models.Category{LedgerID: ledgerID, UserID: 1, Name: name, Description: name + " things"}
+	if err := repo.Create(&category); err != nil {
+		t.Fatalf("create category %q: %v", name, err)
+	}
+	return category
+}
+
+func mustCreateExpense(t *testing.T, repo ExpenseRepository, expense models.Expense) models.Expense {
+	t.Helper()
+	if expense.LedgerID == 0 {
+		expense.LedgerID = ledgerA
+	}
+	if expense.UserID == 0 {
+		expense.UserID = 1
+	}
+	if err := repo.Create(&expense); err != nil {
+		t.Fatalf("create expense %q: %v", expense.Description, err)
+	}
+	return expense
+}
+
+func categoryNames(categories []models.Category) []string {
+	names := make([]string, 0, len(categories))
+	for _, category := range categories {
+		names = append(names, category.Name)
+	}
+	return names
+}
+
+func expenseDescriptions(expenses []models.Expense) []string {
+	descriptions := make([]string, 0, len(expenses))
+	for _, expense := range expenses {
+		descriptions = append(descriptions, expense.Description)
+	}
+	return descriptions
+}
+
+func tagIDs(tags []models.Tag) []int {
+	ids := make([]int, 0, len(tags))
+	for _, tag := range tags {
+		ids = append(ids, tag.ID)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func sameStrings(got, want []string) bool {
+	if len(got) == 0 && len(want) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(got, want)
+}
+
+func closeTo(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestCategoryRepositoryCreateAndGet(t *testing.T) {
+	eachBackend(t, func(t *testing.T, repos backendRepos) {
+		created := mustCreateCategory(t, repos.categories, ledgerA, "Food")
+		if created.ID == 0 || created.CreatedAt.IsZero() || created.UpdatedAt.IsZero() {
+			t.Fatalf("create did not fill in ID and timestamps: %+v", created)
+		}
+
+		got, err := repos.categories.GetByID(ledgerA, uint(created.ID))
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if got.Name != "Food" || got.Description != "Food things" || got.LedgerID != ledgerA || got.UserID != 1 {
+			t.Errorf("got %+v", got)
+		}
+
+		if _, err := repos.categories.GetByID(ledgerB, uint(created.ID)); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("get from another ledger: got %v, want record not found", err)
+		}
+		if _, err := repos.categories.GetByID(ledgerA, 999); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("get missing: got %v, want record not found", err)
+		}
+	})
+}
+
+func TestCategoryRepositoryGetAllPagesInCreationOrder(t *testing.T) {
+	eachBackend(t, func(t *testing.T, repos backendRepos) {
+		for _, name := range []string{"Zeta", "Alpha", "Mid", "Beta"} {
+			mustCreateCategory(t, repos.categories, ledgerA, name)
+		}
+		mustCreateCategory(t, repos.categories, ledgerB, "Other ledger")
+
+		cases := []struct {
+			offset, limit int
+			want          []string
+		}{
+			{0, 0, []string{"Zeta", "Alpha", "Mid", "Beta"}},
+			{0, 2, []string{"Zeta", "Alpha"}},
+			{2, 2, []string{"Mid", "Beta"}},
+			{3, 10, []string{"Beta"}},
+			{4, 10, nil},
+		}
+		for _, c := range cases {
+			got, err := repos.categories.GetAll(ledgerA, c.offset, c.limit, "")
+			if err != nil {
+				t.Fatalf("get all: %v", err)
+			}
+			if names := categoryNames(got); !sameStrings(names, c.want) {
+				t.Errorf("offset %d limit %d: got %v, want %v", c.offset, c.limit, names, c.want)
+			}
+		}
+	})
+}
+
+func TestCategoryRepositoryNameFilter(t *testing.T) {
+	eachBackend(t, func(t *testing.T, repos backendRepos) {
+		for _, name := range likeNames {
+			mustCreateCategory(t, repos.categories, ledgerA, name)
+		}
+		mustCreateCategory(t, repos.categories, ledgerB, "Groceries elsewhere")
+
+		for _, c := range likeCases {
+			got, err := repos.categories.GetAll(ledgerA, 0, 0, c.filter)
+			if err != nil {
+				t.Fatalf("filter %q: %v", c.filter, err)
+			}
+			if names := categoryNames(got); !sameStrings(names, c.want) {
+				t.Errorf("filter %q: got %v, want %v", c.filter, names, c.want)
+			}
+		}
+	})
+}
+
+func TestCategoryRepositoryGetByName(t *testing.T) {
+	eachBackend(t, func(t *testing.T, repos backendRepos) {
+		first := mustCreateCategory(t, repos.categories, ledgerA, "Café Crème")
+		mustCreateCategory(t, repos.categories, ledgerA, "Groceries")
+		mustCreateCategory(t, repos.categories, ledgerB, "Travel")
+
+		for _, name := range []string{"Café Crème", "café crème", "CAFÉ CRÈME"} {
+			got, err := repos.categories.GetByName(ledgerA, name)
+			if err != nil {
+				t.Fatalf("get %q: %v", name, err)
+			}
+			if got.ID != first.ID {
+				t.Errorf("get %q: got category %d, want %d", name, got.ID, first.ID)
+			}
+		}
+
+		// Names are compared whole and literally, unlike filters
+		for _, name := range []string{"Café", "gr_ceries", "Groceries%", "Travel"} {
+			if _, err := repos.categories.GetByName(ledgerA, name); !errors.Is(err, gorm.ErrRecordNotFound) {
+				t.Errorf("get %q: got %v, want record not found", name, err)
+			}
+		}
+	})
+}
+
+func TestCategoryRepositoryUpdateAndDelete(t *testing.T) {
+	eachBackend(t, func(t *testing.T, repos backendRepos) {
+		category := mustCreateCategory(t, repos.categories, ledgerA, "Food")
+		kept := mustCreateCategory(t, repos.categories, ledgerA, "Travel")
+
+		stored, err := repos.categories.GetByID(ledgerA, uint(category.ID))
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		stored.Name = "Dining"
+		stored.Description = ""
+		if err := repos.categories.Update(stored); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		got, err := repos.categories.GetByID(ledgerA, uint(category.ID))
+		if err != nil {
+			t.Fatalf("get updated: %v", err)
+		}
+		if got.Name != "Dining" || got.Description != "" || !got.CreatedAt.Equal(stored.CreatedAt) {
+			t.Errorf("after update got %+v", got)
+		}
+		if got.UpdatedAt.Before(category.UpdatedAt) {
+			t.Errorf("update moved UpdatedAt back from %v to %v", category.UpdatedAt, got.UpdatedAt)
+		}
+
+		// Deletes are confined to the ledger, and deleting what is gone is not an error
+		if err := repos.categories.Delete(ledgerB, uint(category.ID)); err != nil {
+			t.Fatalf("delete from another ledger: %v", err)
+		}
+		if _, err := repos.categories.GetByID(ledgerA, uint(category.ID)); err != nil {
+			t.Errorf("delete from another ledger removed the category: %v", err)
+		}
+		if err := repos.categories.Delete(ledgerA, uint(category.ID)); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if _, err := repos.categories.GetByID(ledgerA, uint(category.ID)); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("get deleted: got %v, want record not found", err)
+		}
+		if err := repos.categories.Delete(ledgerA, uint(category.ID)); err != nil {
+			t.Errorf("delete again: %v", err)
+		}
+		if _, err := repos.categories.GetByID(ledgerA, uint(kept.ID)); err != nil {
+			t.Errorf("delete removed another category: %v", err)
+		}
+	})
+}
+
+func TestExpenseRepositoryCreateAndGet(t *testing.T) {
+	eachBackend(t, func(t *testing.T, repos backendRepos) {
+		food := mustCreateCategory(t, repos.categories, ledgerA, "Food")
+		home := mustCreateCategory(t, repos.categories, ledgerA, "Home")
+		trip := repos.newTag(t, ledgerA, "trip")
+		work := repos.newTag(t, ledgerA, "work")
+
+		created := mustCreateExpense(t, repos.expenses, models.Expense{
+			CategoryID:  food.ID,
+			AccountID:   intPtr(7),
+			PaidByID:    intPtr(3),
+			ShareMode:   "equal",
+			Amount:      60,
+			Currency:    "EUR",
+			Description: "Market",
+			ExternalID:  "bank-1",
+			Date:        date(5),
+			Splits: []models.ExpenseSplit{
+				{CategoryID: food.ID, Amount: 40, Description: "Food"},
+				{CategoryID: home.ID, Amount: 20, Description: "Soap"},
+			},
+			Shares: []models.ExpenseShare{{PersonID: 3, Amount: 30}, {PersonID: 4, Amount: 30}},
+			Tags:   []models.Tag{trip, work},
+		})
+		if created.ID == 0 || created.Kind != models.KindExpense || created.CreatedAt.IsZero() {
+			t.Fatalf("create did not fill in ID, kind and timestamps: %+v", created)
+		}
+		for _, split := range created.Splits {
+			if split.ID == 0 || split.ExpenseID != created.ID {
+				t.Errorf("split not linked: %+v", split)
+			}
+		}
+
+		got, err := repos.expenses.GetByID(ledgerA, uint(created.ID))
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if got.Amount != 60 || got.Currency != "EUR" || got.Description != "Market" || got.ExternalID != "bank-1" ||
+			got.ShareMode != "equal" || got.Kind != models.KindExpense || !got.Date.Equal(date(5)) {
+			t.Errorf("got %+v", got)
+		}
+		if got.AccountID == nil || *got.AccountID != 7 || got.PaidByID == nil || *got.PaidByID != 3 {
+			t.Errorf("got account %v and payer %v", got.AccountID, got.PaidByID)
+		}
+		if len(got.Splits) != 2 || len(got.Shares) != 2 {
+			t.Errorf("got %d splits and %d shares, want 2 and 2", len(got.Splits), len(got.Shares))
+		}
+		if ids := tagIDs(got.Tags); !reflect.DeepEqual(ids, tagIDs([]models.Tag{trip, work})) {
+			t.Errorf("got tags %v", ids)
+		}
+
+		// Changing what was returned does not change what is stored
+		*got.AccountID = 8
+		got.Splits[0].Amount = 1
+		again, err := repos.expenses.GetByID(ledgerA, uint(created.ID))
+		if err != nil {
+			t.Fatalf("get again: %v", err)
+		}
+		if *again.AccountID != 7 || again.Splits[0].Amount == 1 {
+			t.Errorf("stored expense changed through a returned copy: %+v", again)
+		}
+
+		if _, err := repos.expenses.GetByID(ledgerB, uint(created.ID)); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("get from another ledger: got %v, want record not found", err)
+		}
+	})
+}
+
+func TestExpenseRepositoryFilters(t *testing.T) {
+	eachBackend(t, func(t *testing.T, repos backendRepos) {
+		food := mustCreateCategory(t, repos.categories, ledgerA, "Food")
+		home := mustCreateCategory(t, repos.categories, ledgerA, "Home")
+		trip := repos.newTag(t, ledgerA, "trip")
+
+		mustCreateExpense(t, repos.expenses, models.Expense{Description: "Rent", CategoryID: home.ID, AccountID: intPtr(1), Amount: 500, Date: date(1)})
+		mustCreateExpense(t, repos.expenses, models.Expense{Description: "Lunch", CategoryID: food.ID, AccountID: intPtr(2), Amount: 12, Date: date(10), Tags: []models.Tag{trip}})
+		mustCreateExpense(t, repos.expenses, models.Expense{Description: "Market", CategoryID: food.ID, Amount: 30, Date: date(3),
+			Splits: []models.ExpenseSplit{{CategoryID: food.ID, Amount: 20}, {CategoryID: home.ID, Amount: 10}}})
+		mustCreateExpense(t, repos.expenses, models.Expense{Description: "Dinner", CategoryID: food.ID, AccountID: intPtr(1), Amount: 40, Date: date(10), Tags: []models.Tag{trip}})
+		mustCreateExpense(t, repos.expenses, models.Expense{LedgerID: ledgerB, Description: "Rent elsewhere", CategoryID: home.ID, Amount: 700, Date: date(1)})
+
+		cases := []struct {
+			name   string
+			filter ExpenseFilter
+			want   []string // In date order, then ID
+		}{
+			{"none", ExpenseFilter{}, []string{"Rent", "Market", "Lunch", "Dinner"}},
+			{"category with split lines", ExpenseFilter{CategoryID: home.ID}, []string{"Rent", "Market"}},
+			{"account", ExpenseFilter{AccountID: 1}, []string{"Rent", "Dinner"}},
+			{"tag", ExpenseFilter{TagID: trip.ID}, []string{"Lunch", "Dinner"}},
+			{"inclusive dates", ExpenseFilter{From: date(3), To: date(10)}, []string{"Market", "Lunch", "Dinner"}},
+			{"from", ExpenseFilter{From: date(4)}, []string{"Lunch", "Dinner"}},
+			{"to", ExpenseFilter{To: date(2)}, []string{"Rent"}},
+			{"description", ExpenseFilter{Description: "N"}, []string{"Rent", "Lunch", "Dinner"}},
+			{"combined", ExpenseFilter{Description: "ne", CategoryID: food.ID, AccountID: 1}, []string{"Dinner"}},
+		}
+		for _, c := range cases {
+			got, err := repos.expenses.Find(ledgerA, c.filter)
+			if err != nil {
+				t.Fatalf("%s: %v", c.name, err)
+			}
+			if descriptions := expenseDescriptions(got); !sameStrings(descriptions, c.want) {
+				t.Errorf("%s: got %v, want %v", c.name, descriptions, c.want)
+			}
+		}
+	})
+}
+
+func TestExpenseRepositoryDescriptionFilter(t *testing.T) {
+	eachBackend(t, func(t *testing.T, repos backendRepos) {
+		for i, name := range likeNames {
+			mustCreateExpense(t, repos.expenses, models.Expense{Description: name, Amount: 1, Date: date(i + 1)})
+		}
+
+		for _, c := range likeCases {
+			got, err := repos.expenses.GetAll(ledgerA, 0, 0, c.filter, 0)
+			if err != nil {
+				t.Fatalf("filter %q: %v", c.filter, err)
+			}
+			if descriptions := expenseDescriptions(got); !sameStrings(descriptions, c.want) {
+				t.Errorf("filter %q: got %v, want %v", c.filter, descriptions, c.want)
+			}
+		}
+	})
+}
+
+func TestExpenseRepositoryGetAllAndStream(t *testing.T) {
+	eachBackend(t, func(t *testing.T, repos backendRepos) {
+		// Created out of date order, so creation and date order differ
+		for i, day := range []int{9, 2, 7, 2, 5} {
+			mustCreateExpense(t, repos.expenses, models.Expense{Description: string(rune('A' + i)), Amount: 1, Date: date(day)})
+		}
+
+		got, err := repos.expenses.GetAll(ledgerA, 1, 3, "", 0)
+		if err != nil {
+			t.Fatalf("get all: %v", err)
+		}
+		if descriptions := expenseDescriptions(got); !sameStrings(descriptions, []string{"B", "C", "D"}) {
+			t.Errorf("page: got %v, want [B C D]", descriptions)
+		}
+
+		var batches [][]string
+		err = repos.expenses.Stream(ledgerA, ExpenseFilter{}, 2, func(batch []models.Expense) error {
+			batches = append(batches, expenseDescriptions(batch))
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("stream: %v", err)
+		}
+		want := [][]string{{"B", "D"}, {"E", "C"}, {"A"}}
+		if !reflect.DeepEqual(batches, want) {
+			t.Errorf("stream: got %v, want %v", batches, want)
+		}
+
+		stop := errors.New("stop")
+		calls := 0
+		err = repos.expenses.Stream(ledgerA, ExpenseFilter{}, 2, func(batch []models.Expense) error {
+			calls++
+			return stop
+		})
+		if !errors.Is(err, stop) || calls != 1 {
+			t.Errorf("stream did not stop at the first error: got %v after %d calls", err, calls)
+		}
+	})
+}
+
+func TestExpenseRepositoryBatchUpdateAndDelete(t *testing.T) {
+	eachBackend(t, func(t *testing.T, repos backendRepos) {
+		food := mustCreateCategory(t, repos.categories, ledgerA, "Food")
+		home := mustCreateCategory(t, repos.categories, ledgerA, "Home")
+		trip := repos.newTag(t, ledgerA, "trip")
+		work := repos.newTag(t, ledgerA, "work")
+
+		batch := []*models.Expense{
+			{LedgerID: ledgerA, Description: "One", CategoryID: food.ID, Amount: 10, Date: date(1), Tags: []models.Tag{trip}},
+			{LedgerID: ledgerA, Description: "Two", CategoryID: food.ID, Amount: 20, Date: date(2),
+				Splits: []models.ExpenseSplit{{CategoryID: food.ID, Amount: 15}, {CategoryID: home.ID, Amount: 5}}},
+		}
+		if err := repos.expenses.CreateBatch(nil, batch); err != nil {
+			t.Fatalf("create batch: %v", err)
+		}
+		if batch[0].ID == 0 || batch[1].ID == 0 || batch[0].ID == batch[1].ID {
+			t.Fatalf("batch IDs not filled in: %d and %d", batch[0].ID, batch[1].ID)
+		}
+
+		// Categories created with a batch replace the placeholders referring to them
+		garden := &models.Category{LedgerID: ledgerA, UserID: 1, Name: "Garden"}
+		withCategory := []*models.Expense{
+			{LedgerID: ledgerA, Description: "Seeds", CategoryID: PlaceholderCategoryID(0), Amount: 4, Date: date(2),
+				Splits: []models.ExpenseSplit{{CategoryID: PlaceholderCategoryID(0), Amount: 3}, {CategoryID: home.ID, Amount: 1}}},
+		}
+		if err := repos.expenses.CreateBatch([]*models.Category{garden}, withCategory); err != nil {
+			t.Fatalf("create batch with a category: %v", err)
+		}
+		if garden.ID < 1 {
+			t.Fatalf("new category ID not filled in: %d", garden.ID)
+		}
+		if stored, err := repos.categories.GetByName(ledgerA, "Garden"); err != nil || stored.ID != garden.ID {
+			t.Fatalf("new category not stored: %+v, %v", stored, err)
+		}
+		seeds, err := repos.expenses.GetByID(ledgerA, uint(withCategory[0].ID))
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if seeds.CategoryID != garden.ID || len(seeds.Splits) != 2 || seeds.Splits[0].CategoryID != garden.ID || seeds.Splits[1].CategoryID != home.ID {
+			t.Errorf("placeholders not replaced: category %d, splits %+v", seeds.CategoryID, seeds.Splits)
+		}
+		if err := repos.expenses.Delete(ledgerA, uint(seeds.ID)); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+
+		stored, err := repos.expenses.GetByID(ledgerA, uint(batch[1].ID))
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		stored.Description = "Two, corrected"
+		stored.Kind = models.KindRefund
+		stored.CategoryID = home.ID
+		stored.Splits = []models.ExpenseSplit{{CategoryID: home.ID, Amount: 20, Description: "All home"}}
+		stored.Tags = []models.Tag{work}
+		if err := repos.expenses.Update(stored); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+
+		got, err := repos.expenses.GetByID(ledgerA, uint(batch[1].ID))
+		if err != nil {
+			t.Fatalf("get updated: %v", err)
+		}
+		if got.Description != "Two, corrected" || got.Kind != models.KindRefund {
+			t.Errorf("after update got %+v", got)
+		}
+		if len(got.Splits) != 1 || got.Splits[0].CategoryID != home.ID || got.Splits[0].Description != "All home" {
+			t.Errorf("split lines not replaced: %+v", got.Splits)
+		}
+		if ids := tagIDs(got.Tags); !reflect.DeepEqual(ids, []int{work.ID}) {
+			t.Errorf("tags not replaced: %v", ids)
+		}
+
+		// Updating an expense that is not in the caller's ledger, or does not exist, writes nothing
+		foreign := *got
+		foreign.LedgerID = ledgerB
+		foreign.Description = "Moved"
+		if err := repos.expenses.Update(&foreign); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("update from another ledger: got %v, want record not found", err)
+		}
+		missing := models.Expense{ID: batch[1].ID + 100, LedgerID: ledgerA, UserID: 1, Description: "Ghost", CategoryID: food.ID, Amount: 1, Date: date(3)}
+		if err := repos.expenses.Update(&missing); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("update of a missing expense: got %v, want record not found", err)
+		}
+		if _, err := repos.expenses.GetByID(ledgerA, uint(missing.ID)); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("update of a missing expense created it: %v", err)
+		}
+		if _, err := repos.expenses.GetByID(ledgerB, uint(batch[1].ID)); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("update from another ledger moved the expense: %v", err)
+		}
+		if kept, err := repos.expenses.GetByID(ledgerA, uint(batch[1].ID)); err != nil || kept.Description != "Two, corrected" || len(kept.Splits) != 1 {
+			t.Errorf("update from another ledger changed the expense: %+v, %v", kept, err)
+		}
+
+		// The replaced split line no longer counts towards its category
+		found, err := repos.expenses.Find(ledgerA, ExpenseFilter{CategoryID: food.ID})
+		if err != nil {
+			t.Fatalf("find: %v", err)
+		}
+		if descriptions := expenseDescriptions(found); !sameStrings(descriptions, []string{"One"}) {
+			t.Errorf("after update category filter got %v, want [One]", descriptions)
+		}
+
+		if err := repos.expenses.Delete(ledgerB, uint(batch[0].ID)); err != nil {
+			t.Fatalf("delete from another ledger: %v", err)
+		}
+		if _, err := repos.expenses.GetByID(ledgerA, uint(batch[0].ID)); err != nil {
+			t.Errorf("delete from another ledger removed the expense: %v", err)
+		}
+		if err := repos.expenses.Delete(ledgerA, uint(batch[0].ID)); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if _, err := repos.expenses.GetByID(ledgerA, uint(batch[0].ID)); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("get deleted: got %v, want record not found", err)
+		}
+		if err := repos.expenses.Delete(ledgerA, uint(batch[0].ID)); err != nil {
+			t.Errorf("delete again: %v", err)
+		}
+		remaining, err := repos.expenses.Find(ledgerA, ExpenseFilter{TagID: trip.ID})
+		if err != nil || len(remaining) != 0 {
+			t.Errorf("tag filter after delete: got %v, %v", expenseDescriptions(remaining), err)
+		}
+	})
+}
+
+func TestExpenseRepositoryAccounts(t *testing.T) {
+	eachBackend(t, func(t *testing.T, repos backendRepos) {
+		mustCreateExpense(t, repos.expenses, models.Expense{Description: "Shoes", AccountID: intPtr(1), Amount: 100, Date: date(1)})
+		mustCreateExpense(t, repos.expenses, models.Expense{Description: "Shoes back", Kind: models.KindRefund, AccountID: intPtr(1), Amount: 40, Date: date(3)})
+		mustCreateExpense(t, repos.expenses, models.Expense{Description: "Salary", Kind: models.KindIncome, AccountID: intPtr(1), Amount: 1000, Date: date(5)})
+		mustCreateExpense(t, repos.expenses, models.Expense{Description: "Cash", AccountID: intPtr(2), Amount: 7, Date: date(2)})
+		mustCreateExpense(t, repos.expenses, models.Expense{LedgerID: ledgerB, Description: "Elsewhere", AccountID: intPtr(1), Amount: 9, Date: date(2)})
+
+		sums := []struct {
+			before time.Time
+			want   float64
+		}{
+			{time.Time{}, 100 - 40 - 1000},
+			{date(5), 100 - 40}, // Exclusive
+			{date(1), 0},
+		}
+		for _, c := range sums {
+			got, err := repos.expenses.SumByAccount(ledgerA, 1, c.before)
+			if err != nil {
+				t.Fatalf("sum: %v", err)
+			}
+			if !closeTo(got, c.want) {
+				t.Errorf("sum before %v: got %v, want %v", c.before, got, c.want)
+			}
+		}
+		if got, err := repos.expenses.SumByAccount(ledgerA, 99, time.Time{}); err != nil || got != 0 {
+			t.Errorf("sum of an unused account: got %v, %v", got, err)
+		}
+
+		for _, c := range []struct {
+			ledgerID, accountID int
+			want                bool
+		}{{ledgerA, 1, true}, {ledgerA, 2, true}, {ledgerA, 3, false}, {ledgerB, 2, false}} {
+			if got, err := repos.expenses.UsesAccount(c.ledgerID, c.accountID); err != nil || got != c.want {
+				t.Errorf("uses account %d in ledger %d: got %v, %v", c.accountID, c.ledgerID, got, err)
+			}
+		}
+
+		got, err := repos.expenses.GetByAccount(ledgerA, 1, date(1), date(3))
+		if err != nil {
+			t.Fatalf("get by account: %v", err)
+		}
+		if descriptions := expenseDescriptions(got); !sameStrings(descriptions, []string{"Shoes", "Shoes back"}) {
+			t.Errorf("get by account: got %v", descriptions)
+		}
+		got, err = repos.expenses.GetByAccount(ledgerA, 1, time.Time{}, time.Time{})
+		if err != nil {
+			t.Fatalf("get by account: %v", err)
+		}
+		if descriptions := expenseDescriptions(got); !sameStrings(descriptions, []string{"Shoes", "Shoes back", "Salary"}) {
+			t.Errorf("get by account without bounds: got %v", descriptions)
+		}
+	})
+}
+
+func TestExpenseRepositoryCategoryTotals(t *testing.T) {
+	eachBackend(t, func(t *testing.T, repos backendRepos) {
+		food := mustCreateCategory(t, repos.categories, ledgerA, "Food")
+		home := mustCreateCategory(t, repos.categories, ledgerA, "Home")
+		fun := mustCreateCategory(t, repos.categories, ledgerA, "Fun")
+
+		mustCreateExpense(t, repos.expenses, models.Expense{Description: "Lunch", CategoryID: food.ID, Amount: 12.5, Date: date(1)})
+		mustCreateExpense(t, repos.expenses, models.Expense{Description: "Market", CategoryID: food.ID, Amount: 60, Date: date(2),
+			Splits: []models.ExpenseSplit{{CategoryID: food.ID, Amount: 40}, {CategoryID: home.ID, Amount: 20}}})
+		mustCreateExpense(t, repos.expenses, models.Expense{Description: "Lamp back", Kind: models.KindRefund, CategoryID: home.ID, Amount: 5, Date: date(3)})
+		mustCreateExpense(t, repos.expenses, models.Expense{Description: "Prize", Kind: models.KindIncome, CategoryID: fun.ID, Amount: 100, Date: date(3)})
+		mustCreateExpense(t, repos.expenses, models.Expense{Description: "Cinema", CategoryID: fun.ID, Amount: 8, Date: date(20)})
+		mustCreateExpense(t, repos.expenses, models.Expense{LedgerID: ledgerB, Description: "Elsewhere", CategoryID: food.ID, Amount: 50, Date: date(2)})
+
+		got, err := repos.expenses.CategoryTotals(ledgerA, time.Time{}, time.Time{})
+		if err != nil {
+			t.Fatalf("totals: %v", err)
+		}
+		want := []CategoryTotal{
+			{CategoryID: food.ID, Total: 52.5, Count: 2},
+			{CategoryID: home.ID, Total: 15, Count: 2},
+			{CategoryID: fun.ID, Total: 8, Count: 1},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("totals: got %+v, want %+v", got, want)
+		}
+
+		got, err = repos.expenses.CategoryTotals(ledgerA, date(2), date(3))
+		if err != nil {
+			t.Fatalf("totals: %v", err)
+		}
+		want = []CategoryTotal{
+			{CategoryID: food.ID, Total: 40, Count: 1},
+			{CategoryID: home.ID, Total: 15, Count: 2},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("totals within dates: got %+v, want %+v", got, want)
+		}
+	})
+}
+
+func TestExpenseRepositoryLookups(t *testing.T) {
+	eachBackend(t, func(t *testing.T, repos backendRepos) {
+		mustCreateExpense(t, repos.expenses, models.Expense{Description: "Dinner", PaidByID: intPtr(3), ShareMode: "equal", Amount: 90, Date: date(4),
+			Shares: []models.ExpenseShare{{PersonID: 3, Amount: 45}, {PersonID: 6, Amount: 45}}})
+		mustCreateExpense(t, repos.expenses, models.Expense{Description: "Taxi", PaidByID: intPtr(4), ShareMode: "equal", Amount: 20, Date: date(2),
+			Shares: []models.ExpenseShare{{PersonID: 3, Amount: 10}, {PersonID: 4, Amount: 10}}})
+		mustCreateExpense(t, repos.expenses, models.Expense{Description: "Coffee", ExternalID: "bank-7", Amount: 3.5, Date: date(4)})
+		mustCreateExpense(t, repos.expenses, models.Expense{Description: "Coffee again", Amount: 3.504, Date: date(6)})
+		mustCreateExpense(t, repos.expenses, models.Expense{LedgerID: ledgerB, Description: "Elsewhere", PaidByID: intPtr(3), ExternalID: "bank-8", Amount: 3.5, Date: date(4)})
+
+		shared, err := repos.expenses.GetShared(ledgerA)
+		if err != nil {
+			t.Fatalf("get shared: %v", err)
+		}
+		if descriptions := expenseDescriptions(shared); !sameStrings(descriptions, []string{"Taxi", "Dinner"}) {
+			t.Errorf("get shared: got %v", descriptions)
+		}
+		for _, expense := range shared {
+			if len(expense.Shares) != 2 {
+				t.Errorf("%s: got %d shares, want 2", expense.Description, len(expense.Shares))
+			}
+		}
+
+		uses := []struct {
+			name     string
+			check    func(ledgerID int, id int) (bool, error)
+			ledgerID int
+			id       int
+			want     bool
+		}{
+			{"payer", repos.expenses.UsesPerson, ledgerA, 4, true},
+			{"sharer only", repos.expenses.UsesPerson, ledgerA, 6, true},
+			{"stranger", repos.expenses.UsesPerson, ledgerA, 5, false},
+			{"payer elsewhere", repos.expenses.UsesPerson, ledgerB, 4, false},
+			{"unused account", repos.expenses.UsesAccount, ledgerA, 1, false},
+		}
+		for _, c := range uses {
+			got, err := c.check(c.ledgerID, c.id)
+			if err != nil || got != c.want {
+				t.Errorf("%s: got %v, %v, want %v", c.name, got, err, c.want)
+			}
+		}
+
+		exists := []struct {
+			ledgerID   int
+			externalID string
+			want       bool
+		}{
+			{ledgerA, "bank-7", true},
+			{ledgerA, "bank-8", false},
+			{ledgerB, "bank-8", true},
+			{ledgerA, "BANK-7", false},
+		}
+		for _, c := range exists {
+			got, err := repos.expenses.ExistsByExternalID(c.ledgerID, c.externalID)
+			if err != nil || got != c.want {
+				t.Errorf("exists %q in ledger %d: got %v, %v", c.externalID, c.ledgerID, got, err)
+			}
+		}
+
+		byAmount := []struct {
+			amount   float64
+			from, to time.Time
+			want     []string
+		}{
+			{3.5, date(1), date(30), []string{"Coffee", "Coffee again"}},
+			{3.5, date(5), date(6), []string{"Coffee again"}},
+			{3.508, date(1), date(30), []string{"Coffee again"}},
+			{3.49, date(1), date(30), nil},
+		}
+		for _, c := range byAmount {
+			got, err := repos.expenses.FindByAmount(ledgerA, c.amount, c.from, c.to)
+			if err != nil {
+				t.Fatalf("find by amount: %v", err)
+			}
+			if descriptions := expenseDescriptions(got); !sameStrings(descriptions, c.want) {
+				t.Errorf("find %v between %v and %v: got %v, want %v", c.amount, c.from, c.to, descriptions, c.want)
+			}
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"goExpenseTracker/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DuplicateRepository interface {
+	Dismiss(dismissals []models.DuplicateDismissal) error
+	GetDismissed(ledgerID int) ([]models.DuplicateDismissal, error)
+}
+
+type duplicateRepository struct {
+	db *gorm.DB
+}
+
+func NewDuplicateRepository(db *gorm.DB) DuplicateRepository {
+	return &duplicateRepository{db: db}
+}
+
+// Dismiss records the pairs of expenses reviewed as not being duplicates; pairs
+// already dismissed are left as they are
+func (r *duplicateRepository) Dismiss(dismissals []models.DuplicateDismissal) error {
+	if len(dismissals) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&dismissals).Error
+}
+
+// GetDismissed lists every pair dismissed in the ledger
+func (r *duplicateRepository) GetDismissed(ledgerID int) ([]models.DuplicateDismissal, error) {
+	var dismissals []models.DuplicateDismissal
+	err := r.db.Scopes(inLedger(ledgerID)).Order("expense_id, other_id").Find(&dismissals).Error
+	return dismissals, err
+}
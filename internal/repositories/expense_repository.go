@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"time"
+
 	"goExpenseTracker/internal/models"
 
 	"gorm.io/gorm"
@@ -8,10 +10,60 @@ import (
 
 type ExpenseRepository interface {
 	Create(expense *models.Expense) error
-	GetAll(offset int, limit int, descriptionFilter string, categoryID int) ([]models.Expense, error)
-	GetByID(id uint) (*models.Expense, error)
+	CreateBatch(categories []*models.Category, expenses []*models.Expense) error
+	GetAll(ledgerID int, offset int, limit int, descriptionFilter string, categoryID int) ([]models.Expense, error)
+	Find(ledgerID int, filter ExpenseFilter) ([]models.Expense, error)
+	Stream(ledgerID int, filter ExpenseFilter, batchSize int, fn func([]models.Expense) error) error
+	GetByID(ledgerID int, id uint) (*models.Expense, error)
 	Update(expense *models.Expense) error
-	Delete(id uint) error
+	Delete(ledgerID int, id uint) error
+	SumByAccount(ledgerID int, accountID int, before time.Time) (float64, error)
+	GetByAccount(ledgerID int, accountID int, from time.Time, to time.Time) ([]models.Expense, error)
+	CategoryTotals(ledgerID int, from time.Time, to time.Time) ([]CategoryTotal, error)
+	GetShared(ledgerID int) ([]models.Expense, error)
+	ExistsByExternalID(ledgerID int, externalID string) (bool, error)
+	FindByAmount(ledgerID int, amount float64, from time.Time, to time.Time) ([]models.Expense, error)
+	UsesAccount(ledgerID int, accountID int) (bool, error)
+	UsesPerson(ledgerID int, personID int) (bool, error)
+}
+
+// PlaceholderCategoryID is how the expenses of a batch refer to the i-th (from 0)
+// of the categories created along with them, before those have IDs
+func PlaceholderCategoryID(i int) int {
+	return -(i + 1)
+}
+
+// resolvePlaceholders swaps the placeholder category IDs of an expense and its
+// split lines for the IDs the new categories were stored with
+func resolvePlaceholders(categories []*models.Category, expense *models.Expense) {
+	resolve := func(id int) int {
+		if id < 0 && -id <= len(categories) {
+			return categories[-id-1].ID
+		}
+		return id
+	}
+	expense.CategoryID = resolve(expense.CategoryID)
+	for i := range expense.Splits {
+		expense.Splits[i].CategoryID = resolve(expense.Splits[i].CategoryID)
+	}
+}
+
+// ExpenseFilter selects expenses for listings and exports; zero values mean no restriction
+type ExpenseFilter struct {
+	Description string
+	CategoryID  int
+	AccountID   int
+	TagID       int
+	From        time.Time
+	To          time.Time
+}
+
+// CategoryTotal is the amount attributed to a category, counting split lines
+// towards their own category rather than the expense's primary one
+type CategoryTotal struct {
+	CategoryID int
+	Total      float64
+	Count      int
 }
 
 type expenseRepository struct {
@@ -22,45 +74,304 @@ func NewExpenseRepository(db *gorm.DB) ExpenseRepository {
 	return &expenseRepository{db: db}
 }
 
+// Create inserts the expense together with its split lines and shares, and links
+// its tags; the tags themselves must already exist
 func (r *expenseRepository) Create(expense *models.Expense) error {
-	return r.db.Create(expense).Error
+	return r.db.Omit("Tags.*").Create(expense).Error
+}
+
+// CreateBatch inserts new categories and then several expenses, with their split
+// lines and shares, in one transaction. The expenses refer to the new categories
+// by placeholder IDs, which are swapped for the stored ones; see PlaceholderCategoryID.
+func (r *expenseRepository) CreateBatch(categories []*models.Category, expenses []*models.Expense) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, category := range categories {
+			if err := tx.Create(category).Error; err != nil {
+				return err
+			}
+		}
+		for _, expense := range expenses {
+			resolvePlaceholders(categories, expense)
+			if err := tx.Omit("Tags.*").Create(expense).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 // GetAll fetches expenses with pagination and optional filters
-func (r *expenseRepository) GetAll(offset int, limit int, descriptionFilter string, categoryID int) ([]models.Expense, error) {
+func (r *expenseRepository) GetAll(ledgerID int, offset int, limit int, descriptionFilter string, categoryID int) ([]models.Expense, error) {
 	var expenses []models.Expense
 
-	query := r.db.Model(&models.Expense{})
+	query := r.filtered(ledgerID, ExpenseFilter{Description: descriptionFilter, CategoryID: categoryID})
 
-	if descriptionFilter != "" {
-		query = query.Where("description ILIKE ?", "%"+descriptionFilter+"%")
+	if limit > 0 {
+		query = query.Offset(offset).Limit(limit)
 	}
 
-	if categoryID > 0 {
-		query = query.Where("category_id = ?", categoryID)
+	// Pages follow creation order, so they neither overlap nor skip rows
+	err := query.Order("id").Preload("Splits").Preload("Shares").Preload("Tags").Find(&expenses).Error
+	return expenses, err
+}
+
+// Find lists every expense matching the filter in date order, with split lines and shares
+func (r *expenseRepository) Find(ledgerID int, filter ExpenseFilter) ([]models.Expense, error) {
+	var expenses []models.Expense
+	err := r.filtered(ledgerID, filter).Order("date, id").Preload("Splits").Preload("Shares").Preload("Tags").Find(&expenses).Error
+	return expenses, err
+}
+
+// Stream hands the matching expenses to fn in date order, batchSize at a time, so
+// exports never hold the full result set in memory. Each batch is read with a
+// keyset condition on (date, id) rather than an offset, which stays fast on
+// large tables and is not thrown off by rows added while streaming.
+func (r *expenseRepository) Stream(ledgerID int, filter ExpenseFilter, batchSize int, fn func([]models.Expense) error) error {
+	var (
+		lastDate time.Time
+		lastID   int
+	)
+	for first := true; ; first = false {
+		query := r.filtered(ledgerID, filter)
+		if !first {
+			query = query.Where("(date > ? OR (date = ? AND id > ?))", lastDate, lastDate, lastID)
+		}
+
+		var batch []models.Expense
+		err := query.Order("date, id").Limit(batchSize).Preload("Splits").Preload("Shares").Preload("Tags").Find(&batch).Error
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+
+		last := batch[len(batch)-1]
+		lastDate, lastID = last.Date, last.ID
 	}
+}
 
-	if limit > 0 {
-		query = query.Offset(offset).Limit(limit)
+// filtered builds the query shared by listings and exports
+func (r *expenseRepository) filtered(ledgerID int, filter ExpenseFilter) *gorm.DB {
+	query := r.db.Model(&models.Expense{}).Scopes(inLedger(ledgerID))
+
+	if filter.Description != "" {
+		query = query.Scopes(containsFold("description", filter.Description))
 	}
 
-	err := query.Find(&expenses).Error
-	return expenses, err
+	if filter.CategoryID > 0 {
+		// Split expenses match any of their split line categories as well
+		query = query.Where(
+			"category_id = ? OR id IN (SELECT expense_id FROM expense_splits WHERE category_id = ?)",
+			filter.CategoryID, filter.CategoryID,
+		)
+	}
+
+	if filter.AccountID > 0 {
+		query = query.Where("account_id = ?", filter.AccountID)
+	}
+	if filter.TagID > 0 {
+		query = query.Where("id IN (SELECT expense_id FROM expense_tags WHERE tag_id = ?)", filter.TagID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("date >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("date <= ?", filter.To)
+	}
+
+	return query
 }
 
-func (r *expenseRepository) GetByID(id uint) (*models.Expense, error) {
+func (r *expenseRepository) GetByID(ledgerID int, id uint) (*models.Expense, error) {
 	var expense models.Expense
-	err := r.db.First(&expense, id).Error
+	err := r.db.Scopes(inLedger(ledgerID)).Preload("Splits").Preload("Shares").Preload("Tags").First(&expense, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &expense, nil
 }
 
+// Update saves the expense and replaces its split lines, shares and tags. The
+// expense must already exist in its ledger; otherwise gorm.ErrRecordNotFound is
+// returned and nothing is written.
 func (r *expenseRepository) Update(expense *models.Expense) error {
-	return r.db.Save(expense).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if owned, err := owns(tx, &models.Expense{}, expense.LedgerID, expense.ID); err != nil {
+			return err
+		} else if !owned {
+			return gorm.ErrRecordNotFound
+		}
+		if err := deleteExpenseChildren(tx, uint(expense.ID)); err != nil {
+			return err
+		}
+		if err := tx.Omit("Splits", "Shares", "Tags").Save(expense).Error; err != nil {
+			return err
+		}
+
+		// Append adds to expense.Tags as well as linking, so start from an empty list
+		if tags := expense.Tags; len(tags) > 0 {
+			expense.Tags = nil
+			if err := tx.Model(expense).Omit("Tags.*").Association("Tags").Append(tags); err != nil {
+				return err
+			}
+		}
+
+		for i := range expense.Splits {
+			expense.Splits[i].ID = 0
+			expense.Splits[i].ExpenseID = expense.ID
+		}
+		if len(expense.Splits) > 0 {
+			if err := tx.Create(&expense.Splits).Error; err != nil {
+				return err
+			}
+		}
+
+		for i := range expense.Shares {
+			expense.Shares[i].ID = 0
+			expense.Shares[i].ExpenseID = expense.ID
+		}
+		if len(expense.Shares) > 0 {
+			return tx.Create(&expense.Shares).Error
+		}
+		return nil
+	})
+}
+
+func (r *expenseRepository) Delete(ledgerID int, id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if owned, err := owns(tx, &models.Expense{}, ledgerID, id); err != nil || !owned {
+			return err
+		}
+		if err := deleteExpenseChildren(tx, id); err != nil {
+			return err
+		}
+		if err := tx.Where("expense_id = ? OR other_id = ?", id, id).Delete(&models.DuplicateDismissal{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Expense{}, id).Error
+	})
+}
+
+// deleteExpenseChildren removes the rows owned by an expense
+func deleteExpenseChildren(tx *gorm.DB, expenseID uint) error {
+	if err := tx.Where("expense_id = ?", expenseID).Delete(&models.ExpenseSplit{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("DELETE FROM expense_tags WHERE expense_id = ?", expenseID).Error; err != nil {
+		return err
+	}
+	return tx.Where("expense_id = ?", expenseID).Delete(&models.ExpenseShare{}).Error
+}
+
+// SumByAccount totals the net outflow of an account's expenses before the given time
+// (zero means no bound); refunds and income flow back into the account
+func (r *expenseRepository) SumByAccount(ledgerID int, accountID int, before time.Time) (float64, error) {
+	var total float64
+
+	query := r.db.Model(&models.Expense{}).Scopes(inLedger(ledgerID)).Where("account_id = ?", accountID)
+	if !before.IsZero() {
+		query = query.Where("date < ?", before)
+	}
+
+	err := query.Select("COALESCE(SUM(CASE WHEN kind IN ('refund', 'income') THEN -amount ELSE amount END), 0)").Scan(&total).Error
+	return total, err
+}
+
+// GetByAccount lists expenses paid from an account within [from, to] (zero means no bound)
+func (r *expenseRepository) GetByAccount(ledgerID int, accountID int, from time.Time, to time.Time) ([]models.Expense, error) {
+	var expenses []models.Expense
+
+	query := r.db.Model(&models.Expense{}).Scopes(inLedger(ledgerID)).Where("account_id = ?", accountID)
+	if !from.IsZero() {
+		query = query.Where("date >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("date <= ?", to)
+	}
+
+	err := query.Order("date, id").Find(&expenses).Error
+	return expenses, err
+}
+
+// CategoryTotals sums spending per category within [from, to] (zero means no bound),
+// attributing split expenses line by line. Refunds reduce their category's total
+// and income is left out.
+func (r *expenseRepository) CategoryTotals(ledgerID int, from time.Time, to time.Time) ([]CategoryTotal, error) {
+	dateFilter := " AND e.ledger_id = ? AND COALESCE(e.kind, '') <> 'income'"
+	args := []interface{}{ledgerID}
+	if !from.IsZero() {
+		dateFilter += " AND e.date >= ?"
+		args = append(args, from)
+	}
+	if !to.IsZero() {
+		dateFilter += " AND e.date <= ?"
+		args = append(args, to)
+	}
+
+	query := `
+		SELECT category_id, SUM(amount) AS total, COUNT(*) AS count FROM (
+			SELECT e.category_id, ` + signedAmount("e.amount") + ` AS amount FROM expenses e
+			WHERE NOT EXISTS (SELECT 1 FROM expense_splits s WHERE s.expense_id = e.id)` + dateFilter + `
+			UNION ALL
+			SELECT s.category_id, ` + signedAmount("s.amount") + ` AS amount FROM expense_splits s
+			JOIN expenses e ON e.id = s.expense_id
+			WHERE 1 = 1` + dateFilter + `
+		) attributed
+		GROUP BY category_id
+		ORDER BY total DESC`
+
+	var totals []CategoryTotal
+	err := r.db.Raw(query, append(args, args...)...).Scan(&totals).Error
+	return totals, err
+}
+
+// signedAmount negates a refund's amount so it offsets spending
+func signedAmount(column string) string {
+	return "CASE WHEN e.kind = 'refund' THEN -" + column + " ELSE " + column + " END"
+}
+
+// GetShared lists every expense paid by a person on behalf of others, with its shares
+func (r *expenseRepository) GetShared(ledgerID int) ([]models.Expense, error) {
+	var expenses []models.Expense
+	err := r.db.Scopes(inLedger(ledgerID)).Where("paid_by_id IS NOT NULL").Preload("Shares").Order("date, id").Find(&expenses).Error
+	return expenses, err
+}
+
+// ExistsByExternalID reports whether the ledger already has an expense imported with the given bank reference
+func (r *expenseRepository) ExistsByExternalID(ledgerID int, externalID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Expense{}).Scopes(inLedger(ledgerID)).Where("external_id = ?", externalID).Count(&count).Error
+	return count > 0, err
+}
+
+// FindByAmount lists the expenses of the given amount, to the cent, dated within [from, to]
+func (r *expenseRepository) FindByAmount(ledgerID int, amount float64, from time.Time, to time.Time) ([]models.Expense, error) {
+	var expenses []models.Expense
+	err := r.db.Scopes(inLedger(ledgerID)).Where("amount BETWEEN ? AND ?", amount-0.005, amount+0.005).
+		Where("date >= ? AND date <= ?", from, to).
+		Order("date, id").Find(&expenses).Error
+	return expenses, err
+}
+
+// UsesAccount reports whether any expense of the ledger is paid from the account
+func (r *expenseRepository) UsesAccount(ledgerID int, accountID int) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Expense{}).Scopes(inLedger(ledgerID)).Where("account_id = ?", accountID).Count(&count).Error
+	return count > 0, err
 }
 
-func (r *expenseRepository) Delete(id uint) error {
-	return r.db.Delete(&models.Expense{}, id).Error
+// UsesPerson reports whether the person paid for or has a share of any expense of the ledger
+func (r *expenseRepository) UsesPerson(ledgerID int, personID int) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Expense{}).Scopes(inLedger(ledgerID)).
+		Where("paid_by_id = ? OR id IN (SELECT expense_id FROM expense_shares WHERE person_id = ?)", personID, personID).
+		Count(&count).Error
+	return count > 0, err
 }
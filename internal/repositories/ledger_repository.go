@@ -0,0 +1,332 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"goExpenseTracker/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ledgerTables are the tables whose rows belong to a ledger
+var ledgerTables = []string{
+	"categories", "accounts", "people", "tags", "rules", "expenses",
+	"transfers", "settlements", "attachments", "duplicate_dismissals",
+}
+
+// ErrLastOwner is returned when a change would leave a ledger without an owner
+var ErrLastOwner = errors.New("a ledger must keep at least one owner")
+
+// ErrInviteUsed is returned when an invitation was accepted by someone else first
+var ErrInviteUsed = errors.New("invitation already used")
+
+// LedgerMembership is a ledger together with the role a user has in it
+type LedgerMembership struct {
+	models.Ledger
+	Role string
+}
+
+type LedgerRepository interface {
+	Create(ledger *models.Ledger, ownerID int) error
+	GetByID(id uint) (*models.Ledger, error)
+	GetForUser(userID int) ([]LedgerMembership, error)
+	GetPersonal(userID int) (*models.Ledger, error)
+	Update(ledger *models.Ledger) error
+	Delete(id uint) error
+	HasData(id uint) (bool, error)
+
+	GetMember(ledgerID int, userID int) (*models.LedgerMember, error)
+	GetMembers(ledgerID int) ([]models.LedgerMember, error)
+	UpdateMember(member *models.LedgerMember) error
+	DeleteMember(ledgerID int, userID int) error
+
+	CreateInvite(invite *models.LedgerInvite) error
+	GetInvites(ledgerID int) ([]models.LedgerInvite, error)
+	GetInvite(ledgerID int, id uint) (*models.LedgerInvite, error)
+	GetInviteByHash(tokenHash string) (*models.LedgerInvite, error)
+	AcceptInvite(invite *models.LedgerInvite, member *models.LedgerMember) error
+	DeleteInvite(ledgerID int, id uint) error
+
+	HasUnowned() (bool, error)
+	ClaimUnowned(ledgerID int) error
+	ClaimInstance(userID int, ledgerID int) error
+	AdoptUserData() error
+}
+
+type ledgerRepository struct {
+	db *gorm.DB
+}
+
+func NewLedgerRepository(db *gorm.DB) LedgerRepository {
+	return &ledgerRepository{db: db}
+}
+
+// Create inserts the ledger with its creator as the owner
+func (r *ledgerRepository) Create(ledger *models.Ledger, ownerID int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return createLedger(tx, ledger, ownerID)
+	})
+}
+
+func createLedger(tx *gorm.DB, ledger *models.Ledger, ownerID int) error {
+	if err := tx.Create(ledger).Error; err != nil {
+		return err
+	}
+	return tx.Create(&models.LedgerMember{
+		LedgerID:  ledger.ID,
+		UserID:    ownerID,
+		Role:      models.RoleOwner,
+		CreatedAt: ledger.CreatedAt,
+		UpdatedAt: ledger.CreatedAt,
+	}).Error
+}
+
+func (r *ledgerRepository) GetByID(id uint) (*models.Ledger, error) {
+	var ledger models.Ledger
+	err := r.db.First(&ledger, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ledger, nil
+}
+
+// GetForUser lists the ledgers the user is a member of, in the order they joined
+func (r *ledgerRepository) GetForUser(userID int) ([]LedgerMembership, error) {
+	var memberships []LedgerMembership
+	err := r.db.Table("ledgers").
+		Select("ledgers.*, ledger_members.role").
+		Joins("JOIN ledger_members ON ledger_members.ledger_id = ledgers.id").
+		Where("ledger_members.user_id = ?", userID).
+		Order("ledger_members.id").
+		Scan(&memberships).Error
+	return memberships, err
+}
+
+// GetPersonal finds the ledger the user started with
+func (r *ledgerRepository) GetPersonal(userID int) (*models.Ledger, error) {
+	var ledger models.Ledger
+	err := r.db.Where("personal = ? AND created_by = ?", true, userID).First(&ledger).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ledger, nil
+}
+
+func (r *ledgerRepository) Update(ledger *models.Ledger) error {
+	return r.db.Save(ledger).Error
+}
+
+// Delete removes the ledger with its members, invitations and audit trail; its data
+// must be gone already
+func (r *ledgerRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("ledger_id = ?", id).Delete(&models.LedgerInvite{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("ledger_id = ?", id).Delete(&models.LedgerMember{}).Error; err != nil {
+			return err
+		}
+		// HasData ignores the audit trail, which outlives what it describes
+		if err := tx.Where("ledger_id = ?", id).Delete(&models.AuditEntry{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Ledger{}, id).Error
+	})
+}
+
+// HasData reports whether any category, expense or other row belongs to the ledger
+func (r *ledgerRepository) HasData(id uint) (bool, error) {
+	for _, table := range ledgerTables {
+		var count int64
+		if err := r.db.Table(table).Where("ledger_id = ?", id).Count(&count).Error; err != nil || count > 0 {
+			return count > 0, err
+		}
+	}
+	return false, nil
+}
+
+func (r *ledgerRepository) GetMember(ledgerID int, userID int) (*models.LedgerMember, error) {
+	var member models.LedgerMember
+	err := r.db.Where("ledger_id = ? AND user_id = ?", ledgerID, userID).First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// GetMembers lists the members of a ledger in the order they joined
+func (r *ledgerRepository) GetMembers(ledgerID int) ([]models.LedgerMember, error) {
+	var members []models.LedgerMember
+	err := r.db.Where("ledger_id = ?", ledgerID).Order("id").Find(&members).Error
+	return members, err
+}
+
+// UpdateMember saves a member, refusing with ErrLastOwner to demote the last owner
+func (r *ledgerRepository) UpdateMember(member *models.LedgerMember) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if member.Role != models.RoleOwner {
+			if err := keepOwner(tx, member.LedgerID, member.UserID); err != nil {
+				return err
+			}
+		}
+		return tx.Save(member).Error
+	})
+}
+
+// DeleteMember removes a member, refusing with ErrLastOwner to remove the last owner
+func (r *ledgerRepository) DeleteMember(ledgerID int, userID int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := keepOwner(tx, ledgerID, userID); err != nil {
+			return err
+		}
+		return tx.Where("ledger_id = ? AND user_id = ?", ledgerID, userID).Delete(&models.LedgerMember{}).Error
+	})
+}
+
+// keepOwner locks the owners of a ledger until the transaction ends, so two owners
+// cannot step down at once, and refuses to take the last one away
+func keepOwner(tx *gorm.DB, ledgerID int, userID int) error {
+	var owners []models.LedgerMember
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("ledger_id = ? AND role = ?", ledgerID, models.RoleOwner).Find(&owners).Error
+	if err != nil {
+		return err
+	}
+	if len(owners) == 1 && owners[0].UserID == userID {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+func (r *ledgerRepository) CreateInvite(invite *models.LedgerInvite) error {
+	return r.db.Create(invite).Error
+}
+
+// GetInvites lists the invitations of a ledger, newest first
+func (r *ledgerRepository) GetInvites(ledgerID int) ([]models.LedgerInvite, error) {
+	var invites []models.LedgerInvite
+	err := r.db.Where("ledger_id = ?", ledgerID).Order("id DESC").Find(&invites).Error
+	return invites, err
+}
+
+func (r *ledgerRepository) GetInvite(ledgerID int, id uint) (*models.LedgerInvite, error) {
+	var invite models.LedgerInvite
+	err := r.db.Where("ledger_id = ?", ledgerID).First(&invite, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+func (r *ledgerRepository) GetInviteByHash(tokenHash string) (*models.LedgerInvite, error) {
+	var invite models.LedgerInvite
+	err := r.db.Where("token_hash = ?", tokenHash).First(&invite).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// AcceptInvite marks the invitation used and adds the member in one transaction.
+// The invitation is only claimed while still unused, so a token cannot be spent twice.
+func (r *ledgerRepository) AcceptInvite(invite *models.LedgerInvite, member *models.LedgerMember) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.LedgerInvite{}).
+			Where("id = ? AND accepted_at IS NULL", invite.ID).
+			Updates(map[string]interface{}{"accepted_by": invite.AcceptedBy, "accepted_at": invite.AcceptedAt})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInviteUsed
+		}
+		return tx.Create(member).Error
+	})
+}
+
+func (r *ledgerRepository) DeleteInvite(ledgerID int, id uint) error {
+	return r.db.Where("ledger_id = ?", ledgerID).Delete(&models.LedgerInvite{}, id).Error
+}
+
+// ClaimUnowned moves every row without a ledger into the given one, for data
+// recorded before user accounts existed
+func (r *ledgerRepository) ClaimUnowned(ledgerID int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return claimUnowned(tx, ledgerID)
+	})
+}
+
+func claimUnowned(tx *gorm.DB, ledgerID int) error {
+	for _, table := range ledgerTables {
+		err := tx.Table(table).Where("ledger_id IS NULL OR ledger_id = 0").Update("ledger_id", ledgerID).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HasUnowned reports whether any row has no ledger, as data recorded before user
+// accounts existed does
+func (r *ledgerRepository) HasUnowned() (bool, error) {
+	for _, table := range ledgerTables {
+		var count int64
+		if err := r.db.Table(table).Where("ledger_id IS NULL OR ledger_id = 0").Count(&count).Error; err != nil || count > 0 {
+			return count > 0, err
+		}
+	}
+	return false, nil
+}
+
+// ClaimInstance makes the user the admin, unless the instance already has one,
+// and moves every row without a ledger into the given one, in one transaction
+func (r *ledgerRepository) ClaimInstance(userID int, ledgerID int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var admins int64
+		if err := tx.Model(&models.User{}).Where("is_admin = ?", true).Count(&admins).Error; err != nil {
+			return err
+		}
+		if admins == 0 {
+			if err := tx.Model(&models.User{}).Where("id = ?", userID).Update("is_admin", true).Error; err != nil {
+				return err
+			}
+		}
+		return claimUnowned(tx, ledgerID)
+	})
+}
+
+// AdoptUserData gives every user without one a personal ledger and moves the rows
+// they recorded before ledgers existed into it
+func (r *ledgerRepository) AdoptUserData() error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var users []models.User
+		err := tx.Where("id NOT IN (SELECT created_by FROM ledgers WHERE personal = ?)", true).Order("id").Find(&users).Error
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			ledger := models.Ledger{
+				Name:      models.PersonalLedgerName,
+				Personal:  true,
+				CreatedBy: user.ID,
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+			}
+			if err := createLedger(tx, &ledger, user.ID); err != nil {
+				return err
+			}
+		}
+
+		for _, table := range ledgerTables {
+			err := tx.Table(table).
+				Where("(ledger_id IS NULL OR ledger_id = 0) AND user_id > 0").
+				Update("ledger_id", gorm.Expr("(SELECT l.id FROM ledgers l WHERE l.personal = ? AND l.created_by = "+table+".user_id)", true)).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
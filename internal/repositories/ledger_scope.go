@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+)
+
+// inLedger limits a query to the rows of one ledger. Every lookup of ledger data
+// goes through it; rows are created with their ledger already set on the model.
+func inLedger(ledgerID int) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("ledger_id = ?", ledgerID)
+	}
+}
+
+// owns reports whether the row of the model's table with the given ID belongs to
+// the ledger, for deletes that must clear child rows before the row itself
+func owns(db *gorm.DB, model interface{}, ledgerID int, id interface{}) (bool, error) {
+	var count int64
+	err := db.Model(model).Scopes(inLedger(ledgerID)).Where("id = ?", id).Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,137 @@
+package repositories
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"goExpenseTracker/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// memoryCategoryRepository keeps categories in process memory; nothing survives
+// a restart. It behaves like the database repository, down to returning
+// gorm.ErrRecordNotFound for missing rows.
+type memoryCategoryRepository struct {
+	mu         sync.RWMutex
+	lastID     int
+	categories map[int]models.Category
+}
+
+func NewMemoryCategoryRepository() CategoryRepository {
+	return &memoryCategoryRepository{categories: make(map[int]models.Category)}
+}
+
+func (r *memoryCategoryRepository) Create(category *models.Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if category.CreatedAt.IsZero() {
+		category.CreatedAt = now
+	}
+	if category.UpdatedAt.IsZero() {
+		category.UpdatedAt = now
+	}
+	r.lastID++
+	category.ID = r.lastID
+	r.categories[category.ID] = *category
+	return nil
+}
+
+// GetAll fetches categories with pagination and optional filters, in creation order
+func (r *memoryCategoryRepository) GetAll(ledgerID int, offset int, limit int, nameFilter string) ([]models.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	categories := make([]models.Category, 0)
+	for _, category := range r.categories {
+		if category.LedgerID != ledgerID {
+			continue
+		}
+		if nameFilter != "" && !matchLikeFold(category.Name, "%"+nameFilter+"%") {
+			continue
+		}
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i].ID < categories[j].ID
+	})
+
+	if limit > 0 {
+		categories = page(categories, offset, limit)
+	}
+	return categories, nil
+}
+
+func (r *memoryCategoryRepository) GetByID(ledgerID int, id uint) (*models.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	category, ok := r.categories[int(id)]
+	if !ok || category.LedgerID != ledgerID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &category, nil
+}
+
+// GetByName finds a category by its name, ignoring case; the oldest wins if
+// several match
+func (r *memoryCategoryRepository) GetByName(ledgerID int, name string) (*models.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var found *models.Category
+	for _, category := range r.categories {
+		if category.LedgerID != ledgerID || !sameFold(category.Name, name) {
+			continue
+		}
+		if found == nil || category.ID < found.ID {
+			category := category
+			found = &category
+		}
+	}
+	if found == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return found, nil
+}
+
+// Update saves every field of the category, as a database save does
+func (r *memoryCategoryRepository) Update(category *models.Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	category.UpdatedAt = time.Now()
+	if category.ID == 0 {
+		r.lastID++
+		category.ID = r.lastID
+	} else if category.ID > r.lastID {
+		r.lastID = category.ID
+	}
+	r.categories[category.ID] = *category
+	return nil
+}
+
+func (r *memoryCategoryRepository) Delete(ledgerID int, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if category, ok := r.categories[int(id)]; ok && category.LedgerID == ledgerID {
+		delete(r.categories, int(id))
+	}
+	return nil
+}
+
+// page returns the part of items a listing's offset and limit select
+func page[T any](items []T, offset int, limit int) []T {
+	if offset >= len(items) {
+		return items[:0]
+	}
+	items = items[offset:]
+	if limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
@@ -0,0 +1,391 @@
+package repositories
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"goExpenseTracker/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// memoryExpenseRepository keeps expenses, with their split lines, shares and tag
+// links, in process memory; nothing survives a restart. Tags are kept as they
+// were given rather than looked up. The memory database driver uses it, and it
+// backs service tests and the conformance suite.
+type memoryExpenseRepository struct {
+	categories CategoryRepository // Where the categories created along with a batch go
+
+	mu          sync.RWMutex
+	lastID      int
+	lastSplitID int
+	lastShareID int
+	expenses    map[int]models.Expense
+}
+
+func NewMemoryExpenseRepository(categories CategoryRepository) ExpenseRepository {
+	return &memoryExpenseRepository{categories: categories, expenses: make(map[int]models.Expense)}
+}
+
+// Create inserts the expense together with its split lines and shares, and links its tags
+func (r *memoryExpenseRepository) Create(expense *models.Expense) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.insert(expense)
+	return nil
+}
+
+// CreateBatch inserts new categories and then several expenses, with their split
+// lines and shares; see PlaceholderCategoryID
+func (r *memoryExpenseRepository) CreateBatch(categories []*models.Category, expenses []*models.Expense) error {
+	for _, category := range categories {
+		if err := r.categories.Create(category); err != nil {
+			return err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, expense := range expenses {
+		resolvePlaceholders(categories, expense)
+		r.insert(expense)
+	}
+	return nil
+}
+
+// insert stores a new expense, filling in the IDs, timestamps and default kind
+// the database would
+func (r *memoryExpenseRepository) insert(expense *models.Expense) {
+	now := time.Now()
+	if expense.CreatedAt.IsZero() {
+		expense.CreatedAt = now
+	}
+	if expense.UpdatedAt.IsZero() {
+		expense.UpdatedAt = now
+	}
+	if expense.Kind == "" {
+		expense.Kind = models.KindExpense
+	}
+	r.lastID++
+	expense.ID = r.lastID
+	r.storeChildren(expense, now)
+	r.expenses[expense.ID] = copyExpense(*expense, true, true)
+}
+
+// storeChildren numbers the split lines and shares of an expense being saved
+func (r *memoryExpenseRepository) storeChildren(expense *models.Expense, now time.Time) {
+	for i := range expense.Splits {
+		r.lastSplitID++
+		expense.Splits[i].ID = r.lastSplitID
+		expense.Splits[i].ExpenseID = expense.ID
+		if expense.Splits[i].CreatedAt.IsZero() {
+			expense.Splits[i].CreatedAt = now
+		}
+		if expense.Splits[i].UpdatedAt.IsZero() {
+			expense.Splits[i].UpdatedAt = now
+		}
+	}
+	for i := range expense.Shares {
+		r.lastShareID++
+		expense.Shares[i].ID = r.lastShareID
+		expense.Shares[i].ExpenseID = expense.ID
+		if expense.Shares[i].CreatedAt.IsZero() {
+			expense.Shares[i].CreatedAt = now
+		}
+		if expense.Shares[i].UpdatedAt.IsZero() {
+			expense.Shares[i].UpdatedAt = now
+		}
+	}
+}
+
+// GetAll fetches expenses with pagination and optional filters, in creation order
+func (r *memoryExpenseRepository) GetAll(ledgerID int, offset int, limit int, descriptionFilter string, categoryID int) ([]models.Expense, error) {
+	expenses := r.filtered(ledgerID, ExpenseFilter{Description: descriptionFilter, CategoryID: categoryID}, true, true)
+	sort.Slice(expenses, func(i, j int) bool {
+		return expenses[i].ID < expenses[j].ID
+	})
+
+	if limit > 0 {
+		expenses = page(expenses, offset, limit)
+	}
+	return expenses, nil
+}
+
+// Find lists every expense matching the filter in date order, with split lines and shares
+func (r *memoryExpenseRepository) Find(ledgerID int, filter ExpenseFilter) ([]models.Expense, error) {
+	expenses := r.filtered(ledgerID, filter, true, true)
+	sortByDate(expenses)
+	return expenses, nil
+}
+
+// Stream hands the matching expenses to fn in date order, batchSize at a time.
+// The expenses are copied up front, so fn may change the repository.
+func (r *memoryExpenseRepository) Stream(ledgerID int, filter ExpenseFilter, batchSize int, fn func([]models.Expense) error) error {
+	expenses := r.filtered(ledgerID, filter, true, true)
+	sortByDate(expenses)
+
+	for start := 0; start < len(expenses); start += batchSize {
+		end := start + batchSize
+		if end > len(expenses) {
+			end = len(expenses)
+		}
+		if err := fn(expenses[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// filtered copies the ledger's expenses matching the filter, with the children
+// a query would preload
+func (r *memoryExpenseRepository) filtered(ledgerID int, filter ExpenseFilter, withShares bool, withOthers bool) []models.Expense {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	expenses := make([]models.Expense, 0)
+	for _, expense := range r.expenses {
+		if expense.LedgerID == ledgerID && matchesFilter(expense, filter) {
+			expenses = append(expenses, copyExpense(expense, withShares, withOthers))
+		}
+	}
+	return expenses
+}
+
+// matchesFilter applies an ExpenseFilter the way the database query does
+func matchesFilter(expense models.Expense, filter ExpenseFilter) bool {
+	if filter.Description != "" && !matchLikeFold(expense.Description, "%"+filter.Description+"%") {
+		return false
+	}
+	if filter.CategoryID > 0 && expense.CategoryID != filter.CategoryID {
+		// Split expenses match any of their split line categories as well
+		found := false
+		for _, split := range expense.Splits {
+			found = found || split.CategoryID == filter.CategoryID
+		}
+		if !found {
+			return false
+		}
+	}
+	if filter.AccountID > 0 && (expense.AccountID == nil || *expense.AccountID != filter.AccountID) {
+		return false
+	}
+	if filter.TagID > 0 {
+		found := false
+		for _, tag := range expense.Tags {
+			found = found || tag.ID == filter.TagID
+		}
+		if !found {
+			return false
+		}
+	}
+	return withinDates(expense.Date, filter.From, filter.To)
+}
+
+// withinDates reports whether date lies within [from, to], where zero means no bound
+func withinDates(date time.Time, from time.Time, to time.Time) bool {
+	return (from.IsZero() || !date.Before(from)) && (to.IsZero() || !date.After(to))
+}
+
+func (r *memoryExpenseRepository) GetByID(ledgerID int, id uint) (*models.Expense, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	expense, ok := r.expenses[int(id)]
+	if !ok || expense.LedgerID != ledgerID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := copyExpense(expense, true, true)
+	return &found, nil
+}
+
+// Update saves the expense and replaces its split lines, shares and tags. The
+// expense must already exist in its ledger; otherwise gorm.ErrRecordNotFound is
+// returned and nothing is written.
+func (r *memoryExpenseRepository) Update(expense *models.Expense) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stored, ok := r.expenses[expense.ID]; !ok || stored.LedgerID != expense.LedgerID {
+		return gorm.ErrRecordNotFound
+	}
+	now := time.Now()
+	expense.UpdatedAt = now
+	r.storeChildren(expense, now)
+	r.expenses[expense.ID] = copyExpense(*expense, true, true)
+	return nil
+}
+
+func (r *memoryExpenseRepository) Delete(ledgerID int, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if expense, ok := r.expenses[int(id)]; ok && expense.LedgerID == ledgerID {
+		delete(r.expenses, int(id))
+	}
+	return nil
+}
+
+// SumByAccount totals the net outflow of an account's expenses before the given time
+// (zero means no bound); refunds and income flow back into the account
+func (r *memoryExpenseRepository) SumByAccount(ledgerID int, accountID int, before time.Time) (float64, error) {
+	var total float64
+	for _, expense := range r.filtered(ledgerID, ExpenseFilter{AccountID: accountID}, false, false) {
+		if !before.IsZero() && !expense.Date.Before(before) {
+			continue
+		}
+		if expense.Kind == models.KindRefund || expense.Kind == models.KindIncome {
+			total -= expense.Amount
+		} else {
+			total += expense.Amount
+		}
+	}
+	return total, nil
+}
+
+// GetByAccount lists expenses paid from an account within [from, to] (zero means no bound)
+func (r *memoryExpenseRepository) GetByAccount(ledgerID int, accountID int, from time.Time, to time.Time) ([]models.Expense, error) {
+	expenses := r.filtered(ledgerID, ExpenseFilter{AccountID: accountID, From: from, To: to}, false, false)
+	sortByDate(expenses)
+	return expenses, nil
+}
+
+// CategoryTotals sums spending per category within [from, to] (zero means no bound),
+// attributing split expenses line by line. Refunds reduce their category's total
+// and income is left out.
+func (r *memoryExpenseRepository) CategoryTotals(ledgerID int, from time.Time, to time.Time) ([]CategoryTotal, error) {
+	byCategory := make(map[int]*CategoryTotal)
+	add := func(categoryID int, amount float64, kind string) {
+		if kind == models.KindRefund {
+			amount = -amount
+		}
+		total, ok := byCategory[categoryID]
+		if !ok {
+			total = &CategoryTotal{CategoryID: categoryID}
+			byCategory[categoryID] = total
+		}
+		total.Total += amount
+		total.Count++
+	}
+
+	for _, expense := range r.filtered(ledgerID, ExpenseFilter{From: from, To: to}, false, true) {
+		if expense.Kind == models.KindIncome {
+			continue
+		}
+		if len(expense.Splits) == 0 {
+			add(expense.CategoryID, expense.Amount, expense.Kind)
+			continue
+		}
+		for _, split := range expense.Splits {
+			add(split.CategoryID, split.Amount, expense.Kind)
+		}
+	}
+
+	totals := make([]CategoryTotal, 0, len(byCategory))
+	for _, total := range byCategory {
+		totals = append(totals, *total)
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Total != totals[j].Total {
+			return totals[i].Total > totals[j].Total
+		}
+		return totals[i].CategoryID < totals[j].CategoryID
+	})
+	return totals, nil
+}
+
+// GetShared lists every expense paid by a person on behalf of others, with its shares
+func (r *memoryExpenseRepository) GetShared(ledgerID int) ([]models.Expense, error) {
+	expenses := make([]models.Expense, 0)
+	for _, expense := range r.filtered(ledgerID, ExpenseFilter{}, true, false) {
+		if expense.PaidByID != nil {
+			expenses = append(expenses, expense)
+		}
+	}
+	sortByDate(expenses)
+	return expenses, nil
+}
+
+// ExistsByExternalID reports whether the ledger already has an expense imported with the given bank reference
+func (r *memoryExpenseRepository) ExistsByExternalID(ledgerID int, externalID string) (bool, error) {
+	for _, expense := range r.filtered(ledgerID, ExpenseFilter{}, false, false) {
+		if expense.ExternalID == externalID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// FindByAmount lists the expenses of the given amount, to the cent, dated within [from, to]
+func (r *memoryExpenseRepository) FindByAmount(ledgerID int, amount float64, from time.Time, to time.Time) ([]models.Expense, error) {
+	expenses := make([]models.Expense, 0)
+	for _, expense := range r.filtered(ledgerID, ExpenseFilter{From: from, To: to}, false, false) {
+		if expense.Amount >= amount-0.005 && expense.Amount <= amount+0.005 {
+			expenses = append(expenses, expense)
+		}
+	}
+	sortByDate(expenses)
+	return expenses, nil
+}
+
+// UsesAccount reports whether any expense of the ledger is paid from the account
+func (r *memoryExpenseRepository) UsesAccount(ledgerID int, accountID int) (bool, error) {
+	return len(r.filtered(ledgerID, ExpenseFilter{AccountID: accountID}, false, false)) > 0, nil
+}
+
+// UsesPerson reports whether the person paid for or has a share of any expense of the ledger
+func (r *memoryExpenseRepository) UsesPerson(ledgerID int, personID int) (bool, error) {
+	for _, expense := range r.filtered(ledgerID, ExpenseFilter{}, true, false) {
+		if expense.PaidByID != nil && *expense.PaidByID == personID {
+			return true, nil
+		}
+		for _, share := range expense.Shares {
+			if share.PersonID == personID {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// sortByDate orders expenses by date, then by ID
+func sortByDate(expenses []models.Expense) {
+	sort.Slice(expenses, func(i, j int) bool {
+		if !expenses[i].Date.Equal(expenses[j].Date) {
+			return expenses[i].Date.Before(expenses[j].Date)
+		}
+		return expenses[i].ID < expenses[j].ID
+	})
+}
+
+// copyExpense copies an expense so callers cannot change the stored one,
+// keeping the shares and the other children (split lines and tags) as asked
+func copyExpense(expense models.Expense, withShares bool, withOthers bool) models.Expense {
+	if expense.AccountID != nil {
+		accountID := *expense.AccountID
+		expense.AccountID = &accountID
+	}
+	if expense.PaidByID != nil {
+		paidByID := *expense.PaidByID
+		expense.PaidByID = &paidByID
+	}
+
+	if withShares && len(expense.Shares) > 0 {
+		expense.Shares = append([]models.ExpenseShare(nil), expense.Shares...)
+	} else {
+		expense.Shares = nil
+	}
+	if withOthers && len(expense.Splits) > 0 {
+		expense.Splits = append([]models.ExpenseSplit(nil), expense.Splits...)
+	} else {
+		expense.Splits = nil
+	}
+	if withOthers && len(expense.Tags) > 0 {
+		expense.Tags = append([]models.Tag(nil), expense.Tags...)
+	} else {
+		expense.Tags = nil
+	}
+	return expense
+}
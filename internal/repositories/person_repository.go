@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"goExpenseTracker/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type PersonRepository interface {
+	Create(person *models.Person) error
+	GetAll(ledgerID int, offset int, limit int, nameFilter string) ([]models.Person, error)
+	GetByID(ledgerID int, id uint) (*models.Person, error)
+	Update(person *models.Person) error
+	Delete(ledgerID int, id uint) error
+	HasSettlements(ledgerID int, id uint) (bool, error)
+}
+
+type personRepository struct {
+	db *gorm.DB
+}
+
+func NewPersonRepository(db *gorm.DB) PersonRepository {
+	return &personRepository{db: db}
+}
+
+func (r *personRepository) Create(person *models.Person) error {
+	return r.db.Create(person).Error
+}
+
+// GetAll fetches people with pagination and optional filters
+func (r *personRepository) GetAll(ledgerID int, offset int, limit int, nameFilter string) ([]models.Person, error) {
+	var people []models.Person
+
+	query := r.db.Model(&models.Person{}).Scopes(inLedger(ledgerID))
+
+	if nameFilter != "" {
+		query = query.Scopes(containsFold("name", nameFilter))
+	}
+
+	if limit > 0 {
+		query = query.Offset(offset).Limit(limit)
+	}
+
+	err := query.Order("id").Find(&people).Error
+	return people, err
+}
+
+func (r *personRepository) GetByID(ledgerID int, id uint) (*models.Person, error) {
+	var person models.Person
+	err := r.db.Scopes(inLedger(ledgerID)).First(&person, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &person, nil
+}
+
+func (r *personRepository) Update(person *models.Person) error {
+	return r.db.Save(person).Error
+}
+
+func (r *personRepository) Delete(ledgerID int, id uint) error {
+	return r.db.Scopes(inLedger(ledgerID)).Delete(&models.Person{}, id).Error
+}
+
+// HasSettlements reports whether the person paid or received any settlement;
+// shared expenses are checked through ExpenseRepository.UsesPerson
+func (r *personRepository) HasSettlements(ledgerID int, id uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Settlement{}).Scopes(inLedger(ledgerID)).
+		Where("from_person_id = ? OR to_person_id = ?", id, id).
+		Count(&count).Error
+	return count > 0, err
+}
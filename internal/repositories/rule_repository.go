@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"goExpenseTracker/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type RuleRepository interface {
+	Create(rule *models.Rule) error
+	GetAll(ledgerID int, offset int, limit int) ([]models.Rule, error)
+	GetEnabled(ledgerID int) ([]models.Rule, error)
+	GetByID(ledgerID int, id uint) (*models.Rule, error)
+	Update(rule *models.Rule) error
+	Delete(ledgerID int, id uint) error
+}
+
+type ruleRepository struct {
+	db *gorm.DB
+}
+
+func NewRuleRepository(db *gorm.DB) RuleRepository {
+	return &ruleRepository{db: db}
+}
+
+// Create inserts the rule and links its tags; the tags themselves must already exist
+func (r *ruleRepository) Create(rule *models.Rule) error {
+	return r.db.Omit("Tags.*").Create(rule).Error
+}
+
+// GetAll fetches rules in the order they run, with pagination
+func (r *ruleRepository) GetAll(ledgerID int, offset int, limit int) ([]models.Rule, error) {
+	var rules []models.Rule
+
+	query := r.db.Model(&models.Rule{}).Scopes(inLedger(ledgerID))
+
+	if limit > 0 {
+		query = query.Offset(offset).Limit(limit)
+	}
+
+	err := query.Order("priority, id").Preload("Tags").Find(&rules).Error
+	return rules, err
+}
+
+// GetEnabled lists the rules that apply to new expenses, in the order they run
+func (r *ruleRepository) GetEnabled(ledgerID int) ([]models.Rule, error) {
+	var rules []models.Rule
+	err := r.db.Scopes(inLedger(ledgerID)).Where("enabled = ?", true).Order("priority, id").Preload("Tags").Find(&rules).Error
+	return rules, err
+}
+
+func (r *ruleRepository) GetByID(ledgerID int, id uint) (*models.Rule, error) {
+	var rule models.Rule
+	err := r.db.Scopes(inLedger(ledgerID)).Preload("Tags").First(&rule, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// Update saves the rule and replaces its tags
+func (r *ruleRepository) Update(rule *models.Rule) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM rule_tags WHERE rule_id = ?", rule.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Omit("Tags").Save(rule).Error; err != nil {
+			return err
+		}
+
+		// Append adds to rule.Tags as well as linking, so start from an empty list
+		if tags := rule.Tags; len(tags) > 0 {
+			rule.Tags = nil
+			return tx.Model(rule).Omit("Tags.*").Association("Tags").Append(tags)
+		}
+		return nil
+	})
+}
+
+func (r *ruleRepository) Delete(ledgerID int, id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if owned, err := owns(tx, &models.Rule{}, ledgerID, id); err != nil || !owned {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM rule_tags WHERE rule_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Rule{}, id).Error
+	})
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"goExpenseTracker/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type SettlementRepository interface {
+	Create(settlement *models.Settlement) error
+	GetAll(ledgerID int, offset int, limit int, personID int) ([]models.Settlement, error)
+	GetByID(ledgerID int, id uint) (*models.Settlement, error)
+	Delete(ledgerID int, id uint) error
+}
+
+type settlementRepository struct {
+	db *gorm.DB
+}
+
+func NewSettlementRepository(db *gorm.DB) SettlementRepository {
+	return &settlementRepository{db: db}
+}
+
+func (r *settlementRepository) Create(settlement *models.Settlement) error {
+	return r.db.Create(settlement).Error
+}
+
+// GetAll fetches settlements with pagination, optionally involving one person
+func (r *settlementRepository) GetAll(ledgerID int, offset int, limit int, personID int) ([]models.Settlement, error) {
+	var settlements []models.Settlement
+
+	query := r.db.Model(&models.Settlement{}).Scopes(inLedger(ledgerID))
+
+	if personID > 0 {
+		query = query.Where("from_person_id = ? OR to_person_id = ?", personID, personID)
+	}
+
+	if limit > 0 {
+		query = query.Offset(offset).Limit(limit)
+	}
+
+	err := query.Order("date, id").Find(&settlements).Error
+	return settlements, err
+}
+
+func (r *settlementRepository) GetByID(ledgerID int, id uint) (*models.Settlement, error) {
+	var settlement models.Settlement
+	err := r.db.Scopes(inLedger(ledgerID)).First(&settlement, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &settlement, nil
+}
+
+func (r *settlementRepository) Delete(ledgerID int, id uint) error {
+	return r.db.Scopes(inLedger(ledgerID)).Delete(&models.Settlement{}, id).Error
+}
@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"goExpenseTracker/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type TagRepository interface {
+	Create(tag *models.Tag) error
+	GetAll(ledgerID int, offset int, limit int, nameFilter string) ([]models.Tag, error)
+	GetByID(ledgerID int, id uint) (*models.Tag, error)
+	GetByName(ledgerID int, name string) (*models.Tag, error)
+	Update(tag *models.Tag) error
+	Delete(ledgerID int, id uint) error
+}
+
+type tagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+func (r *tagRepository) Create(tag *models.Tag) error {
+	return r.db.Create(tag).Error
+}
+
+// GetAll fetches tags in name order with pagination and optional filters
+func (r *tagRepository) GetAll(ledgerID int, offset int, limit int, nameFilter string) ([]models.Tag, error) {
+	var tags []models.Tag
+
+	query := r.db.Model(&models.Tag{}).Scopes(inLedger(ledgerID))
+
+	if nameFilter != "" {
+		query = query.Scopes(containsFold("name", nameFilter))
+	}
+
+	if limit > 0 {
+		query = query.Offset(offset).Limit(limit)
+	}
+
+	err := query.Order("name, id").Find(&tags).Error
+	return tags, err
+}
+
+func (r *tagRepository) GetByID(ledgerID int, id uint) (*models.Tag, error) {
+	var tag models.Tag
+	err := r.db.Scopes(inLedger(ledgerID)).First(&tag, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// GetByName finds a tag by its name, ignoring case
+func (r *tagRepository) GetByName(ledgerID int, name string) (*models.Tag, error) {
+	var tag models.Tag
+	err := r.db.Scopes(inLedger(ledgerID)).Scopes(equalFold("name", name)).First(&tag).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *tagRepository) Update(tag *models.Tag) error {
+	return r.db.Save(tag).Error
+}
+
+// Delete removes the tag from every expense and rule carrying it, then the tag itself
+func (r *tagRepository) Delete(ledgerID int, id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if owned, err := owns(tx, &models.Tag{}, ledgerID, id); err != nil || !owned {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM expense_tags WHERE tag_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM rule_tags WHERE tag_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Tag{}, id).Error
+	})
+}
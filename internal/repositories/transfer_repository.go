@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"time"
+
+	"goExpenseTracker/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type TransferRepository interface {
+	Create(transfer *models.Transfer) error
+	GetAll(ledgerID int, offset int, limit int, accountID int) ([]models.Transfer, error)
+	GetByID(ledgerID int, id uint) (*models.Transfer, error)
+	Delete(ledgerID int, id uint) error
+	SumForAccount(ledgerID int, accountID int, before time.Time) (incoming float64, outgoing float64, err error)
+	GetForAccount(ledgerID int, accountID int, from time.Time, to time.Time) ([]models.Transfer, error)
+}
+
+type transferRepository struct {
+	db *gorm.DB
+}
+
+func NewTransferRepository(db *gorm.DB) TransferRepository {
+	return &transferRepository{db: db}
+}
+
+func (r *transferRepository) Create(transfer *models.Transfer) error {
+	return r.db.Create(transfer).Error
+}
+
+// GetAll fetches transfers with pagination, optionally restricted to one account
+func (r *transferRepository) GetAll(ledgerID int, offset int, limit int, accountID int) ([]models.Transfer, error) {
+	var transfers []models.Transfer
+
+	query := r.db.Model(&models.Transfer{}).Scopes(inLedger(ledgerID))
+
+	if accountID > 0 {
+		query = query.Where("from_account_id = ? OR to_account_id = ?", accountID, accountID)
+	}
+
+	if limit > 0 {
+		query = query.Offset(offset).Limit(limit)
+	}
+
+	err := query.Order("date, id").Find(&transfers).Error
+	return transfers, err
+}
+
+func (r *transferRepository) GetByID(ledgerID int, id uint) (*models.Transfer, error) {
+	var transfer models.Transfer
+	err := r.db.Scopes(inLedger(ledgerID)).First(&transfer, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+func (r *transferRepository) Delete(ledgerID int, id uint) error {
+	return r.db.Scopes(inLedger(ledgerID)).Delete(&models.Transfer{}, id).Error
+}
+
+// SumForAccount totals money moved into and out of an account before the given time (zero means no bound)
+func (r *transferRepository) SumForAccount(ledgerID int, accountID int, before time.Time) (float64, float64, error) {
+	sum := func(column string) (float64, error) {
+		var total float64
+		query := r.db.Model(&models.Transfer{}).Scopes(inLedger(ledgerID)).Where(column+" = ?", accountID)
+		if !before.IsZero() {
+			query = query.Where("date < ?", before)
+		}
+		err := query.Select("COALESCE(SUM(amount), 0)").Scan(&total).Error
+		return total, err
+	}
+
+	incoming, err := sum("to_account_id")
+	if err != nil {
+		return 0, 0, err
+	}
+	outgoing, err := sum("from_account_id")
+	if err != nil {
+		return 0, 0, err
+	}
+	return incoming, outgoing, nil
+}
+
+// GetForAccount lists transfers touching an account within [from, to] (zero means no bound)
+func (r *transferRepository) GetForAccount(ledgerID int, accountID int, from time.Time, to time.Time) ([]models.Transfer, error) {
+	var transfers []models.Transfer
+
+	query := r.db.Model(&models.Transfer{}).Scopes(inLedger(ledgerID)).
+		Where("from_account_id = ? OR to_account_id = ?", accountID, accountID)
+
+	if !from.IsZero() {
+		query = query.Where("date >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("date <= ?", to)
+	}
+
+	err := query.Order("date, id").Find(&transfers).Error
+	return transfers, err
+}
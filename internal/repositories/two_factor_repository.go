@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"goExpenseTracker/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type TwoFactorRepository interface {
+	Get(userID int) (*models.TwoFactor, error)
+	Save(twoFactor *models.TwoFactor) error
+	Confirm(userID int, step int64, at time.Time, codes []models.RecoveryCode) error
+	UseStep(userID int, step int64) (bool, error)
+	Delete(userID int) error
+
+	CountRecoveryCodes(userID int) (int64, error)
+	ReplaceRecoveryCodes(userID int, codes []models.RecoveryCode) error
+	UseRecoveryCode(userID int, codeHash string, at time.Time) (bool, error)
+}
+
+type twoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
+
+// Get returns the user's secret, or nil when they never enrolled. Other errors
+// are returned so that a failing database does not turn two-factor sign-in off.
+func (r *twoFactorRepository) Get(userID int) (*models.TwoFactor, error) {
+	var twoFactor models.TwoFactor
+	err := r.db.Where("user_id = ?", userID).First(&twoFactor).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &twoFactor, nil
+}
+
+// Save stores the user's secret, replacing any enrolment that was not confirmed
+func (r *twoFactorRepository) Save(twoFactor *models.TwoFactor) error {
+	return r.db.Save(twoFactor).Error
+}
+
+// Confirm turns two-factor sign-in on with the step of the code that confirmed it
+// and the user's first recovery codes
+func (r *twoFactorRepository) Confirm(userID int, step int64, at time.Time, codes []models.RecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.TwoFactor{}).Where("user_id = ?", userID).
+			Updates(map[string]interface{}{"confirmed_at": at, "last_used_step": step}).Error
+		if err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(tx, userID, codes)
+	})
+}
+
+// UseStep records a code's time step as used, reporting false when it or a later
+// step already was, so a code cannot be replayed even by concurrent sign-ins
+func (r *twoFactorRepository) UseStep(userID int, step int64) (bool, error) {
+	result := r.db.Model(&models.TwoFactor{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	return result.RowsAffected == 1, result.Error
+}
+
+// Delete turns two-factor sign-in off, removing the secret and recovery codes
+func (r *twoFactorRepository) Delete(userID int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.TwoFactor{}).Error
+	})
+}
+
+// CountRecoveryCodes counts the user's recovery codes not used yet
+func (r *twoFactorRepository) CountRecoveryCodes(userID int) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+func (r *twoFactorRepository) ReplaceRecoveryCodes(userID int, codes []models.RecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codes)
+	})
+}
+
+// UseRecoveryCode marks the code used, reporting false when the user has no such
+// code left
+func (r *twoFactorRepository) UseRecoveryCode(userID int, codeHash string, at time.Time) (bool, error) {
+	result := r.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID int, codes []models.RecoveryCode) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return tx.Create(&codes).Error
+}
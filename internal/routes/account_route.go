@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"goExpenseTracker/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupAccountRoutes(router *gin.RouterGroup, accountHandler *handlers.AccountHandler) {
+	v1 := router.Group("/v1")
+	{
+		accounts := v1.Group("/accounts")
+		{
+			accounts.POST("", accountHandler.CreateAccount)
+			accounts.GET("", accountHandler.GetAllAccounts)
+			accounts.GET("/:id", accountHandler.GetAccountByID)
+			accounts.PUT("/:id", accountHandler.UpdateAccount)
+			accounts.DELETE("/:id", accountHandler.DeleteAccount)
+			accounts.GET("/:id/balance", accountHandler.GetAccountBalance)
+			accounts.GET("/:id/statement", accountHandler.GetAccountStatement)
+		}
+	}
+}
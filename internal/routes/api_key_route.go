@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"goExpenseTracker/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupAPIKeyRoutes(router *gin.RouterGroup, apiKeyHandler *handlers.APIKeyHandler) {
+	v1 := router.Group("/v1")
+	{
+		keys := v1.Group("/api-keys")
+		{
+			keys.POST("", apiKeyHandler.CreateAPIKey)
+			keys.GET("", apiKeyHandler.GetAllAPIKeys)
+			keys.GET("/scopes", apiKeyHandler.GetAPIKeyScopes)
+			keys.DELETE("/:id", apiKeyHandler.RevokeAPIKey)
+		}
+	}
+}
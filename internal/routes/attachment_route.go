@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"goExpenseTracker/internal/handlers"
+	Logger "goExpenseTracker/internal/middlewears"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupAttachmentRoutes(router *gin.RouterGroup, attachmentHandler *handlers.AttachmentHandler) {
+	v1 := router.Group("/v1")
+	{
+		attachments := v1.Group("/expenses/:id/attachments")
+		{
+			// Receipts go straight to storage, within the upload limit, and not to the log
+			attachments.POST("", Logger.SkipBodies(), attachmentHandler.UploadAttachment)
+			attachments.GET("", attachmentHandler.GetAttachments)
+			attachments.GET("/:attachment_id", Logger.SkipBodies(), attachmentHandler.DownloadAttachment)
+			attachments.DELETE("/:attachment_id", attachmentHandler.DeleteAttachment)
+		}
+	}
+}
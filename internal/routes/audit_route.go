@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"goExpenseTracker/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupAuditRoutes(router *gin.RouterGroup, auditHandler *handlers.AuditHandler) {
+	v1 := router.Group("/v1")
+	{
+		v1.GET("/audit", auditHandler.GetAuditLog)
+	}
+}
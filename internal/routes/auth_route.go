@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"goExpenseTracker/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupAuthRoutes mounts sign-up and sign-in, including its two-factor step, which
+// need no token, and the signed-in user's profile behind requireAuth; only people
+// signed in, not API keys, may change it
+func SetupAuthRoutes(router *gin.RouterGroup, authHandler *handlers.AuthHandler, requireAuth gin.HandlerFunc, requireSession gin.HandlerFunc) {
+	v1 := router.Group("/v1")
+	{
+		v1.POST("/auth/register", authHandler.Register)
+		v1.POST("/auth/login", authHandler.Login)
+		v1.POST("/auth/2fa/verify", authHandler.VerifyTwoFactor)
+		v1.GET("/auth/me", requireAuth, authHandler.GetCurrentUser)
+		v1.PATCH("/auth/me", requireAuth, requireSession, authHandler.UpdateCurrentUser)
+	}
+}
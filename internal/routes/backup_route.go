@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"goExpenseTracker/internal/handlers"
+	Logger "goExpenseTracker/internal/middlewears"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupBackupRoutes(router *gin.RouterGroup, backupHandler *handlers.BackupHandler) {
+	v1 := router.Group("/v1")
+	{
+		// The archive holds every password hash, two-factor secret and API key hash
+		v1.GET("/backup", Logger.SkipBodies(), backupHandler.DownloadBackup)
+		v1.POST("/restore", Logger.SkipBodies(), backupHandler.RestoreBackup)
+	}
+}
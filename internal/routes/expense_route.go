@@ -13,7 +13,10 @@ func SetupExpenseRoutes(router *gin.RouterGroup, expenseHandler *handlers.Expens
 		{
 			expenses.POST("", expenseHandler.CreateExpense)
 			expenses.GET("", expenseHandler.GetAllExpenses)
+			expenses.GET("/duplicates", expenseHandler.GetDuplicateClusters)
+			expenses.POST("/duplicates/resolve", expenseHandler.ResolveDuplicates)
 			expenses.GET("/:id", expenseHandler.GetExpenseByID)
+			expenses.GET("/:id/history", expenseHandler.GetExpenseHistory)
 			expenses.PUT("/:id", expenseHandler.UpdateExpense)
 			expenses.DELETE("/:id", expenseHandler.DeleteExpense)
 		}
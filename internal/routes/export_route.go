@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"goExpenseTracker/internal/handlers"
+	Logger "goExpenseTracker/internal/middlewears"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupExportRoutes(router *gin.RouterGroup, exportHandler *handlers.ExportHandler) {
+	v1 := router.Group("/v1")
+	{
+		// Exports stream as they are read and are too large to log
+		exports := v1.Group("/exports", Logger.SkipBodies())
+		{
+			exports.GET("/qif", exportHandler.ExportQIF)
+			exports.GET("/journal", exportHandler.ExportJournal)
+			exports.GET("/expenses", exportHandler.ExportExpenses)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"goExpenseTracker/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupImportRoutes(router *gin.RouterGroup, importHandler *handlers.ImportHandler) {
+	v1 := router.Group("/v1")
+	{
+		imports := v1.Group("/imports")
+		{
+			imports.POST("", importHandler.ImportExpenses)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"goExpenseTracker/internal/handlers"
+	Logger "goExpenseTracker/internal/middlewears"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupLedgerRoutes(router *gin.RouterGroup, ledgerHandler *handlers.LedgerHandler) {
+	v1 := router.Group("/v1")
+	{
+		ledgers := v1.Group("/ledgers")
+		{
+			ledgers.POST("", ledgerHandler.CreateLedger)
+			ledgers.GET("", ledgerHandler.GetAllLedgers)
+			ledgers.GET("/:id", ledgerHandler.GetLedgerByID)
+			ledgers.PUT("/:id", ledgerHandler.UpdateLedger)
+			ledgers.DELETE("/:id", ledgerHandler.DeleteLedger)
+			ledgers.GET("/:id/members", ledgerHandler.GetLedgerMembers)
+			ledgers.PUT("/:id/members/:user_id", ledgerHandler.UpdateLedgerMember)
+			ledgers.DELETE("/:id/members/:user_id", ledgerHandler.RemoveLedgerMember)
+			// Invite tokens are only stored hashed, so they must not reach the log either
+			ledgers.POST("/:id/invites", Logger.SkipBodies(), ledgerHandler.CreateLedgerInvite)
+			ledgers.GET("/:id/invites", ledgerHandler.GetLedgerInvites)
+			ledgers.DELETE("/:id/invites/:invite_id", ledgerHandler.RevokeLedgerInvite)
+		}
+		v1.POST("/invites/accept", Logger.SkipBodies(), ledgerHandler.AcceptLedgerInvite)
+	}
+}
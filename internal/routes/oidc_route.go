@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"goExpenseTracker/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupOIDCRoutes mounts single sign-on, which needs no token
+func SetupOIDCRoutes(router *gin.RouterGroup, oidcHandler *handlers.OIDCHandler) {
+	v1 := router.Group("/v1")
+	{
+		v1.GET("/auth/oidc/login", oidcHandler.BeginOIDCLogin)
+		v1.GET("/auth/oidc/callback", oidcHandler.CompleteOIDCLogin)
+	}
+}
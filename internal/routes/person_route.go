@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"goExpenseTracker/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupPersonRoutes(router *gin.RouterGroup, personHandler *handlers.PersonHandler) {
+	v1 := router.Group("/v1")
+	{
+		people := v1.Group("/people")
+		{
+			people.POST("", personHandler.CreatePerson)
+			people.GET("", personHandler.GetAllPeople)
+			people.GET("/:id", personHandler.GetPersonByID)
+			people.PUT("/:id", personHandler.UpdatePerson)
+			people.DELETE("/:id", personHandler.DeletePerson)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"goExpenseTracker/internal/handlers"
+	Logger "goExpenseTracker/internal/middlewears"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupReportRoutes(router *gin.RouterGroup, reportHandler *handlers.ReportHandler) {
+	v1 := router.Group("/v1")
+	{
+		reports := v1.Group("/reports")
+		{
+			reports.GET("/categories", reportHandler.GetCategoryReport)
+			reports.GET("/expenses/pdf", Logger.SkipBodies(), reportHandler.GetExpensePDFReport)
+		}
+	}
+}
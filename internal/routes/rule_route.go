@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"goExpenseTracker/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupRuleRoutes(router *gin.RouterGroup, ruleHandler *handlers.RuleHandler) {
+	v1 := router.Group("/v1")
+	{
+		rules := v1.Group("/rules")
+		{
+			rules.POST("", ruleHandler.CreateRule)
+			rules.GET("", ruleHandler.GetAllRules)
+			rules.POST("/test", ruleHandler.TestRule)
+			rules.GET("/:id", ruleHandler.GetRuleByID)
+			rules.PUT("/:id", ruleHandler.UpdateRule)
+			rules.DELETE("/:id", ruleHandler.DeleteRule)
+		}
+	}
+}
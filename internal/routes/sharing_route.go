@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"goExpenseTracker/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupSharingRoutes(router *gin.RouterGroup, sharingHandler *handlers.SharingHandler) {
+	v1 := router.Group("/v1")
+	{
+		shared := v1.Group("/shared")
+		{
+			shared.GET("/balances", sharingHandler.GetBalances)
+			shared.GET("/settle-up", sharingHandler.GetSettleUp)
+		}
+
+		settlements := v1.Group("/settlements")
+		{
+			settlements.POST("", sharingHandler.CreateSettlement)
+			settlements.GET("", sharingHandler.GetAllSettlements)
+			settlements.DELETE("/:id", sharingHandler.DeleteSettlement)
+		}
+	}
+}
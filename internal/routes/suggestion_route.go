@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"goExpenseTracker/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupSuggestionRoutes(router *gin.RouterGroup, suggestionHandler *handlers.SuggestionHandler) {
+	v1 := router.Group("/v1")
+	{
+		v1.GET("/categories/suggest", suggestionHandler.SuggestCategory)
+	}
+}
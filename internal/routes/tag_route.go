@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"goExpenseTracker/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupTagRoutes(router *gin.RouterGroup, tagHandler *handlers.TagHandler) {
+	v1 := router.Group("/v1")
+	{
+		tags := v1.Group("/tags")
+		{
+			tags.POST("", tagHandler.CreateTag)
+			tags.GET("", tagHandler.GetAllTags)
+			tags.GET("/:id", tagHandler.GetTagByID)
+			tags.PUT("/:id", tagHandler.UpdateTag)
+			tags.DELETE("/:id", tagHandler.DeleteTag)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"goExpenseTracker/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupTransferRoutes(router *gin.RouterGroup, transferHandler *handlers.TransferHandler) {
+	v1 := router.Group("/v1")
+	{
+		transfers := v1.Group("/transfers")
+		{
+			transfers.POST("", transferHandler.CreateTransfer)
+			transfers.GET("", transferHandler.GetAllTransfers)
+			transfers.GET("/:id", transferHandler.GetTransferByID)
+			transfers.DELETE("/:id", transferHandler.DeleteTransfer)
+		}
+	}
+}
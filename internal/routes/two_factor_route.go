@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"goExpenseTracker/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupTwoFactorRoutes mounts two-factor enrolment of the signed-in user and its
+// reset by an admin; the sign-in step itself is mounted with the auth routes
+func SetupTwoFactorRoutes(router *gin.RouterGroup, twoFactorHandler *handlers.TwoFactorHandler) {
+	v1 := router.Group("/v1")
+	{
+		twoFactor := v1.Group("/auth/2fa")
+		{
+			twoFactor.GET("", twoFactorHandler.GetTwoFactorStatus)
+			twoFactor.POST("/enroll", twoFactorHandler.EnrollTwoFactor)
+			twoFactor.POST("/confirm", twoFactorHandler.ConfirmTwoFactor)
+			twoFactor.POST("/disable", twoFactorHandler.DisableTwoFactor)
+			twoFactor.POST("/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+		}
+		v1.DELETE("/users/:id/2fa", twoFactorHandler.ResetTwoFactor)
+	}
+}
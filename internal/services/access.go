@@ -0,0 +1,69 @@
+package services
+
+import (
+	"time"
+
+	"goExpenseTracker/internal/models"
+)
+
+// Access is what a signed-in user may do in the ledger a request works on
+type Access struct {
+	UserID    int
+	LedgerID  int
+	Role      string
+	RequestID string // Recorded with the audit entries of the request's changes
+
+	// Location is the user's timezone, or the server's when they have not picked
+	// one; their days begin and end there
+	Location *time.Location
+}
+
+// Now returns the current time in the user's timezone
+func (a Access) Now() time.Time {
+	return time.Now().In(a.location())
+}
+
+// Today returns the user's current date. Like every date the API takes, it is
+// kept as midnight UTC, so it compares with expense and other dates directly.
+func (a Access) Today() time.Time {
+	year, month, day := a.Now().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// StartOfDay returns the moment a date begins in the user's timezone, for
+// comparing dates with timestamps such as when an entry was recorded
+func (a Access) StartOfDay(date time.Time) time.Time {
+	year, month, day := date.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, a.location())
+}
+
+func (a Access) location() *time.Location {
+	if a.Location == nil {
+		return time.UTC
+	}
+	return a.Location
+}
+
+// userLocation returns the timezone a user picked, or fallback when they have
+// not picked one
+func userLocation(user models.User, fallback *time.Location) *time.Location {
+	if user.Timezone != "" {
+		if location, err := time.LoadLocation(user.Timezone); err == nil {
+			return location
+		}
+	}
+	return fallback
+}
+
+// CanEdit reports whether the role allows changing the ledger's data
+func (a Access) CanEdit() bool {
+	return a.Role == models.RoleOwner || a.Role == models.RoleEditor
+}
+
+// requireEditor refuses changes from viewers
+func requireEditor(access Access) error {
+	if !access.CanEdit() {
+		return &ForbiddenError{Message: "viewers cannot change the ledger"}
+	}
+	return nil
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/models"
+	"goExpenseTracker/internal/repositories"
+)
+
+type AccountService interface {
+	Create(access Access, req dto.AccountRequestDTO) (dto.AccountResponseDTO, error)
+	GetAll(access Access, offset, limit int, nameFilter string) ([]dto.AccountResponseDTO, error)
+	GetByID(access Access, id int) (dto.AccountResponseDTO, error)
+	Update(access Access, id int, req dto.AccountRequestDTO) (dto.AccountResponseDTO, error)
+	Delete(access Access, id int) error
+	Balance(access Access, id int, asOf time.Time) (dto.AccountBalanceDTO, error)
+	Statement(access Access, id int, from, to time.Time) (dto.AccountStatementDTO, error)
+}
+
+type accountService struct {
+	accountRepo  repositories.AccountRepository
+	expenseRepo  repositories.ExpenseRepository
+	transferRepo repositories.TransferRepository
+}
+
+func NewAccountService(accountRepo repositories.AccountRepository, expenseRepo repositories.ExpenseRepository, transferRepo repositories.TransferRepository) AccountService {
+	return &accountService{
+		accountRepo:  accountRepo,
+		expenseRepo:  expenseRepo,
+		transferRepo: transferRepo,
+	}
+}
+
+// Create account
+func (s *accountService) Create(access Access, req dto.AccountRequestDTO) (dto.AccountResponseDTO, error) {
+	if err := requireEditor(access); err != nil {
+		return dto.AccountResponseDTO{}, err
+	}
+	account := models.Account{
+		UserID:         access.UserID,
+		LedgerID:       access.LedgerID,
+		Name:           req.Name,
+		Type:           req.Type,
+		Currency:       req.Currency,
+		OpeningBalance: req.OpeningBalance,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	if err := s.accountRepo.Create(&account); err != nil {
+		return dto.AccountResponseDTO{}, err
+	}
+
+	return s.toResponseDTO(account)
+}
+
+// Get all accounts with their current balances
+func (s *accountService) GetAll(access Access, offset, limit int, nameFilter string) ([]dto.AccountResponseDTO, error) {
+	accounts, err := s.accountRepo.GetAll(access.LedgerID, offset, limit, nameFilter)
+	if err != nil {
+		return []dto.AccountResponseDTO{}, err
+	}
+
+	responses := make([]dto.AccountResponseDTO, 0)
+	for _, account := range accounts {
+		response, err := s.toResponseDTO(account)
+		if err != nil {
+			return []dto.AccountResponseDTO{}, err
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// Get single account
+func (s *accountService) GetByID(access Access, id int) (dto.AccountResponseDTO, error) {
+	account, err := s.accountRepo.GetByID(access.LedgerID, uint(id))
+	if err != nil {
+		return dto.AccountResponseDTO{}, fmt.Errorf("account not found")
+	}
+	return s.toResponseDTO(*account)
+}
+
+// Update account
+func (s *accountService) Update(access Access, id int, req dto.AccountRequestDTO) (dto.AccountResponseDTO, error) {
+	if err := requireEditor(access); err != nil {
+		return dto.AccountResponseDTO{}, err
+	}
+	existing, err := s.accountRepo.GetByID(access.LedgerID, uint(id))
+	if err != nil {
+		return dto.AccountResponseDTO{}, fmt.Errorf("account not found")
+	}
+
+	if existing.Currency != req.Currency {
+		hasActivity, err := s.hasActivity(access.LedgerID, id)
+		if err != nil {
+			return dto.AccountResponseDTO{}, err
+		}
+		if hasActivity {
+			return dto.AccountResponseDTO{}, newValidationError("cannot change the currency of an account with expenses or transfers")
+		}
+	}
+
+	existing.Name = req.Name
+	existing.Type = req.Type
+	existing.Currency = req.Currency
+	existing.OpeningBalance = req.OpeningBalance
+	existing.UpdatedAt = time.Now()
+
+	if err := s.accountRepo.Update(existing); err != nil {
+		return dto.AccountResponseDTO{}, err
+	}
+
+	return s.toResponseDTO(*existing)
+}
+
+// Delete account, refusing while expenses or transfers still reference it
+func (s *accountService) Delete(access Access, id int) error {
+	if err := requireEditor(access); err != nil {
+		return err
+	}
+	hasActivity, err := s.hasActivity(access.LedgerID, id)
+	if err != nil {
+		return err
+	}
+	if hasActivity {
+		return newValidationError("account has linked expenses or transfers")
+	}
+	return s.accountRepo.Delete(access.LedgerID, uint(id))
+}
+
+// hasActivity reports whether any expense or transfer references the account
+func (s *accountService) hasActivity(ledgerID int, id int) (bool, error) {
+	if used, err := s.expenseRepo.UsesAccount(ledgerID, id); err != nil || used {
+		return used, err
+	}
+	return s.accountRepo.HasTransfers(ledgerID, uint(id))
+}
+
+// Balance returns the balance at the end of asOf (zero means including everything)
+func (s *accountService) Balance(access Access, id int, asOf time.Time) (dto.AccountBalanceDTO, error) {
+	account, err := s.accountRepo.GetByID(access.LedgerID, uint(id))
+	if err != nil {
+		return dto.AccountBalanceDTO{}, fmt.Errorf("account not found")
+	}
+
+	var before time.Time
+	if !asOf.IsZero() {
+		before = asOf.AddDate(0, 0, 1)
+	}
+
+	balance, err := s.balanceBefore(*account, before)
+	if err != nil {
+		return dto.AccountBalanceDTO{}, err
+	}
+
+	response := dto.AccountBalanceDTO{
+		AccountID:      account.ID,
+		Currency:       account.Currency,
+		OpeningBalance: account.OpeningBalance,
+		Balance:        balance,
+	}
+	if !asOf.IsZero() {
+		response.AsOf = asOf.Format("2006-01-02")
+	}
+	return response, nil
+}
+
+// Statement lists every movement on the account within [from, to] with a running balance
+func (s *accountService) Statement(access Access, id int, from, to time.Time) (dto.AccountStatementDTO, error) {
+	account, err := s.accountRepo.GetByID(access.LedgerID, uint(id))
+	if err != nil {
+		return dto.AccountStatementDTO{}, fmt.Errorf("account not found")
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return dto.AccountStatementDTO{}, newValidationError("to must not be before from")
+	}
+
+	opening := account.OpeningBalance
+	if !from.IsZero() {
+		if opening, err = s.balanceBefore(*account, from); err != nil {
+			return dto.AccountStatementDTO{}, err
+		}
+	}
+
+	expenses, err := s.expenseRepo.GetByAccount(access.LedgerID, account.ID, from, to)
+	if err != nil {
+		return dto.AccountStatementDTO{}, err
+	}
+	transfers, err := s.transferRepo.GetForAccount(access.LedgerID, account.ID, from, to)
+	if err != nil {
+		return dto.AccountStatementDTO{}, err
+	}
+
+	type movement struct {
+		date  time.Time
+		entry dto.StatementEntryDTO
+	}
+	movements := make([]movement, 0, len(expenses)+len(transfers))
+	for _, expense := range expenses {
+		entry := dto.StatementEntryDTO{
+			Type:        models.KindExpense,
+			ReferenceID: expense.ID,
+			Description: expense.Description,
+			Amount:      -expense.Amount,
+		}
+		if expense.Kind == models.KindRefund || expense.Kind == models.KindIncome {
+			entry.Type = expense.Kind
+			entry.Amount = expense.Amount
+		}
+		movements = append(movements, movement{expense.Date, entry})
+	}
+	for _, transfer := range transfers {
+		entry := dto.StatementEntryDTO{
+			Type:        "transfer_in",
+			ReferenceID: transfer.ID,
+			Description: transfer.Description,
+			Amount:      transfer.Amount,
+		}
+		if transfer.FromAccountID == account.ID {
+			entry.Type = "transfer_out"
+			entry.Amount = -transfer.Amount
+		}
+		movements = append(movements, movement{transfer.Date, entry})
+	}
+	sort.SliceStable(movements, func(i, j int) bool {
+		return movements[i].date.Before(movements[j].date)
+	})
+
+	statement := dto.AccountStatementDTO{
+		AccountID:      account.ID,
+		AccountName:    account.Name,
+		Currency:       account.Currency,
+		OpeningBalance: roundMoney(opening),
+		Entries:        make([]dto.StatementEntryDTO, 0, len(movements)),
+	}
+	if !from.IsZero() {
+		statement.From = from.Format("2006-01-02")
+	}
+	if !to.IsZero() {
+		statement.To = to.Format("2006-01-02")
+	}
+
+	running := opening
+	for _, m := range movements {
+		running += m.entry.Amount
+		m.entry.Date = m.date.Format("2006-01-02")
+		m.entry.Balance = roundMoney(running)
+		statement.Entries = append(statement.Entries, m.entry)
+	}
+	statement.ClosingBalance = roundMoney(running)
+
+	return statement, nil
+}
+
+// balanceBefore computes the balance from every movement dated before the given time (zero means all)
+func (s *accountService) balanceBefore(account models.Account, before time.Time) (float64, error) {
+	spent, err := s.expenseRepo.SumByAccount(account.LedgerID, account.ID, before)
+	if err != nil {
+		return 0, err
+	}
+	incoming, outgoing, err := s.transferRepo.SumForAccount(account.LedgerID, account.ID, before)
+	if err != nil {
+		return 0, err
+	}
+	return roundMoney(account.OpeningBalance + incoming - outgoing - spent), nil
+}
+
+// Private helper for mapping model → DTO
+func (s *accountService) toResponseDTO(account models.Account) (dto.AccountResponseDTO, error) {
+	balance, err := s.balanceBefore(account, time.Time{})
+	if err != nil {
+		return dto.AccountResponseDTO{}, err
+	}
+
+	return dto.AccountResponseDTO{
+		ID:             account.ID,
+		Name:           account.Name,
+		Type:           account.Type,
+		Currency:       account.Currency,
+		OpeningBalance: account.OpeningBalance,
+		Balance:        balance,
+		CreatedAt:      account.CreatedAt,
+		UpdatedAt:      account.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/models"
+	"goExpenseTracker/internal/repositories"
+)
+
+// APIKeyPrefix starts every API key, telling keys apart from access tokens
+const APIKeyPrefix = "gxt_"
+
+// apiKeyPrefixLength is how much of a key is kept in the clear to identify it
+const apiKeyPrefixLength = len(APIKeyPrefix) + 8
+
+// apiKeyTouchInterval limits how often the last use of a key is written, so a busy
+// script does not update its key on every request
+const apiKeyTouchInterval = time.Minute
+
+// API key scopes. Write scopes include the matching read scope.
+const (
+	ScopeExpensesRead    = "expenses:read"
+	ScopeExpensesWrite   = "expenses:write"
+	ScopeCategoriesRead  = "categories:read"
+	ScopeCategoriesAdmin = "categories:admin"
+	ScopeAccountsRead    = "accounts:read"
+	ScopeAccountsWrite   = "accounts:write"
+	ScopeSharingRead     = "sharing:read"
+	ScopeSharingWrite    = "sharing:write"
+)
+
+// APIKeyScopes lists every scope with what it allows
+var APIKeyScopes = []dto.APIKeyScopeDTO{
+	{Name: ScopeExpensesRead, Description: "Read expenses, receipts, duplicates, suggestions, reports and exports"},
+	{Name: ScopeExpensesWrite, Description: "Record, change and delete expenses, upload receipts and import files"},
+	{Name: ScopeCategoriesRead, Description: "Read categories, tags and rules"},
+	{Name: ScopeCategoriesAdmin, Description: "Manage categories, tags and rules, and test rules"},
+	{Name: ScopeAccountsRead, Description: "Read accounts, balances, statements and transfers"},
+	{Name: ScopeAccountsWrite, Description: "Manage accounts and transfers"},
+	{Name: ScopeSharingRead, Description: "Read people, balances and settlements"},
+	{Name: ScopeSharingWrite, Description: "Manage people and settlements"},
+}
+
+// impliedScopes are granted along with a scope
+var impliedScopes = map[string]string{
+	ScopeExpensesWrite:   ScopeExpensesRead,
+	ScopeCategoriesAdmin: ScopeCategoriesRead,
+	ScopeAccountsWrite:   ScopeAccountsRead,
+	ScopeSharingWrite:    ScopeSharingRead,
+}
+
+// API key statuses
+const (
+	APIKeyStatusActive  = "active"
+	APIKeyStatusExpired = "expired"
+	APIKeyStatusRevoked = "revoked"
+)
+
+// HasScope reports whether the granted scopes allow what the scope names
+func HasScope(granted []string, scope string) bool {
+	for _, name := range granted {
+		if name == scope || impliedScopes[name] == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type APIKeyService interface {
+	Create(userID int, req dto.APIKeyRequestDTO) (dto.APIKeyResponseDTO, error)
+	GetAll(userID int) ([]dto.APIKeyResponseDTO, error)
+	Revoke(userID int, id int) error
+	Authenticate(key string) (models.User, []string, error)
+}
+
+type apiKeyService struct {
+	apiKeyRepo repositories.APIKeyRepository
+	userRepo   repositories.UserRepository
+}
+
+func NewAPIKeyService(apiKeyRepo repositories.APIKeyRepository, userRepo repositories.UserRepository) APIKeyService {
+	return &apiKeyService{
+		apiKeyRepo: apiKeyRepo,
+		userRepo:   userRepo,
+	}
+}
+
+// Create issues a key with the requested scopes. Only its hash is stored, so the
+// key is shown this once.
+func (s *apiKeyService) Create(userID int, req dto.APIKeyRequestDTO) (dto.APIKeyResponseDTO, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return dto.APIKeyResponseDTO{}, newValidationError("name is required")
+	}
+	scopes, err := normalizeScopes(req.Scopes)
+	if err != nil {
+		return dto.APIKeyResponseDTO{}, err
+	}
+
+	secret, err := newSecretToken(APIKeyPrefix)
+	if err != nil {
+		return dto.APIKeyResponseDTO{}, err
+	}
+	key := models.APIKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    secret[:apiKeyPrefixLength],
+		KeyHash:   hashSecretToken(secret),
+		Scopes:    strings.Join(scopes, " "),
+		CreatedAt: time.Now(),
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := key.CreatedAt.AddDate(0, 0, req.ExpiresInDays)
+		key.ExpiresAt = &expiresAt
+	}
+	if err := s.apiKeyRepo.Create(&key); err != nil {
+		return dto.APIKeyResponseDTO{}, err
+	}
+
+	response := toAPIKeyDTO(key)
+	response.Key = secret
+	return response, nil
+}
+
+// Get the user's API keys
+func (s *apiKeyService) GetAll(userID int) ([]dto.APIKeyResponseDTO, error) {
+	keys, err := s.apiKeyRepo.GetAll(userID)
+	if err != nil {
+		return []dto.APIKeyResponseDTO{}, err
+	}
+
+	responses := make([]dto.APIKeyResponseDTO, 0, len(keys))
+	for _, key := range keys {
+		responses = append(responses, toAPIKeyDTO(key))
+	}
+	return responses, nil
+}
+
+// Revoke stops a key working straight away; it stays listed as revoked
+func (s *apiKeyService) Revoke(userID int, id int) error {
+	if _, err := s.apiKeyRepo.GetByID(userID, uint(id)); err != nil {
+		return fmt.Errorf("API key not found")
+	}
+	return s.apiKeyRepo.Revoke(userID, uint(id), time.Now())
+}
+
+// Authenticate checks an API key and returns its user with its scopes,
+// recording when it was used
+func (s *apiKeyService) Authenticate(secret string) (models.User, []string, error) {
+	key, err := s.apiKeyRepo.GetByHash(hashSecretToken(secret))
+	if err != nil {
+		return models.User{}, nil, &AuthError{Message: "invalid API key"}
+	}
+	switch apiKeyStatus(key) {
+	case APIKeyStatusRevoked:
+		return models.User{}, nil, &AuthError{Message: "API key has been revoked"}
+	case APIKeyStatusExpired:
+		return models.User{}, nil, &AuthError{Message: "API key has expired"}
+	}
+	// Keys of deleted users stop working straight away
+	user, err := s.userRepo.GetByID(uint(key.UserID))
+	if err != nil {
+		return models.User{}, nil, &AuthError{Message: "invalid API key"}
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.apiKeyRepo.TouchLastUsed(key.ID, now); err != nil {
+			return models.User{}, nil, err
+		}
+	}
+	return *user, strings.Fields(key.Scopes), nil
+}
+
+// Helper: Check requested scopes against the known ones, sorted and without repeats
+func normalizeScopes(requested []string) ([]string, error) {
+	known := make(map[string]bool, len(APIKeyScopes))
+	for _, scope := range APIKeyScopes {
+		known[scope.Name] = true
+	}
+
+	seen := make(map[string]bool, len(requested))
+	scopes := make([]string, 0, len(requested))
+	for _, scope := range requested {
+		scope = strings.TrimSpace(scope)
+		if !known[scope] {
+			return nil, newValidationError("unknown scope %q", scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	sort.Strings(scopes)
+	return scopes, nil
+}
+
+func apiKeyStatus(key *models.APIKey) string {
+	switch {
+	case key.RevokedAt != nil:
+		return APIKeyStatusRevoked
+	case key.ExpiresAt != nil && time.Now().After(*key.ExpiresAt):
+		return APIKeyStatusExpired
+	}
+	return APIKeyStatusActive
+}
+
+// Helper: Convert model → Response DTO
+func toAPIKeyDTO(key models.APIKey) dto.APIKeyResponseDTO {
+	return dto.APIKeyResponseDTO{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     strings.Fields(key.Scopes),
+		Status:     apiKeyStatus(&key),
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/models"
+	"goExpenseTracker/internal/repositories"
+	"goExpenseTracker/internal/storage"
+)
+
+// allowedAttachmentTypes are the sniffed content types accepted as receipts
+var allowedAttachmentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+type AttachmentService interface {
+	Upload(access Access, expenseID int, fileName string, file io.ReadSeeker, size int64) (dto.AttachmentResponseDTO, error)
+	List(access Access, expenseID int) ([]dto.AttachmentResponseDTO, error)
+	Open(access Access, expenseID, attachmentID int) (dto.AttachmentResponseDTO, io.ReadCloser, error)
+	Delete(access Access, expenseID, attachmentID int) error
+	DeleteAllForExpense(ledgerID int, expenseID int) error
+}
+
+type attachmentService struct {
+	attachmentRepo repositories.AttachmentRepository
+	expenseRepo    repositories.ExpenseRepository
+	store          storage.Storage
+	maxBytes       int64
+}
+
+func NewAttachmentService(attachmentRepo repositories.AttachmentRepository, expenseRepo repositories.ExpenseRepository, store storage.Storage, maxBytes int64) AttachmentService {
+	return &attachmentService{
+		attachmentRepo: attachmentRepo,
+		expenseRepo:    expenseRepo,
+		store:          store,
+		maxBytes:       maxBytes,
+	}
+}
+
+// Upload sniffs, hashes and stores a receipt for an expense
+func (s *attachmentService) Upload(access Access, expenseID int, fileName string, file io.ReadSeeker, size int64) (dto.AttachmentResponseDTO, error) {
+	if err := requireEditor(access); err != nil {
+		return dto.AttachmentResponseDTO{}, err
+	}
+	if _, err := s.expenseRepo.GetByID(access.LedgerID, uint(expenseID)); err != nil {
+		return dto.AttachmentResponseDTO{}, fmt.Errorf("expense not found")
+	}
+	if size <= 0 {
+		return dto.AttachmentResponseDTO{}, newValidationError("file is empty")
+	}
+	if size > s.maxBytes {
+		return dto.AttachmentResponseDTO{}, newValidationError("file exceeds the %d byte limit", s.maxBytes)
+	}
+
+	// Sniff the content type from the first 512 bytes rather than trusting the client
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return dto.AttachmentResponseDTO{}, err
+	}
+	contentType := http.DetectContentType(head[:n])
+	if !allowedAttachmentTypes[contentType] {
+		return dto.AttachmentResponseDTO{}, newValidationError("unsupported file type %s, expected a JPEG, PNG, GIF, WebP image or a PDF", contentType)
+	}
+
+	// Hash the whole file, then rewind to upload it
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return dto.AttachmentResponseDTO{}, err
+	}
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return dto.AttachmentResponseDTO{}, err
+	}
+	sum := hex.EncodeToString(hasher.Sum(nil))
+
+	existing, err := s.attachmentRepo.GetByExpense(access.LedgerID, expenseID)
+	if err != nil {
+		return dto.AttachmentResponseDTO{}, err
+	}
+	for _, attachment := range existing {
+		if attachment.SHA256 == sum {
+			return dto.AttachmentResponseDTO{}, newValidationError("this file is already attached to the expense")
+		}
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return dto.AttachmentResponseDTO{}, err
+	}
+	key := fmt.Sprintf("expenses/%d/%s", expenseID, sum)
+	if err := s.store.Put(context.Background(), key, file, size, contentType); err != nil {
+		return dto.AttachmentResponseDTO{}, fmt.Errorf("failed to store attachment: %w", err)
+	}
+
+	attachment := models.Attachment{
+		UserID:      access.UserID,
+		LedgerID:    access.LedgerID,
+		ExpenseID:   expenseID,
+		FileName:    sanitizeFileName(fileName),
+		ContentType: contentType,
+		Size:        size,
+		SHA256:      sum,
+		StorageKey:  key,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.attachmentRepo.Create(&attachment); err != nil {
+		_ = s.store.Delete(context.Background(), key)
+		return dto.AttachmentResponseDTO{}, err
+	}
+
+	return s.toResponseDTO(attachment), nil
+}
+
+// List the attachments of an expense
+func (s *attachmentService) List(access Access, expenseID int) ([]dto.AttachmentResponseDTO, error) {
+	if _, err := s.expenseRepo.GetByID(access.LedgerID, uint(expenseID)); err != nil {
+		return nil, fmt.Errorf("expense not found")
+	}
+
+	attachments, err := s.attachmentRepo.GetByExpense(access.LedgerID, expenseID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.AttachmentResponseDTO, 0)
+	for _, attachment := range attachments {
+		responses = append(responses, s.toResponseDTO(attachment))
+	}
+	return responses, nil
+}
+
+// Open returns an attachment's metadata and a reader over its content; the caller closes the reader
+func (s *attachmentService) Open(access Access, expenseID, attachmentID int) (dto.AttachmentResponseDTO, io.ReadCloser, error) {
+	attachment, err := s.get(access.LedgerID, expenseID, attachmentID)
+	if err != nil {
+		return dto.AttachmentResponseDTO{}, nil, err
+	}
+
+	reader, err := s.store.Get(context.Background(), attachment.StorageKey)
+	if err != nil {
+		return dto.AttachmentResponseDTO{}, nil, err
+	}
+	return s.toResponseDTO(*attachment), reader, nil
+}
+
+// Delete an attachment and its stored content
+func (s *attachmentService) Delete(access Access, expenseID, attachmentID int) error {
+	if err := requireEditor(access); err != nil {
+		return err
+	}
+	attachment, err := s.get(access.LedgerID, expenseID, attachmentID)
+	if err != nil {
+		return err
+	}
+	if err := s.attachmentRepo.Delete(access.LedgerID, uint(attachment.ID)); err != nil {
+		return err
+	}
+	return s.store.Delete(context.Background(), attachment.StorageKey)
+}
+
+// DeleteAllForExpense removes every attachment of an expense that is being deleted
+func (s *attachmentService) DeleteAllForExpense(ledgerID int, expenseID int) error {
+	attachments, err := s.attachmentRepo.GetByExpense(ledgerID, expenseID)
+	if err != nil {
+		return err
+	}
+	for _, attachment := range attachments {
+		if err := s.attachmentRepo.Delete(ledgerID, uint(attachment.ID)); err != nil {
+			return err
+		}
+		if err := s.store.Delete(context.Background(), attachment.StorageKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Helper: Load an attachment, making sure it belongs to the expense
+func (s *attachmentService) get(ledgerID int, expenseID, attachmentID int) (*models.Attachment, error) {
+	attachment, err := s.attachmentRepo.GetByID(ledgerID, uint(attachmentID))
+	if err != nil || attachment.ExpenseID != expenseID {
+		return nil, fmt.Errorf("attachment not found")
+	}
+	return attachment, nil
+}
+
+// Helper: Convert model → Response DTO
+func (s *attachmentService) toResponseDTO(attachment models.Attachment) dto.AttachmentResponseDTO {
+	return dto.AttachmentResponseDTO{
+		ID:          attachment.ID,
+		ExpenseID:   attachment.ExpenseID,
+		FileName:    attachment.FileName,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		SHA256:      attachment.SHA256,
+		CreatedAt:   attachment.CreatedAt,
+	}
+}
+
+// sanitizeFileName keeps only the base name of an uploaded file, without control characters
+func sanitizeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	if len(name) > 255 {
+		name = name[len(name)-255:]
+	}
+	return name
+}
@@ -0,0 +1,287 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"sort"
+	"time"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/models"
+	"goExpenseTracker/internal/repositories"
+)
+
+// defaultAuditPageSize is how many audit entries a search returns unless asked
+const defaultAuditPageSize = 50
+
+// auditFields are the audited fields of an entity by name, with JSON-encodable values
+type auditFields map[string]interface{}
+
+// auditChange is a changed field as stored in an audit entry
+type auditChange struct {
+	Field string          `json:"field"`
+	From  json.RawMessage `json:"from"`
+	To    json.RawMessage `json:"to"`
+}
+
+type AuditService interface {
+	Record(access Access, entityType string, entityID int, action string, before, after auditFields)
+	History(access Access, entityType string, entityID int) ([]dto.AuditEntryDTO, error)
+	Search(access Access, filter dto.AuditFilterDTO) (dto.AuditLogDTO, error)
+}
+
+type auditService struct {
+	auditRepo repositories.AuditRepository
+	userRepo  repositories.UserRepository
+}
+
+func NewAuditService(auditRepo repositories.AuditRepository, userRepo repositories.UserRepository) AuditService {
+	return &auditService{
+		auditRepo: auditRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// Record appends a change to the audit trail with the fields that differ between
+// before and after; before is nil for a create and after for a delete. Updates
+// that change nothing are not recorded. The change has already been saved, so a
+// failure to record it is logged rather than returned.
+func (s *auditService) Record(access Access, entityType string, entityID int, action string, before, after auditFields) {
+	changes, err := diffAuditFields(before, after)
+	if err != nil {
+		log.Printf("audit: compare %s %d: %v", entityType, entityID, err)
+		return
+	}
+	if len(changes) == 0 && action == models.AuditActionUpdate {
+		return
+	}
+	encoded, err := json.Marshal(changes)
+	if err != nil {
+		log.Printf("audit: encode changes to %s %d: %v", entityType, entityID, err)
+		return
+	}
+
+	entry := models.AuditEntry{
+		LedgerID:   access.LedgerID,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		ActorID:    access.UserID,
+		RequestID:  access.RequestID,
+		Changes:    string(encoded),
+		CreatedAt:  time.Now().UTC(),
+	}
+	if err := s.auditRepo.Create(&entry); err != nil {
+		log.Printf("audit: record %s of %s %d by user %d: %v", action, entityType, entityID, access.UserID, err)
+	}
+}
+
+// History lists every recorded change to an expense or category, oldest first,
+// including its deletion
+func (s *auditService) History(access Access, entityType string, entityID int) ([]dto.AuditEntryDTO, error) {
+	entries, err := s.auditRepo.GetForEntity(access.LedgerID, entityType, entityID)
+	if err != nil {
+		return []dto.AuditEntryDTO{}, err
+	}
+	return s.toAuditDTOs(entries), nil
+}
+
+// Search pages through the ledger's audit trail, newest first
+func (s *auditService) Search(access Access, filter dto.AuditFilterDTO) (dto.AuditLogDTO, error) {
+	from, err := dto.ParseOptionalDate(filter.From)
+	if err != nil {
+		return dto.AuditLogDTO{}, newValidationError("from: %s", err.Error())
+	}
+	to, err := dto.ParseOptionalDate(filter.To)
+	if err != nil {
+		return dto.AuditLogDTO{}, newValidationError("to: %s", err.Error())
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return dto.AuditLogDTO{}, newValidationError("to must not be before from")
+	}
+	// Entries are timestamped, so the days run from midnight to midnight in the
+	// user's timezone, the whole of the last day included
+	if !from.IsZero() {
+		from = access.StartOfDay(from)
+	}
+	if !to.IsZero() {
+		to = access.StartOfDay(to.AddDate(0, 0, 1))
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultAuditPageSize
+	}
+
+	entries, total, err := s.auditRepo.Search(access.LedgerID, repositories.AuditFilter{
+		EntityType: filter.EntityType,
+		EntityID:   filter.EntityID,
+		Action:     filter.Action,
+		ActorID:    filter.ActorID,
+		RequestID:  filter.RequestID,
+		From:       from,
+		To:         to,
+	}, filter.Offset, limit)
+	if err != nil {
+		return dto.AuditLogDTO{}, err
+	}
+
+	return dto.AuditLogDTO{
+		Total:   total,
+		Offset:  filter.Offset,
+		Limit:   limit,
+		Entries: s.toAuditDTOs(entries),
+	}, nil
+}
+
+// Helper: Convert models → Response DTOs, looking up each actor once
+func (s *auditService) toAuditDTOs(entries []models.AuditEntry) []dto.AuditEntryDTO {
+	emails := make(map[int]string)
+	responses := make([]dto.AuditEntryDTO, 0, len(entries))
+	for _, entry := range entries {
+		email, ok := emails[entry.ActorID]
+		if !ok {
+			if user, err := s.userRepo.GetByID(uint(entry.ActorID)); err == nil {
+				email = user.Email
+			}
+			emails[entry.ActorID] = email
+		}
+
+		var stored []auditChange
+		if err := json.Unmarshal([]byte(entry.Changes), &stored); err != nil {
+			log.Printf("audit: decode changes of entry %d: %v", entry.ID, err)
+		}
+		changes := make([]dto.AuditChangeDTO, 0, len(stored))
+		for _, change := range stored {
+			changes = append(changes, dto.AuditChangeDTO{
+				Field: change.Field,
+				From:  decodeAuditValue(change.From),
+				To:    decodeAuditValue(change.To),
+			})
+		}
+
+		responses = append(responses, dto.AuditEntryDTO{
+			ID:         entry.ID,
+			EntityType: entry.EntityType,
+			EntityID:   entry.EntityID,
+			Action:     entry.Action,
+			ActorID:    entry.ActorID,
+			ActorEmail: email,
+			RequestID:  entry.RequestID,
+			CreatedAt:  entry.CreatedAt,
+			Changes:    changes,
+		})
+	}
+	return responses
+}
+
+// diffAuditFields lists the fields whose values differ, by name. Values are
+// compared in their JSON form, which is also how they are stored. A created or
+// deleted entity lists only the fields that hold something.
+func diffAuditFields(before, after auditFields) ([]auditChange, error) {
+	names := make([]string, 0, len(before)+len(after))
+	for name := range before {
+		names = append(names, name)
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := make([]auditChange, 0, len(names))
+	for _, name := range names {
+		from, err := encodeAuditValue(before, name)
+		if err != nil {
+			return nil, err
+		}
+		to, err := encodeAuditValue(after, name)
+		if err != nil {
+			return nil, err
+		}
+		if (before == nil && emptyAuditValue(to)) || (after == nil && emptyAuditValue(from)) {
+			continue
+		}
+		if !bytes.Equal(from, to) {
+			changes = append(changes, auditChange{Field: name, From: from, To: to})
+		}
+	}
+	return changes, nil
+}
+
+// encodeAuditValue is the JSON of a field, null when the entity or field is absent
+func encodeAuditValue(fields auditFields, name string) (json.RawMessage, error) {
+	value, ok := fields[name]
+	if !ok {
+		return json.RawMessage("null"), nil
+	}
+	return json.Marshal(value)
+}
+
+// emptyAuditValue reports whether a JSON value holds nothing: null, "" or an empty list
+func emptyAuditValue(raw json.RawMessage) bool {
+	switch string(raw) {
+	case "null", `""`, "[]", "{}":
+		return true
+	}
+	return false
+}
+
+func decodeAuditValue(raw json.RawMessage) interface{} {
+	var value interface{}
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &value)
+	}
+	return value
+}
+
+// expenseAuditFields are the fields of an expense that its audit entries track
+func expenseAuditFields(expense models.Expense) auditFields {
+	tagIDs := make([]int, 0, len(expense.Tags))
+	for _, tag := range expense.Tags {
+		tagIDs = append(tagIDs, tag.ID)
+	}
+	sort.Ints(tagIDs)
+
+	splits := make([]map[string]interface{}, 0, len(expense.Splits))
+	for _, split := range expense.Splits {
+		splits = append(splits, map[string]interface{}{
+			"category_id": split.CategoryID,
+			"amount":      split.Amount,
+			"description": split.Description,
+		})
+	}
+	shares := make([]map[string]interface{}, 0, len(expense.Shares))
+	for _, share := range expense.Shares {
+		shares = append(shares, map[string]interface{}{
+			"person_id": share.PersonID,
+			"value":     share.Value,
+			"amount":    share.Amount,
+		})
+	}
+
+	return auditFields{
+		"kind":        expenseKind(expense.Kind),
+		"amount":      expense.Amount,
+		"currency":    expense.Currency,
+		"description": expense.Description,
+		"date":        expense.Date.Format("2006-01-02"),
+		"category_id": expense.CategoryID,
+		"account_id":  expense.AccountID,
+		"paid_by_id":  expense.PaidByID,
+		"share_mode":  expense.ShareMode,
+		"external_id": expense.ExternalID,
+		"tag_ids":     tagIDs,
+		"splits":      splits,
+		"shares":      shares,
+	}
+}
+
+// categoryAuditFields are the fields of a category that its audit entries track
+func categoryAuditFields(category models.Category) auditFields {
+	return auditFields{
+		"name":        category.Name,
+		"description": category.Description,
+	}
+}
@@ -0,0 +1,398 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"goExpenseTracker/config"
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/models"
+	"goExpenseTracker/internal/repositories"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// tokenIssuer names this server in the tokens it signs
+const tokenIssuer = "goExpenseTracker"
+
+// twoFactorAudience marks the challenge tokens of sign-ins waiting for a second
+// factor, which are not access tokens
+const twoFactorAudience = "two-factor"
+
+// twoFactorChallengeTTL is how long a user has to enter their second factor
+const twoFactorChallengeTTL = 5 * time.Minute
+
+// AuthOptions configures sign-up and access tokens
+type AuthOptions struct {
+	Secret      []byte        // HMAC key signing access tokens
+	TokenTTL    time.Duration // How long an access token stays valid
+	AllowSignup bool          // Whether anyone may register once the first user exists
+}
+
+// ExternalIdentity is a user as vouched for by an identity provider
+type ExternalIdentity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified *bool // Nil when the provider does not say
+	Name          string
+}
+
+// TwoFactorRequiredError answers a sign-in of a user with two-factor sign-in
+// turned on: the challenge is exchanged for an access token along with a code
+type TwoFactorRequiredError struct {
+	Challenge string
+	ExpiresAt time.Time
+}
+
+func (e *TwoFactorRequiredError) Error() string {
+	return "a two-factor code is required to finish signing in"
+}
+
+type AuthService interface {
+	Register(req dto.RegisterRequestDTO) (dto.AuthTokenDTO, error)
+	Login(req dto.LoginRequestDTO) (dto.AuthTokenDTO, error)
+	SignInExternal(identity ExternalIdentity, provision bool) (dto.AuthTokenDTO, error)
+	VerifyTwoFactor(req dto.TwoFactorLoginRequestDTO) (dto.AuthTokenDTO, error)
+	Authenticate(token string) (models.User, error)
+	Me(userID int) (dto.UserResponseDTO, error)
+	UpdateMe(userID int, req dto.UserUpdateRequestDTO) (dto.UserResponseDTO, error)
+	ClaimLegacyData(email string) error
+}
+
+type authService struct {
+	userRepo   repositories.UserRepository
+	ledgerRepo repositories.LedgerRepository
+	twoFactor  TwoFactorService
+	options    AuthOptions
+
+	// dummyHash is compared against when an email is unknown, so a failed sign-in
+	// takes as long whether or not the account exists
+	dummyHash []byte
+}
+
+func NewAuthService(userRepo repositories.UserRepository, ledgerRepo repositories.LedgerRepository, twoFactor TwoFactorService, options AuthOptions) AuthService {
+	if options.TokenTTL <= 0 {
+		options.TokenTTL = config.DefaultTokenTTL
+	}
+	dummyHash, _ := bcrypt.GenerateFromPassword([]byte("not a real password"), bcrypt.DefaultCost)
+	return &authService{
+		userRepo:   userRepo,
+		ledgerRepo: ledgerRepo,
+		twoFactor:  twoFactor,
+		options:    options,
+		dummyHash:  dummyHash,
+	}
+}
+
+// Register creates a user with a password and signs them in
+func (s *authService) Register(req dto.RegisterRequestDTO) (dto.AuthTokenDTO, error) {
+	email := normalizeEmail(req.Email)
+	if len(req.Password) > 72 {
+		return dto.AuthTokenDTO{}, newValidationError("password must not exceed 72 bytes")
+	}
+
+	if _, err := s.userRepo.GetByEmail(email); err == nil {
+		return dto.AuthTokenDTO{}, newValidationError("an account with this email already exists")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return dto.AuthTokenDTO{}, err
+	}
+	user, err := s.createUser(email, req.Name, string(hash), s.options.AllowSignup)
+	if err != nil {
+		return dto.AuthTokenDTO{}, err
+	}
+	return s.issueToken(user)
+}
+
+// Login checks an email and password and issues an access token, or a challenge
+// when the user also signs in with a second factor
+func (s *authService) Login(req dto.LoginRequestDTO) (dto.AuthTokenDTO, error) {
+	user, err := s.userRepo.GetByEmail(normalizeEmail(req.Email))
+	if err != nil || user.PasswordHash == "" {
+		_ = bcrypt.CompareHashAndPassword(s.dummyHash, []byte(req.Password))
+		return dto.AuthTokenDTO{}, &AuthError{Message: "invalid email or password"}
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		return dto.AuthTokenDTO{}, &AuthError{Message: "invalid email or password"}
+	}
+	return s.signIn(*user)
+}
+
+// SignInExternal signs in a user vouched for by an identity provider. Known
+// identities sign in straight away. Otherwise the identity is linked to the user
+// with the same email address, provided the provider verified it, or when
+// provision is set a new user is created for it.
+func (s *authService) SignInExternal(identity ExternalIdentity, provision bool) (dto.AuthTokenDTO, error) {
+	email := normalizeEmail(identity.Email)
+	if linked, err := s.userRepo.GetIdentity(identity.Issuer, identity.Subject); err == nil {
+		user, err := s.userRepo.GetByID(uint(linked.UserID))
+		if err != nil {
+			return dto.AuthTokenDTO{}, &AuthError{Message: "the account linked to this identity no longer exists"}
+		}
+		linked.Email = email
+		linked.LastLoginAt = time.Now()
+		if err := s.userRepo.UpdateIdentity(linked); err != nil {
+			return dto.AuthTokenDTO{}, err
+		}
+		return s.signIn(*user)
+	}
+
+	if email == "" {
+		return dto.AuthTokenDTO{}, &AuthError{Message: "the identity provider did not share an email address"}
+	}
+	user, err := s.userRepo.GetByEmail(email)
+	if err == nil {
+		// Linking takes over an existing account, so the address must be proven
+		if identity.EmailVerified == nil || !*identity.EmailVerified {
+			return dto.AuthTokenDTO{}, &AuthError{Message: "an account with this email already exists; the identity provider must verify the address to sign in to it"}
+		}
+	} else {
+		if !provision {
+			return dto.AuthTokenDTO{}, &ForbiddenError{Message: "no account exists for this email address"}
+		}
+		if identity.EmailVerified != nil && !*identity.EmailVerified {
+			return dto.AuthTokenDTO{}, &AuthError{Message: "the identity provider has not verified this email address"}
+		}
+		created, err := s.createUser(email, identity.Name, "", true)
+		if err != nil {
+			return dto.AuthTokenDTO{}, err
+		}
+		user = &created
+	}
+
+	err = s.userRepo.CreateIdentity(&models.UserIdentity{
+		UserID:      user.ID,
+		Issuer:      identity.Issuer,
+		Subject:     identity.Subject,
+		Email:       email,
+		CreatedAt:   time.Now(),
+		LastLoginAt: time.Now(),
+	})
+	if err != nil {
+		return dto.AuthTokenDTO{}, err
+	}
+	return s.signIn(*user)
+}
+
+// VerifyTwoFactor finishes a sign-in that asked for a second factor, exchanging
+// its challenge and a TOTP or recovery code for an access token
+func (s *authService) VerifyTwoFactor(req dto.TwoFactorLoginRequestDTO) (dto.AuthTokenDTO, error) {
+	var claims jwt.RegisteredClaims
+	_, err := jwt.ParseWithClaims(req.Challenge, &claims, func(*jwt.Token) (interface{}, error) {
+		return s.options.Secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(tokenIssuer), jwt.WithAudience(twoFactorAudience), jwt.WithExpirationRequired())
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return dto.AuthTokenDTO{}, &AuthError{Message: "sign-in expired; start again"}
+	}
+	if err != nil {
+		return dto.AuthTokenDTO{}, &AuthError{Message: "invalid challenge"}
+	}
+	userID, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return dto.AuthTokenDTO{}, &AuthError{Message: "invalid challenge"}
+	}
+	user, err := s.userRepo.GetByID(uint(userID))
+	if err != nil {
+		return dto.AuthTokenDTO{}, &AuthError{Message: "invalid challenge"}
+	}
+
+	if err := s.twoFactor.Check(user.ID, req.Code); err != nil {
+		return dto.AuthTokenDTO{}, err
+	}
+	return s.issueToken(*user)
+}
+
+// Authenticate verifies an access token and returns the user it was issued to
+func (s *authService) Authenticate(token string) (models.User, error) {
+	var claims jwt.RegisteredClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return s.options.Secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(tokenIssuer), jwt.WithExpirationRequired())
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return models.User{}, &AuthError{Message: "token has expired"}
+	}
+	// Tokens meant for something else, such as two-factor challenges, carry an audience
+	if err != nil || len(claims.Audience) > 0 {
+		return models.User{}, &AuthError{Message: "invalid token"}
+	}
+
+	userID, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return models.User{}, &AuthError{Message: "invalid token"}
+	}
+	// Tokens of deleted users stop working straight away
+	user, err := s.userRepo.GetByID(uint(userID))
+	if err != nil {
+		return models.User{}, &AuthError{Message: "invalid token"}
+	}
+	return *user, nil
+}
+
+// Me returns the signed-in user
+func (s *authService) Me(userID int) (dto.UserResponseDTO, error) {
+	user, err := s.userRepo.GetByID(uint(userID))
+	if err != nil {
+		return dto.UserResponseDTO{}, fmt.Errorf("user not found")
+	}
+	return toUserDTO(*user), nil
+}
+
+// UpdateMe changes the signed-in user's settings
+func (s *authService) UpdateMe(userID int, req dto.UserUpdateRequestDTO) (dto.UserResponseDTO, error) {
+	user, err := s.userRepo.GetByID(uint(userID))
+	if err != nil {
+		return dto.UserResponseDTO{}, fmt.Errorf("user not found")
+	}
+
+	if req.Timezone != nil {
+		timezone := strings.TrimSpace(*req.Timezone)
+		// time.LoadLocation takes "Local" for the server's own zone, which is not a place
+		if _, err := time.LoadLocation(timezone); err != nil || timezone == "Local" {
+			return dto.UserResponseDTO{}, newValidationError("timezone: unknown timezone %q, expected an IANA name such as Europe/Berlin", timezone)
+		}
+		user.Timezone = timezone
+	}
+
+	user.UpdatedAt = time.Now()
+	if err := s.userRepo.Update(user); err != nil {
+		return dto.UserResponseDTO{}, err
+	}
+	return toUserDTO(*user), nil
+}
+
+// ClaimLegacyData hands the data recorded before user accounts existed to the
+// user with the given email, moving it into their personal ledger, and makes
+// them the admin unless the instance already has one. It is run by the claim
+// command rather than happening on sign-up, so that nobody takes the data over
+// just by registering first.
+func (s *authService) ClaimLegacyData(email string) error {
+	user, err := s.userRepo.GetByEmail(normalizeEmail(email))
+	if err != nil {
+		return fmt.Errorf("no account exists for %s", email)
+	}
+	personal, err := s.ledgerRepo.GetPersonal(user.ID)
+	if err != nil {
+		return err
+	}
+	return s.ledgerRepo.ClaimInstance(user.ID, personal.ID)
+}
+
+// Helper: Create a user with their personal ledger. The first user becomes the
+// admin, unless the instance holds data from before user accounts existed,
+// which waits for the claim command. Users beyond the first are only created
+// when open is set.
+func (s *authService) createUser(email, name, passwordHash string, open bool) (models.User, error) {
+	for attempt := 0; ; attempt++ {
+		count, err := s.userRepo.Count()
+		if err != nil {
+			return models.User{}, err
+		}
+		if count > 0 && !open {
+			return models.User{}, &ForbiddenError{Message: "sign-up is closed on this server"}
+		}
+		first := count == 0
+		if first {
+			unowned, err := s.ledgerRepo.HasUnowned()
+			if err != nil {
+				return models.User{}, err
+			}
+			first = !unowned
+		}
+
+		user := models.User{
+			Email:        email,
+			Name:         strings.TrimSpace(name),
+			PasswordHash: passwordHash,
+			IsAdmin:      first,
+			CreatedAt:    time.Now(),
+			UpdatedAt:    time.Now(),
+		}
+		personal := models.Ledger{
+			Name:      models.PersonalLedgerName,
+			Personal:  true,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		err = s.userRepo.CreateWithLedger(&user, &personal)
+		// The database allows a single admin, so when two first sign-ups race one
+		// of them fails; it tries again as an ordinary user
+		if err != nil && first && attempt == 0 {
+			continue
+		}
+		return user, err
+	}
+}
+
+// Helper: Sign the user in, unless they must also give a second factor, in which
+// case a challenge for it is returned as a TwoFactorRequiredError
+func (s *authService) signIn(user models.User) (dto.AuthTokenDTO, error) {
+	enabled, err := s.twoFactor.Enabled(user.ID)
+	if err != nil {
+		return dto.AuthTokenDTO{}, err
+	}
+	if !enabled {
+		return s.issueToken(user)
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(twoFactorChallengeTTL)
+	claims := jwt.RegisteredClaims{
+		Issuer:    tokenIssuer,
+		Subject:   strconv.Itoa(user.ID),
+		Audience:  jwt.ClaimStrings{twoFactorAudience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}
+	challenge, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.options.Secret)
+	if err != nil {
+		return dto.AuthTokenDTO{}, err
+	}
+	return dto.AuthTokenDTO{}, &TwoFactorRequiredError{Challenge: challenge, ExpiresAt: expiresAt.UTC().Truncate(time.Second)}
+}
+
+// Helper: Sign an access token for the user
+func (s *authService) issueToken(user models.User) (dto.AuthTokenDTO, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.options.TokenTTL)
+	claims := jwt.RegisteredClaims{
+		Issuer:    tokenIssuer,
+		Subject:   strconv.Itoa(user.ID),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.options.Secret)
+	if err != nil {
+		return dto.AuthTokenDTO{}, err
+	}
+
+	return dto.AuthTokenDTO{
+		Token:     signed,
+		TokenType: "Bearer",
+		ExpiresAt: expiresAt.UTC().Truncate(time.Second),
+		User:      toUserDTO(user),
+	}, nil
+}
+
+// Helper: Email addresses are compared without case or surrounding spaces
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Helper: Convert model → Response DTO
+func toUserDTO(user models.User) dto.UserResponseDTO {
+	return dto.UserResponseDTO{
+		ID:        user.ID,
+		Email:     user.Email,
+		Name:      user.Name,
+		IsAdmin:   user.IsAdmin,
+		Timezone:  user.Timezone,
+		CreatedAt: user.CreatedAt,
+	}
+}
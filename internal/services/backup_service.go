@@ -0,0 +1,386 @@
+package services
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/repositories"
+	"goExpenseTracker/internal/storage"
+)
+
+// Backup archive layout. The format version changes when the archive layout does;
+// the schema version changes when a table or column is added, renamed or removed.
+const (
+	backupFormat        = "goexpensetracker-backup"
+	backupFormatVersion = 1
+	backupSchemaVersion = 11
+	backupManifestFile  = "manifest.json"
+	backupTablesDir     = "tables/"
+	backupBlobsDir      = "attachments/"
+	restoreBatchSize    = 500
+)
+
+// backupTables lists every table a backup covers, parents before the tables that
+// reference them so a restore never inserts a row before what it points to.
+// New tables must be added here (and the schema version raised).
+var backupTables = []string{
+	"users",
+	"user_identities",
+	"two_factors",
+	"recovery_codes",
+	"ledgers",
+	"ledger_members",
+	"ledger_invites",
+	"api_keys",
+	"categories",
+	"accounts",
+	"people",
+	"tags",
+	"rules",
+	"rule_tags",
+	"expenses",
+	"expense_splits",
+	"expense_shares",
+	"expense_tags",
+	"duplicate_dismissals",
+	"transfers",
+	"settlements",
+	"attachments",
+	"audit_entries",
+}
+
+// backupManifest describes the contents of an archive
+type backupManifest struct {
+	Format        string               `json:"format"`
+	FormatVersion int                  `json:"format_version"`
+	SchemaVersion int                  `json:"schema_version"`
+	CreatedAt     time.Time            `json:"created_at"`
+	Tables        []backupTableSummary `json:"tables"`
+	Attachments   int                  `json:"attachments"`
+}
+
+type backupTableSummary struct {
+	Name string `json:"name"`
+	Rows int    `json:"rows"`
+}
+
+type BackupService interface {
+	Backup(userID int, w io.Writer) error
+	Restore(userID int, r io.ReaderAt, size int64, conflict string) (dto.RestoreResultDTO, error)
+}
+
+// backupService backs up and restores the whole instance, every user's data
+// included, so only admins may use it
+type backupService struct {
+	backupRepo  repositories.BackupRepository
+	userRepo    repositories.UserRepository
+	ledgerRepo  repositories.LedgerRepository
+	store       storage.Storage
+	suggestions SuggestionService
+}
+
+func NewBackupService(backupRepo repositories.BackupRepository, userRepo repositories.UserRepository, ledgerRepo repositories.LedgerRepository, store storage.Storage, suggestions SuggestionService) BackupService {
+	return &backupService{
+		backupRepo:  backupRepo,
+		userRepo:    userRepo,
+		ledgerRepo:  ledgerRepo,
+		store:       store,
+		suggestions: suggestions,
+	}
+}
+
+// Backup writes a zip archive with one NDJSON file per table, the receipt files
+// and a manifest. Rows are streamed, so the ledger never has to fit in memory.
+func (s *backupService) Backup(userID int, w io.Writer) error {
+	if err := s.requireAdmin(userID); err != nil {
+		return err
+	}
+
+	archive := zip.NewWriter(w)
+	manifest := backupManifest{
+		Format:        backupFormat,
+		FormatVersion: backupFormatVersion,
+		SchemaVersion: backupSchemaVersion,
+		CreatedAt:     time.Now().UTC(),
+	}
+
+	// Every table is read in one transaction, so rows refer to rows in the backup
+	var blobKeys []string
+	err := s.backupRepo.Snapshot(func(reader repositories.TableReader) error {
+		for _, table := range backupTables {
+			file, err := archive.Create(backupTablesDir + table + ".ndjson")
+			if err != nil {
+				return err
+			}
+			out := bufio.NewWriter(file)
+			encoder := json.NewEncoder(out)
+
+			count := 0
+			err = reader.DumpTable(table, func(row map[string]interface{}) error {
+				count++
+				if table == "attachments" {
+					if key, ok := row["storage_key"].(string); ok && key != "" {
+						blobKeys = append(blobKeys, key)
+					}
+				}
+				return encoder.Encode(row)
+			})
+			if err != nil {
+				return fmt.Errorf("back up %s: %w", table, err)
+			}
+			if err := out.Flush(); err != nil {
+				return err
+			}
+			manifest.Tables = append(manifest.Tables, backupTableSummary{Name: table, Rows: count})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, key := range blobKeys {
+		blob, err := s.store.Get(context.Background(), key)
+		if errors.Is(err, storage.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("back up attachment %s: %w", key, err)
+		}
+		file, err := archive.Create(backupBlobsDir + key)
+		if err == nil {
+			_, err = io.Copy(file, blob)
+		}
+		blob.Close()
+		if err != nil {
+			return err
+		}
+		manifest.Attachments++
+	}
+
+	// The manifest goes last, once the counts are known
+	file, err := archive.Create(backupManifestFile)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// Restore loads an archive made by Backup in a single transaction, keeping IDs and
+// timestamps. Rows whose primary key already exists are skipped, overwritten or
+// fail the whole restore depending on the conflict policy.
+func (s *backupService) Restore(userID int, r io.ReaderAt, size int64, conflict string) (dto.RestoreResultDTO, error) {
+	if err := s.requireAdmin(userID); err != nil {
+		return dto.RestoreResultDTO{}, err
+	}
+
+	switch conflict {
+	case "":
+		conflict = repositories.ConflictFail
+	case repositories.ConflictSkip, repositories.ConflictOverwrite, repositories.ConflictFail:
+	default:
+		return dto.RestoreResultDTO{}, newValidationError("conflict must be one of: skip, overwrite, fail")
+	}
+
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return dto.RestoreResultDTO{}, newValidationError("not a backup archive: %s", err.Error())
+	}
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	manifest, err := readManifest(files[backupManifestFile])
+	if err != nil {
+		return dto.RestoreResultDTO{}, err
+	}
+
+	known := make(map[string]bool, len(backupTables))
+	for _, table := range backupTables {
+		known[table] = true
+	}
+	for _, table := range manifest.Tables {
+		if !known[table.Name] {
+			return dto.RestoreResultDTO{}, newValidationError("backup contains unknown table %s", table.Name)
+		}
+	}
+
+	result := dto.RestoreResultDTO{
+		SchemaVersion: manifest.SchemaVersion,
+		CreatedAt:     manifest.CreatedAt,
+		Conflict:      conflict,
+		Tables:        make([]dto.RestoreTableResultDTO, 0, len(backupTables)),
+	}
+
+	err = s.backupRepo.Restore(func(w repositories.TableWriter) error {
+		for _, table := range backupTables {
+			file, ok := files[backupTablesDir+table+".ndjson"]
+			if !ok {
+				continue
+			}
+			tableResult, err := s.restoreTable(w, table, file, conflict, files, &result)
+			if err != nil {
+				return err
+			}
+			result.Tables = append(result.Tables, tableResult)
+		}
+		return nil
+	})
+	if err != nil {
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			return dto.RestoreResultDTO{}, err
+		}
+		return dto.RestoreResultDTO{}, fmt.Errorf("restore rolled back: %w", err)
+	}
+
+	// Backups made before ledgers existed keep their rows with the users who
+	// recorded them, and those made before user accounts existed have no owners;
+	// their rows go to the personal ledger of the admin restoring them
+	if err := s.ledgerRepo.AdoptUserData(); err != nil {
+		return dto.RestoreResultDTO{}, err
+	}
+	personal, err := s.ledgerRepo.GetPersonal(userID)
+	if err != nil {
+		return dto.RestoreResultDTO{}, err
+	}
+	if err := s.ledgerRepo.ClaimUnowned(personal.ID); err != nil {
+		return dto.RestoreResultDTO{}, err
+	}
+
+	// Restored expenses bypass the expense service, so relearn the category suggestions
+	s.suggestions.Reset()
+	return result, nil
+}
+
+// Helper: Only admins may back up or restore the instance
+func (s *backupService) requireAdmin(userID int) error {
+	user, err := s.userRepo.GetByID(uint(userID))
+	if err != nil || !user.IsAdmin {
+		return &ForbiddenError{Message: "only an admin can back up or restore the instance"}
+	}
+	return nil
+}
+
+// restoreTable inserts the rows of one table in batches and puts back the receipt
+// files of restored attachments
+func (s *backupService) restoreTable(w repositories.TableWriter, table string, file *zip.File, conflict string, files map[string]*zip.File, result *dto.RestoreResultDTO) (dto.RestoreTableResultDTO, error) {
+	tableResult := dto.RestoreTableResultDTO{Name: table}
+
+	reader, err := file.Open()
+	if err != nil {
+		return tableResult, err
+	}
+	defer reader.Close()
+
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+
+	batch := make([]map[string]interface{}, 0, restoreBatchSize)
+	flush := func() error {
+		written, err := w.Write(table, batch, conflict)
+		if err != nil {
+			if conflict == repositories.ConflictFail {
+				return newValidationError("restoring %s failed, possibly on an existing row (use conflict=skip or overwrite): %s", table, err.Error())
+			}
+			return fmt.Errorf("restore %s: %w", table, err)
+		}
+		tableResult.Written += written
+		batch = batch[:0]
+		return nil
+	}
+
+	for {
+		var row map[string]interface{}
+		if err := decoder.Decode(&row); err == io.EOF {
+			break
+		} else if err != nil {
+			return tableResult, newValidationError("%s: invalid row %d: %s", table, tableResult.Rows+1, err.Error())
+		}
+		tableResult.Rows++
+
+		if table == "attachments" {
+			if err := s.restoreBlob(row, files, result); err != nil {
+				return tableResult, err
+			}
+		}
+
+		batch = append(batch, row)
+		if len(batch) == restoreBatchSize {
+			if err := flush(); err != nil {
+				return tableResult, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return tableResult, err
+	}
+	return tableResult, nil
+}
+
+// restoreBlob writes the receipt file of an attachment row back to storage. Keys
+// are content hashes, so writing a file that is already there changes nothing.
+func (s *backupService) restoreBlob(row map[string]interface{}, files map[string]*zip.File, result *dto.RestoreResultDTO) error {
+	key, _ := row["storage_key"].(string)
+	file, ok := files[backupBlobsDir+key]
+	if key == "" || !ok {
+		return nil
+	}
+
+	contentType, _ := row["content_type"].(string)
+	blob, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer blob.Close()
+
+	if err := s.store.Put(context.Background(), key, blob, int64(file.UncompressedSize64), contentType); err != nil {
+		return fmt.Errorf("restore attachment %s: %w", key, err)
+	}
+	result.Attachments++
+	return nil
+}
+
+// readManifest reads the manifest and checks the archive can be restored here
+func readManifest(file *zip.File) (backupManifest, error) {
+	var manifest backupManifest
+	if file == nil {
+		return manifest, newValidationError("not a backup archive: %s is missing", backupManifestFile)
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return manifest, err
+	}
+	defer reader.Close()
+
+	if err := json.NewDecoder(reader).Decode(&manifest); err != nil {
+		return manifest, newValidationError("invalid %s: %s", backupManifestFile, err.Error())
+	}
+	if manifest.Format != backupFormat {
+		return manifest, newValidationError("not a backup archive: unexpected format %q", manifest.Format)
+	}
+	if manifest.FormatVersion != backupFormatVersion {
+		return manifest, newValidationError("unsupported backup format version %d (expected %d)", manifest.FormatVersion, backupFormatVersion)
+	}
+	if manifest.SchemaVersion > backupSchemaVersion {
+		return manifest, newValidationError("backup schema version %d is newer than this server's %d; upgrade the server first", manifest.SchemaVersion, backupSchemaVersion)
+	}
+	if manifest.SchemaVersion < 1 {
+		return manifest, newValidationError("invalid backup schema version %d", manifest.SchemaVersion)
+	}
+	return manifest, nil
+}
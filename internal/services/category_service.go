@@ -8,24 +8,30 @@ import (
 )
 
 type CategoryService interface {
-	Create(req dto.CategoryRequestDTO) (dto.CategoryResponseDTO, error)
-	GetAll(offset, limit int, nameFilter string) ([]dto.CategoryResponseDTO, error)
-	GetByID(id int) (dto.CategoryResponseDTO, error)
-	Update(id int, req dto.CategoryRequestDTO) (dto.CategoryResponseDTO, error)
-	Delete(id int) error
+	Create(access Access, req dto.CategoryRequestDTO) (dto.CategoryResponseDTO, error)
+	GetAll(access Access, offset, limit int, nameFilter string) ([]dto.CategoryResponseDTO, error)
+	GetByID(access Access, id int) (dto.CategoryResponseDTO, error)
+	Update(access Access, id int, req dto.CategoryRequestDTO) (dto.CategoryResponseDTO, error)
+	Delete(access Access, id int) error
 }
 
 type categoryService struct {
-	repo repositories.CategoryRepository
+	repo  repositories.CategoryRepository
+	audit AuditService
 }
 
-func NewCategoryService(repo repositories.CategoryRepository) CategoryService {
-	return &categoryService{repo: repo}
+func NewCategoryService(repo repositories.CategoryRepository, audit AuditService) CategoryService {
+	return &categoryService{repo: repo, audit: audit}
 }
 
 // Create category
-func (s *categoryService) Create(req dto.CategoryRequestDTO) (dto.CategoryResponseDTO, error) {
+func (s *categoryService) Create(access Access, req dto.CategoryRequestDTO) (dto.CategoryResponseDTO, error) {
+	if err := requireEditor(access); err != nil {
+		return dto.CategoryResponseDTO{}, err
+	}
 	category := models.Category{
+		UserID:      access.UserID,
+		LedgerID:    access.LedgerID,
 		Name:        req.Name,
 		Description: req.Description,
 		CreatedAt:   time.Now(),
@@ -37,13 +43,14 @@ func (s *categoryService) Create(req dto.CategoryRequestDTO) (dto.CategoryRespon
 	if err != nil {
 		return dto.CategoryResponseDTO{}, err
 	}
+	s.audit.Record(access, models.AuditEntityCategory, category.ID, models.AuditActionCreate, nil, categoryAuditFields(category))
 
 	return s.toResponseDTO(category), nil
 }
 
 // Get all categories
-func (s *categoryService) GetAll(offset, limit int, nameFilter string) ([]dto.CategoryResponseDTO, error) {
-	categories, err := s.repo.GetAll(offset, limit, nameFilter)
+func (s *categoryService) GetAll(access Access, offset, limit int, nameFilter string) ([]dto.CategoryResponseDTO, error) {
+	categories, err := s.repo.GetAll(access.LedgerID, offset, limit, nameFilter)
 	if err != nil {
 		return []dto.CategoryResponseDTO{}, err
 	}
@@ -56,8 +63,8 @@ func (s *categoryService) GetAll(offset, limit int, nameFilter string) ([]dto.Ca
 }
 
 // Get single category
-func (s *categoryService) GetByID(id int) (dto.CategoryResponseDTO, error) {
-	categoryPtr, err := s.repo.GetByID(uint(id))
+func (s *categoryService) GetByID(access Access, id int) (dto.CategoryResponseDTO, error) {
+	categoryPtr, err := s.repo.GetByID(access.LedgerID, uint(id))
 	if err != nil {
 		return dto.CategoryResponseDTO{}, err
 	}
@@ -65,11 +72,15 @@ func (s *categoryService) GetByID(id int) (dto.CategoryResponseDTO, error) {
 }
 
 // Update category
-func (s *categoryService) Update(id int, req dto.CategoryRequestDTO) (dto.CategoryResponseDTO, error) {
-	existing, err := s.repo.GetByID(uint(id))
+func (s *categoryService) Update(access Access, id int, req dto.CategoryRequestDTO) (dto.CategoryResponseDTO, error) {
+	if err := requireEditor(access); err != nil {
+		return dto.CategoryResponseDTO{}, err
+	}
+	existing, err := s.repo.GetByID(access.LedgerID, uint(id))
 	if err != nil {
 		return dto.CategoryResponseDTO{}, err
 	}
+	before := categoryAuditFields(*existing)
 
 	existing.Name = req.Name
 	existing.Description = req.Description
@@ -79,13 +90,27 @@ func (s *categoryService) Update(id int, req dto.CategoryRequestDTO) (dto.Catego
 	if err != nil {
 		return dto.CategoryResponseDTO{}, err
 	}
+	s.audit.Record(access, models.AuditEntityCategory, existing.ID, models.AuditActionUpdate, before, categoryAuditFields(*existing))
 
 	return s.toResponseDTO(*existing), nil
 }
 
 // Delete category
-func (s *categoryService) Delete(id int) error {
-	return s.repo.Delete(uint(id))
+func (s *categoryService) Delete(access Access, id int) error {
+	if err := requireEditor(access); err != nil {
+		return err
+	}
+	existing, err := s.repo.GetByID(access.LedgerID, uint(id))
+	if err != nil {
+		existing = nil
+	}
+	if err := s.repo.Delete(access.LedgerID, uint(id)); err != nil {
+		return err
+	}
+	if existing != nil {
+		s.audit.Record(access, models.AuditEntityCategory, id, models.AuditActionDelete, categoryAuditFields(*existing), nil)
+	}
+	return nil
 }
 
 // Private helper for mapping model → DTO
@@ -0,0 +1,347 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"goExpenseTracker/config"
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/models"
+	"goExpenseTracker/internal/repositories"
+)
+
+// duplicateMinSimilarity is the share of description words two expenses must have
+// in common to be taken for duplicates
+const duplicateMinSimilarity = 0.5
+
+// duplicateScanBatchSize is how many expenses are read at a time when looking for clusters
+const duplicateScanBatchSize = 500
+
+// DuplicateOptions configures duplicate detection
+type DuplicateOptions struct {
+	Policy     string
+	WindowDays int
+}
+
+// DuplicateError rejects an expense that looks like one already recorded
+type DuplicateError struct {
+	Duplicates []dto.DuplicateExpenseDTO
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("looks like a duplicate of %s; set allow_duplicate to save it anyway", describeDuplicates(e.Duplicates))
+}
+
+// describeDuplicates names the duplicates for a message, as "expense 4" or "expenses 4, 9"
+func describeDuplicates(duplicates []dto.DuplicateExpenseDTO) string {
+	ids := make([]string, 0, len(duplicates))
+	for _, duplicate := range duplicates {
+		ids = append(ids, strconv.Itoa(duplicate.ExpenseID))
+	}
+	if len(ids) == 1 {
+		return "expense " + ids[0]
+	}
+	return "expenses " + strings.Join(ids, ", ")
+}
+
+type DuplicateService interface {
+	Policy() string
+	Find(expense models.Expense) ([]dto.DuplicateExpenseDTO, error)
+	Matches(expense models.Expense, other models.Expense) bool
+	Clusters(access Access, filter dto.DuplicateFilterDTO) (dto.DuplicateClustersDTO, error)
+	Dismiss(access Access, keepID int, duplicateIDs []int) error
+}
+
+type duplicateService struct {
+	expenseRepo   repositories.ExpenseRepository
+	duplicateRepo repositories.DuplicateRepository
+	categoryRepo  repositories.CategoryRepository
+	options       DuplicateOptions
+}
+
+func NewDuplicateService(expenseRepo repositories.ExpenseRepository, duplicateRepo repositories.DuplicateRepository, categoryRepo repositories.CategoryRepository, options DuplicateOptions) DuplicateService {
+	if options.Policy == "" {
+		options.Policy = config.DuplicatePolicyWarn
+	}
+	return &duplicateService{
+		expenseRepo:   expenseRepo,
+		duplicateRepo: duplicateRepo,
+		categoryRepo:  categoryRepo,
+		options:       options,
+	}
+}
+
+// Policy reports how likely duplicates of a new expense are handled
+func (s *duplicateService) Policy() string {
+	return s.options.Policy
+}
+
+// Find lists the stored expenses of the same ledger that look like duplicates of the
+// given one, most similar first. Duplicates share the kind and amount, are dated at
+// most the window apart and have similar descriptions; expenses paid from different
+// accounts or carrying different bank references are never duplicates.
+func (s *duplicateService) Find(expense models.Expense) ([]dto.DuplicateExpenseDTO, error) {
+	day := civilDay(expense.Date)
+	window := s.options.WindowDays
+	from := day.AddDate(0, 0, -window)
+	to := day.AddDate(0, 0, window+1).Add(-time.Nanosecond)
+
+	candidates, err := s.expenseRepo.FindByAmount(expense.LedgerID, expense.Amount, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := newDuplicateEntry(expense)
+	names := make(map[int]string)
+	duplicates := make([]dto.DuplicateExpenseDTO, 0)
+	for _, candidate := range candidates {
+		if expense.ID != 0 && candidate.ID == expense.ID {
+			continue
+		}
+		entry := newDuplicateEntry(candidate)
+		similarity, ok := subject.duplicates(entry)
+		if !ok {
+			continue
+		}
+		duplicate := s.toDuplicateDTO(candidate, names)
+		duplicate.Similarity = math.Round(similarity*100) / 100
+		duplicates = append(duplicates, duplicate)
+	}
+
+	sort.SliceStable(duplicates, func(i, j int) bool {
+		return duplicates[i].Similarity > duplicates[j].Similarity
+	})
+	return duplicates, nil
+}
+
+// Matches reports whether two expenses, saved or not, look like duplicates of each
+// other by the rules Find applies
+func (s *duplicateService) Matches(expense models.Expense, other models.Expense) bool {
+	days := civilDay(expense.Date).Sub(civilDay(other.Date)).Hours() / 24
+	if math.Abs(days) > float64(s.options.WindowDays) {
+		return false
+	}
+	_, ok := newDuplicateEntry(expense).duplicates(newDuplicateEntry(other))
+	return ok
+}
+
+// Clusters groups the expenses matching the filter that look like duplicates of one
+// another, leaving out pairs dismissed as genuine. Two expenses land in the same
+// cluster when they duplicate each other directly or through other members.
+func (s *duplicateService) Clusters(access Access, filter dto.DuplicateFilterDTO) (dto.DuplicateClustersDTO, error) {
+	expenseFilter, err := toExpenseFilter(filter.ExpenseFilterDTO)
+	if err != nil {
+		return dto.DuplicateClustersDTO{}, err
+	}
+	window := s.options.WindowDays
+	if filter.WindowDays != nil {
+		window = *filter.WindowDays
+	}
+
+	dismissals, err := s.duplicateRepo.GetDismissed(access.LedgerID)
+	if err != nil {
+		return dto.DuplicateClustersDTO{}, err
+	}
+	dismissed := make(map[[2]int]bool, len(dismissals))
+	for _, dismissal := range dismissals {
+		dismissed[[2]int{dismissal.ExpenseID, dismissal.OtherID}] = true
+	}
+
+	// Only expenses of the same kind and amount can be duplicates, so compare
+	// within those groups; each group is in date order as the expenses stream in
+	groups := make(map[string][]duplicateEntry)
+	expenses := make(map[int]models.Expense)
+	err = s.expenseRepo.Stream(access.LedgerID, expenseFilter, duplicateScanBatchSize, func(batch []models.Expense) error {
+		for _, expense := range batch {
+			key := expenseKind(expense.Kind) + ":" + strconv.FormatInt(toCents(expense.Amount), 10)
+			groups[key] = append(groups[key], newDuplicateEntry(expense))
+			expense.Splits, expense.Shares, expense.Tags = nil, nil, nil
+			expenses[expense.ID] = expense
+		}
+		return nil
+	})
+	if err != nil {
+		return dto.DuplicateClustersDTO{}, err
+	}
+
+	parents := make(map[int]int)
+	var root func(id int) int
+	root = func(id int) int {
+		parent, ok := parents[id]
+		if !ok || parent == id {
+			return id
+		}
+		parents[id] = root(parent)
+		return parents[id]
+	}
+	for _, group := range groups {
+		for i := range group {
+			for j := i + 1; j < len(group) && group[j].day-group[i].day <= int64(window); j++ {
+				first, second := orderedPair(group[i].id, group[j].id)
+				if dismissed[[2]int{first, second}] {
+					continue
+				}
+				if _, ok := group[i].duplicates(group[j]); ok {
+					for _, id := range []int{first, second} {
+						if _, ok := parents[id]; !ok {
+							parents[id] = id
+						}
+					}
+					parents[root(second)] = root(first)
+				}
+			}
+		}
+	}
+
+	members := make(map[int][]int)
+	for id := range parents {
+		members[root(id)] = append(members[root(id)], id)
+	}
+
+	names := make(map[int]string)
+	clusters := make([]dto.DuplicateClusterDTO, 0, len(members))
+	for _, ids := range members {
+		sort.Slice(ids, func(i, j int) bool {
+			a, b := expenses[ids[i]], expenses[ids[j]]
+			if !a.Date.Equal(b.Date) {
+				return a.Date.Before(b.Date)
+			}
+			return a.ID < b.ID
+		})
+		first := expenses[ids[0]]
+		cluster := dto.DuplicateClusterDTO{
+			Kind:     expenseKind(first.Kind),
+			Amount:   first.Amount,
+			Currency: first.Currency,
+			Expenses: make([]dto.DuplicateExpenseDTO, 0, len(ids)),
+		}
+		for _, id := range ids {
+			cluster.Expenses = append(cluster.Expenses, s.toDuplicateDTO(expenses[id], names))
+		}
+		clusters = append(clusters, cluster)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		a, b := clusters[i].Expenses[0], clusters[j].Expenses[0]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		return a.ExpenseID < b.ExpenseID
+	})
+
+	return dto.DuplicateClustersDTO{WindowDays: window, Clusters: clusters}, nil
+}
+
+// Dismiss records that the duplicates are genuine expenses next to the kept one
+func (s *duplicateService) Dismiss(access Access, keepID int, duplicateIDs []int) error {
+	dismissals := make([]models.DuplicateDismissal, 0, len(duplicateIDs))
+	for _, id := range duplicateIDs {
+		first, second := orderedPair(keepID, id)
+		dismissals = append(dismissals, models.DuplicateDismissal{LedgerID: access.LedgerID, UserID: access.UserID, ExpenseID: first, OtherID: second, CreatedAt: time.Now()})
+	}
+	return s.duplicateRepo.Dismiss(dismissals)
+}
+
+// Private helper for mapping model → DTO
+func (s *duplicateService) toDuplicateDTO(expense models.Expense, names map[int]string) dto.DuplicateExpenseDTO {
+	duplicate := dto.DuplicateExpenseDTO{
+		ExpenseID:   expense.ID,
+		Date:        expense.Date.Format("2006-01-02"),
+		Kind:        expenseKind(expense.Kind),
+		Amount:      expense.Amount,
+		Currency:    expense.Currency,
+		Description: expense.Description,
+		CategoryID:  expense.CategoryID,
+		ExternalID:  expense.ExternalID,
+	}
+	if expense.AccountID != nil {
+		duplicate.AccountID = *expense.AccountID
+	}
+	if name, ok := names[expense.CategoryID]; ok {
+		duplicate.CategoryName = name
+	} else if category, err := s.categoryRepo.GetByID(expense.LedgerID, uint(expense.CategoryID)); err == nil {
+		duplicate.CategoryName = category.Name
+		names[expense.CategoryID] = category.Name
+	}
+	return duplicate
+}
+
+// duplicateEntry is what duplicate detection compares of an expense
+type duplicateEntry struct {
+	id         int
+	day        int64 // Days since the Unix epoch
+	kind       string
+	cents      int64
+	currency   string
+	accountID  int
+	externalID string
+	words      map[string]bool
+}
+
+func newDuplicateEntry(expense models.Expense) duplicateEntry {
+	entry := duplicateEntry{
+		id:         expense.ID,
+		day:        civilDay(expense.Date).Unix() / 86400,
+		kind:       expenseKind(expense.Kind),
+		cents:      toCents(expense.Amount),
+		currency:   strings.ToUpper(expense.Currency),
+		externalID: expense.ExternalID,
+		words:      make(map[string]bool),
+	}
+	if expense.AccountID != nil {
+		entry.accountID = *expense.AccountID
+	}
+	for _, token := range tokenize(expense.Description) {
+		entry.words[token] = true
+	}
+	return entry
+}
+
+// duplicates reports whether two expenses look like the same one recorded twice,
+// with the share of description words they have in common. The date window is
+// left to the caller. A missing currency, account or bank reference says nothing
+// either way; only values that are present and differ tell expenses apart.
+// Descriptions without words only match each other, as letting them match any
+// description would chain unrelated expenses into one cluster.
+func (e duplicateEntry) duplicates(other duplicateEntry) (float64, bool) {
+	if e.kind != other.kind || e.cents != other.cents {
+		return 0, false
+	}
+	if e.currency != "" && other.currency != "" && e.currency != other.currency {
+		return 0, false
+	}
+	if e.accountID != 0 && other.accountID != 0 && e.accountID != other.accountID {
+		return 0, false
+	}
+	if e.externalID != "" && other.externalID != "" && e.externalID != other.externalID {
+		return 0, false
+	}
+	if len(e.words) == 0 || len(other.words) == 0 {
+		return 0, len(e.words) == len(other.words)
+	}
+
+	common := 0
+	for word := range e.words {
+		if other.words[word] {
+			common++
+		}
+	}
+	similarity := float64(common) / float64(len(e.words)+len(other.words)-common)
+	return similarity, similarity >= duplicateMinSimilarity
+}
+
+// civilDay is the start of the calendar day of t, in UTC
+func civilDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func orderedPair(a, b int) (int, int) {
+	if a > b {
+		return b, a
+	}
+	return a, b
+}
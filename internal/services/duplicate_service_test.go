@@ -0,0 +1,258 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"goExpenseTracker/config"
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/models"
+	"goExpenseTracker/internal/repositories"
+)
+
+// memoryDismissals is a DuplicateRepository over a slice, for service tests
+type memoryDismissals struct {
+	dismissals []models.DuplicateDismissal
+}
+
+func (m *memoryDismissals) Dismiss(dismissals []models.DuplicateDismissal) error {
+	m.dismissals = append(m.dismissals, dismissals...)
+	return nil
+}
+
+func (m *memoryDismissals) GetDismissed(ledgerID int) ([]models.DuplicateDismissal, error) {
+	dismissals := make([]models.DuplicateDismissal, 0)
+	for _, dismissal := range m.dismissals {
+		if dismissal.LedgerID == ledgerID {
+			dismissals = append(dismissals, dismissal)
+		}
+	}
+	return dismissals, nil
+}
+
+// recorded is an expense for a duplicate test, dated days after the first
+type recorded struct {
+	days        int
+	amount      float64
+	description string
+	kind        string
+	accountID   int
+	externalID  string
+	ledgerID    int // The test ledger when zero
+}
+
+var duplicateDay = time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+// newDuplicateTest returns the service over memory repositories holding the
+// expenses, numbered from 1 in the order given
+func newDuplicateTest(t *testing.T, expenses []recorded) (DuplicateService, *memoryDismissals) {
+	t.Helper()
+	categories := repositories.NewMemoryCategoryRepository()
+	expenseRepo := repositories.NewMemoryExpenseRepository(categories)
+	for _, r := range expenses {
+		expense := models.Expense{
+			LedgerID:    testLedger,
+			Amount:      r.amount,
+			Description: r.description,
+			Kind:        r.kind,
+			ExternalID:  r.externalID,
+			Date:        duplicateDay.AddDate(0, 0, r.days),
+		}
+		if r.ledgerID != 0 {
+			expense.LedgerID = r.ledgerID
+		}
+		if r.accountID != 0 {
+			accountID := r.accountID
+			expense.AccountID = &accountID
+		}
+		if err := expenseRepo.Create(&expense); err != nil {
+			t.Fatalf("create expense: %v", err)
+		}
+	}
+	dismissals := &memoryDismissals{}
+	service := NewDuplicateService(expenseRepo, dismissals, categories, DuplicateOptions{
+		Policy:     config.DuplicatePolicyWarn,
+		WindowDays: config.DefaultDuplicateWindowDays,
+	})
+	return service, dismissals
+}
+
+// clusterIDs lists the expense IDs of each cluster
+func clusterIDs(clusters dto.DuplicateClustersDTO) [][]int {
+	ids := make([][]int, 0, len(clusters.Clusters))
+	for _, cluster := range clusters.Clusters {
+		members := make([]int, 0, len(cluster.Expenses))
+		for _, expense := range cluster.Expenses {
+			members = append(members, expense.ExpenseID)
+		}
+		ids = append(ids, members)
+	}
+	return ids
+}
+
+func TestDuplicateClusters(t *testing.T) {
+	window := func(days int) *int { return &days }
+	cases := []struct {
+		name      string
+		expenses  []recorded
+		dismissed [][2]int
+		filter    dto.DuplicateFilterDTO
+		want      [][]int
+	}{
+		{
+			name: "nothing alike",
+			expenses: []recorded{
+				{days: 0, amount: 12, description: "Coffee beans"},
+				{days: 0, amount: 13, description: "Coffee beans"},
+				{days: 0, amount: 12, description: "Train ticket"},
+				{days: 0, amount: 12, description: "Coffee beans", kind: models.KindRefund},
+			},
+			want: [][]int{},
+		},
+		{
+			name: "members linked through others",
+			expenses: []recorded{
+				{days: 0, amount: 4.5, description: "Coffee beans shop"},
+				{days: 2, amount: 4.5, description: "coffee beans"},
+				{days: 4, amount: 4.5, description: "Coffee beans!"},
+			},
+			// The first and last are four days apart, more than the window, but
+			// both duplicate the second
+			want: [][]int{{1, 2, 3}},
+		},
+		{
+			name: "outside the window",
+			expenses: []recorded{
+				{days: 0, amount: 30, description: "Gym membership"},
+				{days: 4, amount: 30, description: "Gym membership"},
+			},
+			want: [][]int{},
+		},
+		{
+			name: "wider window from the filter",
+			expenses: []recorded{
+				{days: 0, amount: 30, description: "Gym membership"},
+				{days: 4, amount: 30, description: "Gym membership"},
+			},
+			filter: dto.DuplicateFilterDTO{WindowDays: window(5)},
+			want:   [][]int{{1, 2}},
+		},
+		{
+			name: "separate clusters in date order, unordered input",
+			expenses: []recorded{
+				{days: 10, amount: 8, description: "Lunch"},
+				{days: 1, amount: 20, description: "Fuel station"},
+				{days: 11, amount: 8, description: "Lunch"},
+				{days: 0, amount: 20, description: "Fuel station"},
+				{days: 20, amount: 8, description: "Lunch"},
+			},
+			want: [][]int{{4, 2}, {1, 3}},
+		},
+		{
+			name: "accounts and bank references tell expenses apart",
+			expenses: []recorded{
+				{days: 0, amount: 9.99, description: "Streaming", accountID: 1},
+				{days: 0, amount: 9.99, description: "Streaming", accountID: 2},
+				{days: 0, amount: 15, description: "Bookshop", externalID: "ofx:1:A"},
+				{days: 0, amount: 15, description: "Bookshop", externalID: "ofx:1:B"},
+				{days: 0, amount: 15, description: "Bookshop"},
+			},
+			// Without an account or reference an expense can match either side
+			want: [][]int{{3, 4, 5}},
+		},
+		{
+			name: "descriptions without words only match each other",
+			expenses: []recorded{
+				{days: 0, amount: 50, description: ""},
+				{days: 0, amount: 50, description: "1234"},
+				{days: 0, amount: 50, description: "Groceries"},
+			},
+			want: [][]int{{1, 2}},
+		},
+		{
+			name: "a dismissed pair stays linked through a third",
+			expenses: []recorded{
+				{days: 0, amount: 3, description: "Bus fare"},
+				{days: 0, amount: 3, description: "Bus fare"},
+				{days: 1, amount: 3, description: "Bus fare"},
+			},
+			dismissed: [][2]int{{1, 2}},
+			want:      [][]int{{1, 2, 3}},
+		},
+		{
+			name: "dismissed pairs",
+			expenses: []recorded{
+				{days: 0, amount: 3, description: "Bus fare"},
+				{days: 0, amount: 3, description: "Bus fare"},
+				{days: 1, amount: 3, description: "Bus fare"},
+			},
+			dismissed: [][2]int{{1, 2}, {1, 3}},
+			want:      [][]int{{2, 3}},
+		},
+		{
+			name: "filtered and other ledgers left out",
+			expenses: []recorded{
+				{days: 0, amount: 7, description: "Cinema"},
+				{days: 1, amount: 7, description: "Cinema"},
+				{days: 1, amount: 7, description: "Cinema", ledgerID: otherLedger},
+				{days: 2, amount: 7, description: "Cinema"},
+			},
+			filter: dto.DuplicateFilterDTO{ExpenseFilterDTO: dto.ExpenseFilterDTO{From: "2025-03-11"}},
+			want:   [][]int{{2, 4}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			service, _ := newDuplicateTest(t, c.expenses)
+			for _, pair := range c.dismissed {
+				if err := service.Dismiss(ownerAccess(), pair[1], []int{pair[0]}); err != nil {
+					t.Fatalf("dismiss: %v", err)
+				}
+			}
+			clusters, err := service.Clusters(ownerAccess(), c.filter)
+			if err != nil {
+				t.Fatalf("clusters: %v", err)
+			}
+			if got := clusterIDs(clusters); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got clusters %v, want %v", got, c.want)
+			}
+			wantWindow := config.DefaultDuplicateWindowDays
+			if c.filter.WindowDays != nil {
+				wantWindow = *c.filter.WindowDays
+			}
+			if clusters.WindowDays != wantWindow {
+				t.Errorf("got window %d, want %d", clusters.WindowDays, wantWindow)
+			}
+		})
+	}
+}
+
+func TestDuplicateFind(t *testing.T) {
+	service, _ := newDuplicateTest(t, []recorded{
+		{days: 0, amount: 25, description: "Pharmacy vitamins"},
+		{days: 2, amount: 25, description: "Pharmacy"},
+		{days: 5, amount: 25, description: "Pharmacy vitamins"},
+		{days: 1, amount: 25, description: "Hardware store"},
+	})
+	duplicates, err := service.Find(models.Expense{
+		LedgerID:    testLedger,
+		Amount:      25,
+		Description: "pharmacy vitamins",
+		Date:        duplicateDay.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	got := make([]int, 0, len(duplicates))
+	for _, duplicate := range duplicates {
+		got = append(got, duplicate.ExpenseID)
+	}
+	// Most similar first; the third is five days away
+	if want := []int{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if duplicates[0].Similarity != 1 || duplicates[1].Similarity != 0.5 {
+		t.Errorf("got similarities %v and %v, want 1 and 0.5", duplicates[0].Similarity, duplicates[1].Similarity)
+	}
+}
@@ -0,0 +1,50 @@
+package services
+
+import "fmt"
+
+// ValidationError marks errors caused by invalid input rather than a failure of the service,
+// letting handlers answer with 400 instead of 500
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func newValidationError(format string, args ...interface{}) error {
+	return &ValidationError{Message: fmt.Sprintf(format, args...)}
+}
+
+// AuthError marks failed sign-ins and unusable tokens, answered with 401
+type AuthError struct {
+	Message string
+}
+
+func (e *AuthError) Error() string {
+	return e.Message
+}
+
+// ForbiddenError marks requests the signed-in user is not allowed to make, answered with 403
+type ForbiddenError struct {
+	Message string
+}
+
+func (e *ForbiddenError) Error() string {
+	return e.Message
+}
+
+// BatchError marks the request of a batch that could not be saved, by its index,
+// so callers can point at the row it came from
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("expense %d: %s", e.Index+1, e.Err.Error())
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
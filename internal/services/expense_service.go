@@ -2,67 +2,143 @@ package services
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
+	"goExpenseTracker/config"
 	dto "goExpenseTracker/internal/DTOs"
 	"goExpenseTracker/internal/models"
 	"goExpenseTracker/internal/repositories"
 )
 
 type ExpenseService interface {
-	Create(req dto.ExpenseRequestDTO) (dto.ExpenseResponseDTO, error)
-	GetAll(offset int, limit int, descriptionFilter string, categoryID int) ([]dto.ExpenseResponseDTO, error)
-	GetByID(id int) (dto.ExpenseResponseDTO, error)
-	Update(id int, req dto.ExpenseRequestDTO) (dto.ExpenseResponseDTO, error)
-	Delete(id int) error
+	Create(access Access, req dto.ExpenseRequestDTO) (dto.ExpenseResponseDTO, error)
+	GetAll(access Access, offset int, limit int, descriptionFilter string, categoryID int) ([]dto.ExpenseResponseDTO, error)
+	GetByID(access Access, id int) (dto.ExpenseResponseDTO, error)
+	Update(access Access, id int, req dto.ExpenseRequestDTO) (dto.ExpenseResponseDTO, error)
+	Delete(access Access, id int) error
+	Check(access Access, req dto.ExpenseRequestDTO) error
+	CreateBatch(access Access, newCategories []string, reqs []dto.ExpenseRequestDTO) ([]dto.ExpenseResponseDTO, error)
+	DuplicateClusters(access Access, filter dto.DuplicateFilterDTO) (dto.DuplicateClustersDTO, error)
+	ResolveDuplicates(access Access, req dto.DuplicateResolveRequestDTO) (dto.DuplicateResolveResultDTO, error)
+	History(access Access, id int) ([]dto.AuditEntryDTO, error)
 }
 
 type expenseService struct {
 	expenseRepo  repositories.ExpenseRepository
 	categoryRepo repositories.CategoryRepository
+	accountRepo  repositories.AccountRepository
+	personRepo   repositories.PersonRepository
+	tagRepo      repositories.TagRepository
+	rules        RuleService
+	suggestions  SuggestionService
+	duplicates   DuplicateService
+	attachments  AttachmentService
+	audit        AuditService
 }
 
-func NewExpenseService(expenseRepo repositories.ExpenseRepository, categoryRepo repositories.CategoryRepository) ExpenseService {
+func NewExpenseService(expenseRepo repositories.ExpenseRepository, categoryRepo repositories.CategoryRepository, accountRepo repositories.AccountRepository, personRepo repositories.PersonRepository, tagRepo repositories.TagRepository, rules RuleService, suggestions SuggestionService, duplicates DuplicateService, attachments AttachmentService, audit AuditService) ExpenseService {
 	return &expenseService{
 		expenseRepo:  expenseRepo,
 		categoryRepo: categoryRepo,
+		accountRepo:  accountRepo,
+		personRepo:   personRepo,
+		tagRepo:      tagRepo,
+		rules:        rules,
+		suggestions:  suggestions,
+		duplicates:   duplicates,
+		attachments:  attachments,
+		audit:        audit,
 	}
 }
 
-// Create a new expense
-func (s *expenseService) Create(req dto.ExpenseRequestDTO) (dto.ExpenseResponseDTO, error) {
-	// Verify category exists
-	_, err := s.categoryRepo.GetByID(uint(req.CategoryID))
-	if err != nil {
-		return dto.ExpenseResponseDTO{}, fmt.Errorf("category not found")
+// Create a new expense. Unless allow_duplicate is set, likely duplicates of it are
+// listed in the response or, under the reject policy, stop it being saved.
+func (s *expenseService) Create(access Access, req dto.ExpenseRequestDTO) (dto.ExpenseResponseDTO, error) {
+	if err := requireEditor(access); err != nil {
+		return dto.ExpenseResponseDTO{}, err
 	}
-
-	// Parse the date
-	parsedDate, err := req.ParseDate()
+	expense, err := s.buildExpense(access, req, 0)
 	if err != nil {
 		return dto.ExpenseResponseDTO{}, err
 	}
 
-	expense := models.Expense{
-		CategoryID:  req.CategoryID,
-		Description: req.Description,
-		Amount:      req.Amount,
-		Date:        parsedDate,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+	var duplicates []dto.DuplicateExpenseDTO
+	if policy := s.duplicates.Policy(); !req.AllowDuplicate && policy != config.DuplicatePolicyOff {
+		duplicates, err = s.duplicates.Find(expense)
+		if err != nil {
+			return dto.ExpenseResponseDTO{}, err
+		}
+		if len(duplicates) > 0 && policy == config.DuplicatePolicyReject {
+			return dto.ExpenseResponseDTO{}, &DuplicateError{Duplicates: duplicates}
+		}
 	}
 
 	err = s.expenseRepo.Create(&expense)
 	if err != nil {
 		return dto.ExpenseResponseDTO{}, err
 	}
+	s.suggestions.Learn(expense)
+	s.audit.Record(access, models.AuditEntityExpense, expense.ID, models.AuditActionCreate, nil, expenseAuditFields(expense))
+
+	response := s.toResponseDTO(expense)
+	response.PossibleDuplicates = duplicates
+	return response, nil
+}
 
-	return s.toResponseDTO(expense), nil
+// Check runs the validation Create would apply without saving anything
+func (s *expenseService) Check(access Access, req dto.ExpenseRequestDTO) error {
+	_, err := s.buildExpense(access, req, 0)
+	return err
+}
+
+// CreateBatch creates the named categories and several expenses in one
+// transaction: either all are saved or none. The requests refer to the new
+// categories by the IDs repositories.PlaceholderCategoryID gives them.
+func (s *expenseService) CreateBatch(access Access, newCategories []string, reqs []dto.ExpenseRequestDTO) ([]dto.ExpenseResponseDTO, error) {
+	if err := requireEditor(access); err != nil {
+		return nil, err
+	}
+	categories := make([]*models.Category, 0, len(newCategories))
+	for _, name := range newCategories {
+		categories = append(categories, &models.Category{
+			LedgerID:  access.LedgerID,
+			UserID:    access.UserID,
+			Name:      name,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		})
+	}
+	expenses := make([]*models.Expense, 0, len(reqs))
+	for i, req := range reqs {
+		expense, err := s.buildExpense(access, req, len(categories))
+		if err != nil {
+			return nil, &BatchError{Index: i, Err: newValidationError("%s", err.Error())}
+		}
+		expenses = append(expenses, &expense)
+	}
+
+	if err := s.expenseRepo.CreateBatch(categories, expenses); err != nil {
+		return nil, err
+	}
+	for _, category := range categories {
+		s.audit.Record(access, models.AuditEntityCategory, category.ID, models.AuditActionCreate, nil, categoryAuditFields(*category))
+	}
+	for _, expense := range expenses {
+		s.suggestions.Learn(*expense)
+		s.audit.Record(access, models.AuditEntityExpense, expense.ID, models.AuditActionCreate, nil, expenseAuditFields(*expense))
+	}
+
+	responses := make([]dto.ExpenseResponseDTO, 0, len(expenses))
+	for _, expense := range expenses {
+		responses = append(responses, s.toResponseDTO(*expense))
+	}
+	return responses, nil
 }
 
 // Get all expenses
-func (s *expenseService) GetAll(offset int, limit int, descriptionFilter string, categoryID int) ([]dto.ExpenseResponseDTO, error) {
-	expenses, err := s.expenseRepo.GetAll(offset, limit, descriptionFilter, categoryID)
+func (s *expenseService) GetAll(access Access, offset int, limit int, descriptionFilter string, categoryID int) ([]dto.ExpenseResponseDTO, error) {
+	expenses, err := s.expenseRepo.GetAll(access.LedgerID, offset, limit, descriptionFilter, categoryID)
 	if err != nil {
 		return []dto.ExpenseResponseDTO{}, err
 	}
@@ -76,8 +152,8 @@ func (s *expenseService) GetAll(offset int, limit int, descriptionFilter string,
 }
 
 // Get expense by ID
-func (s *expenseService) GetByID(id int) (dto.ExpenseResponseDTO, error) {
-	expensePtr, err := s.expenseRepo.GetByID(uint(id))
+func (s *expenseService) GetByID(access Access, id int) (dto.ExpenseResponseDTO, error) {
+	expensePtr, err := s.expenseRepo.GetByID(access.LedgerID, uint(id))
 	if err != nil {
 		return dto.ExpenseResponseDTO{}, fmt.Errorf("expense not found")
 	}
@@ -85,59 +161,473 @@ func (s *expenseService) GetByID(id int) (dto.ExpenseResponseDTO, error) {
 }
 
 // Update existing expense
-func (s *expenseService) Update(id int, req dto.ExpenseRequestDTO) (dto.ExpenseResponseDTO, error) {
-	expense, err := s.expenseRepo.GetByID(uint(id))
+func (s *expenseService) Update(access Access, id int, req dto.ExpenseRequestDTO) (dto.ExpenseResponseDTO, error) {
+	if err := requireEditor(access); err != nil {
+		return dto.ExpenseResponseDTO{}, err
+	}
+	expense, err := s.expenseRepo.GetByID(access.LedgerID, uint(id))
 	if err != nil {
 		return dto.ExpenseResponseDTO{}, fmt.Errorf("expense not found")
 	}
+	before := expenseAuditFields(*expense)
+
+	// Validate split lines and resolve the primary category
+	splits, categoryID, err := s.buildSplits(access.LedgerID, req, 0)
+	if err != nil {
+		return dto.ExpenseResponseDTO{}, err
+	}
 
 	// Verify category exists if changed
-	if expense.CategoryID != req.CategoryID {
-		_, err := s.categoryRepo.GetByID(uint(req.CategoryID))
+	if expense.CategoryID != categoryID {
+		_, err := s.categoryRepo.GetByID(access.LedgerID, uint(categoryID))
 		if err != nil {
-			return dto.ExpenseResponseDTO{}, fmt.Errorf("category not found")
+			return dto.ExpenseResponseDTO{}, newValidationError("category not found")
 		}
 	}
 
+	accountID, err := s.resolveAccount(access.LedgerID, req.AccountID, req.Currency)
+	if err != nil {
+		return dto.ExpenseResponseDTO{}, err
+	}
+
+	// Resolve who paid and how the cost is shared
+	paidByID, shareMode, shares, err := s.buildShares(access.LedgerID, req)
+	if err != nil {
+		return dto.ExpenseResponseDTO{}, err
+	}
+
+	tags, err := s.resolveTags(access.LedgerID, req.TagIDs)
+	if err != nil {
+		return dto.ExpenseResponseDTO{}, err
+	}
+
 	// Parse the date
 	parsedDate, err := req.ParseDate()
 	if err != nil {
 		return dto.ExpenseResponseDTO{}, err
 	}
 
+	if req.ExternalID != "" && req.ExternalID != expense.ExternalID {
+		if err := s.checkExternalID(access.LedgerID, req.ExternalID); err != nil {
+			return dto.ExpenseResponseDTO{}, err
+		}
+		expense.ExternalID = req.ExternalID
+	}
+
+	expense.Kind = expenseKind(req.Kind)
 	expense.Amount = req.Amount
-	expense.CategoryID = req.CategoryID
+	expense.Currency = strings.ToUpper(req.Currency)
+	expense.CategoryID = categoryID
+	expense.AccountID = accountID
 	expense.Description = req.Description
 	expense.Date = parsedDate
 	expense.UpdatedAt = time.Now()
+	expense.PaidByID = paidByID
+	expense.ShareMode = shareMode
+	expense.Splits = splits
+	expense.Shares = shares
+	expense.Tags = tags
 
 	err = s.expenseRepo.Update(expense)
 	if err != nil {
 		return dto.ExpenseResponseDTO{}, err
 	}
+	s.suggestions.Learn(*expense)
+	s.audit.Record(access, models.AuditEntityExpense, expense.ID, models.AuditActionUpdate, before, expenseAuditFields(*expense))
 
 	return s.toResponseDTO(*expense), nil
 }
 
-// Delete expense by ID
-func (s *expenseService) Delete(id int) error {
-	return s.expenseRepo.Delete(uint(id))
+// Delete expense by ID along with its receipts
+func (s *expenseService) Delete(access Access, id int) error {
+	if err := requireEditor(access); err != nil {
+		return err
+	}
+	// Deleting an expense that does not exist succeeds, with nothing to audit
+	existing, err := s.expenseRepo.GetByID(access.LedgerID, uint(id))
+	if err != nil {
+		existing = nil
+	}
+	if err := s.attachments.DeleteAllForExpense(access.LedgerID, id); err != nil {
+		return err
+	}
+	if err := s.expenseRepo.Delete(access.LedgerID, uint(id)); err != nil {
+		return err
+	}
+	s.suggestions.Forget(access.LedgerID, id)
+	if existing != nil {
+		s.audit.Record(access, models.AuditEntityExpense, id, models.AuditActionDelete, expenseAuditFields(*existing), nil)
+	}
+	return nil
+}
+
+// DuplicateClusters lists groups of expenses that look like duplicates of each other
+func (s *expenseService) DuplicateClusters(access Access, filter dto.DuplicateFilterDTO) (dto.DuplicateClustersDTO, error) {
+	return s.duplicates.Clusters(access, filter)
+}
+
+// ResolveDuplicates settles a duplicate cluster by deleting the duplicates, receipts
+// included, or by dismissing them as genuine next to the kept expense
+func (s *expenseService) ResolveDuplicates(access Access, req dto.DuplicateResolveRequestDTO) (dto.DuplicateResolveResultDTO, error) {
+	if err := requireEditor(access); err != nil {
+		return dto.DuplicateResolveResultDTO{}, err
+	}
+	if _, err := s.expenseRepo.GetByID(access.LedgerID, uint(req.KeepID)); err != nil {
+		return dto.DuplicateResolveResultDTO{}, newValidationError("keep_id: expense not found")
+	}
+
+	ids := make([]int, 0, len(req.DuplicateIDs))
+	for i, id := range req.DuplicateIDs {
+		if id == req.KeepID {
+			return dto.DuplicateResolveResultDTO{}, newValidationError("duplicate_ids[%d]: the kept expense cannot be its own duplicate", i)
+		}
+		if containsID(ids, id) {
+			continue
+		}
+		if _, err := s.expenseRepo.GetByID(access.LedgerID, uint(id)); err != nil {
+			return dto.DuplicateResolveResultDTO{}, newValidationError("duplicate_ids[%d]: expense not found", i)
+		}
+		ids = append(ids, id)
+	}
+
+	switch req.Action {
+	case "delete":
+		for _, id := range ids {
+			if err := s.Delete(access, id); err != nil {
+				return dto.DuplicateResolveResultDTO{}, err
+			}
+		}
+	case "dismiss":
+		if err := s.duplicates.Dismiss(access, req.KeepID, ids); err != nil {
+			return dto.DuplicateResolveResultDTO{}, err
+		}
+	default:
+		return dto.DuplicateResolveResultDTO{}, newValidationError("action must be one of: delete, dismiss")
+	}
+
+	return dto.DuplicateResolveResultDTO{KeepID: req.KeepID, Action: req.Action, DuplicateIDs: ids}, nil
+}
+
+// History lists the recorded changes to an expense, oldest first. It outlives the
+// expense, so a deleted expense still has its history.
+func (s *expenseService) History(access Access, id int) ([]dto.AuditEntryDTO, error) {
+	entries, err := s.audit.History(access, models.AuditEntityExpense, id)
+	if err != nil {
+		return []dto.AuditEntryDTO{}, err
+	}
+	if len(entries) == 0 {
+		return []dto.AuditEntryDTO{}, fmt.Errorf("expense not found")
+	}
+	return entries, nil
+}
+
+// Helper: Validate a request and build the expense model Create would save.
+// A request without a category is first run through the rules. The first planned
+// placeholder category IDs stand for categories created along with the expense.
+func (s *expenseService) buildExpense(access Access, req dto.ExpenseRequestDTO, planned int) (models.Expense, error) {
+	if req.CategoryID == 0 && len(req.Splits) == 0 {
+		rules, err := s.rules.Load(access.LedgerID)
+		if err != nil {
+			return models.Expense{}, err
+		}
+		if rules.Apply(&req); req.CategoryID == 0 {
+			return models.Expense{}, newValidationError("category_id is required as no rule assigns a category")
+		}
+	}
+
+	// Validate split lines and resolve the primary category
+	splits, categoryID, err := s.buildSplits(access.LedgerID, req, planned)
+	if err != nil {
+		return models.Expense{}, err
+	}
+
+	// Verify category exists
+	if !s.categoryExists(access.LedgerID, categoryID, planned) {
+		return models.Expense{}, newValidationError("category not found")
+	}
+
+	accountID, err := s.resolveAccount(access.LedgerID, req.AccountID, req.Currency)
+	if err != nil {
+		return models.Expense{}, err
+	}
+
+	// Resolve who paid and how the cost is shared
+	paidByID, shareMode, shares, err := s.buildShares(access.LedgerID, req)
+	if err != nil {
+		return models.Expense{}, err
+	}
+
+	tags, err := s.resolveTags(access.LedgerID, req.TagIDs)
+	if err != nil {
+		return models.Expense{}, err
+	}
+
+	// Parse the date
+	parsedDate, err := req.ParseDate()
+	if err != nil {
+		return models.Expense{}, newValidationError("%s", err.Error())
+	}
+
+	if req.ExternalID != "" {
+		if err := s.checkExternalID(access.LedgerID, req.ExternalID); err != nil {
+			return models.Expense{}, err
+		}
+	}
+
+	return models.Expense{
+		UserID:      access.UserID,
+		LedgerID:    access.LedgerID,
+		CategoryID:  categoryID,
+		AccountID:   accountID,
+		Kind:        expenseKind(req.Kind),
+		ExternalID:  req.ExternalID,
+		Description: req.Description,
+		Amount:      req.Amount,
+		Currency:    strings.ToUpper(req.Currency),
+		Date:        parsedDate,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+		PaidByID:    paidByID,
+		ShareMode:   shareMode,
+		Splits:      splits,
+		Shares:      shares,
+		Tags:        tags,
+	}, nil
+}
+
+// Helper: Report whether a category exists in the ledger or is one of the first
+// planned placeholders
+func (s *expenseService) categoryExists(ledgerID int, id int, planned int) bool {
+	if isPlanned(id, planned) {
+		return true
+	}
+	_, err := s.categoryRepo.GetByID(ledgerID, uint(id))
+	return err == nil
+}
+
+// Helper: Report whether an ID is one of the first planned placeholder category IDs
+func isPlanned(id int, planned int) bool {
+	return id < 0 && id >= repositories.PlaceholderCategoryID(planned-1)
+}
+
+// Helper: Reject a bank reference that was already imported
+func (s *expenseService) checkExternalID(ledgerID int, externalID string) error {
+	exists, err := s.expenseRepo.ExistsByExternalID(ledgerID, externalID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return newValidationError("an expense with external_id %s already exists", externalID)
+	}
+	return nil
+}
+
+// Helper: Default an empty kind to a plain expense
+func expenseKind(kind string) string {
+	if kind == "" {
+		return models.KindExpense
+	}
+	return kind
+}
+
+// Helper: Verify the paying account exists and uses the expense's currency,
+// returning nil when no account was given
+func (s *expenseService) resolveAccount(ledgerID int, accountID int, currency string) (*int, error) {
+	if accountID == 0 {
+		return nil, nil
+	}
+	account, err := s.accountRepo.GetByID(ledgerID, uint(accountID))
+	if err != nil {
+		return nil, newValidationError("account not found")
+	}
+	if currency != "" && account.Currency != "" && !strings.EqualFold(currency, account.Currency) {
+		return nil, newValidationError("currency %s does not match the account currency %s", strings.ToUpper(currency), account.Currency)
+	}
+	return &accountID, nil
+}
+
+// Helper: Look up the tags to label an expense with, ignoring repeats
+func (s *expenseService) resolveTags(ledgerID int, tagIDs []int) ([]models.Tag, error) {
+	var tags []models.Tag
+	seen := make(map[int]bool)
+	for i, id := range tagIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		tag, err := s.tagRepo.GetByID(ledgerID, uint(id))
+		if err != nil {
+			return nil, newValidationError("tag_ids[%d]: tag not found", i)
+		}
+		tags = append(tags, *tag)
+	}
+	return tags, nil
+}
+
+// Helper: Validate split lines against the expense amount. Without an explicit
+// category the largest split line becomes the expense's primary category.
+func (s *expenseService) buildSplits(ledgerID int, req dto.ExpenseRequestDTO, planned int) ([]models.ExpenseSplit, int, error) {
+	if len(req.Splits) == 0 {
+		if req.CategoryID < 1 && !isPlanned(req.CategoryID, planned) {
+			return nil, 0, newValidationError("category_id must be greater than 0")
+		}
+		return nil, req.CategoryID, nil
+	}
+	if len(req.Splits) < 2 {
+		return nil, 0, newValidationError("a split expense needs at least two split lines")
+	}
+
+	categoryID := req.CategoryID
+	largest := 0.0
+	totalCents := int64(0)
+	splits := make([]models.ExpenseSplit, 0, len(req.Splits))
+	for i, line := range req.Splits {
+		if line.Amount <= 0 {
+			return nil, 0, newValidationError("splits[%d]: amount must be greater than 0", i)
+		}
+		if !s.categoryExists(ledgerID, line.CategoryID, planned) {
+			return nil, 0, newValidationError("splits[%d]: category not found", i)
+		}
+		totalCents += toCents(line.Amount)
+
+		if req.CategoryID == 0 && line.Amount > largest {
+			largest = line.Amount
+			categoryID = line.CategoryID
+		}
+
+		splits = append(splits, models.ExpenseSplit{
+			CategoryID:  line.CategoryID,
+			Amount:      line.Amount,
+			Description: line.Description,
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		})
+	}
+
+	if totalCents != toCents(req.Amount) {
+		return nil, 0, newValidationError("split amounts add up to %.2f but the expense amount is %.2f", float64(totalCents)/100, req.Amount)
+	}
+
+	return splits, categoryID, nil
+}
+
+// Helper: Validate the sharing section and resolve each participant's share
+func (s *expenseService) buildShares(ledgerID int, req dto.ExpenseRequestDTO) (*int, string, []models.ExpenseShare, error) {
+	if req.Sharing == nil {
+		return nil, "", nil, nil
+	}
+	sharing := req.Sharing
+
+	if _, err := s.personRepo.GetByID(ledgerID, uint(sharing.PaidByID)); err != nil {
+		return nil, "", nil, newValidationError("sharing.paid_by_id: person not found")
+	}
+	if len(sharing.Participants) == 0 {
+		return nil, "", nil, newValidationError("sharing needs at least one participant")
+	}
+
+	seen := make(map[int]bool)
+	for i, participant := range sharing.Participants {
+		if seen[participant.PersonID] {
+			return nil, "", nil, newValidationError("sharing.participants[%d]: person listed twice", i)
+		}
+		seen[participant.PersonID] = true
+		if _, err := s.personRepo.GetByID(ledgerID, uint(participant.PersonID)); err != nil {
+			return nil, "", nil, newValidationError("sharing.participants[%d]: person not found", i)
+		}
+	}
+
+	amounts, err := resolveShares(toCents(req.Amount), sharing.Mode, sharing.Participants)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	shares := make([]models.ExpenseShare, 0, len(amounts))
+	for i, participant := range sharing.Participants {
+		value := participant.Share
+		if sharing.Mode == "equal" {
+			value = 0
+		}
+		shares = append(shares, models.ExpenseShare{
+			PersonID:  participant.PersonID,
+			Value:     value,
+			Amount:    float64(amounts[i]) / 100,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		})
+	}
+
+	paidByID := sharing.PaidByID
+	return &paidByID, sharing.Mode, shares, nil
 }
 
 // Helper: Convert model → Response DTO
 func (s *expenseService) toResponseDTO(expense models.Expense) dto.ExpenseResponseDTO {
 	// Fetch category name
 	var categoryName string
-	if category, err := s.categoryRepo.GetByID(uint(expense.CategoryID)); err == nil {
+	if category, err := s.categoryRepo.GetByID(expense.LedgerID, uint(expense.CategoryID)); err == nil {
 		categoryName = category.Name
 	}
 
+	var accountID int
+	if expense.AccountID != nil {
+		accountID = *expense.AccountID
+	}
+
+	var splits []dto.ExpenseSplitResponseDTO
+	for _, split := range expense.Splits {
+		line := dto.ExpenseSplitResponseDTO{
+			ID:          split.ID,
+			CategoryID:  split.CategoryID,
+			Amount:      split.Amount,
+			Description: split.Description,
+		}
+		if category, err := s.categoryRepo.GetByID(expense.LedgerID, uint(split.CategoryID)); err == nil {
+			line.CategoryName = category.Name
+		}
+		splits = append(splits, line)
+	}
+
+	var sharing *dto.ExpenseSharingResponseDTO
+	if expense.PaidByID != nil {
+		sharing = &dto.ExpenseSharingResponseDTO{
+			PaidByID: *expense.PaidByID,
+			Mode:     expense.ShareMode,
+			Shares:   make([]dto.ExpenseShareResponseDTO, 0, len(expense.Shares)),
+		}
+		if person, err := s.personRepo.GetByID(expense.LedgerID, uint(*expense.PaidByID)); err == nil {
+			sharing.PaidByName = person.Name
+		}
+		for _, share := range expense.Shares {
+			line := dto.ExpenseShareResponseDTO{
+				PersonID: share.PersonID,
+				Share:    share.Value,
+				Amount:   share.Amount,
+			}
+			if person, err := s.personRepo.GetByID(expense.LedgerID, uint(share.PersonID)); err == nil {
+				line.PersonName = person.Name
+			}
+			sharing.Shares = append(sharing.Shares, line)
+		}
+	}
+
+	var tags []dto.TagSummaryDTO
+	for _, tag := range expense.Tags {
+		tags = append(tags, dto.TagSummaryDTO{ID: tag.ID, Name: tag.Name})
+	}
+
 	return dto.ExpenseResponseDTO{
 		ID:           expense.ID,
 		Amount:       expense.Amount,
+		Currency:     expense.Currency,
 		CategoryID:   expense.CategoryID,
 		CategoryName: categoryName,
+		AccountID:    accountID,
+		Kind:         expenseKind(expense.Kind),
 		Description:  expense.Description,
 		Date:         expense.Date.Format("2006-01-02"),
+		Splits:       splits,
+		Sharing:      sharing,
+		ExternalID:   expense.ExternalID,
+		Tags:         tags,
 	}
 }
@@ -0,0 +1,347 @@
+package services
+
+import (
+	"io"
+	"strings"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/exporters"
+	"goExpenseTracker/internal/models"
+	"goExpenseTracker/internal/repositories"
+)
+
+type ExportService interface {
+	QIF(access Access, w io.Writer, filter dto.ExpenseFilterDTO) error
+	Journal(access Access, w io.Writer, req dto.JournalExportDTO) error
+	Expenses(access Access, w io.Writer, req dto.ExpenseExportDTO) error
+}
+
+// exportBatchSize is how many expenses a streaming export reads at a time
+const exportBatchSize = 500
+
+type exportService struct {
+	expenseRepo  repositories.ExpenseRepository
+	categoryRepo repositories.CategoryRepository
+	accountRepo  repositories.AccountRepository
+}
+
+func NewExportService(expenseRepo repositories.ExpenseRepository, categoryRepo repositories.CategoryRepository, accountRepo repositories.AccountRepository) ExportService {
+	return &exportService{
+		expenseRepo:  expenseRepo,
+		categoryRepo: categoryRepo,
+		accountRepo:  accountRepo,
+	}
+}
+
+// QIF writes the filtered expenses as a QIF file. Expenses are money going out;
+// refunds and income are written as deposits.
+func (s *exportService) QIF(access Access, w io.Writer, filter dto.ExpenseFilterDTO) error {
+	criteria, err := toExpenseFilter(filter)
+	if err != nil {
+		return err
+	}
+
+	expenses, err := s.expenseRepo.Find(access.LedgerID, criteria)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[int]string)
+	transactions := make([]exporters.QIFTransaction, 0, len(expenses))
+	for _, expense := range expenses {
+		sign := -1.0
+		if expense.Kind == models.KindRefund || expense.Kind == models.KindIncome {
+			sign = 1
+		}
+
+		txn := exporters.QIFTransaction{
+			Date:   expense.Date,
+			Amount: sign * expense.Amount,
+			Payee:  expense.Description,
+		}
+		if len(expense.Splits) == 0 {
+			txn.Category = s.categoryName(access.LedgerID, expense.CategoryID, names)
+		}
+		for _, split := range expense.Splits {
+			txn.Splits = append(txn.Splits, exporters.QIFSplit{
+				Category: s.categoryName(access.LedgerID, split.CategoryID, names),
+				Amount:   sign * split.Amount,
+				Memo:     split.Description,
+			})
+		}
+		transactions = append(transactions, txn)
+	}
+
+	return exporters.WriteQIF(w, s.qifAccountType(access.LedgerID, criteria.AccountID), transactions)
+}
+
+// Expenses streams the filtered expenses as CSV, XLSX or NDJSON. Rows are read from
+// the database in batches and written as they arrive; nothing is written before
+// the filter has been validated.
+func (s *exportService) Expenses(access Access, w io.Writer, req dto.ExpenseExportDTO) error {
+	criteria, err := toExpenseFilter(req.ExpenseFilterDTO)
+	if err != nil {
+		return err
+	}
+
+	format := req.Format
+	if format == "" {
+		format = exporters.CSV
+	}
+	out, err := exporters.NewRowWriter(format, w)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[int]string)
+	accounts := make(map[int]*models.Account)
+	err = s.expenseRepo.Stream(access.LedgerID, criteria, exportBatchSize, func(expenses []models.Expense) error {
+		for _, expense := range expenses {
+			row := exporters.ExpenseRow{
+				ID:          expense.ID,
+				Date:        expense.Date,
+				Kind:        expense.Kind,
+				Amount:      expense.Amount,
+				Currency:    expense.Currency,
+				CategoryID:  expense.CategoryID,
+				Category:    s.categoryName(access.LedgerID, expense.CategoryID, names),
+				Description: expense.Description,
+				ExternalID:  expense.ExternalID,
+			}
+			if row.Kind == "" {
+				row.Kind = models.KindExpense
+			}
+			if account := s.account(access.LedgerID, expense.AccountID, accounts); account != nil {
+				row.AccountID = account.ID
+				row.Account = account.Name
+			}
+			for _, split := range expense.Splits {
+				row.Splits = append(row.Splits, exporters.SplitRow{
+					CategoryID:  split.CategoryID,
+					Category:    s.categoryName(access.LedgerID, split.CategoryID, names),
+					Amount:      split.Amount,
+					Description: split.Description,
+				})
+			}
+			if err := out.Write(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// beancountRoots are the only top-level accounts beancount allows
+var beancountRoots = map[string]bool{
+	"Assets":      true,
+	"Liabilities": true,
+	"Equity":      true,
+	"Income":      true,
+	"Expenses":    true,
+}
+
+// Journal writes the filtered expenses as a ledger, hledger or beancount journal.
+// Each expense posts to the account mapped from its category (Expenses:<Category>,
+// or Income:<Category> for income unless mapped) and is balanced against the
+// funding account, which defaults to the expense's own account.
+func (s *exportService) Journal(access Access, w io.Writer, req dto.JournalExportDTO) error {
+	criteria, err := toExpenseFilter(req.ExpenseFilterDTO)
+	if err != nil {
+		return err
+	}
+
+	mapping := make(map[string]string)
+	for _, pair := range req.Accounts {
+		category, account, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(category) == "" || strings.TrimSpace(account) == "" {
+			return newValidationError("account mapping %q must look like Category=Account:Path", pair)
+		}
+		mapping[strings.ToLower(strings.TrimSpace(category))] = account
+	}
+
+	expenses, err := s.expenseRepo.Find(access.LedgerID, criteria)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[int]string)
+	accounts := make(map[int]*models.Account)
+	journal := &journalBuilder{format: req.Format}
+	transactions := make([]exporters.JournalTransaction, 0, len(expenses))
+	for _, expense := range expenses {
+		account := s.account(access.LedgerID, expense.AccountID, accounts)
+
+		currency := strings.ToUpper(req.Currency)
+		if expense.Currency != "" {
+			currency = expense.Currency
+		} else if account != nil && account.Currency != "" {
+			currency = account.Currency
+		}
+		if currency == "" && req.Format == exporters.Beancount {
+			return newValidationError("expense %d has no currency; set the currency parameter", expense.ID)
+		}
+
+		// Expenses move money into the category account; refunds and income out of it
+		sign := 1.0
+		if expense.Kind == models.KindRefund || expense.Kind == models.KindIncome {
+			sign = -1
+		}
+
+		categoryAccount := func(categoryID int) (string, error) {
+			name := s.categoryName(access.LedgerID, categoryID, names)
+			if mapped, ok := mapping[strings.ToLower(name)]; ok {
+				return journal.account(mapped)
+			}
+			root := "Expenses"
+			if expense.Kind == models.KindIncome {
+				root = "Income"
+			}
+			return journal.account(root + ":" + name)
+		}
+
+		txn := exporters.JournalTransaction{Date: expense.Date, Description: expense.Description}
+		totalCents := int64(0)
+		lines := expense.Splits
+		if len(lines) == 0 {
+			lines = []models.ExpenseSplit{{CategoryID: expense.CategoryID, Amount: expense.Amount}}
+		}
+		for _, line := range lines {
+			target, err := categoryAccount(line.CategoryID)
+			if err != nil {
+				return err
+			}
+			cents := toCents(sign * line.Amount)
+			totalCents += cents
+			txn.Postings = append(txn.Postings, exporters.JournalPosting{
+				Account:  target,
+				Amount:   float64(cents) / 100,
+				Currency: currency,
+				Comment:  line.Description,
+			})
+		}
+
+		funding, err := journal.account(fundingAccount(req.FundingAccount, account))
+		if err != nil {
+			return err
+		}
+		txn.Postings = append(txn.Postings, exporters.JournalPosting{
+			Account:  funding,
+			Amount:   float64(-totalCents) / 100,
+			Currency: currency,
+		})
+
+		transactions = append(transactions, txn)
+	}
+
+	return exporters.WriteJournal(w, req.Format, transactions)
+}
+
+// journalBuilder cleans and checks account paths for one journal format
+type journalBuilder struct {
+	format string
+}
+
+func (b *journalBuilder) account(path string) (string, error) {
+	account := exporters.JournalAccount(b.format, path)
+	if account == "" {
+		return "", newValidationError("account path %q is empty", path)
+	}
+	if b.format == exporters.Beancount {
+		root, _, _ := strings.Cut(account, ":")
+		if !beancountRoots[root] {
+			return "", newValidationError("beancount account %s must start with Assets, Liabilities, Equity, Income or Expenses", account)
+		}
+	}
+	return account, nil
+}
+
+// fundingAccount is the configured funding account, else the account the expense
+// was paid from (cards are liabilities), else a generic bank account
+func fundingAccount(configured string, account *models.Account) string {
+	if configured != "" {
+		return configured
+	}
+	if account == nil {
+		return "Assets:Bank"
+	}
+	if account.Type == "card" {
+		return "Liabilities:" + account.Name
+	}
+	return "Assets:" + account.Name
+}
+
+// account loads the account an expense was paid from, caching lookups for the rest of the export
+func (s *exportService) account(ledgerID int, id *int, accounts map[int]*models.Account) *models.Account {
+	if id == nil {
+		return nil
+	}
+	if account, ok := accounts[*id]; ok {
+		return account
+	}
+	account, err := s.accountRepo.GetByID(ledgerID, uint(*id))
+	if err != nil {
+		account = nil
+	}
+	accounts[*id] = account
+	return account
+}
+
+// toExpenseFilter checks an export or report filter and converts it for the repository
+func toExpenseFilter(filter dto.ExpenseFilterDTO) (repositories.ExpenseFilter, error) {
+	from, err := dto.ParseOptionalDate(filter.From)
+	if err != nil {
+		return repositories.ExpenseFilter{}, newValidationError("from: %s", err.Error())
+	}
+	to, err := dto.ParseOptionalDate(filter.To)
+	if err != nil {
+		return repositories.ExpenseFilter{}, newValidationError("to: %s", err.Error())
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return repositories.ExpenseFilter{}, newValidationError("to must not be before from")
+	}
+
+	return repositories.ExpenseFilter{
+		Description: filter.Description,
+		CategoryID:  filter.CategoryID,
+		AccountID:   filter.AccountID,
+		TagID:       filter.TagID,
+		From:        from,
+		To:          to,
+	}, nil
+}
+
+// categoryName resolves a category name, caching lookups for the rest of the export
+func (s *exportService) categoryName(ledgerID int, id int, names map[int]string) string {
+	if name, ok := names[id]; ok {
+		return name
+	}
+	var name string
+	if category, err := s.categoryRepo.GetByID(ledgerID, uint(id)); err == nil {
+		name = category.Name
+	}
+	names[id] = name
+	return name
+}
+
+// qifAccountType picks the QIF section for an export of one account, defaulting to Bank
+func (s *exportService) qifAccountType(ledgerID int, accountID int) string {
+	if accountID == 0 {
+		return "Bank"
+	}
+	account, err := s.accountRepo.GetByID(ledgerID, uint(accountID))
+	if err != nil {
+		return "Bank"
+	}
+	switch account.Type {
+	case "cash":
+		return "Cash"
+	case "card":
+		return "CCard"
+	default:
+		return "Bank"
+	}
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"goExpenseTracker/internal/migrations"
+	"goExpenseTracker/internal/models"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Ledgers of the service tests: the one they work in and another whose data
+// must stay out of the results
+const (
+	testLedger  = 1
+	otherLedger = 2
+)
+
+// ownerAccess is the access of the ledger owner to the test ledger
+func ownerAccess() Access {
+	return Access{UserID: 1, LedgerID: testLedger, Role: models.RoleOwner, Location: time.UTC}
+}
+
+// openTestDB returns a migrated SQLite database in the test's temporary directory
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")+"?_pragma=foreign_keys(1)"),
+		&gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	runner, err := migrations.New(db)
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if _, err := runner.Up(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
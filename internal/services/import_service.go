@@ -0,0 +1,525 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"goExpenseTracker/config"
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/importers"
+	"goExpenseTracker/internal/models"
+	"goExpenseTracker/internal/repositories"
+)
+
+type ImportService interface {
+	Import(access Access, format string, r io.Reader, opts dto.ImportOptionsDTO, dryRun bool) (dto.ImportResultDTO, error)
+}
+
+type importService struct {
+	expenseService   ExpenseService
+	ruleService      RuleService
+	duplicateService DuplicateService
+	auditService     AuditService
+	expenseRepo      repositories.ExpenseRepository
+	categoryRepo     repositories.CategoryRepository
+}
+
+func NewImportService(expenseService ExpenseService, ruleService RuleService, duplicateService DuplicateService, auditService AuditService, expenseRepo repositories.ExpenseRepository, categoryRepo repositories.CategoryRepository) ImportService {
+	return &importService{
+		expenseService:   expenseService,
+		ruleService:      ruleService,
+		duplicateService: duplicateService,
+		auditService:     auditService,
+		expenseRepo:      expenseRepo,
+		categoryRepo:     categoryRepo,
+	}
+}
+
+// Import parses a file and validates every row through ExpenseService. Rows without
+// a category are run through the rules before falling back to default_category_id.
+// Rows that look like expenses already recorded, or like an earlier row of the file,
+// are flagged, or skipped under the reject policy, unless allow_duplicates is set. A dry run stops there; otherwise
+// all valid rows, and the categories they need, are created in a single transaction.
+func (s *importService) Import(access Access, format string, r io.Reader, opts dto.ImportOptionsDTO, dryRun bool) (dto.ImportResultDTO, error) {
+	if !dryRun {
+		if err := requireEditor(access); err != nil {
+			return dto.ImportResultDTO{}, err
+		}
+	}
+	records, err := s.parse(format, r, opts)
+	if err != nil {
+		return dto.ImportResultDTO{}, err
+	}
+
+	result := dto.ImportResultDTO{
+		Format: format,
+		DryRun: dryRun,
+		Total:  len(records),
+		Rows:   make([]dto.ImportRowResultDTO, 0, len(records)),
+	}
+
+	rules, err := s.ruleService.Load(access.LedgerID)
+	if err != nil {
+		return dto.ImportResultDTO{}, err
+	}
+
+	categories := make(map[string]int)
+	seen := make(map[string]bool)
+	pending := make([]dto.ExpenseRequestDTO, 0, len(records))
+	pendingRows := make([]int, 0, len(records))
+	probes := make([]models.Expense, 0, len(records))
+
+	for _, record := range records {
+		row := dto.ImportRowResultDTO{Line: record.Line, Status: "failed"}
+		if record.Err != nil {
+			row.Error = record.Err.Error()
+			result.Rows = append(result.Rows, row)
+			continue
+		}
+
+		row.Date = record.Date.Format("2006-01-02")
+		row.Amount = roundMoney(record.Amount)
+		row.Currency = record.Currency
+		row.Description = record.Description
+		row.Kind = record.Kind
+		row.ExternalID = record.ExternalID
+
+		if record.SkipReason != "" {
+			row.Status = "skipped"
+			row.Error = record.SkipReason
+			result.Rows = append(result.Rows, row)
+			continue
+		}
+
+		// Entries already imported, or repeated within the file, are skipped
+		if reason, err := s.duplicateReason(access.LedgerID, record.ExternalID, seen); err != nil {
+			return dto.ImportResultDTO{}, err
+		} else if reason != "" {
+			row.Status = "skipped"
+			row.Error = reason
+			result.Rows = append(result.Rows, row)
+			continue
+		}
+
+		// Categories are planned only for rows that get this far, and dropped
+		// again when the row is not imported after all
+		planned := s.prepareCategories(access, record, opts, categories, &result)
+		req, applied, err := s.toRequest(access, record, opts, categories, rules)
+		if err == nil {
+			err = s.check(access, req)
+		}
+
+		if req.CategoryID > 0 {
+			row.CategoryID = req.CategoryID
+		}
+		row.Description = req.Description
+		row.TagIDs = req.TagIDs
+		row.RuleIDs = applied
+		if err != nil {
+			unplanCategories(planned, categories, &result)
+			row.Error = err.Error()
+			result.Rows = append(result.Rows, row)
+			continue
+		}
+
+		// Rows are compared with the stored expenses and with the rows before them
+		probe := duplicateProbe(access, req)
+		if !opts.AllowDuplicates && s.duplicateService.Policy() != config.DuplicatePolicyOff {
+			duplicates, err := s.duplicateService.Find(probe)
+			if err != nil {
+				return dto.ImportResultDTO{}, err
+			}
+			row.Duplicates = duplicates
+			for i, earlier := range probes {
+				if s.duplicateService.Matches(probe, earlier) {
+					row.DuplicateLines = append(row.DuplicateLines, result.Rows[pendingRows[i]].Line)
+				}
+			}
+			if (len(duplicates) > 0 || len(row.DuplicateLines) > 0) && s.duplicateService.Policy() == config.DuplicatePolicyReject {
+				unplanCategories(planned, categories, &result)
+				row.Status = "skipped"
+				row.Error = fmt.Sprintf("looks like a duplicate of %s; set allow_duplicates to import it anyway", describeRowDuplicates(duplicates, row.DuplicateLines))
+				result.Rows = append(result.Rows, row)
+				continue
+			}
+		}
+
+		row.Status = "valid"
+		pending = append(pending, req)
+		pendingRows = append(pendingRows, len(result.Rows))
+		probes = append(probes, probe)
+		result.Rows = append(result.Rows, row)
+	}
+
+	if !dryRun && len(pending) > 0 {
+		// The new categories are created in the same transaction as the expenses
+		expenses, err := s.expenseService.CreateBatch(access, result.NewCategories, pending)
+		var batchErr *BatchError
+		if errors.As(err, &batchErr) {
+			line := result.Rows[pendingRows[batchErr.Index]].Line
+			return dto.ImportResultDTO{}, fmt.Errorf("import rolled back: line %d: %w", line, batchErr.Err)
+		} else if err != nil {
+			return dto.ImportResultDTO{}, fmt.Errorf("import rolled back: %w", err)
+		}
+		for i, index := range pendingRows {
+			expense := expenses[i]
+			result.Rows[index].Status = "imported"
+			result.Rows[index].CategoryID = expense.CategoryID
+			result.Rows[index].Expense = &expense
+		}
+	} else if !dryRun {
+		// Nothing was imported, so the new categories are not created either
+		result.NewCategories = nil
+	}
+
+	for _, row := range result.Rows {
+		switch row.Status {
+		case "valid":
+			result.Valid++
+		case "imported":
+			result.Valid++
+			result.Imported++
+		case "skipped":
+			result.Skipped++
+		default:
+			result.Failed++
+		}
+	}
+
+	return result, nil
+}
+
+// parse dispatches to the parser of the requested format
+func (s *importService) parse(format string, r io.Reader, opts dto.ImportOptionsDTO) ([]importers.Record, error) {
+	switch format {
+	case "csv":
+		csvOpts, err := csvOptions(opts)
+		if err != nil {
+			return nil, err
+		}
+		records, err := importers.ParseCSV(r, csvOpts)
+		if err != nil {
+			return nil, newValidationError("could not read CSV: %s", err.Error())
+		}
+		return records, nil
+
+	case "ofx", "qfx":
+		records, err := importers.ParseOFX(r)
+		if err != nil {
+			return nil, newValidationError("could not read OFX: %s", err.Error())
+		}
+		return records, nil
+
+	case "camt053", "camt.053":
+		records, err := importers.ParseCamt053(r)
+		if err != nil {
+			return nil, newValidationError("could not read camt.053: %s", err.Error())
+		}
+		return records, nil
+
+	case "mt940":
+		records, err := importers.ParseMT940(r)
+		if err != nil {
+			return nil, newValidationError("could not read MT940: %s", err.Error())
+		}
+		return records, nil
+
+	case "qif":
+		separator := opts.DecimalSeparator
+		if separator != "" && separator != "." && separator != "," {
+			return nil, newValidationError("decimal_separator must be \".\" or \",\"")
+		}
+		records, err := importers.ParseQIF(r, importers.QIFOptions{
+			DateFormats:      opts.DateFormats,
+			DecimalSeparator: separator,
+		})
+		if err != nil {
+			return nil, newValidationError("could not read QIF: %s", err.Error())
+		}
+		return records, nil
+
+	default:
+		return nil, newValidationError("unsupported import format %q", format)
+	}
+}
+
+// duplicateReason explains why an entry with the given bank reference must be skipped,
+// returning an empty string when it is new
+func (s *importService) duplicateReason(ledgerID int, externalID string, seen map[string]bool) (string, error) {
+	if externalID == "" {
+		return "", nil
+	}
+	if seen[externalID] {
+		return "duplicate of an earlier entry in this file", nil
+	}
+	seen[externalID] = true
+
+	exists, err := s.expenseRepo.ExistsByExternalID(ledgerID, externalID)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return "already imported", nil
+	}
+	return "", nil
+}
+
+// duplicateProbe is the part of the expense a row would create that duplicate detection looks at
+func duplicateProbe(access Access, req dto.ExpenseRequestDTO) models.Expense {
+	date, _ := req.ParseDate()
+	probe := models.Expense{
+		UserID:      access.UserID,
+		LedgerID:    access.LedgerID,
+		Kind:        expenseKind(req.Kind),
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		Description: req.Description,
+		Date:        date,
+		ExternalID:  req.ExternalID,
+	}
+	if req.AccountID > 0 {
+		accountID := req.AccountID
+		probe.AccountID = &accountID
+	}
+	return probe
+}
+
+// describeRowDuplicates names the stored expenses and earlier lines a row may
+// duplicate, as "expense 4 and line 3 of this file"
+func describeRowDuplicates(duplicates []dto.DuplicateExpenseDTO, lines []int) string {
+	parts := make([]string, 0, 2)
+	if len(duplicates) > 0 {
+		parts = append(parts, describeDuplicates(duplicates))
+	}
+	if len(lines) > 0 {
+		numbers := make([]string, 0, len(lines))
+		for _, line := range lines {
+			numbers = append(numbers, strconv.Itoa(line))
+		}
+		if len(numbers) == 1 {
+			parts = append(parts, "line "+numbers[0]+" of this file")
+		} else {
+			parts = append(parts, "lines "+strings.Join(numbers, ", ")+" of this file")
+		}
+	}
+	return strings.Join(parts, " and ")
+}
+
+// prepareCategories resolves the category names a row uses and, when requested,
+// plans the missing ones, listing them in the result and returning their keys. Rows
+// refer to a planned category by a placeholder ID until the import creates it, in
+// the same transaction as the expenses; a dry run creates nothing.
+func (s *importService) prepareCategories(access Access, record importers.Record, opts dto.ImportOptionsDTO, categories map[string]int, result *dto.ImportResultDTO) []string {
+	if !opts.CreateCategories {
+		return nil
+	}
+
+	names := []string{record.CategoryName}
+	for _, split := range record.Splits {
+		names = append(names, split.CategoryName)
+	}
+
+	var planned []string
+	for _, name := range names {
+		key := strings.ToLower(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := categories[key]; ok {
+			continue
+		}
+
+		if category, err := s.categoryRepo.GetByName(access.LedgerID, name); err == nil {
+			categories[key] = category.ID
+			continue
+		}
+
+		categories[key] = repositories.PlaceholderCategoryID(len(result.NewCategories))
+		result.NewCategories = append(result.NewCategories, name)
+		planned = append(planned, key)
+	}
+	return planned
+}
+
+// unplanCategories drops the categories planned for a row that is not imported. They
+// are the last ones planned, so the placeholders of the others stay valid.
+func unplanCategories(planned []string, categories map[string]int, result *dto.ImportResultDTO) {
+	for _, key := range planned {
+		delete(categories, key)
+	}
+	result.NewCategories = result.NewCategories[:len(result.NewCategories)-len(planned)]
+}
+
+// check validates a request the way ExpenseService.Create would. Rows that use
+// planned categories only get the checks that need no lookup here; the rest
+// happen when the batch is created.
+func (s *importService) check(access Access, req dto.ExpenseRequestDTO) error {
+	planned := req.CategoryID < 0
+	for _, split := range req.Splits {
+		planned = planned || split.CategoryID < 0
+	}
+	if !planned {
+		if err := req.ValidateFields(); err != nil {
+			return err
+		}
+		return s.expenseService.Check(access, req)
+	}
+
+	// Any positive ID passes the field checks; the real one is only known on commit
+	probe := req
+	probe.CategoryID = 0
+	if len(req.Splits) == 0 {
+		probe.CategoryID = 1
+	}
+	if err := probe.ValidateFields(); err != nil {
+		return err
+	}
+	if len(req.Splits) > 0 {
+		total := int64(0)
+		for _, split := range req.Splits {
+			total += toCents(split.Amount)
+		}
+		if total != toCents(req.Amount) {
+			return newValidationError("split amounts add up to %.2f but the expense amount is %.2f", float64(total)/100, req.Amount)
+		}
+	}
+	return nil
+}
+
+// toRequest turns a parsed record into the request ExpenseService would receive,
+// returning the IDs of the rules applied to it
+func (s *importService) toRequest(access Access, record importers.Record, opts dto.ImportOptionsDTO, categories map[string]int, rules *RuleSet) (dto.ExpenseRequestDTO, []int, error) {
+	req := dto.ExpenseRequestDTO{
+		CategoryID:  record.CategoryID,
+		AccountID:   opts.AccountID,
+		Kind:        record.Kind,
+		Amount:      roundMoney(record.Amount),
+		Currency:    record.Currency,
+		Description: record.Description,
+		Date:        record.Date.Format("2006-01-02"),
+		ExternalID:  record.ExternalID,
+	}
+
+	if record.CategoryName != "" {
+		id, err := s.categoryID(access.LedgerID, record.CategoryName, categories)
+		if err != nil {
+			return req, nil, err
+		}
+		req.CategoryID = id
+	}
+
+	for _, split := range record.Splits {
+		line := dto.ExpenseSplitRequestDTO{
+			CategoryID:  opts.DefaultCategoryID,
+			Amount:      roundMoney(split.Amount),
+			Description: split.Description,
+		}
+		if split.CategoryName != "" {
+			id, err := s.categoryID(access.LedgerID, split.CategoryName, categories)
+			if err != nil {
+				return req, nil, err
+			}
+			line.CategoryID = id
+		}
+		if line.CategoryID == 0 {
+			return req, nil, fmt.Errorf("split line has no category and no default_category_id is set")
+		}
+		req.Splits = append(req.Splits, line)
+	}
+
+	// Split expenses take their primary category from the largest line; rules and
+	// the default only fill in rows without a category
+	applied := rules.Apply(&req)
+	if req.CategoryID == 0 && len(req.Splits) == 0 {
+		if opts.DefaultCategoryID == 0 {
+			return req, applied, fmt.Errorf("row has no category, no rule assigns one and no default_category_id is set")
+		}
+		req.CategoryID = opts.DefaultCategoryID
+	}
+	return req, applied, nil
+}
+
+// categoryID looks a category up by name, caching the answer for the rest of the file
+func (s *importService) categoryID(ledgerID int, name string, categories map[string]int) (int, error) {
+	key := strings.ToLower(name)
+	if id, ok := categories[key]; ok {
+		return id, nil
+	}
+	category, err := s.categoryRepo.GetByName(ledgerID, name)
+	if err != nil {
+		return 0, fmt.Errorf("category %q not found", name)
+	}
+	categories[key] = category.ID
+	return category.ID, nil
+}
+
+// csvOptions applies defaults to the CSV settings sent by the client
+func csvOptions(opts dto.ImportOptionsDTO) (importers.CSVOptions, error) {
+	csvOpts := importers.CSVOptions{
+		Columns: importers.CSVColumns{
+			Date:        opts.Columns.Date,
+			Amount:      opts.Columns.Amount,
+			Description: opts.Columns.Description,
+			Category:    opts.Columns.Category,
+		},
+		DateFormats:      opts.DateFormats,
+		DecimalSeparator: opts.DecimalSeparator,
+		Delimiter:        ',',
+		HasHeader:        true,
+	}
+
+	if opts.HasHeader != nil {
+		csvOpts.HasHeader = *opts.HasHeader
+	}
+	if csvOpts.Columns.Date == "" {
+		csvOpts.Columns.Date = "date"
+	}
+	if csvOpts.Columns.Amount == "" {
+		csvOpts.Columns.Amount = "amount"
+	}
+	if csvOpts.Columns.Description == "" && csvOpts.HasHeader {
+		csvOpts.Columns.Description = "description"
+	}
+	if csvOpts.Columns.Category == "" && csvOpts.HasHeader {
+		csvOpts.Columns.Category = "category"
+	}
+	if len(csvOpts.DateFormats) == 0 {
+		csvOpts.DateFormats = []string{"dd-mm-yyyy", "yyyy-mm-dd", "dd/mm/yyyy"}
+	}
+
+	switch opts.CategoryBy {
+	case "", "name":
+	case "id":
+		csvOpts.CategoryByID = true
+	default:
+		return csvOpts, newValidationError("category_by must be name or id")
+	}
+
+	switch opts.DecimalSeparator {
+	case "":
+		csvOpts.DecimalSeparator = "."
+	case ".", ",":
+	default:
+		return csvOpts, newValidationError("decimal_separator must be \".\" or \",\"")
+	}
+
+	switch opts.Delimiter {
+	case "", ",":
+	case ";":
+		csvOpts.Delimiter = ';'
+	case "|":
+		csvOpts.Delimiter = '|'
+	case "\t", "tab":
+		csvOpts.Delimiter = '\t'
+	default:
+		return csvOpts, newValidationError("delimiter must be one of: \",\", \";\", \"|\", tab")
+	}
+	if csvOpts.Delimiter == ',' && csvOpts.DecimalSeparator == "," {
+		return csvOpts, newValidationError("a comma decimal separator needs a different delimiter, such as \";\"")
+	}
+
+	return csvOpts, nil
+}
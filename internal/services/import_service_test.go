@@ -0,0 +1,137 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"goExpenseTracker/config"
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/models"
+	"goExpenseTracker/internal/repositories"
+)
+
+// newImportTest returns the import service over memory category and expense
+// repositories holding the expenses, with the rest in a test database
+func newImportTest(t *testing.T, policy string, expenses []recorded) ImportService {
+	t.Helper()
+	db := openTestDB(t)
+	categories := repositories.NewMemoryCategoryRepository()
+	expenseRepo := repositories.NewMemoryExpenseRepository(categories)
+	for _, r := range expenses {
+		expense := models.Expense{
+			LedgerID:    testLedger,
+			Amount:      r.amount,
+			Description: r.description,
+			Date:        duplicateDay.AddDate(0, 0, r.days),
+			ExternalID:  r.externalID,
+		}
+		if err := expenseRepo.Create(&expense); err != nil {
+			t.Fatalf("create expense: %v", err)
+		}
+	}
+
+	accountRepo := repositories.NewAccountRepository(db)
+	tagRepo := repositories.NewTagRepository(db)
+	audit := NewAuditService(repositories.NewAuditRepository(db), repositories.NewUserRepository(db))
+	rules := NewRuleService(repositories.NewRuleRepository(db), expenseRepo, categories, accountRepo, tagRepo)
+	duplicates := NewDuplicateService(expenseRepo, &memoryDismissals{}, categories, DuplicateOptions{
+		Policy:     policy,
+		WindowDays: config.DefaultDuplicateWindowDays,
+	})
+	expenseService := NewExpenseService(expenseRepo, categories, accountRepo, repositories.NewPersonRepository(db), tagRepo,
+		rules, NewSuggestionService(expenseRepo, categories), duplicates, nil, audit)
+	return NewImportService(expenseService, rules, duplicates, audit, expenseRepo, categories)
+}
+
+func TestImportPlansCategoriesOnlyForImportedRows(t *testing.T) {
+	service := newImportTest(t, config.DuplicatePolicyReject, []recorded{
+		{amount: 40, description: "Hotel"},
+	})
+	file := "date,amount,description,category\n" +
+		"10/03/2025,12.50,Lunch,Food\n" +
+		"10/03/2025,40,Hotel,Travel\n" +
+		"10/03/2025,-,Broken,Misc\n"
+
+	result, err := service.Import(ownerAccess(), "csv", strings.NewReader(file), dto.ImportOptionsDTO{
+		DateFormats:      []string{"dd/mm/yyyy"},
+		CreateCategories: true,
+	}, true)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if want := []string{"Food"}; !reflect.DeepEqual(result.NewCategories, want) {
+		t.Errorf("new categories = %v, want %v", result.NewCategories, want)
+	}
+	statuses := make([]string, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		statuses = append(statuses, row.Status)
+	}
+	if want := []string{"valid", "skipped", "failed"}; !reflect.DeepEqual(statuses, want) {
+		t.Errorf("statuses = %v, want %v", statuses, want)
+	}
+}
+
+func TestImportReportsBatchFailuresByLine(t *testing.T) {
+	service := newImportTest(t, config.DuplicatePolicyOff, nil)
+	file := "date,amount,description,category\n" +
+		"10/03/2025,-,Broken,Misc\n" +
+		"10/03/2025,12.50,Lunch,Food\n"
+
+	// The account is only looked up when the batch is created, as the row's
+	// category is new
+	_, err := service.Import(ownerAccess(), "csv", strings.NewReader(file), dto.ImportOptionsDTO{
+		DateFormats:      []string{"dd/mm/yyyy"},
+		CreateCategories: true,
+		AccountID:        999,
+	}, false)
+	if err == nil {
+		t.Fatal("import succeeded, want an error")
+	}
+	if !strings.Contains(err.Error(), "line 3:") {
+		t.Errorf("error = %q, want it to name line 3", err)
+	}
+}
+
+func TestImportComparesRowsOfTheFile(t *testing.T) {
+	file := "date,amount,description,category\n" +
+		"10/03/2025,12.50,Lunch at the canteen,Food\n" +
+		"11/03/2025,12.50,Lunch at the canteen,Food\n" +
+		"11/03/2025,12.50,Train ticket,Food\n" +
+		"30/03/2025,12.50,Lunch at the canteen,Food\n"
+	cases := []struct {
+		policy    string
+		allow     bool
+		statuses  []string
+		duplicate []int // Lines the second row duplicates
+	}{
+		{config.DuplicatePolicyReject, false, []string{"valid", "skipped", "valid", "valid"}, []int{2}},
+		{config.DuplicatePolicyWarn, false, []string{"valid", "valid", "valid", "valid"}, []int{2}},
+		{config.DuplicatePolicyReject, true, []string{"valid", "valid", "valid", "valid"}, nil},
+		{config.DuplicatePolicyOff, false, []string{"valid", "valid", "valid", "valid"}, nil},
+	}
+	for _, c := range cases {
+		service := newImportTest(t, c.policy, nil)
+		result, err := service.Import(ownerAccess(), "csv", strings.NewReader(file), dto.ImportOptionsDTO{
+			DateFormats:      []string{"dd/mm/yyyy"},
+			CreateCategories: true,
+			AllowDuplicates:  c.allow,
+		}, true)
+		if err != nil {
+			t.Fatalf("%s: import: %v", c.policy, err)
+		}
+		statuses := make([]string, 0, len(result.Rows))
+		for _, row := range result.Rows {
+			statuses = append(statuses, row.Status)
+			if row.Line != 3 && len(row.DuplicateLines) > 0 {
+				t.Errorf("%s: line %d flagged as a duplicate of %v", c.policy, row.Line, row.DuplicateLines)
+			}
+		}
+		if !reflect.DeepEqual(statuses, c.statuses) {
+			t.Errorf("%s, allow %v: statuses = %v, want %v", c.policy, c.allow, statuses, c.statuses)
+		}
+		if got := result.Rows[1].DuplicateLines; !reflect.DeepEqual(got, c.duplicate) {
+			t.Errorf("%s, allow %v: line 3 duplicates %v, want %v", c.policy, c.allow, got, c.duplicate)
+		}
+	}
+}
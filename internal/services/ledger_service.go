@@ -0,0 +1,418 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/models"
+	"goExpenseTracker/internal/repositories"
+)
+
+// DefaultInviteTTL is how long an invitation can be accepted unless the owner chooses otherwise
+const DefaultInviteTTL = 7 * 24 * time.Hour
+
+// Invitation statuses
+const (
+	InviteStatusPending  = "pending"
+	InviteStatusAccepted = "accepted"
+	InviteStatusExpired  = "expired"
+)
+
+type LedgerService interface {
+	Create(userID int, req dto.LedgerRequestDTO) (dto.LedgerResponseDTO, error)
+	GetAll(userID int) ([]dto.LedgerResponseDTO, error)
+	GetByID(userID int, id int) (dto.LedgerResponseDTO, error)
+	Update(userID int, id int, req dto.LedgerRequestDTO) (dto.LedgerResponseDTO, error)
+	Delete(userID int, id int) error
+
+	GetMembers(userID int, id int) ([]dto.LedgerMemberResponseDTO, error)
+	UpdateMember(userID int, id int, memberID int, req dto.LedgerMemberRequestDTO) (dto.LedgerMemberResponseDTO, error)
+	RemoveMember(userID int, id int, memberID int) error
+
+	CreateInvite(userID int, id int, req dto.LedgerInviteRequestDTO) (dto.LedgerInviteResponseDTO, error)
+	GetInvites(userID int, id int) ([]dto.LedgerInviteResponseDTO, error)
+	RevokeInvite(userID int, id int, inviteID int) error
+	AcceptInvite(userID int, req dto.LedgerInviteAcceptDTO) (dto.LedgerResponseDTO, error)
+
+	Resolve(user models.User, ledgerID int) (Access, error)
+}
+
+type ledgerService struct {
+	ledgerRepo repositories.LedgerRepository
+	userRepo   repositories.UserRepository
+	location   *time.Location // The server timezone, for users who have not picked one
+}
+
+func NewLedgerService(ledgerRepo repositories.LedgerRepository, userRepo repositories.UserRepository, location *time.Location) LedgerService {
+	return &ledgerService{
+		ledgerRepo: ledgerRepo,
+		userRepo:   userRepo,
+		location:   location,
+	}
+}
+
+// Create ledger, owned by the user creating it
+func (s *ledgerService) Create(userID int, req dto.LedgerRequestDTO) (dto.LedgerResponseDTO, error) {
+	ledger := models.Ledger{
+		Name:      req.Name,
+		CreatedBy: userID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := s.ledgerRepo.Create(&ledger, userID); err != nil {
+		return dto.LedgerResponseDTO{}, err
+	}
+	return toLedgerDTO(ledger, models.RoleOwner), nil
+}
+
+// Get the ledgers the user is a member of
+func (s *ledgerService) GetAll(userID int) ([]dto.LedgerResponseDTO, error) {
+	memberships, err := s.ledgerRepo.GetForUser(userID)
+	if err != nil {
+		return []dto.LedgerResponseDTO{}, err
+	}
+
+	responses := make([]dto.LedgerResponseDTO, 0, len(memberships))
+	for _, membership := range memberships {
+		responses = append(responses, toLedgerDTO(membership.Ledger, membership.Role))
+	}
+	return responses, nil
+}
+
+// Get single ledger
+func (s *ledgerService) GetByID(userID int, id int) (dto.LedgerResponseDTO, error) {
+	ledger, member, err := s.membership(userID, id)
+	if err != nil {
+		return dto.LedgerResponseDTO{}, err
+	}
+	return toLedgerDTO(*ledger, member.Role), nil
+}
+
+// Rename ledger
+func (s *ledgerService) Update(userID int, id int, req dto.LedgerRequestDTO) (dto.LedgerResponseDTO, error) {
+	ledger, member, err := s.membership(userID, id)
+	if err != nil {
+		return dto.LedgerResponseDTO{}, err
+	}
+	if err := requireOwner(member); err != nil {
+		return dto.LedgerResponseDTO{}, err
+	}
+
+	ledger.Name = req.Name
+	ledger.UpdatedAt = time.Now()
+	if err := s.ledgerRepo.Update(ledger); err != nil {
+		return dto.LedgerResponseDTO{}, err
+	}
+	return toLedgerDTO(*ledger, member.Role), nil
+}
+
+// Delete removes an empty ledger. Personal ledgers stay, and ledgers still
+// holding data must be emptied first so nothing is lost by accident.
+func (s *ledgerService) Delete(userID int, id int) error {
+	ledger, member, err := s.membership(userID, id)
+	if err != nil {
+		return err
+	}
+	if err := requireOwner(member); err != nil {
+		return err
+	}
+	if ledger.Personal {
+		return newValidationError("a personal ledger cannot be deleted")
+	}
+
+	hasData, err := s.ledgerRepo.HasData(uint(id))
+	if err != nil {
+		return err
+	}
+	if hasData {
+		return newValidationError("ledger still has data; delete its expenses, categories and other records first")
+	}
+	return s.ledgerRepo.Delete(uint(id))
+}
+
+// Get the members of a ledger, for any of its members
+func (s *ledgerService) GetMembers(userID int, id int) ([]dto.LedgerMemberResponseDTO, error) {
+	if _, _, err := s.membership(userID, id); err != nil {
+		return []dto.LedgerMemberResponseDTO{}, err
+	}
+
+	members, err := s.ledgerRepo.GetMembers(id)
+	if err != nil {
+		return []dto.LedgerMemberResponseDTO{}, err
+	}
+
+	responses := make([]dto.LedgerMemberResponseDTO, 0, len(members))
+	for _, member := range members {
+		responses = append(responses, s.toMemberDTO(member))
+	}
+	return responses, nil
+}
+
+// UpdateMember changes the role of a member; a ledger always keeps an owner
+func (s *ledgerService) UpdateMember(userID int, id int, memberID int, req dto.LedgerMemberRequestDTO) (dto.LedgerMemberResponseDTO, error) {
+	_, caller, err := s.membership(userID, id)
+	if err != nil {
+		return dto.LedgerMemberResponseDTO{}, err
+	}
+	if err := requireOwner(caller); err != nil {
+		return dto.LedgerMemberResponseDTO{}, err
+	}
+
+	member, err := s.ledgerRepo.GetMember(id, memberID)
+	if err != nil {
+		return dto.LedgerMemberResponseDTO{}, fmt.Errorf("member not found")
+	}
+
+	member.Role = req.Role
+	member.UpdatedAt = time.Now()
+	if err := s.ledgerRepo.UpdateMember(member); err != nil {
+		if errors.Is(err, repositories.ErrLastOwner) {
+			return dto.LedgerMemberResponseDTO{}, newValidationError("%s", err.Error())
+		}
+		return dto.LedgerMemberResponseDTO{}, err
+	}
+	return s.toMemberDTO(*member), nil
+}
+
+// RemoveMember takes a user out of a ledger. Owners may remove anyone and every
+// member may leave, except the last owner.
+func (s *ledgerService) RemoveMember(userID int, id int, memberID int) error {
+	_, caller, err := s.membership(userID, id)
+	if err != nil {
+		return err
+	}
+	if memberID != userID {
+		if err := requireOwner(caller); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.ledgerRepo.GetMember(id, memberID); err != nil {
+		return fmt.Errorf("member not found")
+	}
+	if err := s.ledgerRepo.DeleteMember(id, memberID); err != nil {
+		if errors.Is(err, repositories.ErrLastOwner) {
+			return newValidationError("%s", err.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+// CreateInvite issues a single-use invitation token. Only its hash is stored, so
+// the token is shown this once.
+func (s *ledgerService) CreateInvite(userID int, id int, req dto.LedgerInviteRequestDTO) (dto.LedgerInviteResponseDTO, error) {
+	_, caller, err := s.membership(userID, id)
+	if err != nil {
+		return dto.LedgerInviteResponseDTO{}, err
+	}
+	if err := requireOwner(caller); err != nil {
+		return dto.LedgerInviteResponseDTO{}, err
+	}
+
+	token, err := newSecretToken("")
+	if err != nil {
+		return dto.LedgerInviteResponseDTO{}, err
+	}
+	ttl := DefaultInviteTTL
+	if req.ExpiresInHours > 0 {
+		ttl = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+	invite := models.LedgerInvite{
+		LedgerID:  id,
+		TokenHash: hashSecretToken(token),
+		Role:      req.Role,
+		Email:     normalizeEmail(req.Email),
+		CreatedBy: userID,
+		ExpiresAt: time.Now().Add(ttl),
+		CreatedAt: time.Now(),
+	}
+	if err := s.ledgerRepo.CreateInvite(&invite); err != nil {
+		return dto.LedgerInviteResponseDTO{}, err
+	}
+
+	response := toInviteDTO(invite)
+	response.Token = token
+	return response, nil
+}
+
+// Get the invitations of a ledger, for its owners
+func (s *ledgerService) GetInvites(userID int, id int) ([]dto.LedgerInviteResponseDTO, error) {
+	_, caller, err := s.membership(userID, id)
+	if err != nil {
+		return []dto.LedgerInviteResponseDTO{}, err
+	}
+	if err := requireOwner(caller); err != nil {
+		return []dto.LedgerInviteResponseDTO{}, err
+	}
+
+	invites, err := s.ledgerRepo.GetInvites(id)
+	if err != nil {
+		return []dto.LedgerInviteResponseDTO{}, err
+	}
+
+	responses := make([]dto.LedgerInviteResponseDTO, 0, len(invites))
+	for _, invite := range invites {
+		responses = append(responses, toInviteDTO(invite))
+	}
+	return responses, nil
+}
+
+// RevokeInvite deletes an invitation so its token can no longer be used
+func (s *ledgerService) RevokeInvite(userID int, id int, inviteID int) error {
+	_, caller, err := s.membership(userID, id)
+	if err != nil {
+		return err
+	}
+	if err := requireOwner(caller); err != nil {
+		return err
+	}
+
+	if _, err := s.ledgerRepo.GetInvite(id, uint(inviteID)); err != nil {
+		return fmt.Errorf("invitation not found")
+	}
+	return s.ledgerRepo.DeleteInvite(id, uint(inviteID))
+}
+
+// AcceptInvite adds the user to the ledger of an unused, unexpired invitation
+// with the role it grants
+func (s *ledgerService) AcceptInvite(userID int, req dto.LedgerInviteAcceptDTO) (dto.LedgerResponseDTO, error) {
+	invalid := newValidationError("invitation is invalid, used or expired")
+	invite, err := s.ledgerRepo.GetInviteByHash(hashSecretToken(req.Token))
+	if err != nil || invite.AcceptedAt != nil || time.Now().After(invite.ExpiresAt) {
+		return dto.LedgerResponseDTO{}, invalid
+	}
+
+	if invite.Email != "" {
+		user, err := s.userRepo.GetByID(uint(userID))
+		if err != nil {
+			return dto.LedgerResponseDTO{}, err
+		}
+		if user.Email != invite.Email {
+			return dto.LedgerResponseDTO{}, &ForbiddenError{Message: "this invitation is for another email address"}
+		}
+	}
+	if _, err := s.ledgerRepo.GetMember(invite.LedgerID, userID); err == nil {
+		return dto.LedgerResponseDTO{}, newValidationError("you are already a member of this ledger")
+	}
+
+	ledger, err := s.ledgerRepo.GetByID(uint(invite.LedgerID))
+	if err != nil {
+		return dto.LedgerResponseDTO{}, invalid
+	}
+
+	now := time.Now()
+	invite.AcceptedBy = &userID
+	invite.AcceptedAt = &now
+	member := models.LedgerMember{
+		LedgerID:  invite.LedgerID,
+		UserID:    userID,
+		Role:      invite.Role,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.ledgerRepo.AcceptInvite(invite, &member); err != nil {
+		if errors.Is(err, repositories.ErrInviteUsed) {
+			return dto.LedgerResponseDTO{}, invalid
+		}
+		return dto.LedgerResponseDTO{}, err
+	}
+	return toLedgerDTO(*ledger, member.Role), nil
+}
+
+// Resolve checks the user's membership of a ledger and returns what they may do
+// in it, and in which timezone. Without a ledger ID it picks the user's first
+// ledger, their personal one. The user is the one authentication loaded, so
+// their timezone costs no further lookup.
+func (s *ledgerService) Resolve(user models.User, ledgerID int) (Access, error) {
+	userID := user.ID
+	access := Access{UserID: userID, LedgerID: ledgerID, Location: userLocation(user, s.location)}
+
+	if ledgerID == 0 {
+		memberships, err := s.ledgerRepo.GetForUser(userID)
+		if err != nil {
+			return Access{}, err
+		}
+		if len(memberships) == 0 {
+			return Access{}, &ForbiddenError{Message: "you are not a member of any ledger"}
+		}
+		access.LedgerID, access.Role = memberships[0].ID, memberships[0].Role
+		return access, nil
+	}
+
+	member, err := s.ledgerRepo.GetMember(ledgerID, userID)
+	if err != nil {
+		return Access{}, &ForbiddenError{Message: "you are not a member of this ledger"}
+	}
+	access.Role = member.Role
+	return access, nil
+}
+
+// Helper: Find a ledger with the user's membership; ledgers of others are not found
+func (s *ledgerService) membership(userID int, id int) (*models.Ledger, *models.LedgerMember, error) {
+	member, err := s.ledgerRepo.GetMember(id, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ledger not found")
+	}
+	ledger, err := s.ledgerRepo.GetByID(uint(id))
+	if err != nil {
+		return nil, nil, fmt.Errorf("ledger not found")
+	}
+	return ledger, member, nil
+}
+
+// Helper: Convert model → Response DTO
+func (s *ledgerService) toMemberDTO(member models.LedgerMember) dto.LedgerMemberResponseDTO {
+	response := dto.LedgerMemberResponseDTO{
+		UserID:   member.UserID,
+		Role:     member.Role,
+		JoinedAt: member.CreatedAt,
+	}
+	if user, err := s.userRepo.GetByID(uint(member.UserID)); err == nil {
+		response.Email = user.Email
+		response.Name = user.Name
+	}
+	return response
+}
+
+// requireOwner refuses managing a ledger to everyone but its owners
+func requireOwner(member *models.LedgerMember) error {
+	if member.Role != models.RoleOwner {
+		return &ForbiddenError{Message: "only owners can manage the ledger"}
+	}
+	return nil
+}
+
+func toLedgerDTO(ledger models.Ledger, role string) dto.LedgerResponseDTO {
+	return dto.LedgerResponseDTO{
+		ID:        ledger.ID,
+		Name:      ledger.Name,
+		Personal:  ledger.Personal,
+		Role:      role,
+		CreatedAt: ledger.CreatedAt,
+		UpdatedAt: ledger.UpdatedAt,
+	}
+}
+
+func toInviteDTO(invite models.LedgerInvite) dto.LedgerInviteResponseDTO {
+	status := InviteStatusPending
+	switch {
+	case invite.AcceptedAt != nil:
+		status = InviteStatusAccepted
+	case time.Now().After(invite.ExpiresAt):
+		status = InviteStatusExpired
+	}
+	return dto.LedgerInviteResponseDTO{
+		ID:         invite.ID,
+		LedgerID:   invite.LedgerID,
+		Role:       invite.Role,
+		Email:      invite.Email,
+		Status:     status,
+		ExpiresAt:  invite.ExpiresAt,
+		AcceptedBy: invite.AcceptedBy,
+		AcceptedAt: invite.AcceptedAt,
+		CreatedAt:  invite.CreatedAt,
+	}
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/models"
+	"goExpenseTracker/internal/repositories"
+)
+
+// newSharedLedger returns the service over a test database holding a ledger owned
+// by users 1 and 2
+func newSharedLedger(t *testing.T) (LedgerService, int) {
+	t.Helper()
+	db := openTestDB(t)
+	ledgerRepo := repositories.NewLedgerRepository(db)
+	ledger := models.Ledger{Name: "Household", CreatedBy: 1, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	if err := ledgerRepo.Create(&ledger, 1); err != nil {
+		t.Fatalf("create ledger: %v", err)
+	}
+	second := models.LedgerMember{LedgerID: ledger.ID, UserID: 2, Role: models.RoleOwner, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	if err := db.Create(&second).Error; err != nil {
+		t.Fatalf("add owner: %v", err)
+	}
+	return NewLedgerService(ledgerRepo, repositories.NewUserRepository(db), time.UTC), ledger.ID
+}
+
+func TestLedgerKeepsAnOwner(t *testing.T) {
+	service, id := newSharedLedger(t)
+	var validationErr *ValidationError
+
+	if _, err := service.UpdateMember(1, id, 2, dto.LedgerMemberRequestDTO{Role: models.RoleEditor}); err != nil {
+		t.Fatalf("demote the second owner: %v", err)
+	}
+	if _, err := service.UpdateMember(1, id, 1, dto.LedgerMemberRequestDTO{Role: models.RoleViewer}); !errors.As(err, &validationErr) {
+		t.Errorf("demote the last owner: err = %v, want a validation error", err)
+	}
+	if err := service.RemoveMember(1, id, 1); !errors.As(err, &validationErr) {
+		t.Errorf("last owner leaves: err = %v, want a validation error", err)
+	}
+	if _, err := service.UpdateMember(1, id, 1, dto.LedgerMemberRequestDTO{Role: models.RoleOwner}); err != nil {
+		t.Errorf("the last owner stays an owner: %v", err)
+	}
+	if err := service.RemoveMember(1, id, 2); err != nil {
+		t.Errorf("remove an editor: %v", err)
+	}
+}
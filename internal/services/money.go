@@ -0,0 +1,13 @@
+package services
+
+import "math"
+
+// roundMoney rounds an amount to two decimal places
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// toCents converts an amount to whole cents so sums can be compared exactly
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	dto "goExpenseTracker/internal/DTOs"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// oidcLoginTTL is how long a sign-in started at the identity provider can be completed
+const oidcLoginTTL = 10 * time.Minute
+
+// oidcMaxPendingLogins bounds the sign-ins in progress kept in memory
+const oidcMaxPendingLogins = 10000
+
+// oidcHTTPTimeout bounds each call to the identity provider
+const oidcHTTPTimeout = 10 * time.Second
+
+// OIDCOptions configures sign-in through an OpenID Connect identity provider
+type OIDCOptions struct {
+	IssuerURL     string   // Where the discovery document is found, under /.well-known/openid-configuration
+	ClientID      string   // This server's client at the provider
+	ClientSecret  string   // Empty for public clients, which rely on PKCE alone
+	RedirectURL   string   // The callback route as the provider sends users back to it
+	Scopes        []string // Requested besides openid
+	AutoProvision bool     // Whether users unknown here are created on their first sign-in
+}
+
+// Enabled reports whether an identity provider is configured
+func (o OIDCOptions) Enabled() bool {
+	return o.IssuerURL != "" && o.ClientID != "" && o.RedirectURL != ""
+}
+
+// ErrOIDCDisabled is returned when no identity provider is configured
+var ErrOIDCDisabled = errors.New("single sign-on is not configured")
+
+type OIDCService interface {
+	Begin(ctx context.Context) (string, error)
+	Complete(ctx context.Context, state, code string) (dto.AuthTokenDTO, error)
+}
+
+// oidcService signs users in with the authorization code flow and PKCE. The
+// provider's discovery document is read on first use, and ID tokens are checked
+// against its published signing keys, which are fetched again when they rotate.
+// Sign-ins in progress are kept in memory, so the callback must reach the server
+// instance that started them.
+type oidcService struct {
+	authService AuthService
+	options     OIDCOptions
+	client      *http.Client
+
+	mu       sync.Mutex
+	provider *oidc.Provider // Nil until discovered
+	config   oauth2.Config
+	verifier *oidc.IDTokenVerifier
+	pending  map[string]pendingLogin // By state
+}
+
+// pendingLogin is what the callback of a sign-in needs to finish it
+type pendingLogin struct {
+	codeVerifier string
+	nonce        string
+	expiresAt    time.Time
+}
+
+func NewOIDCService(authService AuthService, options OIDCOptions) OIDCService {
+	return &oidcService{
+		authService: authService,
+		options:     options,
+		client:      &http.Client{Timeout: oidcHTTPTimeout},
+		pending:     make(map[string]pendingLogin),
+	}
+}
+
+// Begin starts a sign-in and returns the provider URL to send the user to
+func (s *oidcService) Begin(ctx context.Context) (string, error) {
+	if !s.options.Enabled() {
+		return "", ErrOIDCDisabled
+	}
+	config, _, err := s.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	state, err := newSecretToken("")
+	if err != nil {
+		return "", err
+	}
+	nonce, err := newSecretToken("")
+	if err != nil {
+		return "", err
+	}
+	codeVerifier := oauth2.GenerateVerifier()
+
+	s.mu.Lock()
+	now := time.Now()
+	for key, login := range s.pending {
+		if now.After(login.expiresAt) {
+			delete(s.pending, key)
+		}
+	}
+	if len(s.pending) >= oidcMaxPendingLogins {
+		s.mu.Unlock()
+		return "", errors.New("too many sign-ins in progress; try again shortly")
+	}
+	s.pending[state] = pendingLogin{codeVerifier: codeVerifier, nonce: nonce, expiresAt: now.Add(oidcLoginTTL)}
+	s.mu.Unlock()
+
+	return config.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(codeVerifier)), nil
+}
+
+// Complete finishes a sign-in when the provider sends the user back: it redeems
+// the code with the PKCE verifier, validates the ID token and signs in the user
+// it names, creating them when allowed
+func (s *oidcService) Complete(ctx context.Context, state, code string) (dto.AuthTokenDTO, error) {
+	if !s.options.Enabled() {
+		return dto.AuthTokenDTO{}, ErrOIDCDisabled
+	}
+
+	// A state can be used once, whatever the outcome
+	s.mu.Lock()
+	login, ok := s.pending[state]
+	delete(s.pending, state)
+	s.mu.Unlock()
+	if !ok || time.Now().After(login.expiresAt) {
+		return dto.AuthTokenDTO{}, &AuthError{Message: "sign-in expired or was already completed; start again"}
+	}
+
+	config, verifier, err := s.discover(ctx)
+	if err != nil {
+		return dto.AuthTokenDTO{}, err
+	}
+	ctx = oidc.ClientContext(ctx, s.client)
+	token, err := config.Exchange(ctx, code, oauth2.VerifierOption(login.codeVerifier))
+	if err != nil {
+		log.Printf("oidc: exchange authorization code: %v", err)
+		return dto.AuthTokenDTO{}, &AuthError{Message: "the identity provider did not accept the authorization code"}
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return dto.AuthTokenDTO{}, &AuthError{Message: "the identity provider returned no ID token"}
+	}
+
+	idToken, err := verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		log.Printf("oidc: verify ID token: %v", err)
+		return dto.AuthTokenDTO{}, &AuthError{Message: "invalid ID token"}
+	}
+	if idToken.Nonce != login.nonce {
+		return dto.AuthTokenDTO{}, &AuthError{Message: "invalid ID token"}
+	}
+
+	var claims struct {
+		Email             string `json:"email"`
+		EmailVerified     *bool  `json:"email_verified"`
+		Name              string `json:"name"`
+		PreferredUsername string `json:"preferred_username"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return dto.AuthTokenDTO{}, &AuthError{Message: "invalid ID token"}
+	}
+	name := claims.Name
+	if name == "" {
+		name = claims.PreferredUsername
+	}
+
+	return s.authService.SignInExternal(ExternalIdentity{
+		Issuer:        idToken.Issuer,
+		Subject:       idToken.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          name,
+	}, s.options.AutoProvision)
+}
+
+// Helper: Read the provider's discovery document unless that already happened.
+// Failures are not remembered, so a provider that was down is tried again.
+func (s *oidcService) discover(ctx context.Context) (oauth2.Config, *oidc.IDTokenVerifier, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.provider != nil {
+		return s.config, s.verifier, nil
+	}
+
+	provider, err := oidc.NewProvider(oidc.ClientContext(ctx, s.client), s.options.IssuerURL)
+	if err != nil {
+		log.Printf("oidc: discover %s: %v", s.options.IssuerURL, err)
+		return oauth2.Config{}, nil, fmt.Errorf("could not reach the identity provider")
+	}
+
+	var metadata struct {
+		CodeChallengeMethods []string `json:"code_challenge_methods_supported"`
+	}
+	if err := provider.Claims(&metadata); err != nil {
+		return oauth2.Config{}, nil, err
+	}
+	// Providers that do not list their PKCE methods are given the benefit of the doubt
+	if len(metadata.CodeChallengeMethods) > 0 && !slices.Contains(metadata.CodeChallengeMethods, "S256") {
+		return oauth2.Config{}, nil, fmt.Errorf("the identity provider does not support PKCE with S256")
+	}
+
+	scopes := []string{oidc.ScopeOpenID}
+	for _, scope := range s.options.Scopes {
+		if scope != oidc.ScopeOpenID {
+			scopes = append(scopes, scope)
+		}
+	}
+	s.provider = provider
+	s.config = oauth2.Config{
+		ClientID:     s.options.ClientID,
+		ClientSecret: s.options.ClientSecret,
+		RedirectURL:  s.options.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       scopes,
+	}
+	s.verifier = provider.Verifier(&oidc.Config{ClientID: s.options.ClientID})
+	return s.config, s.verifier, nil
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/models"
+	"goExpenseTracker/internal/repositories"
+)
+
+type PersonService interface {
+	Create(access Access, req dto.PersonRequestDTO) (dto.PersonResponseDTO, error)
+	GetAll(access Access, offset, limit int, nameFilter string) ([]dto.PersonResponseDTO, error)
+	GetByID(access Access, id int) (dto.PersonResponseDTO, error)
+	Update(access Access, id int, req dto.PersonRequestDTO) (dto.PersonResponseDTO, error)
+	Delete(access Access, id int) error
+}
+
+type personService struct {
+	repo        repositories.PersonRepository
+	expenseRepo repositories.ExpenseRepository
+}
+
+func NewPersonService(repo repositories.PersonRepository, expenseRepo repositories.ExpenseRepository) PersonService {
+	return &personService{repo: repo, expenseRepo: expenseRepo}
+}
+
+// Create person
+func (s *personService) Create(access Access, req dto.PersonRequestDTO) (dto.PersonResponseDTO, error) {
+	if err := requireEditor(access); err != nil {
+		return dto.PersonResponseDTO{}, err
+	}
+	person := models.Person{
+		UserID:    access.UserID,
+		LedgerID:  access.LedgerID,
+		Name:      req.Name,
+		Email:     req.Email,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if err := s.repo.Create(&person); err != nil {
+		return dto.PersonResponseDTO{}, err
+	}
+
+	return s.toResponseDTO(person), nil
+}
+
+// Get all people
+func (s *personService) GetAll(access Access, offset, limit int, nameFilter string) ([]dto.PersonResponseDTO, error) {
+	people, err := s.repo.GetAll(access.LedgerID, offset, limit, nameFilter)
+	if err != nil {
+		return []dto.PersonResponseDTO{}, err
+	}
+
+	responses := make([]dto.PersonResponseDTO, 0)
+	for _, person := range people {
+		responses = append(responses, s.toResponseDTO(person))
+	}
+	return responses, nil
+}
+
+// Get single person
+func (s *personService) GetByID(access Access, id int) (dto.PersonResponseDTO, error) {
+	person, err := s.repo.GetByID(access.LedgerID, uint(id))
+	if err != nil {
+		return dto.PersonResponseDTO{}, fmt.Errorf("person not found")
+	}
+	return s.toResponseDTO(*person), nil
+}
+
+// Update person
+func (s *personService) Update(access Access, id int, req dto.PersonRequestDTO) (dto.PersonResponseDTO, error) {
+	if err := requireEditor(access); err != nil {
+		return dto.PersonResponseDTO{}, err
+	}
+	existing, err := s.repo.GetByID(access.LedgerID, uint(id))
+	if err != nil {
+		return dto.PersonResponseDTO{}, fmt.Errorf("person not found")
+	}
+
+	existing.Name = req.Name
+	existing.Email = req.Email
+	existing.UpdatedAt = time.Now()
+
+	if err := s.repo.Update(existing); err != nil {
+		return dto.PersonResponseDTO{}, err
+	}
+
+	return s.toResponseDTO(*existing), nil
+}
+
+// Delete person, refusing while shared expenses or settlements still reference them
+func (s *personService) Delete(access Access, id int) error {
+	if err := requireEditor(access); err != nil {
+		return err
+	}
+	hasActivity, err := s.expenseRepo.UsesPerson(access.LedgerID, id)
+	if err == nil && !hasActivity {
+		hasActivity, err = s.repo.HasSettlements(access.LedgerID, uint(id))
+	}
+	if err != nil {
+		return err
+	}
+	if hasActivity {
+		return newValidationError("person has shared expenses or settlements")
+	}
+	return s.repo.Delete(access.LedgerID, uint(id))
+}
+
+// Private helper for mapping model → DTO
+func (s *personService) toResponseDTO(person models.Person) dto.PersonResponseDTO {
+	return dto.PersonResponseDTO{
+		ID:        person.ID,
+		Name:      person.Name,
+		Email:     person.Email,
+		CreatedAt: person.CreatedAt,
+		UpdatedAt: person.UpdatedAt,
+	}
+}
@@ -0,0 +1,294 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/exporters"
+	"goExpenseTracker/internal/models"
+	"goExpenseTracker/internal/repositories"
+)
+
+type ReportService interface {
+	CategoryTotals(access Access, from, to time.Time) (dto.CategoryReportDTO, error)
+	ExpensePDF(access Access, w io.Writer, req dto.ExpenseReportPDFDTO) error
+}
+
+// defaultSignOff are the signature lines of a PDF report when none are requested
+var defaultSignOff = []string{"Prepared by", "Approved by"}
+
+// thumbnailTypes are the receipt types a PDF report can preview
+var thumbnailTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+type reportService struct {
+	expenseRepo  repositories.ExpenseRepository
+	categoryRepo repositories.CategoryRepository
+	accountRepo  repositories.AccountRepository
+	tagRepo      repositories.TagRepository
+	attachments  AttachmentService
+}
+
+func NewReportService(expenseRepo repositories.ExpenseRepository, categoryRepo repositories.CategoryRepository, accountRepo repositories.AccountRepository, tagRepo repositories.TagRepository, attachments AttachmentService) ReportService {
+	return &reportService{
+		expenseRepo:  expenseRepo,
+		categoryRepo: categoryRepo,
+		accountRepo:  accountRepo,
+		tagRepo:      tagRepo,
+		attachments:  attachments,
+	}
+}
+
+// CategoryTotals reports spending per category, attributing split expenses line by line
+func (s *reportService) CategoryTotals(access Access, from, to time.Time) (dto.CategoryReportDTO, error) {
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return dto.CategoryReportDTO{}, newValidationError("to must not be before from")
+	}
+
+	totals, err := s.expenseRepo.CategoryTotals(access.LedgerID, from, to)
+	if err != nil {
+		return dto.CategoryReportDTO{}, err
+	}
+
+	report := dto.CategoryReportDTO{
+		Categories: make([]dto.CategoryTotalDTO, 0, len(totals)),
+	}
+	if !from.IsZero() {
+		report.From = from.Format("2006-01-02")
+	}
+	if !to.IsZero() {
+		report.To = to.Format("2006-01-02")
+	}
+
+	for _, total := range totals {
+		line := dto.CategoryTotalDTO{
+			CategoryID: total.CategoryID,
+			Total:      roundMoney(total.Total),
+			Count:      total.Count,
+		}
+		if category, err := s.categoryRepo.GetByID(access.LedgerID, uint(total.CategoryID)); err == nil {
+			line.CategoryName = category.Name
+		}
+		report.Total += total.Total
+		report.Categories = append(report.Categories, line)
+	}
+	report.Total = roundMoney(report.Total)
+
+	return report, nil
+}
+
+// ExpensePDF renders the selected expenses as a PDF with a section per category.
+// Split expenses contribute each split line to its own category, and when the
+// selection names a category only the lines in that category are printed.
+// Refunds reduce their category's subtotal; income is left out.
+func (s *reportService) ExpensePDF(access Access, w io.Writer, req dto.ExpenseReportPDFDTO) error {
+	criteria, err := toExpenseFilter(req.ExpenseFilterDTO)
+	if err != nil {
+		return err
+	}
+
+	report := exporters.ExpenseReport{
+		Title:       strings.TrimSpace(req.Title),
+		Period:      reportPeriod(criteria.From, criteria.To),
+		GeneratedAt: access.Now(),
+		SignOff:     signOffRoles(req.SignOff),
+	}
+	if report.Title == "" {
+		report.Title = "Expense report"
+	}
+	if report.Selection, err = s.describeSelection(access.LedgerID, criteria); err != nil {
+		return err
+	}
+
+	expenses, err := s.expenseRepo.Find(access.LedgerID, criteria)
+	if err != nil {
+		return err
+	}
+
+	sections := make(map[int]*exporters.ReportSection)
+	subtotals := make(map[int]int64)
+	var included []models.Expense
+	addLine := func(categoryID int, line exporters.ReportLine) {
+		section, ok := sections[categoryID]
+		if !ok {
+			section = &exporters.ReportSection{Category: s.categoryName(access.LedgerID, categoryID)}
+			sections[categoryID] = section
+		}
+		section.Lines = append(section.Lines, line)
+		subtotals[categoryID] += toCents(line.Amount)
+	}
+
+	for _, expense := range expenses {
+		if expense.Kind == models.KindIncome {
+			continue
+		}
+		sign := 1.0
+		if expense.Kind == models.KindRefund {
+			sign = -1
+		}
+		names := make([]string, 0, len(expense.Tags))
+		for _, tag := range expense.Tags {
+			names = append(names, tag.Name)
+		}
+		line := exporters.ReportLine{
+			ExpenseID:   expense.ID,
+			Date:        expense.Date,
+			Description: expense.Description,
+			Tags:        strings.Join(names, ", "),
+			Currency:    expense.Currency,
+		}
+
+		printed := false
+		if len(expense.Splits) == 0 {
+			line.Amount = sign * expense.Amount
+			addLine(expense.CategoryID, line)
+			printed = true
+		}
+		for _, split := range expense.Splits {
+			if criteria.CategoryID > 0 && split.CategoryID != criteria.CategoryID {
+				continue
+			}
+			splitLine := line
+			splitLine.Amount = sign * split.Amount
+			if split.Description != "" {
+				splitLine.Description = strings.TrimSpace(expense.Description + " - " + split.Description)
+			}
+			addLine(split.CategoryID, splitLine)
+			printed = true
+		}
+		if printed {
+			included = append(included, expense)
+		}
+	}
+
+	var totalCents int64
+	for categoryID, section := range sections {
+		section.Subtotal = float64(subtotals[categoryID]) / 100
+		totalCents += subtotals[categoryID]
+		report.Sections = append(report.Sections, *section)
+	}
+	sort.Slice(report.Sections, func(i, j int) bool {
+		return strings.ToLower(report.Sections[i].Category) < strings.ToLower(report.Sections[j].Category)
+	})
+	report.Total = float64(totalCents) / 100
+
+	if req.Receipts {
+		report.Receipts = s.receipts(access, included)
+	}
+
+	return exporters.WriteExpenseReport(w, report)
+}
+
+// describeSelection lists the filters of a report in words, checking that the
+// category, account and tag it names exist
+func (s *reportService) describeSelection(ledgerID int, criteria repositories.ExpenseFilter) ([]string, error) {
+	var selection []string
+	if criteria.CategoryID > 0 {
+		category, err := s.categoryRepo.GetByID(ledgerID, uint(criteria.CategoryID))
+		if err != nil {
+			return nil, newValidationError("category not found")
+		}
+		selection = append(selection, "Category: "+category.Name)
+	}
+	if criteria.AccountID > 0 {
+		account, err := s.accountRepo.GetByID(ledgerID, uint(criteria.AccountID))
+		if err != nil {
+			return nil, newValidationError("account not found")
+		}
+		selection = append(selection, "Account: "+account.Name)
+	}
+	if criteria.TagID > 0 {
+		tag, err := s.tagRepo.GetByID(ledgerID, uint(criteria.TagID))
+		if err != nil {
+			return nil, newValidationError("tag not found")
+		}
+		selection = append(selection, "Tag: "+tag.Name)
+	}
+	if criteria.Description != "" {
+		selection = append(selection, fmt.Sprintf("Description contains %q", criteria.Description))
+	}
+	return selection, nil
+}
+
+// receipts collects previews of the receipts of the printed expenses. A receipt
+// that cannot be read is listed without a preview rather than failing the report.
+func (s *reportService) receipts(access Access, expenses []models.Expense) []exporters.ReportReceipt {
+	var receipts []exporters.ReportReceipt
+	for _, expense := range expenses {
+		attachments, err := s.attachments.List(access, expense.ID)
+		if err != nil {
+			log.Printf("report: list receipts of expense %d: %v", expense.ID, err)
+			continue
+		}
+		for _, attachment := range attachments {
+			receipt := exporters.ReportReceipt{
+				ExpenseID: expense.ID,
+				Date:      expense.Date,
+				FileName:  attachment.FileName,
+			}
+			if thumbnailTypes[attachment.ContentType] {
+				thumbnail, err := s.thumbnail(access, expense.ID, attachment.ID)
+				if err != nil {
+					log.Printf("report: preview receipt %d of expense %d: %v", attachment.ID, expense.ID, err)
+				}
+				receipt.Thumbnail = thumbnail
+			}
+			receipts = append(receipts, receipt)
+		}
+	}
+	return receipts
+}
+
+func (s *reportService) thumbnail(access Access, expenseID, attachmentID int) ([]byte, error) {
+	_, reader, err := s.attachments.Open(access, expenseID, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return exporters.ReceiptThumbnail(reader)
+}
+
+func (s *reportService) categoryName(ledgerID int, id int) string {
+	if category, err := s.categoryRepo.GetByID(ledgerID, uint(id)); err == nil {
+		return category.Name
+	}
+	return fmt.Sprintf("Category %d", id)
+}
+
+// reportPeriod describes a date range where zero means no bound
+func reportPeriod(from, to time.Time) string {
+	const layout = "02 Jan 2006"
+	switch {
+	case from.IsZero() && to.IsZero():
+		return "All dates"
+	case from.IsZero():
+		return "Up to " + to.Format(layout)
+	case to.IsZero():
+		return "From " + from.Format(layout)
+	default:
+		return from.Format(layout) + " – " + to.Format(layout)
+	}
+}
+
+// signOffRoles drops blank roles; asking for none at all gives the default lines,
+// while a single blank sign_off leaves them out
+func signOffRoles(requested []string) []string {
+	if len(requested) == 0 {
+		return defaultSignOff
+	}
+	var roles []string
+	for _, role := range requested {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
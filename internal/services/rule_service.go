@@ -0,0 +1,391 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/models"
+	"goExpenseTracker/internal/repositories"
+)
+
+type RuleService interface {
+	Create(access Access, req dto.RuleRequestDTO) (dto.RuleResponseDTO, error)
+	GetAll(access Access, offset, limit int) ([]dto.RuleResponseDTO, error)
+	GetByID(access Access, id int) (dto.RuleResponseDTO, error)
+	Update(access Access, id int, req dto.RuleRequestDTO) (dto.RuleResponseDTO, error)
+	Delete(access Access, id int) error
+	Test(access Access, req dto.RuleRequestDTO, limit int) (dto.RuleTestResultDTO, error)
+	Load(ledgerID int) (*RuleSet, error)
+}
+
+// ruleTestBatchSize is how many expenses a rule test reads at a time
+const ruleTestBatchSize = 500
+
+type ruleService struct {
+	ruleRepo     repositories.RuleRepository
+	expenseRepo  repositories.ExpenseRepository
+	categoryRepo repositories.CategoryRepository
+	accountRepo  repositories.AccountRepository
+	tagRepo      repositories.TagRepository
+}
+
+func NewRuleService(ruleRepo repositories.RuleRepository, expenseRepo repositories.ExpenseRepository, categoryRepo repositories.CategoryRepository, accountRepo repositories.AccountRepository, tagRepo repositories.TagRepository) RuleService {
+	return &ruleService{
+		ruleRepo:     ruleRepo,
+		expenseRepo:  expenseRepo,
+		categoryRepo: categoryRepo,
+		accountRepo:  accountRepo,
+		tagRepo:      tagRepo,
+	}
+}
+
+// Create rule
+func (s *ruleService) Create(access Access, req dto.RuleRequestDTO) (dto.RuleResponseDTO, error) {
+	if err := requireEditor(access); err != nil {
+		return dto.RuleResponseDTO{}, err
+	}
+	rule, err := s.buildRule(access, req)
+	if err != nil {
+		return dto.RuleResponseDTO{}, err
+	}
+	rule.CreatedAt = rule.UpdatedAt
+
+	if err := s.ruleRepo.Create(&rule); err != nil {
+		return dto.RuleResponseDTO{}, err
+	}
+
+	return s.toResponseDTO(rule), nil
+}
+
+// Get all rules in the order they run
+func (s *ruleService) GetAll(access Access, offset, limit int) ([]dto.RuleResponseDTO, error) {
+	rules, err := s.ruleRepo.GetAll(access.LedgerID, offset, limit)
+	if err != nil {
+		return []dto.RuleResponseDTO{}, err
+	}
+
+	responses := make([]dto.RuleResponseDTO, 0, len(rules))
+	for _, rule := range rules {
+		responses = append(responses, s.toResponseDTO(rule))
+	}
+	return responses, nil
+}
+
+// Get single rule
+func (s *ruleService) GetByID(access Access, id int) (dto.RuleResponseDTO, error) {
+	rule, err := s.ruleRepo.GetByID(access.LedgerID, uint(id))
+	if err != nil {
+		return dto.RuleResponseDTO{}, fmt.Errorf("rule not found")
+	}
+	return s.toResponseDTO(*rule), nil
+}
+
+// Update rule
+func (s *ruleService) Update(access Access, id int, req dto.RuleRequestDTO) (dto.RuleResponseDTO, error) {
+	if err := requireEditor(access); err != nil {
+		return dto.RuleResponseDTO{}, err
+	}
+	existing, err := s.ruleRepo.GetByID(access.LedgerID, uint(id))
+	if err != nil {
+		return dto.RuleResponseDTO{}, fmt.Errorf("rule not found")
+	}
+
+	rule, err := s.buildRule(access, req)
+	if err != nil {
+		return dto.RuleResponseDTO{}, err
+	}
+	rule.ID = existing.ID
+	rule.CreatedAt = existing.CreatedAt
+
+	if err := s.ruleRepo.Update(&rule); err != nil {
+		return dto.RuleResponseDTO{}, err
+	}
+
+	return s.toResponseDTO(rule), nil
+}
+
+// Delete rule
+func (s *ruleService) Delete(access Access, id int) error {
+	if err := requireEditor(access); err != nil {
+		return err
+	}
+	return s.ruleRepo.Delete(access.LedgerID, uint(id))
+}
+
+// Test runs an unsaved rule against every existing expense of the ledger, whatever its current
+// category, and lists up to limit of the matches with what the rule would change
+func (s *ruleService) Test(access Access, req dto.RuleRequestDTO, limit int) (dto.RuleTestResultDTO, error) {
+	rule, err := s.buildRule(access, req)
+	if err != nil {
+		return dto.RuleTestResultDTO{}, err
+	}
+	compiled, err := compileRule(rule)
+	if err != nil {
+		return dto.RuleTestResultDTO{}, newValidationError("%s", err.Error())
+	}
+
+	result := dto.RuleTestResultDTO{Matches: make([]dto.RuleMatchDTO, 0)}
+	names := make(map[int]string)
+	err = s.expenseRepo.Stream(access.LedgerID, repositories.ExpenseFilter{}, ruleTestBatchSize, func(expenses []models.Expense) error {
+		for _, expense := range expenses {
+			result.Checked++
+			var accountID int
+			if expense.AccountID != nil {
+				accountID = *expense.AccountID
+			}
+			if !compiled.matches(expense.Description, expense.Amount, accountID) {
+				continue
+			}
+
+			result.Matched++
+			if len(result.Matches) >= limit {
+				continue
+			}
+			match := dto.RuleMatchDTO{
+				ExpenseID:    expense.ID,
+				Date:         expense.Date.Format("2006-01-02"),
+				Amount:       expense.Amount,
+				Description:  expense.Description,
+				CategoryID:   expense.CategoryID,
+				CategoryName: s.categoryName(access.LedgerID, expense.CategoryID, names),
+			}
+			if rule.CategoryID != nil {
+				match.NewCategoryID = *rule.CategoryID
+				match.NewCategoryName = s.categoryName(access.LedgerID, *rule.CategoryID, names)
+			}
+			if description := compiled.rewrite(expense.Description); description != expense.Description {
+				match.NewDescription = description
+			}
+			result.Matches = append(result.Matches, match)
+		}
+		return nil
+	})
+	if err != nil {
+		return dto.RuleTestResultDTO{}, err
+	}
+	return result, nil
+}
+
+// Load compiles the ledger's enabled rules for applying to its new expenses
+func (s *ruleService) Load(ledgerID int) (*RuleSet, error) {
+	rules, err := s.ruleRepo.GetEnabled(ledgerID)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &RuleSet{rules: make([]compiledRule, 0, len(rules))}
+	for _, rule := range rules {
+		compiled, err := compileRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", rule.ID, err)
+		}
+		set.rules = append(set.rules, compiled)
+	}
+	return set, nil
+}
+
+// Helper: Check the category, account and tags a rule refers to and build the model
+func (s *ruleService) buildRule(access Access, req dto.RuleRequestDTO) (models.Rule, error) {
+	rule := models.Rule{
+		UserID:         access.UserID,
+		LedgerID:       access.LedgerID,
+		Name:           req.Name,
+		Priority:       req.Priority,
+		Enabled:        req.Enabled == nil || *req.Enabled,
+		MatchType:      req.MatchType,
+		Pattern:        req.Pattern,
+		MinAmount:      req.MinAmount,
+		MaxAmount:      req.MaxAmount,
+		SetDescription: req.SetDescription,
+		UpdatedAt:      time.Now(),
+	}
+	if rule.MatchType == "" {
+		rule.MatchType = models.MatchContains
+	}
+
+	if req.AccountID > 0 {
+		if _, err := s.accountRepo.GetByID(access.LedgerID, uint(req.AccountID)); err != nil {
+			return models.Rule{}, newValidationError("account not found")
+		}
+		accountID := req.AccountID
+		rule.AccountID = &accountID
+	}
+	if req.CategoryID > 0 {
+		if _, err := s.categoryRepo.GetByID(access.LedgerID, uint(req.CategoryID)); err != nil {
+			return models.Rule{}, newValidationError("category not found")
+		}
+		categoryID := req.CategoryID
+		rule.CategoryID = &categoryID
+	}
+
+	seen := make(map[int]bool)
+	for i, id := range req.TagIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		tag, err := s.tagRepo.GetByID(access.LedgerID, uint(id))
+		if err != nil {
+			return models.Rule{}, newValidationError("tag_ids[%d]: tag not found", i)
+		}
+		rule.Tags = append(rule.Tags, *tag)
+	}
+
+	return rule, nil
+}
+
+func (s *ruleService) categoryName(ledgerID int, id int, names map[int]string) string {
+	if name, ok := names[id]; ok {
+		return name
+	}
+	var name string
+	if category, err := s.categoryRepo.GetByID(ledgerID, uint(id)); err == nil {
+		name = category.Name
+	}
+	names[id] = name
+	return name
+}
+
+// Private helper for mapping model → DTO
+func (s *ruleService) toResponseDTO(rule models.Rule) dto.RuleResponseDTO {
+	response := dto.RuleResponseDTO{
+		ID:             rule.ID,
+		Name:           rule.Name,
+		Priority:       rule.Priority,
+		Enabled:        rule.Enabled,
+		MatchType:      rule.MatchType,
+		Pattern:        rule.Pattern,
+		MinAmount:      rule.MinAmount,
+		MaxAmount:      rule.MaxAmount,
+		SetDescription: rule.SetDescription,
+		CreatedAt:      rule.CreatedAt,
+		UpdatedAt:      rule.UpdatedAt,
+	}
+	if rule.AccountID != nil {
+		response.AccountID = *rule.AccountID
+	}
+	if rule.CategoryID != nil {
+		response.CategoryID = *rule.CategoryID
+		if category, err := s.categoryRepo.GetByID(rule.LedgerID, uint(*rule.CategoryID)); err == nil {
+			response.CategoryName = category.Name
+		}
+	}
+	for _, tag := range rule.Tags {
+		response.Tags = append(response.Tags, dto.TagSummaryDTO{ID: tag.ID, Name: tag.Name})
+	}
+	return response
+}
+
+// RuleSet is the enabled rules, compiled, in the order they run
+type RuleSet struct {
+	rules []compiledRule
+}
+
+// Apply fills in a request that has neither a category nor split lines from the
+// rules matching its description, amount and account. Every matching rule adds
+// its tags; the category and the new description come from the first matching
+// rule that sets them. It returns the IDs of the rules that matched.
+func (set *RuleSet) Apply(req *dto.ExpenseRequestDTO) []int {
+	if req.CategoryID != 0 || len(req.Splits) > 0 {
+		return nil
+	}
+
+	var applied []int
+	description := req.Description
+	rewritten := false
+	for _, rule := range set.rules {
+		if !rule.matches(description, req.Amount, req.AccountID) {
+			continue
+		}
+		applied = append(applied, rule.ID)
+
+		if req.CategoryID == 0 && rule.CategoryID != nil {
+			req.CategoryID = *rule.CategoryID
+		}
+		if !rewritten && rule.SetDescription != "" {
+			req.Description = truncateDescription(rule.rewrite(description))
+			rewritten = true
+		}
+		for _, tag := range rule.Tags {
+			if !containsID(req.TagIDs, tag.ID) {
+				req.TagIDs = append(req.TagIDs, tag.ID)
+			}
+		}
+	}
+	return applied
+}
+
+// compiledRule is a rule with its pattern ready for matching
+type compiledRule struct {
+	models.Rule
+	pattern *regexp.Regexp // Regex rules only
+	needle  string         // Lower-cased pattern of contains rules
+}
+
+func compileRule(rule models.Rule) (compiledRule, error) {
+	compiled := compiledRule{Rule: rule}
+	if rule.MatchType == models.MatchRegex {
+		pattern, err := regexp.Compile("(?i)" + rule.Pattern)
+		if err != nil {
+			return compiledRule{}, fmt.Errorf("pattern is not a valid regular expression: %v", err)
+		}
+		compiled.pattern = pattern
+	} else {
+		compiled.needle = strings.ToLower(rule.Pattern)
+	}
+	return compiled, nil
+}
+
+// matches reports whether every condition of the rule holds
+func (r compiledRule) matches(description string, amount float64, accountID int) bool {
+	if r.AccountID != nil && *r.AccountID != accountID {
+		return false
+	}
+	if r.MinAmount != nil && toCents(amount) < toCents(*r.MinAmount) {
+		return false
+	}
+	if r.MaxAmount != nil && toCents(amount) > toCents(*r.MaxAmount) {
+		return false
+	}
+	if r.pattern != nil {
+		return r.pattern.MatchString(description)
+	}
+	return strings.Contains(strings.ToLower(description), r.needle)
+}
+
+// rewrite returns the description the rule sets, expanding group references of
+// regex rules; without a new description the original is kept
+func (r compiledRule) rewrite(description string) string {
+	if r.SetDescription == "" {
+		return description
+	}
+	if r.pattern == nil {
+		return r.SetDescription
+	}
+	match := r.pattern.FindStringSubmatchIndex(description)
+	if match == nil {
+		return r.SetDescription
+	}
+	return strings.TrimSpace(string(r.pattern.ExpandString(nil, r.SetDescription, description, match)))
+}
+
+// truncateDescription keeps a rewritten description within the 255 characters an expense allows
+func truncateDescription(description string) string {
+	runes := []rune(description)
+	if len(runes) > 255 {
+		return string(runes[:255])
+	}
+	return description
+}
+
+func containsID(ids []int, id int) bool {
+	for _, existing := range ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
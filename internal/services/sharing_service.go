@@ -0,0 +1,281 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/models"
+	"goExpenseTracker/internal/repositories"
+)
+
+type SharingService interface {
+	Balances(access Access) ([]dto.PersonBalanceDTO, error)
+	SettleUp(access Access) ([]dto.SettlementResponseDTO, error)
+	CreateSettlement(access Access, req dto.SettlementRequestDTO) (dto.SettlementResponseDTO, error)
+	GetSettlements(access Access, offset, limit int, personID int) ([]dto.SettlementResponseDTO, error)
+	DeleteSettlement(access Access, id int) error
+}
+
+type sharingService struct {
+	expenseRepo    repositories.ExpenseRepository
+	personRepo     repositories.PersonRepository
+	settlementRepo repositories.SettlementRepository
+}
+
+func NewSharingService(expenseRepo repositories.ExpenseRepository, personRepo repositories.PersonRepository, settlementRepo repositories.SettlementRepository) SharingService {
+	return &sharingService{
+		expenseRepo:    expenseRepo,
+		personRepo:     personRepo,
+		settlementRepo: settlementRepo,
+	}
+}
+
+// Balances computes, per person, what they paid, what they owe and the net position
+// after recorded settlements
+func (s *sharingService) Balances(access Access) ([]dto.PersonBalanceDTO, error) {
+	type position struct {
+		paid, owed, settled int64
+	}
+	positions := make(map[int]*position)
+	get := func(personID int) *position {
+		if positions[personID] == nil {
+			positions[personID] = &position{}
+		}
+		return positions[personID]
+	}
+
+	expenses, err := s.expenseRepo.GetShared(access.LedgerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, expense := range expenses {
+		get(*expense.PaidByID).paid += toCents(expense.Amount)
+		for _, share := range expense.Shares {
+			get(share.PersonID).owed += toCents(share.Amount)
+		}
+	}
+
+	settlements, err := s.settlementRepo.GetAll(access.LedgerID, 0, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, settlement := range settlements {
+		get(settlement.FromPersonID).settled += toCents(settlement.Amount)
+		get(settlement.ToPersonID).settled -= toCents(settlement.Amount)
+	}
+
+	balances := make([]dto.PersonBalanceDTO, 0, len(positions))
+	for personID, p := range positions {
+		balances = append(balances, dto.PersonBalanceDTO{
+			PersonID:   personID,
+			PersonName: s.personName(access.LedgerID, personID),
+			Paid:       float64(p.paid) / 100,
+			Owed:       float64(p.owed) / 100,
+			Balance:    float64(p.paid-p.owed+p.settled) / 100,
+		})
+	}
+	sort.Slice(balances, func(i, j int) bool { return balances[i].PersonID < balances[j].PersonID })
+
+	return balances, nil
+}
+
+// SettleUp suggests the transfers that clear every balance. Exact debtor/creditor
+// matches are paired first, then the largest debtor pays the largest creditor, which
+// never needs more than one transfer fewer than the number of people involved.
+func (s *sharingService) SettleUp(access Access) ([]dto.SettlementResponseDTO, error) {
+	balances, err := s.Balances(access)
+	if err != nil {
+		return nil, err
+	}
+
+	type party struct {
+		personID int
+		name     string
+		cents    int64
+	}
+	var debtors, creditors []*party
+	for _, balance := range balances {
+		cents := toCents(balance.Balance)
+		switch {
+		case cents < 0:
+			debtors = append(debtors, &party{balance.PersonID, balance.PersonName, -cents})
+		case cents > 0:
+			creditors = append(creditors, &party{balance.PersonID, balance.PersonName, cents})
+		}
+	}
+
+	transfers := make([]dto.SettlementResponseDTO, 0)
+	pay := func(from, to *party, cents int64) {
+		transfers = append(transfers, dto.SettlementResponseDTO{
+			FromPersonID:   from.personID,
+			FromPersonName: from.name,
+			ToPersonID:     to.personID,
+			ToPersonName:   to.name,
+			Amount:         float64(cents) / 100,
+		})
+		from.cents -= cents
+		to.cents -= cents
+	}
+
+	// Settle exact matches with a single transfer each
+	for _, debtor := range debtors {
+		for _, creditor := range creditors {
+			if debtor.cents > 0 && debtor.cents == creditor.cents {
+				pay(debtor, creditor, debtor.cents)
+				break
+			}
+		}
+	}
+
+	// Greedily pair the largest remaining debtor and creditor
+	for {
+		sort.Slice(debtors, func(i, j int) bool { return debtors[i].cents > debtors[j].cents })
+		sort.Slice(creditors, func(i, j int) bool { return creditors[i].cents > creditors[j].cents })
+		if len(debtors) == 0 || len(creditors) == 0 || debtors[0].cents == 0 || creditors[0].cents == 0 {
+			break
+		}
+
+		cents := debtors[0].cents
+		if creditors[0].cents < cents {
+			cents = creditors[0].cents
+		}
+		pay(debtors[0], creditors[0], cents)
+	}
+
+	return transfers, nil
+}
+
+// CreateSettlement records a repayment between two people
+func (s *sharingService) CreateSettlement(access Access, req dto.SettlementRequestDTO) (dto.SettlementResponseDTO, error) {
+	if err := requireEditor(access); err != nil {
+		return dto.SettlementResponseDTO{}, err
+	}
+	if _, err := s.personRepo.GetByID(access.LedgerID, uint(req.FromPersonID)); err != nil {
+		return dto.SettlementResponseDTO{}, newValidationError("from_person_id: person not found")
+	}
+	if _, err := s.personRepo.GetByID(access.LedgerID, uint(req.ToPersonID)); err != nil {
+		return dto.SettlementResponseDTO{}, newValidationError("to_person_id: person not found")
+	}
+
+	parsedDate, err := req.ParseDate(access.Today())
+	if err != nil {
+		return dto.SettlementResponseDTO{}, err
+	}
+
+	settlement := models.Settlement{
+		UserID:       access.UserID,
+		LedgerID:     access.LedgerID,
+		FromPersonID: req.FromPersonID,
+		ToPersonID:   req.ToPersonID,
+		Amount:       req.Amount,
+		Note:         req.Note,
+		Date:         parsedDate,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+
+	if err := s.settlementRepo.Create(&settlement); err != nil {
+		return dto.SettlementResponseDTO{}, err
+	}
+
+	return s.toSettlementDTO(settlement), nil
+}
+
+// GetSettlements lists recorded settlements, optionally involving one person
+func (s *sharingService) GetSettlements(access Access, offset, limit int, personID int) ([]dto.SettlementResponseDTO, error) {
+	settlements, err := s.settlementRepo.GetAll(access.LedgerID, offset, limit, personID)
+	if err != nil {
+		return []dto.SettlementResponseDTO{}, err
+	}
+
+	responses := make([]dto.SettlementResponseDTO, 0)
+	for _, settlement := range settlements {
+		responses = append(responses, s.toSettlementDTO(settlement))
+	}
+	return responses, nil
+}
+
+// DeleteSettlement removes a recorded settlement
+func (s *sharingService) DeleteSettlement(access Access, id int) error {
+	if err := requireEditor(access); err != nil {
+		return err
+	}
+	if _, err := s.settlementRepo.GetByID(access.LedgerID, uint(id)); err != nil {
+		return fmt.Errorf("settlement not found")
+	}
+	return s.settlementRepo.Delete(access.LedgerID, uint(id))
+}
+
+// Helper: Resolve a person's name, empty when they no longer exist
+func (s *sharingService) personName(ledgerID int, personID int) string {
+	if person, err := s.personRepo.GetByID(ledgerID, uint(personID)); err == nil {
+		return person.Name
+	}
+	return ""
+}
+
+// Helper: Convert model → Response DTO
+func (s *sharingService) toSettlementDTO(settlement models.Settlement) dto.SettlementResponseDTO {
+	return dto.SettlementResponseDTO{
+		ID:             settlement.ID,
+		FromPersonID:   settlement.FromPersonID,
+		FromPersonName: s.personName(settlement.LedgerID, settlement.FromPersonID),
+		ToPersonID:     settlement.ToPersonID,
+		ToPersonName:   s.personName(settlement.LedgerID, settlement.ToPersonID),
+		Amount:         settlement.Amount,
+		Note:           settlement.Note,
+		Date:           settlement.Date.Format("2006-01-02"),
+	}
+}
+
+// resolveShares divides an amount in cents between participants. Equal splits hand
+// leftover cents to the first participants; percentage splits give the rounding
+// difference to the largest share; exact splits must add up to the amount.
+func resolveShares(totalCents int64, mode string, participants []dto.ExpenseParticipantDTO) ([]int64, error) {
+	shares := make([]int64, len(participants))
+
+	switch mode {
+	case "equal":
+		n := int64(len(participants))
+		for i := range shares {
+			shares[i] = totalCents / n
+			if int64(i) < totalCents%n {
+				shares[i]++
+			}
+		}
+
+	case "percentage":
+		var percentTotal float64
+		var allocated int64
+		largest := 0
+		for i, participant := range participants {
+			percentTotal += participant.Share
+			shares[i] = toCents(float64(totalCents) * participant.Share / 10000)
+			allocated += shares[i]
+			if shares[i] > shares[largest] {
+				largest = i
+			}
+		}
+		if toCents(percentTotal) != 10000 {
+			return nil, newValidationError("sharing percentages add up to %.2f, expected 100", percentTotal)
+		}
+		shares[largest] += totalCents - allocated
+
+	case "exact":
+		var allocated int64
+		for i, participant := range participants {
+			shares[i] = toCents(participant.Share)
+			allocated += shares[i]
+		}
+		if allocated != totalCents {
+			return nil, newValidationError("sharing amounts add up to %.2f but the expense amount is %.2f", float64(allocated)/100, float64(totalCents)/100)
+		}
+
+	default:
+		return nil, newValidationError("sharing mode must be one of: equal, percentage, exact")
+	}
+
+	return shares, nil
+}
@@ -0,0 +1,337 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/models"
+	"goExpenseTracker/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+// memoryPeople is a PersonRepository over a map, for service tests
+type memoryPeople map[int]models.Person
+
+func (m memoryPeople) Create(person *models.Person) error {
+	person.ID = len(m) + 1
+	m[person.ID] = *person
+	return nil
+}
+
+func (m memoryPeople) GetAll(ledgerID int, offset int, limit int, nameFilter string) ([]models.Person, error) {
+	people := make([]models.Person, 0)
+	for _, person := range m {
+		if person.LedgerID == ledgerID {
+			people = append(people, person)
+		}
+	}
+	sort.Slice(people, func(i, j int) bool { return people[i].ID < people[j].ID })
+	return people, nil
+}
+
+func (m memoryPeople) GetByID(ledgerID int, id uint) (*models.Person, error) {
+	person, ok := m[int(id)]
+	if !ok || person.LedgerID != ledgerID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &person, nil
+}
+
+func (m memoryPeople) Update(person *models.Person) error {
+	m[person.ID] = *person
+	return nil
+}
+
+func (m memoryPeople) Delete(ledgerID int, id uint) error {
+	delete(m, int(id))
+	return nil
+}
+
+func (m memoryPeople) HasSettlements(ledgerID int, id uint) (bool, error) {
+	return false, nil
+}
+
+// memorySettlements is a SettlementRepository over a slice, for service tests
+type memorySettlements struct {
+	settlements []models.Settlement
+}
+
+func (m *memorySettlements) Create(settlement *models.Settlement) error {
+	settlement.ID = len(m.settlements) + 1
+	m.settlements = append(m.settlements, *settlement)
+	return nil
+}
+
+func (m *memorySettlements) GetAll(ledgerID int, offset int, limit int, personID int) ([]models.Settlement, error) {
+	settlements := make([]models.Settlement, 0)
+	for _, settlement := range m.settlements {
+		if settlement.LedgerID != ledgerID {
+			continue
+		}
+		if personID != 0 && settlement.FromPersonID != personID && settlement.ToPersonID != personID {
+			continue
+		}
+		settlements = append(settlements, settlement)
+	}
+	return settlements, nil
+}
+
+func (m *memorySettlements) GetByID(ledgerID int, id uint) (*models.Settlement, error) {
+	for _, settlement := range m.settlements {
+		if settlement.ID == int(id) && settlement.LedgerID == ledgerID {
+			return &settlement, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *memorySettlements) Delete(ledgerID int, id uint) error {
+	for i, settlement := range m.settlements {
+		if settlement.ID == int(id) && settlement.LedgerID == ledgerID {
+			m.settlements = append(m.settlements[:i], m.settlements[i+1:]...)
+		}
+	}
+	return nil
+}
+
+// share is what one person owes of a shared expense
+type share struct {
+	personID int
+	amount   float64
+}
+
+// paid is a shared expense: who paid how much and who owes what of it
+type paid struct {
+	ledgerID int
+	by       int
+	amount   float64
+	shares   []share
+}
+
+// newSharingTest returns the service over memory repositories holding people 1 to
+// 5 (Ana, Ben, Cas, Dev, Eli) in the sharing ledger, the expenses and the settlements
+func newSharingTest(t *testing.T, expenses []paid, settlements []models.Settlement) SharingService {
+	t.Helper()
+	people := memoryPeople{}
+	for _, name := range []string{"Ana", "Ben", "Cas", "Dev", "Eli"} {
+		people.Create(&models.Person{LedgerID: testLedger, Name: name})
+	}
+	people.Create(&models.Person{LedgerID: otherLedger, Name: "Fay"})
+
+	expenseRepo := repositories.NewMemoryExpenseRepository(repositories.NewMemoryCategoryRepository())
+	for _, e := range expenses {
+		paidBy := e.by
+		expense := models.Expense{LedgerID: e.ledgerID, PaidByID: &paidBy, Amount: e.amount, Date: time.Now(), Description: "Shared"}
+		for _, s := range e.shares {
+			expense.Shares = append(expense.Shares, models.ExpenseShare{PersonID: s.personID, Amount: s.amount})
+		}
+		if err := expenseRepo.Create(&expense); err != nil {
+			t.Fatalf("create expense: %v", err)
+		}
+	}
+	// An expense nobody shares has no payer and takes no part
+	if err := expenseRepo.Create(&models.Expense{LedgerID: testLedger, Amount: 99, Date: time.Now()}); err != nil {
+		t.Fatalf("create expense: %v", err)
+	}
+
+	settlementRepo := &memorySettlements{}
+	for _, settlement := range settlements {
+		settlementRepo.Create(&settlement)
+	}
+	return NewSharingService(expenseRepo, people, settlementRepo)
+}
+
+func TestSettleUp(t *testing.T) {
+	type transfer struct {
+		from, to int
+		amount   float64
+	}
+	cases := []struct {
+		name        string
+		expenses    []paid
+		settlements []models.Settlement
+		want        []transfer
+	}{
+		{
+			name: "nothing shared",
+			want: []transfer{},
+		},
+		{
+			name: "one payer, equal shares",
+			expenses: []paid{
+				{testLedger, 1, 90, []share{{1, 30}, {2, 30}, {3, 30}}},
+			},
+			want: []transfer{{2, 1, 30}, {3, 1, 30}},
+		},
+		{
+			name: "exact matches are paired first",
+			expenses: []paid{
+				// Ana is owed 50 by Dev, Ben 30 by Cas; largest first would have Dev pay Ben as well
+				{testLedger, 1, 50, []share{{4, 50}}},
+				{testLedger, 2, 30, []share{{3, 30}}},
+			},
+			want: []transfer{{3, 2, 30}, {4, 1, 50}},
+		},
+		{
+			name: "largest debtor pays largest creditor",
+			expenses: []paid{
+				{testLedger, 1, 100, []share{{2, 60}, {3, 40}}},
+				{testLedger, 4, 45, []share{{2, 20}, {3, 25}}},
+			},
+			// Ana +100, Dev +45, Ben -80, Cas -65
+			want: []transfer{{2, 1, 80}, {3, 4, 45}, {3, 1, 20}},
+		},
+		{
+			name: "debts between the same people cancel out",
+			expenses: []paid{
+				{testLedger, 1, 40, []share{{1, 20}, {2, 20}}},
+				{testLedger, 2, 30, []share{{1, 15}, {2, 15}}},
+			},
+			want: []transfer{{2, 1, 5}},
+		},
+		{
+			name: "recorded settlements count",
+			expenses: []paid{
+				{testLedger, 1, 90, []share{{1, 30}, {2, 30}, {3, 30}}},
+			},
+			settlements: []models.Settlement{
+				{LedgerID: testLedger, FromPersonID: 2, ToPersonID: 1, Amount: 30},
+				{LedgerID: testLedger, FromPersonID: 3, ToPersonID: 1, Amount: 10},
+			},
+			want: []transfer{{3, 1, 20}},
+		},
+		{
+			name: "settled in full",
+			expenses: []paid{
+				{testLedger, 1, 10, []share{{2, 10}}},
+			},
+			settlements: []models.Settlement{
+				{LedgerID: testLedger, FromPersonID: 2, ToPersonID: 1, Amount: 10},
+			},
+			want: []transfer{},
+		},
+		{
+			name: "cents add up exactly",
+			expenses: []paid{
+				{testLedger, 1, 100, []share{{1, 33.34}, {2, 33.33}, {3, 33.33}}},
+				{testLedger, 2, 0.1, []share{{3, 0.1}}},
+				{testLedger, 2, 0.2, []share{{3, 0.2}}},
+			},
+			want: []transfer{{3, 1, 33.63}, {2, 1, 33.03}},
+		},
+		{
+			name: "other ledgers are left out",
+			expenses: []paid{
+				{testLedger, 1, 20, []share{{2, 20}}},
+				{otherLedger, 6, 500, []share{{1, 500}}},
+			},
+			settlements: []models.Settlement{
+				{LedgerID: otherLedger, FromPersonID: 2, ToPersonID: 1, Amount: 20},
+			},
+			want: []transfer{{2, 1, 20}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			service := newSharingTest(t, c.expenses, c.settlements)
+			transfers, err := service.SettleUp(ownerAccess())
+			if err != nil {
+				t.Fatalf("settle up: %v", err)
+			}
+			if transfers == nil {
+				t.Fatal("got nil, want an empty list")
+			}
+			got := make([]transfer, 0, len(transfers))
+			for _, tr := range transfers {
+				got = append(got, transfer{tr.FromPersonID, tr.ToPersonID, tr.Amount})
+				if tr.FromPersonName == "" || tr.ToPersonName == "" {
+					t.Errorf("transfer %+v is missing a name", tr)
+				}
+			}
+			if len(got) != len(c.want) {
+				t.Fatalf("got %+v, want %+v", got, c.want)
+			}
+			for i := range got {
+				if got[i] != c.want[i] {
+					t.Fatalf("got %+v, want %+v", got, c.want)
+				}
+			}
+
+			// Recording the suggested transfers clears every balance
+			for _, tr := range transfers {
+				_, err := service.CreateSettlement(ownerAccess(), dto.SettlementRequestDTO{
+					FromPersonID: tr.FromPersonID, ToPersonID: tr.ToPersonID, Amount: tr.Amount,
+				})
+				if err != nil {
+					t.Fatalf("record transfer: %v", err)
+				}
+			}
+			balances, err := service.Balances(ownerAccess())
+			if err != nil {
+				t.Fatalf("balances: %v", err)
+			}
+			for _, balance := range balances {
+				if math.Abs(balance.Balance) > 0.001 {
+					t.Errorf("%s still has a balance of %.2f", balance.PersonName, balance.Balance)
+				}
+			}
+		})
+	}
+}
+
+func TestBalances(t *testing.T) {
+	service := newSharingTest(t, []paid{
+		{testLedger, 1, 90, []share{{1, 30}, {2, 30}, {3, 30}}},
+		{testLedger, 2, 12, []share{{1, 12}}},
+	}, []models.Settlement{
+		{LedgerID: testLedger, FromPersonID: 3, ToPersonID: 1, Amount: 5},
+	})
+	balances, err := service.Balances(ownerAccess())
+	if err != nil {
+		t.Fatalf("balances: %v", err)
+	}
+	want := []dto.PersonBalanceDTO{
+		{PersonID: 1, PersonName: "Ana", Paid: 90, Owed: 42, Balance: 43},
+		{PersonID: 2, PersonName: "Ben", Paid: 12, Owed: 30, Balance: -18},
+		{PersonID: 3, PersonName: "Cas", Paid: 0, Owed: 30, Balance: -25},
+	}
+	if len(balances) != len(want) {
+		t.Fatalf("got %+v, want %+v", balances, want)
+	}
+	for i := range want {
+		if balances[i] != want[i] {
+			t.Errorf("got %+v, want %+v", balances[i], want[i])
+		}
+	}
+}
+
+func TestCreateSettlement(t *testing.T) {
+	service := newSharingTest(t, nil, nil)
+
+	_, err := service.CreateSettlement(ownerAccess(), dto.SettlementRequestDTO{FromPersonID: 2, ToPersonID: 6, Amount: 5})
+	var validation *ValidationError
+	if !errors.As(err, &validation) {
+		t.Errorf("person of another ledger: got %v, want a validation error", err)
+	}
+
+	viewer := ownerAccess()
+	viewer.Role = models.RoleViewer
+	_, err = service.CreateSettlement(viewer, dto.SettlementRequestDTO{FromPersonID: 2, ToPersonID: 1, Amount: 5})
+	var forbidden *ForbiddenError
+	if !errors.As(err, &forbidden) {
+		t.Errorf("viewer: got %v, want a forbidden error", err)
+	}
+
+	settlement, err := service.CreateSettlement(ownerAccess(), dto.SettlementRequestDTO{FromPersonID: 2, ToPersonID: 1, Amount: 5, Date: "2025-03-01"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if settlement.FromPersonName != "Ben" || settlement.ToPersonName != "Ana" || settlement.Date != "2025-03-01" {
+		t.Errorf("got %+v", settlement)
+	}
+}
@@ -0,0 +1,348 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/models"
+	"goExpenseTracker/internal/repositories"
+)
+
+type SuggestionService interface {
+	Suggest(access Access, description string, limit int) (dto.CategorySuggestionsDTO, error)
+	Learn(expense models.Expense)
+	Forget(ledgerID int, expenseID int)
+	Reset()
+}
+
+// suggestionTrainBatchSize is how many expenses are read at a time while training
+const suggestionTrainBatchSize = 500
+
+// suggestionModelTTL is how long a model is used before it is trained again from
+// the expense history
+const suggestionModelTTL = 15 * time.Minute
+
+// suggestionService suggests categories with a multinomial naive Bayes model over
+// the words of past expense descriptions. Each ledger has its own model, trained
+// from its expense history on first use and then kept current as expenses are
+// saved and deleted.
+//
+// Models live in process memory, so with several instances behind one database
+// each sees only the expenses saved through it. Models are therefore retrained
+// from the database once they are suggestionModelTTL old, which bounds how long
+// changes made through other instances go unnoticed.
+type suggestionService struct {
+	expenseRepo  repositories.ExpenseRepository
+	categoryRepo repositories.CategoryRepository
+
+	mu       sync.RWMutex
+	byLedger map[int]*ledgerModel // By ledger ID; ledgers without one have not been trained yet
+	training map[int]*modelTraining
+}
+
+// ledgerModel is a ledger's trained model and when its training started
+type ledgerModel struct {
+	*bayesModel
+	trainedAt time.Time
+}
+
+// modelTraining is a ledger's model being trained. Expenses saved and deleted
+// meanwhile are queued, to be applied once the history has been read.
+type modelTraining struct {
+	done    chan struct{}
+	err     error
+	pending []func(*bayesModel)
+}
+
+func NewSuggestionService(expenseRepo repositories.ExpenseRepository, categoryRepo repositories.CategoryRepository) SuggestionService {
+	return &suggestionService{
+		expenseRepo:  expenseRepo,
+		categoryRepo: categoryRepo,
+		byLedger:     make(map[int]*ledgerModel),
+		training:     make(map[int]*modelTraining),
+	}
+}
+
+// Suggest ranks the categories most likely for a description, best first, with
+// confidences that add up to 1 across every known category. Descriptions sharing
+// no word with past expenses get no suggestions.
+func (s *suggestionService) Suggest(access Access, description string, limit int) (dto.CategorySuggestionsDTO, error) {
+	if err := s.ensureTrained(access.LedgerID); err != nil {
+		return dto.CategorySuggestionsDTO{}, err
+	}
+
+	tokens := tokenize(description)
+	s.mu.RLock()
+	var (
+		scores    []categoryScore
+		known     = make([]string, 0)
+		trainedOn int
+	)
+	if model := s.byLedger[access.LedgerID]; model != nil {
+		scores, known = model.classify(tokens)
+		trainedOn = model.documents
+	}
+	s.mu.RUnlock()
+
+	result := dto.CategorySuggestionsDTO{
+		Description: description,
+		Tokens:      known,
+		TrainedOn:   trainedOn,
+		Suggestions: make([]dto.CategorySuggestionDTO, 0, limit),
+	}
+	for _, score := range scores {
+		if len(result.Suggestions) >= limit {
+			break
+		}
+		// Categories deleted since their expenses were learned are left out
+		category, err := s.categoryRepo.GetByID(access.LedgerID, uint(score.categoryID))
+		if err != nil {
+			continue
+		}
+		result.Suggestions = append(result.Suggestions, dto.CategorySuggestionDTO{
+			CategoryID:   score.categoryID,
+			CategoryName: category.Name,
+			Confidence:   math.Round(score.probability*10000) / 10000,
+		})
+	}
+	return result, nil
+}
+
+// Learn adds a saved expense to its ledger's model, replacing what was learned from
+// it before so that recategorised expenses move to their new category
+func (s *suggestionService) Learn(expense models.Expense) {
+	tokens := tokenize(expense.Description)
+	s.update(expense.LedgerID, func(model *bayesModel) {
+		model.remove(expense.ID)
+		model.add(expense.ID, expense.CategoryID, tokens)
+	})
+}
+
+// Forget removes a deleted expense from its ledger's model
+func (s *suggestionService) Forget(ledgerID int, expenseID int) {
+	s.update(ledgerID, func(model *bayesModel) {
+		model.remove(expenseID)
+	})
+}
+
+// Reset drops every model so each is trained again from the expense history on
+// next use, for changes made outside the expense service such as a restore.
+// Models still being trained are thrown away when they finish.
+func (s *suggestionService) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.byLedger = make(map[int]*ledgerModel)
+	s.training = make(map[int]*modelTraining)
+}
+
+// Helper: Apply a change to the ledger's model, and queue it for a model being
+// trained. An untrained model reads the expense from the database when it is
+// first used.
+func (s *suggestionService) update(ledgerID int, change func(*bayesModel)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if model, trained := s.byLedger[ledgerID]; trained {
+		change(model.bayesModel)
+	}
+	if training := s.training[ledgerID]; training != nil {
+		training.pending = append(training.pending, change)
+	}
+}
+
+// Helper: Train the ledger's model from its stored expenses unless a recent one
+// exists. The history is read without holding the lock, so training one ledger
+// does not hold up suggestions and changes in others; callers wanting the same
+// ledger wait for the one training it.
+func (s *suggestionService) ensureTrained(ledgerID int) error {
+	s.mu.Lock()
+	if model, trained := s.byLedger[ledgerID]; trained && time.Since(model.trainedAt) < suggestionModelTTL {
+		s.mu.Unlock()
+		return nil
+	}
+	if training := s.training[ledgerID]; training != nil {
+		s.mu.Unlock()
+		<-training.done
+		return training.err
+	}
+	training := &modelTraining{done: make(chan struct{})}
+	s.training[ledgerID] = training
+	s.mu.Unlock()
+
+	startedAt := time.Now()
+	model := newBayesModel()
+	err := s.expenseRepo.Stream(ledgerID, repositories.ExpenseFilter{}, suggestionTrainBatchSize, func(expenses []models.Expense) error {
+		for _, expense := range expenses {
+			model.add(expense.ID, expense.CategoryID, tokenize(expense.Description))
+		}
+		return nil
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	training.err = err
+	close(training.done)
+	// A reset while training started over, and the model read before it is stale
+	if s.training[ledgerID] != training {
+		return err
+	}
+	delete(s.training, ledgerID)
+	if err != nil {
+		return err
+	}
+	for _, change := range training.pending {
+		change(model)
+	}
+	s.byLedger[ledgerID] = &ledgerModel{bayesModel: model, trainedAt: startedAt}
+	return nil
+}
+
+// bayesModel holds the word counts of a naive Bayes classifier. The category and
+// description learned from each expense are kept so it can be taken out again.
+type bayesModel struct {
+	documents  int                    // Expenses learned
+	categories map[int]int            // Expenses learned per category
+	words      map[int]map[string]int // Word counts per category
+	wordTotals map[int]int            // Words learned per category
+	vocabulary map[string]int         // Occurrences of each word over all categories
+	learned    map[int]learnedExpense // By expense ID
+}
+
+type learnedExpense struct {
+	categoryID int
+	tokens     []string
+}
+
+type categoryScore struct {
+	categoryID  int
+	probability float64
+}
+
+func newBayesModel() *bayesModel {
+	return &bayesModel{
+		categories: make(map[int]int),
+		words:      make(map[int]map[string]int),
+		wordTotals: make(map[int]int),
+		vocabulary: make(map[string]int),
+		learned:    make(map[int]learnedExpense),
+	}
+}
+
+func (m *bayesModel) add(expenseID, categoryID int, tokens []string) {
+	if categoryID < 1 || len(tokens) == 0 {
+		return
+	}
+	m.learned[expenseID] = learnedExpense{categoryID: categoryID, tokens: tokens}
+	m.documents++
+	m.categories[categoryID]++
+	words := m.words[categoryID]
+	if words == nil {
+		words = make(map[string]int)
+		m.words[categoryID] = words
+	}
+	for _, token := range tokens {
+		words[token]++
+		m.vocabulary[token]++
+	}
+	m.wordTotals[categoryID] += len(tokens)
+}
+
+func (m *bayesModel) remove(expenseID int) {
+	learned, ok := m.learned[expenseID]
+	if !ok {
+		return
+	}
+	delete(m.learned, expenseID)
+	categoryID := learned.categoryID
+
+	m.documents--
+	if m.categories[categoryID]--; m.categories[categoryID] == 0 {
+		delete(m.categories, categoryID)
+		delete(m.words, categoryID)
+		delete(m.wordTotals, categoryID)
+	} else {
+		words := m.words[categoryID]
+		for _, token := range learned.tokens {
+			if words[token]--; words[token] == 0 {
+				delete(words, token)
+			}
+		}
+		m.wordTotals[categoryID] -= len(learned.tokens)
+	}
+	for _, token := range learned.tokens {
+		if m.vocabulary[token]--; m.vocabulary[token] == 0 {
+			delete(m.vocabulary, token)
+		}
+	}
+}
+
+// classify scores every category for the tokens with Laplace smoothing and turns
+// the log likelihoods into probabilities. Words never seen before say nothing
+// about the category and are ignored; it returns the tokens that were used.
+func (m *bayesModel) classify(tokens []string) ([]categoryScore, []string) {
+	known := make([]string, 0, len(tokens))
+	if m == nil || m.documents == 0 {
+		return nil, known
+	}
+	for _, token := range tokens {
+		if m.vocabulary[token] > 0 {
+			known = append(known, token)
+		}
+	}
+	if len(known) == 0 {
+		return nil, known
+	}
+
+	vocabularySize := float64(len(m.vocabulary))
+	scores := make([]categoryScore, 0, len(m.categories))
+	best := math.Inf(-1)
+	for categoryID, count := range m.categories {
+		logProbability := math.Log(float64(count) / float64(m.documents))
+		denominator := float64(m.wordTotals[categoryID]) + vocabularySize
+		for _, token := range known {
+			logProbability += math.Log((float64(m.words[categoryID][token]) + 1) / denominator)
+		}
+		scores = append(scores, categoryScore{categoryID: categoryID, probability: logProbability})
+		best = math.Max(best, logProbability)
+	}
+
+	// Normalise relative to the best score so the exponentials cannot underflow
+	var sum float64
+	for i := range scores {
+		scores[i].probability = math.Exp(scores[i].probability - best)
+		sum += scores[i].probability
+	}
+	for i := range scores {
+		scores[i].probability /= sum
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].probability != scores[j].probability {
+			return scores[i].probability > scores[j].probability
+		}
+		return scores[i].categoryID < scores[j].categoryID
+	})
+	return scores, known
+}
+
+// tokenize splits a description into lower-cased words. Numbers alone, such as
+// card numbers, dates and references, vary from one expense to the next and are
+// dropped, as are single characters.
+func tokenize(description string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if len([]rune(field)) < 2 || strings.IndexFunc(field, unicode.IsLetter) < 0 {
+			continue
+		}
+		tokens = append(tokens, field)
+	}
+	return tokens
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/models"
+	"goExpenseTracker/internal/repositories"
+)
+
+type TagService interface {
+	Create(access Access, req dto.TagRequestDTO) (dto.TagResponseDTO, error)
+	GetAll(access Access, offset, limit int, nameFilter string) ([]dto.TagResponseDTO, error)
+	GetByID(access Access, id int) (dto.TagResponseDTO, error)
+	Update(access Access, id int, req dto.TagRequestDTO) (dto.TagResponseDTO, error)
+	Delete(access Access, id int) error
+}
+
+type tagService struct {
+	repo repositories.TagRepository
+}
+
+func NewTagService(repo repositories.TagRepository) TagService {
+	return &tagService{repo: repo}
+}
+
+// Create tag, refusing a name already in use
+func (s *tagService) Create(access Access, req dto.TagRequestDTO) (dto.TagResponseDTO, error) {
+	if err := requireEditor(access); err != nil {
+		return dto.TagResponseDTO{}, err
+	}
+	if err := s.checkName(access.LedgerID, req.Name, 0); err != nil {
+		return dto.TagResponseDTO{}, err
+	}
+
+	tag := models.Tag{
+		UserID:    access.UserID,
+		LedgerID:  access.LedgerID,
+		Name:      req.Name,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if err := s.repo.Create(&tag); err != nil {
+		return dto.TagResponseDTO{}, err
+	}
+
+	return s.toResponseDTO(tag), nil
+}
+
+// Get all tags
+func (s *tagService) GetAll(access Access, offset, limit int, nameFilter string) ([]dto.TagResponseDTO, error) {
+	tags, err := s.repo.GetAll(access.LedgerID, offset, limit, nameFilter)
+	if err != nil {
+		return []dto.TagResponseDTO{}, err
+	}
+
+	responses := make([]dto.TagResponseDTO, 0, len(tags))
+	for _, tag := range tags {
+		responses = append(responses, s.toResponseDTO(tag))
+	}
+	return responses, nil
+}
+
+// Get single tag
+func (s *tagService) GetByID(access Access, id int) (dto.TagResponseDTO, error) {
+	tag, err := s.repo.GetByID(access.LedgerID, uint(id))
+	if err != nil {
+		return dto.TagResponseDTO{}, fmt.Errorf("tag not found")
+	}
+	return s.toResponseDTO(*tag), nil
+}
+
+// Rename tag
+func (s *tagService) Update(access Access, id int, req dto.TagRequestDTO) (dto.TagResponseDTO, error) {
+	if err := requireEditor(access); err != nil {
+		return dto.TagResponseDTO{}, err
+	}
+	existing, err := s.repo.GetByID(access.LedgerID, uint(id))
+	if err != nil {
+		return dto.TagResponseDTO{}, fmt.Errorf("tag not found")
+	}
+	if err := s.checkName(access.LedgerID, req.Name, id); err != nil {
+		return dto.TagResponseDTO{}, err
+	}
+
+	existing.Name = req.Name
+	existing.UpdatedAt = time.Now()
+
+	if err := s.repo.Update(existing); err != nil {
+		return dto.TagResponseDTO{}, err
+	}
+
+	return s.toResponseDTO(*existing), nil
+}
+
+// Delete tag, removing it from the expenses that carry it
+func (s *tagService) Delete(access Access, id int) error {
+	if err := requireEditor(access); err != nil {
+		return err
+	}
+	return s.repo.Delete(access.LedgerID, uint(id))
+}
+
+// Helper: Tag names are unique regardless of case
+func (s *tagService) checkName(ledgerID int, name string, id int) error {
+	if existing, err := s.repo.GetByName(ledgerID, name); err == nil && existing.ID != id {
+		return newValidationError("a tag named %s already exists", existing.Name)
+	}
+	return nil
+}
+
+// Private helper for mapping model → DTO
+func (s *tagService) toResponseDTO(tag models.Tag) dto.TagResponseDTO {
+	return dto.TagResponseDTO{
+		ID:        tag.ID,
+		Name:      tag.Name,
+		CreatedAt: tag.CreatedAt,
+		UpdatedAt: tag.UpdatedAt,
+	}
+}
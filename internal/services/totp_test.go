@@ -0,0 +1,217 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"goExpenseTracker/internal/models"
+	"goExpenseTracker/internal/repositories"
+)
+
+// rfc6238Secret is the SHA1 key of the RFC 6238 test vectors, "12345678901234567890"
+var rfc6238Secret = totpEncoding.EncodeToString([]byte("12345678901234567890"))
+
+// TestTOTPCodeRFC6238 checks the SHA1 vectors of RFC 6238 appendix B, cut to the
+// six digits codes have here (the eight-digit values modulo 10^6)
+func TestTOTPCodeRFC6238(t *testing.T) {
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},          // 94287082
+		{1111111109, "081804"},  // 07081804
+		{1111111111, "050471"},  // 14050471
+		{1234567890, "005924"},  // 89005924
+		{2000000000, "279037"},  // 69279037
+		{20000000000, "353130"}, // 65353130
+	}
+	for _, v := range vectors {
+		at := time.Unix(v.unix, 0)
+		code, err := totpCode(rfc6238Secret, totpStep(at))
+		if err != nil {
+			t.Fatalf("%d: %v", v.unix, err)
+		}
+		if code != v.code {
+			t.Errorf("%d: got %s, want %s", v.unix, code, v.code)
+		}
+
+		// Lower-case secrets as some apps show them decode the same
+		if lower, _ := totpCode(strings.ToLower(rfc6238Secret), totpStep(at)); lower != v.code {
+			t.Errorf("%d: lower-case secret gave %s, want %s", v.unix, lower, v.code)
+		}
+	}
+
+	if _, err := totpCode("not base32!", 1); err == nil {
+		t.Error("invalid secret: got no error")
+	}
+}
+
+func TestMatchTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := totpStep(now)
+	codeAt := func(step int64) string {
+		code, err := totpCode(rfc6238Secret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	cases := []struct {
+		name     string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "current step", code: codeAt(step), wantStep: step, wantOK: true},
+		{name: "previous step, for clock drift", code: codeAt(step - 1), wantStep: step - 1, wantOK: true},
+		{name: "next step, for clock drift", code: codeAt(step + 1), wantStep: step + 1, wantOK: true},
+		{name: "two steps back", code: codeAt(step - 2)},
+		{name: "two steps ahead", code: codeAt(step + 2)},
+		{name: "wrong length", code: codeAt(step)[:5]},
+		{name: "eight digits", code: "14050471"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, ok := matchTOTP(rfc6238Secret, c.code, now)
+			if ok != c.wantOK || got != c.wantStep {
+				t.Errorf("got step %d, %v; want %d, %v", got, ok, c.wantStep, c.wantOK)
+			}
+		})
+	}
+}
+
+// newTwoFactorTest returns the service over a fresh database and a user who has
+// two-factor sign-in on, with their secret and recovery codes
+func newTwoFactorTest(t *testing.T) (TwoFactorService, int, string, []string) {
+	t.Helper()
+	db := openTestDB(t)
+	userRepo := repositories.NewUserRepository(db)
+	user := models.User{Email: "ada@example.com", PasswordHash: "x"}
+	if err := userRepo.Create(&user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	service := NewTwoFactorService(repositories.NewTwoFactorRepository(db), userRepo)
+
+	// Tests work out codes around the current step; start them clear of its end
+	if left := totpPeriod - time.Duration(time.Now().UnixNano())%totpPeriod; left < 2*time.Second {
+		time.Sleep(left)
+	}
+
+	enrollment, err := service.Enroll(user.ID)
+	if err != nil {
+		t.Fatalf("enroll: %v", err)
+	}
+	// Confirm with the previous step's code so the current one is still unused
+	code, _ := totpCode(enrollment.Secret, totpStep(time.Now())-1)
+	recovery, err := service.Confirm(user.ID, code)
+	if err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+	return service, user.ID, enrollment.Secret, recovery.RecoveryCodes
+}
+
+func TestTwoFactorRefusesReplayedCodes(t *testing.T) {
+	service, userID, secret, _ := newTwoFactorTest(t)
+	step := totpStep(time.Now())
+
+	previous, _ := totpCode(secret, step-1)
+	if err := service.Check(userID, previous); err == nil || !strings.Contains(err.Error(), "already been used") {
+		t.Fatalf("code used to confirm: got %v, want it refused as used", err)
+	}
+
+	current, _ := totpCode(secret, step)
+	if err := service.Check(userID, current[:3]+" "+current[3:]); err != nil {
+		t.Fatalf("fresh code: %v", err)
+	}
+	if err := service.Check(userID, current); err == nil || !strings.Contains(err.Error(), "already been used") {
+		t.Fatalf("same code again: got %v, want it refused as used", err)
+	}
+
+	// Once a later step is used, earlier codes still within the drift window are spent too
+	next, _ := totpCode(secret, step+1)
+	if err := service.Check(userID, next); err != nil {
+		t.Fatalf("next code: %v", err)
+	}
+	if err := service.Check(userID, current); err == nil {
+		t.Fatal("earlier code after a later one: got no error")
+	}
+
+	// Replays are not wrong guesses and do not count towards the lockout
+	for i := 0; i < twoFactorMaxFailures; i++ {
+		if err := service.Check(userID, next); err == nil || errors.Is(err, errTwoFactorLockedOut) {
+			t.Fatalf("replay %d: got %v", i, err)
+		}
+	}
+}
+
+func TestTwoFactorRecoveryCodesWorkOnce(t *testing.T) {
+	service, userID, _, recovery := newTwoFactorTest(t)
+	if len(recovery) != recoveryCodeCount {
+		t.Fatalf("got %d recovery codes, want %d", len(recovery), recoveryCodeCount)
+	}
+
+	// Codes are compared without case or hyphen
+	if err := service.Check(userID, strings.ToUpper(strings.ReplaceAll(recovery[0], "-", ""))); err != nil {
+		t.Fatalf("recovery code: %v", err)
+	}
+	if err := service.Check(userID, recovery[0]); err == nil {
+		t.Fatal("recovery code used twice: got no error")
+	}
+	if err := service.Check(userID, recovery[1]); err != nil {
+		t.Fatalf("second recovery code: %v", err)
+	}
+}
+
+func TestTwoFactorLockout(t *testing.T) {
+	service, userID, secret, recovery := newTwoFactorTest(t)
+
+	current, _ := totpCode(secret, totpStep(time.Now()))
+	wrong := "000000"
+	if wrong == current {
+		wrong = "000001"
+	}
+	for i := 0; i < twoFactorMaxFailures; i++ {
+		if err := service.Check(userID, wrong); err == nil || err.Error() != errWrongTwoFactorCode.Error() {
+			t.Fatalf("wrong code %d: got %v", i+1, err)
+		}
+	}
+
+	// Locked out, even right codes are refused, recovery codes included
+	for _, code := range []string{current, recovery[0]} {
+		if err := service.Check(userID, code); err == nil || err.Error() != errTwoFactorLockedOut.Error() {
+			t.Fatalf("code %q while locked out: got %v", code, err)
+		}
+	}
+
+	// The lockout ends twoFactorLockout after the first wrong code
+	impl := service.(*twoFactorService)
+	impl.mu.Lock()
+	failures := impl.failures[userID]
+	failures.since = time.Now().Add(-twoFactorLockout - time.Second)
+	impl.failures[userID] = failures
+	impl.mu.Unlock()
+	if err := service.Check(userID, current); err != nil {
+		t.Fatalf("code after the lockout: %v", err)
+	}
+}
+
+func TestTwoFactorFailuresResetOnSuccess(t *testing.T) {
+	service, userID, secret, _ := newTwoFactorTest(t)
+
+	for i := 0; i < twoFactorMaxFailures-1; i++ {
+		service.Check(userID, "not-a-recovery-code")
+	}
+	current, _ := totpCode(secret, totpStep(time.Now()))
+	if err := service.Check(userID, current); err != nil {
+		t.Fatalf("right code: %v", err)
+	}
+	// The count started over, so another wrong code does not lock the user out
+	service.Check(userID, "not-a-recovery-code")
+	next, _ := totpCode(secret, totpStep(time.Now())+1)
+	if err := service.Check(userID, next); err != nil {
+		t.Fatalf("right code after one more failure: %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/models"
+	"goExpenseTracker/internal/repositories"
+)
+
+type TransferService interface {
+	Create(access Access, req dto.TransferRequestDTO) (dto.TransferResponseDTO, error)
+	GetAll(access Access, offset, limit int, accountID int) ([]dto.TransferResponseDTO, error)
+	GetByID(access Access, id int) (dto.TransferResponseDTO, error)
+	Delete(access Access, id int) error
+}
+
+type transferService struct {
+	transferRepo repositories.TransferRepository
+	accountRepo  repositories.AccountRepository
+}
+
+func NewTransferService(transferRepo repositories.TransferRepository, accountRepo repositories.AccountRepository) TransferService {
+	return &transferService{
+		transferRepo: transferRepo,
+		accountRepo:  accountRepo,
+	}
+}
+
+// Create a transfer between two accounts of the same currency
+func (s *transferService) Create(access Access, req dto.TransferRequestDTO) (dto.TransferResponseDTO, error) {
+	if err := requireEditor(access); err != nil {
+		return dto.TransferResponseDTO{}, err
+	}
+	from, err := s.accountRepo.GetByID(access.LedgerID, uint(req.FromAccountID))
+	if err != nil {
+		return dto.TransferResponseDTO{}, fmt.Errorf("source account not found")
+	}
+	to, err := s.accountRepo.GetByID(access.LedgerID, uint(req.ToAccountID))
+	if err != nil {
+		return dto.TransferResponseDTO{}, fmt.Errorf("destination account not found")
+	}
+	if from.Currency != to.Currency {
+		return dto.TransferResponseDTO{}, fmt.Errorf("transfers between accounts with different currencies are not supported")
+	}
+
+	parsedDate, err := req.ParseDate()
+	if err != nil {
+		return dto.TransferResponseDTO{}, err
+	}
+
+	transfer := models.Transfer{
+		UserID:        access.UserID,
+		LedgerID:      access.LedgerID,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Description:   req.Description,
+		Date:          parsedDate,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	if err := s.transferRepo.Create(&transfer); err != nil {
+		return dto.TransferResponseDTO{}, err
+	}
+
+	return s.toResponseDTO(transfer), nil
+}
+
+// Get all transfers, optionally for one account
+func (s *transferService) GetAll(access Access, offset, limit int, accountID int) ([]dto.TransferResponseDTO, error) {
+	transfers, err := s.transferRepo.GetAll(access.LedgerID, offset, limit, accountID)
+	if err != nil {
+		return []dto.TransferResponseDTO{}, err
+	}
+
+	responses := make([]dto.TransferResponseDTO, 0)
+	for _, transfer := range transfers {
+		responses = append(responses, s.toResponseDTO(transfer))
+	}
+	return responses, nil
+}
+
+// Get transfer by ID
+func (s *transferService) GetByID(access Access, id int) (dto.TransferResponseDTO, error) {
+	transfer, err := s.transferRepo.GetByID(access.LedgerID, uint(id))
+	if err != nil {
+		return dto.TransferResponseDTO{}, fmt.Errorf("transfer not found")
+	}
+	return s.toResponseDTO(*transfer), nil
+}
+
+// Delete transfer by ID
+func (s *transferService) Delete(access Access, id int) error {
+	if err := requireEditor(access); err != nil {
+		return err
+	}
+	return s.transferRepo.Delete(access.LedgerID, uint(id))
+}
+
+// Helper: Convert model → Response DTO
+func (s *transferService) toResponseDTO(transfer models.Transfer) dto.TransferResponseDTO {
+	return dto.TransferResponseDTO{
+		ID:            transfer.ID,
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		Description:   transfer.Description,
+		Date:          transfer.Date.Format("2006-01-02"),
+	}
+}
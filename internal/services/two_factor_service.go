@@ -0,0 +1,357 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	dto "goExpenseTracker/internal/DTOs"
+	"goExpenseTracker/internal/models"
+	"goExpenseTracker/internal/repositories"
+)
+
+// recoveryCodeCount is how many recovery codes a user is given at a time
+const recoveryCodeCount = 10
+
+// recoveryCodeAlphabet leaves out letters easily mistaken for digits. It has 32
+// characters, so each random byte picks one without bias.
+const recoveryCodeAlphabet = "0123456789abcdefghjkmnpqrstvwxyz"
+
+// Wrong codes lock a user's two-factor checks for a while, so the million
+// possible codes cannot be tried one after the other
+const (
+	twoFactorMaxFailures = 5
+	twoFactorLockout     = 15 * time.Minute
+)
+
+var (
+	errWrongTwoFactorCode = errors.New("invalid two-factor code")
+	errUsedTwoFactorCode  = errors.New("this code has already been used; wait for the next one")
+	errTwoFactorLockedOut = errors.New("too many wrong two-factor codes; try again in a few minutes")
+)
+
+type TwoFactorService interface {
+	Status(userID int) (dto.TwoFactorStatusDTO, error)
+	Enroll(userID int) (dto.TwoFactorEnrollmentDTO, error)
+	Confirm(userID int, code string) (dto.RecoveryCodesDTO, error)
+	Disable(userID int, code string) error
+	RegenerateRecoveryCodes(userID int, code string) (dto.RecoveryCodesDTO, error)
+	Reset(adminID int, userID int) error
+	Enabled(userID int) (bool, error)
+	Check(userID int, code string) error
+}
+
+type twoFactorService struct {
+	twoFactorRepo repositories.TwoFactorRepository
+	userRepo      repositories.UserRepository
+
+	mu       sync.Mutex
+	failures map[int]codeFailures // By user ID
+}
+
+// codeFailures counts the wrong codes of a user since the first of them
+type codeFailures struct {
+	count int
+	since time.Time
+}
+
+func NewTwoFactorService(twoFactorRepo repositories.TwoFactorRepository, userRepo repositories.UserRepository) TwoFactorService {
+	return &twoFactorService{
+		twoFactorRepo: twoFactorRepo,
+		userRepo:      userRepo,
+		failures:      make(map[int]codeFailures),
+	}
+}
+
+// Status tells whether the user signs in with a second factor
+func (s *twoFactorService) Status(userID int) (dto.TwoFactorStatusDTO, error) {
+	twoFactor, err := s.twoFactorRepo.Get(userID)
+	if err != nil || twoFactor == nil {
+		return dto.TwoFactorStatusDTO{}, err
+	}
+	status := dto.TwoFactorStatusDTO{
+		Enabled:     twoFactor.ConfirmedAt != nil,
+		Pending:     twoFactor.ConfirmedAt == nil,
+		ConfirmedAt: twoFactor.ConfirmedAt,
+	}
+	if status.Enabled {
+		remaining, err := s.twoFactorRepo.CountRecoveryCodes(userID)
+		if err != nil {
+			return dto.TwoFactorStatusDTO{}, err
+		}
+		status.RecoveryCodesRemaining = int(remaining)
+	}
+	return status, nil
+}
+
+// Enroll gives the user a new TOTP secret. Sign-ins ask for codes only once the
+// secret is confirmed with one, so starting over replaces an unconfirmed secret.
+func (s *twoFactorService) Enroll(userID int) (dto.TwoFactorEnrollmentDTO, error) {
+	twoFactor, err := s.twoFactorRepo.Get(userID)
+	if err != nil {
+		return dto.TwoFactorEnrollmentDTO{}, err
+	}
+	if twoFactor != nil && twoFactor.ConfirmedAt != nil {
+		return dto.TwoFactorEnrollmentDTO{}, newValidationError("two-factor sign-in is already on; turn it off before enrolling again")
+	}
+	user, err := s.userRepo.GetByID(uint(userID))
+	if err != nil {
+		return dto.TwoFactorEnrollmentDTO{}, fmt.Errorf("user not found")
+	}
+
+	secret, err := newTOTPSecret()
+	if err != nil {
+		return dto.TwoFactorEnrollmentDTO{}, err
+	}
+	err = s.twoFactorRepo.Save(&models.TwoFactor{
+		UserID:    userID,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return dto.TwoFactorEnrollmentDTO{}, err
+	}
+
+	return dto.TwoFactorEnrollmentDTO{
+		Secret:          secret,
+		ProvisioningURI: totpProvisioningURI(tokenIssuer, user.Email, secret),
+		Digits:          totpDigits,
+		Period:          int(totpPeriod / time.Second),
+	}, nil
+}
+
+// Confirm turns two-factor sign-in on once the user proves their app has the
+// secret, and hands out the first recovery codes
+func (s *twoFactorService) Confirm(userID int, code string) (dto.RecoveryCodesDTO, error) {
+	twoFactor, err := s.twoFactorRepo.Get(userID)
+	if err != nil {
+		return dto.RecoveryCodesDTO{}, err
+	}
+	if twoFactor == nil {
+		return dto.RecoveryCodesDTO{}, newValidationError("start enrolment before confirming it")
+	}
+	if twoFactor.ConfirmedAt != nil {
+		return dto.RecoveryCodesDTO{}, newValidationError("two-factor sign-in is already on")
+	}
+	if err := s.lockedOut(userID); err != nil {
+		return dto.RecoveryCodesDTO{}, &ValidationError{Message: err.Error()}
+	}
+	step, ok := matchTOTP(twoFactor.Secret, normalizeTOTPCode(code), time.Now())
+	if !ok {
+		s.recordFailure(userID)
+		return dto.RecoveryCodesDTO{}, newValidationError("invalid two-factor code; check that the device clock is right")
+	}
+	s.clearFailures(userID)
+
+	plain, codes, err := newRecoveryCodes(userID)
+	if err != nil {
+		return dto.RecoveryCodesDTO{}, err
+	}
+	if err := s.twoFactorRepo.Confirm(userID, step, time.Now(), codes); err != nil {
+		return dto.RecoveryCodesDTO{}, err
+	}
+	return dto.RecoveryCodesDTO{RecoveryCodes: plain}, nil
+}
+
+// Disable turns two-factor sign-in off given a current code or a recovery code,
+// so a stolen access token alone cannot remove it
+func (s *twoFactorService) Disable(userID int, code string) error {
+	twoFactor, err := s.twoFactorRepo.Get(userID)
+	if err != nil {
+		return err
+	}
+	if twoFactor == nil {
+		return newValidationError("two-factor sign-in is not on")
+	}
+	if twoFactor.ConfirmedAt != nil {
+		if err := s.verify(twoFactor, code); err != nil {
+			return &ValidationError{Message: err.Error()}
+		}
+	}
+	return s.twoFactorRepo.Delete(userID)
+}
+
+// RegenerateRecoveryCodes replaces every recovery code of the user with new ones
+func (s *twoFactorService) RegenerateRecoveryCodes(userID int, code string) (dto.RecoveryCodesDTO, error) {
+	twoFactor, err := s.twoFactorRepo.Get(userID)
+	if err != nil {
+		return dto.RecoveryCodesDTO{}, err
+	}
+	if twoFactor == nil || twoFactor.ConfirmedAt == nil {
+		return dto.RecoveryCodesDTO{}, newValidationError("two-factor sign-in is not on")
+	}
+	if err := s.verify(twoFactor, code); err != nil {
+		return dto.RecoveryCodesDTO{}, &ValidationError{Message: err.Error()}
+	}
+
+	plain, codes, err := newRecoveryCodes(userID)
+	if err != nil {
+		return dto.RecoveryCodesDTO{}, err
+	}
+	if err := s.twoFactorRepo.ReplaceRecoveryCodes(userID, codes); err != nil {
+		return dto.RecoveryCodesDTO{}, err
+	}
+	return dto.RecoveryCodesDTO{RecoveryCodes: plain}, nil
+}
+
+// Reset turns two-factor sign-in off for a user who lost both their app and their
+// recovery codes. Only an admin may do so.
+func (s *twoFactorService) Reset(adminID int, userID int) error {
+	admin, err := s.userRepo.GetByID(uint(adminID))
+	if err != nil || !admin.IsAdmin {
+		return &ForbiddenError{Message: "only an admin can reset two-factor sign-in"}
+	}
+	if _, err := s.userRepo.GetByID(uint(userID)); err != nil {
+		return fmt.Errorf("user not found")
+	}
+	s.clearFailures(userID)
+	return s.twoFactorRepo.Delete(userID)
+}
+
+// Enabled tells whether sign-ins of the user must pass Check
+func (s *twoFactorService) Enabled(userID int) (bool, error) {
+	twoFactor, err := s.twoFactorRepo.Get(userID)
+	if err != nil {
+		return false, err
+	}
+	return twoFactor != nil && twoFactor.ConfirmedAt != nil, nil
+}
+
+// Check accepts a current TOTP code or an unused recovery code of the user as the
+// second factor of a sign-in. Each code works once.
+func (s *twoFactorService) Check(userID int, code string) error {
+	twoFactor, err := s.twoFactorRepo.Get(userID)
+	if err != nil {
+		return err
+	}
+	if twoFactor == nil || twoFactor.ConfirmedAt == nil {
+		return &AuthError{Message: "two-factor sign-in is not on for this account"}
+	}
+	if err := s.verify(twoFactor, code); err != nil {
+		return &AuthError{Message: err.Error()}
+	}
+	return nil
+}
+
+// Helper: Check a TOTP or recovery code, counting wrong codes towards a lockout.
+// Repository failures are returned as they are, any other error explains why the
+// code was refused.
+func (s *twoFactorService) verify(twoFactor *models.TwoFactor, code string) error {
+	userID := twoFactor.UserID
+	if err := s.lockedOut(userID); err != nil {
+		return err
+	}
+
+	if totp := normalizeTOTPCode(code); isTOTPCode(totp) {
+		step, ok := matchTOTP(twoFactor.Secret, totp, time.Now())
+		if !ok {
+			s.recordFailure(userID)
+			return errWrongTwoFactorCode
+		}
+		fresh, err := s.twoFactorRepo.UseStep(userID, step)
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			return errUsedTwoFactorCode
+		}
+		s.clearFailures(userID)
+		return nil
+	}
+
+	used, err := s.twoFactorRepo.UseRecoveryCode(userID, hashSecretToken(normalizeRecoveryCode(code)), time.Now())
+	if err != nil {
+		return err
+	}
+	if !used {
+		s.recordFailure(userID)
+		return errWrongTwoFactorCode
+	}
+	s.clearFailures(userID)
+	return nil
+}
+
+// Helper: Refuse further codes from a user who got too many wrong lately
+func (s *twoFactorService) lockedOut(userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	failures, ok := s.failures[userID]
+	if !ok {
+		return nil
+	}
+	if time.Since(failures.since) > twoFactorLockout {
+		delete(s.failures, userID)
+		return nil
+	}
+	if failures.count >= twoFactorMaxFailures {
+		return errTwoFactorLockedOut
+	}
+	return nil
+}
+
+func (s *twoFactorService) recordFailure(userID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	failures, ok := s.failures[userID]
+	if !ok || time.Since(failures.since) > twoFactorLockout {
+		failures = codeFailures{since: time.Now()}
+	}
+	failures.count++
+	s.failures[userID] = failures
+}
+
+func (s *twoFactorService) clearFailures(userID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.failures, userID)
+}
+
+// newRecoveryCodes returns fresh recovery codes as shown to the user, formatted as
+// "xxxxx-xxxxx", and as stored
+func newRecoveryCodes(userID int) ([]string, []models.RecoveryCode, error) {
+	plain := make([]string, 0, recoveryCodeCount)
+	codes := make([]models.RecoveryCode, 0, recoveryCodeCount)
+	buf := make([]byte, 10)
+	for i := 0; i < recoveryCodeCount; i++ {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		code := make([]byte, len(buf))
+		for j, b := range buf {
+			code[j] = recoveryCodeAlphabet[b%byte(len(recoveryCodeAlphabet))]
+		}
+		plain = append(plain, string(code[:5])+"-"+string(code[5:]))
+		codes = append(codes, models.RecoveryCode{
+			UserID:    userID,
+			CodeHash:  hashSecretToken(string(code)),
+			CreatedAt: time.Now(),
+		})
+	}
+	return plain, codes, nil
+}
+
+// normalizeTOTPCode drops the spaces apps show in the middle of a code
+func normalizeTOTPCode(code string) string {
+	return strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != totpDigits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeRecoveryCode compares recovery codes without case, spaces or hyphens
+func normalizeRecoveryCode(code string) string {
+	replacer := strings.NewReplacer("-", "", " ", "")
+	return replacer.Replace(strings.ToLower(strings.TrimSpace(code)))
+}
@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// Every backend runs through the same cases. The local backend always runs; S3
+// runs against a MinIO (or other S3-compatible service) when TEST_S3_ENDPOINT is
+// set, with TEST_S3_ACCESS_KEY_ID and TEST_S3_SECRET_ACCESS_KEY, and
+// TEST_S3_USE_SSL=true for HTTPS. Each run creates its own bucket and removes it
+// afterwards.
+
+type backend struct {
+	name string
+	open func(t *testing.T) Storage
+}
+
+func backends(t *testing.T) []backend {
+	list := []backend{
+		{name: "local", open: func(t *testing.T) Storage {
+			s, err := NewLocalStorage(t.TempDir())
+			if err != nil {
+				t.Fatalf("open local storage: %v", err)
+			}
+			return s
+		}},
+	}
+	if endpoint := os.Getenv("TEST_S3_ENDPOINT"); endpoint != "" {
+		list = append(list, backend{name: "s3", open: func(t *testing.T) Storage {
+			return openS3(t, endpoint)
+		}})
+	} else {
+		t.Log("TEST_S3_ENDPOINT is not set; skipping the s3 backend")
+	}
+	return list
+}
+
+// openS3 creates a bucket of its own for the test, emptied and removed at the end
+func openS3(t *testing.T, endpoint string) *S3Storage {
+	t.Helper()
+	useSSL, _ := strconv.ParseBool(os.Getenv("TEST_S3_USE_SSL"))
+	ctx := context.Background()
+	s, err := NewS3Storage(ctx, S3Config{
+		Endpoint:        endpoint,
+		AccessKeyID:     os.Getenv("TEST_S3_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("TEST_S3_SECRET_ACCESS_KEY"),
+		Bucket:          fmt.Sprintf("storage-test-%d", time.Now().UnixNano()),
+		UseSSL:          useSSL,
+	})
+	if err != nil {
+		t.Fatalf("open s3 storage: %v", err)
+	}
+	t.Cleanup(func() {
+		for object := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Recursive: true}) {
+			if object.Err == nil {
+				s.client.RemoveObject(ctx, s.bucket, object.Key, minio.RemoveObjectOptions{})
+			}
+		}
+		if err := s.client.RemoveBucket(ctx, s.bucket); err != nil {
+			t.Logf("remove bucket %s: %v", s.bucket, err)
+		}
+	})
+	return s
+}
+
+// eachBackend runs the test against empty storage of every backend
+func eachBackend(t *testing.T, test func(t *testing.T, s Storage)) {
+	for _, b := range backends(t) {
+		t.Run(b.name, func(t *testing.T) {
+			test(t, b.open(t))
+		})
+	}
+}
+
+func put(t *testing.T, s Storage, key string, data []byte) {
+	t.Helper()
+	if err := s.Put(context.Background(), key, bytes.NewReader(data), int64(len(data)), "image/png"); err != nil {
+		t.Fatalf("put %s: %v", key, err)
+	}
+}
+
+func get(t *testing.T, s Storage, key string) []byte {
+	t.Helper()
+	r, err := s.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("get %s: %v", key, err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read %s: %v", key, err)
+	}
+	return data
+}
+
+func TestPutGetDelete(t *testing.T) {
+	eachBackend(t, func(t *testing.T, s Storage) {
+		ctx := context.Background()
+		key := "ledgers/1/expenses/7/receipt.png"
+		data := bytes.Repeat([]byte{0x89, 'P', 'N', 'G'}, 1024)
+
+		put(t, s, key, data)
+		if got := get(t, s, key); !bytes.Equal(got, data) {
+			t.Fatalf("got %d bytes back, want the %d stored", len(got), len(data))
+		}
+
+		// Putting the key again replaces the object
+		put(t, s, key, []byte("second"))
+		if got := get(t, s, key); string(got) != "second" {
+			t.Fatalf("after replacing: got %q", got)
+		}
+
+		if err := s.Delete(ctx, key); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if _, err := s.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("get after delete: got %v, want %v", err, ErrNotFound)
+		}
+		// Deleting what is not there is not an error
+		if err := s.Delete(ctx, key); err != nil {
+			t.Fatalf("delete again: %v", err)
+		}
+	})
+}
+
+func TestGetMissing(t *testing.T) {
+	eachBackend(t, func(t *testing.T, s Storage) {
+		put(t, s, "a/present", []byte("x"))
+		if _, err := s.Get(context.Background(), "a/missing"); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("got %v, want %v", err, ErrNotFound)
+		}
+	})
+}
+
+func TestLocalStorageRejectsEscapingKeys(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("open local storage: %v", err)
+	}
+	ctx := context.Background()
+	for _, key := range []string{"", "../outside", "a/../../outside"} {
+		if err := s.Put(ctx, key, bytes.NewReader(nil), 0, ""); err == nil {
+			t.Errorf("put %q: got no error", key)
+		}
+		if _, err := s.Get(ctx, key); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("get %q: got %v, want an invalid key error", key, err)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"os"
 
@@ -8,15 +11,21 @@ import (
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 
+	"goExpenseTracker/config"
 	DB "goExpenseTracker/config/DB"
+	authConfig "goExpenseTracker/config/auth"
+	duplicatesConfig "goExpenseTracker/config/duplicates"
+	oidcConfig "goExpenseTracker/config/oidc"
+	storageConfig "goExpenseTracker/config/storage"
 	swaggerConfig "goExpenseTracker/config/swagger"
 	docs "goExpenseTracker/docs"
 	"goExpenseTracker/internal/handlers"
 	Logger "goExpenseTracker/internal/middlewears"
-	"goExpenseTracker/internal/models"
+	"goExpenseTracker/internal/migrations"
 	"goExpenseTracker/internal/repositories"
 	"goExpenseTracker/internal/routes"
 	"goExpenseTracker/internal/services"
+	"goExpenseTracker/internal/storage"
 
 	"gorm.io/gorm"
 )
@@ -36,42 +45,86 @@ import (
 // @host localhost:8080
 // @BasePath /api
 // @schemes http https
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Access token from POST /v1/auth/login or an API key from POST /v1/api-keys, as "Bearer <token>"
 func main() {
-	app := bootstrapApp()
-	port := getPort()
-	if err := app.Run(":" + port); err != nil {
+	cfg, args, err := config.Load(os.Args[1:])
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		os.Exit(0)
+	case errors.Is(err, config.ErrUsage):
+		os.Exit(2)
+	case err != nil:
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "migrate":
+			os.Exit(runMigrate(cfg, args[1:]))
+		case "claim":
+			os.Exit(runClaim(cfg, args[1:]))
+		case "config":
+			cfg.Print(os.Stdout)
+			return
+		default:
+			fmt.Fprintf(os.Stderr, "Unknown command %q, expected migrate, claim or config\n", args[0])
+			os.Exit(2)
+		}
+	}
+
+	app := bootstrapApp(cfg)
+	if err := app.Run(fmt.Sprintf(":%d", cfg.Server.Port)); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
 
 // bootstrapApp sets up the full app (DB + Dependencies + Routes)
-func bootstrapApp() *gin.Engine {
-	db, err := DB.ConnectPostgres()
+func bootstrapApp(cfg *config.Config) *gin.Engine {
+	db, err := DB.Connect(cfg.Database, cfg.Server.Timezone)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
-	// Auto-migrate database tables
-	if err := db.AutoMigrate(&models.Category{}, &models.Expense{}); err != nil {
-		log.Fatalf("Failed to migrate database: %v", err)
+	// The schema is migrated by `migrate up` rather than on every start, unless
+	// database.migrate_on_start is set; instances starting together then take turns. An
+	// in-memory database starts empty, so it is always migrated.
+	runner, err := migrations.New(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	if cfg.Database.MigrateOnStart || cfg.Database.Driver == config.DriverMemory {
+		if _, err := runner.Up(); err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+		log.Println("Database tables migrated successfully!")
+	} else if err := runner.Check(); err != nil {
+		log.Fatalf("Database schema is out of date: %v; run `%s migrate up`", err, os.Args[0])
+	}
+
+	// Receipt attachment storage (local filesystem or S3-compatible)
+	store, err := storageConfig.ConnectStorage(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to initialise attachment storage: %v", err)
 	}
-	log.Println("Database tables migrated successfully!")
 
 	// Initialize repositories, services, handlers
-	categoryHandler, expenseHandler := initializeDependencies(db)
+	h := initializeDependencies(cfg, db, store)
 
 	// Create Gin router and attach middleware
 	router := gin.New()
-	router.Use(Logger.Logger(), gin.Recovery())
+	router.Use(Logger.RequestID(), Logger.Logger(), gin.Recovery())
 
 	// Swagger setup with multiple server options
-	swaggerHost := os.Getenv("SWAGGER_HOST")
-	if swaggerHost != "" {
-		docs.SwaggerInfo.Host = swaggerHost
+	if cfg.Swagger.Host != "" {
+		docs.SwaggerInfo.Host = cfg.Swagger.Host
 	}
 
 	// Custom swagger.json endpoint with servers array (at /api level to avoid routing conflict)
-	router.GET("/api/swagger.json", swaggerConfig.CustomSwaggerHandler())
+	router.GET("/api/swagger.json", swaggerConfig.CustomSwaggerHandler(cfg.Swagger))
 
 	// Swagger UI - configure it to use our custom swagger.json
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(
@@ -80,39 +133,164 @@ func bootstrapApp() *gin.Engine {
 	))
 
 	// Mount routes
-	setupRoutes(router, categoryHandler, expenseHandler)
+	setupRoutes(router, h)
 
 	return router
 }
 
+// appHandlers groups the HTTP handlers mounted by setupRoutes
+type appHandlers struct {
+	requireAuth   gin.HandlerFunc // Guards every route but sign-up and sign-in
+	requireLedger gin.HandlerFunc // Picks the ledger of data routes from X-Ledger-ID
+	auth          *handlers.AuthHandler
+	oidc          *handlers.OIDCHandler
+	twoFactor     *handlers.TwoFactorHandler
+	ledger        *handlers.LedgerHandler
+	apiKey        *handlers.APIKeyHandler
+	category      *handlers.CategoryHandler
+	suggestion    *handlers.SuggestionHandler
+	tag           *handlers.TagHandler
+	rule          *handlers.RuleHandler
+	expense       *handlers.ExpenseHandler
+	account       *handlers.AccountHandler
+	transfer      *handlers.TransferHandler
+	report        *handlers.ReportHandler
+	person        *handlers.PersonHandler
+	sharing       *handlers.SharingHandler
+	attachment    *handlers.AttachmentHandler
+	imports       *handlers.ImportHandler
+	exports       *handlers.ExportHandler
+	backup        *handlers.BackupHandler
+	audit         *handlers.AuditHandler
+}
+
 // initializeDependencies wires repositories → services → handlers
-func initializeDependencies(db *gorm.DB) (*handlers.CategoryHandler, *handlers.ExpenseHandler) {
+func initializeDependencies(cfg *config.Config, db *gorm.DB, store storage.Storage) *appHandlers {
+	// User, authentication and ledger dependencies
+	userRepo := repositories.NewUserRepository(db)
+	ledgerRepo := repositories.NewLedgerRepository(db)
+	twoFactorService := services.NewTwoFactorService(repositories.NewTwoFactorRepository(db), userRepo)
+	authService := services.NewAuthService(userRepo, ledgerRepo, twoFactorService, authConfig.Options(cfg.Auth))
+	oidcService := services.NewOIDCService(authService, oidcConfig.Options(cfg.OIDC))
+	ledgerService := services.NewLedgerService(ledgerRepo, userRepo, cfg.Server.Location())
+	apiKeyService := services.NewAPIKeyService(repositories.NewAPIKeyRepository(db), userRepo)
+
+	// Audit trail of expense and category changes
+	auditService := services.NewAuditService(repositories.NewAuditRepository(db), userRepo)
+
+	// The memory driver keeps categories and expenses in process memory; everything
+	// else lives in the in-memory SQLite database
+	var categoryRepo repositories.CategoryRepository
+	var expenseRepo repositories.ExpenseRepository
+	if cfg.Database.Driver == config.DriverMemory {
+		categoryRepo = repositories.NewMemoryCategoryRepository()
+		expenseRepo = repositories.NewMemoryExpenseRepository(categoryRepo)
+	} else {
+		categoryRepo = repositories.NewCategoryRepository(db)
+		expenseRepo = repositories.NewExpenseRepository(db)
+	}
+
 	// Category dependencies
-	categoryRepo := repositories.NewCategoryRepository(db)
-	categoryService := services.NewCategoryService(categoryRepo)
+	categoryService := services.NewCategoryService(categoryRepo, auditService)
 	categoryHandler := handlers.NewCategoryHandler(categoryService)
 
-	// Expense dependencies (with category repo for relationship mapping)
-	expenseRepo := repositories.NewExpenseRepository(db)
-	expenseService := services.NewExpenseService(expenseRepo, categoryRepo)
+	// Tag dependencies
+	tagRepo := repositories.NewTagRepository(db)
+	tagService := services.NewTagService(tagRepo)
+
+	// Account, transfer and shared-expense dependencies
+	accountRepo := repositories.NewAccountRepository(db)
+	transferRepo := repositories.NewTransferRepository(db)
+	personRepo := repositories.NewPersonRepository(db)
+	settlementRepo := repositories.NewSettlementRepository(db)
+
+	// Expense dependencies (with category, account and person repos for relationship mapping)
+	attachmentRepo := repositories.NewAttachmentRepository(db)
+	maxAttachmentBytes := cfg.Storage.MaxAttachmentBytes
+	attachmentService := services.NewAttachmentService(attachmentRepo, expenseRepo, store, maxAttachmentBytes)
+	ruleService := services.NewRuleService(repositories.NewRuleRepository(db), expenseRepo, categoryRepo, accountRepo, tagRepo)
+	suggestionService := services.NewSuggestionService(expenseRepo, categoryRepo)
+	duplicateService := services.NewDuplicateService(expenseRepo, repositories.NewDuplicateRepository(db), categoryRepo, duplicatesConfig.Options(cfg.Duplicates))
+	expenseService := services.NewExpenseService(expenseRepo, categoryRepo, accountRepo, personRepo, tagRepo, ruleService, suggestionService, duplicateService, attachmentService, auditService)
 	expenseHandler := handlers.NewExpenseHandler(expenseService)
 
-	return categoryHandler, expenseHandler
+	accountService := services.NewAccountService(accountRepo, expenseRepo, transferRepo)
+	transferService := services.NewTransferService(transferRepo, accountRepo)
+	reportService := services.NewReportService(expenseRepo, categoryRepo, accountRepo, tagRepo, attachmentService)
+	personService := services.NewPersonService(personRepo, expenseRepo)
+	sharingService := services.NewSharingService(expenseRepo, personRepo, settlementRepo)
+	importService := services.NewImportService(expenseService, ruleService, duplicateService, auditService, expenseRepo, categoryRepo)
+	exportService := services.NewExportService(expenseRepo, categoryRepo, accountRepo)
+	backupService := services.NewBackupService(repositories.NewBackupRepository(db), userRepo, ledgerRepo, store, suggestionService)
+
+	return &appHandlers{
+		requireAuth:   Logger.Auth(authService, apiKeyService),
+		requireLedger: Logger.Ledger(ledgerService),
+		auth:          handlers.NewAuthHandler(authService),
+		oidc:          handlers.NewOIDCHandler(oidcService),
+		twoFactor:     handlers.NewTwoFactorHandler(twoFactorService),
+		ledger:        handlers.NewLedgerHandler(ledgerService),
+		apiKey:        handlers.NewAPIKeyHandler(apiKeyService),
+		category:      categoryHandler,
+		suggestion:    handlers.NewSuggestionHandler(suggestionService),
+		tag:           handlers.NewTagHandler(tagService),
+		rule:          handlers.NewRuleHandler(ruleService),
+		expense:       expenseHandler,
+		account:       handlers.NewAccountHandler(accountService),
+		transfer:      handlers.NewTransferHandler(transferService),
+		report:        handlers.NewReportHandler(reportService),
+		person:        handlers.NewPersonHandler(personService),
+		sharing:       handlers.NewSharingHandler(sharingService),
+		attachment:    handlers.NewAttachmentHandler(attachmentService, maxAttachmentBytes),
+		imports:       handlers.NewImportHandler(importService),
+		exports:       handlers.NewExportHandler(exportService),
+		backup:        handlers.NewBackupHandler(backupService),
+		audit:         handlers.NewAuditHandler(auditService),
+	}
 }
 
 // setupRoutes registers all route groups
-func setupRoutes(router *gin.Engine, categoryHandler *handlers.CategoryHandler, expenseHandler *handlers.ExpenseHandler) {
-	api := router.Group("/api")
+func setupRoutes(router *gin.Engine, h *appHandlers) {
+	// Sign-up and sign-in are the only routes open without a token
+	routes.SetupAuthRoutes(router.Group("/api"), h.auth, h.requireAuth, Logger.RequireSession())
+	routes.SetupOIDCRoutes(router.Group("/api"), h.oidc)
+
+	// Two-factor sign-in, ledgers, API keys and the instance backup are not tied to
+	// a single ledger, and are managed by people rather than scripts
+	account := router.Group("/api", h.requireAuth, Logger.RequireSession())
 	{
-		routes.SetupCategoryRoutes(api, categoryHandler)
-		routes.SetupExpenseRoutes(api, expenseHandler)
+		routes.SetupTwoFactorRoutes(account, h.twoFactor)
+		routes.SetupLedgerRoutes(account, h.ledger)
+		routes.SetupAPIKeyRoutes(account, h.apiKey)
+		routes.SetupBackupRoutes(account, h.backup)
 	}
-}
 
-// getPort retrieves the server port or defaults to 8080
-func getPort() string {
-	if port := os.Getenv("PORT"); port != "" {
-		return port
+	// API keys reach the data routes their scopes allow
+	api := router.Group("/api", h.requireAuth, h.requireLedger)
+	scoped := func(read, write string) *gin.RouterGroup {
+		return api.Group("", Logger.RequireScope(read, write))
+	}
+	{
+		categories := scoped(services.ScopeCategoriesRead, services.ScopeCategoriesAdmin)
+		routes.SetupCategoryRoutes(categories, h.category)
+		routes.SetupTagRoutes(categories, h.tag)
+		routes.SetupRuleRoutes(categories, h.rule)
+
+		expenses := scoped(services.ScopeExpensesRead, services.ScopeExpensesWrite)
+		routes.SetupSuggestionRoutes(expenses, h.suggestion)
+		routes.SetupExpenseRoutes(expenses, h.expense)
+		routes.SetupReportRoutes(expenses, h.report)
+		routes.SetupAttachmentRoutes(expenses, h.attachment)
+		routes.SetupImportRoutes(expenses, h.imports)
+		routes.SetupExportRoutes(expenses, h.exports)
+		routes.SetupAuditRoutes(expenses, h.audit)
+
+		accounts := scoped(services.ScopeAccountsRead, services.ScopeAccountsWrite)
+		routes.SetupAccountRoutes(accounts, h.account)
+		routes.SetupTransferRoutes(accounts, h.transfer)
+
+		sharing := scoped(services.ScopeSharingRead, services.ScopeSharingWrite)
+		routes.SetupPersonRoutes(sharing, h.person)
+		routes.SetupSharingRoutes(sharing, h.sharing)
 	}
-	return "8080"
 }
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"goExpenseTracker/config"
+	DB "goExpenseTracker/config/DB"
+	"goExpenseTracker/internal/migrations"
+)
+
+const migrateUsage = `Usage: %s [flags] migrate <command>
+
+Commands:
+  up            apply every pending migration
+  down [steps]  roll back the last steps migrations (1 by default)
+  status        list the migrations and whether each is applied
+`
+
+// runMigrate runs the migrate subcommand and returns the exit code
+func runMigrate(cfg *config.Config, args []string) int {
+	valid := len(args) == 1 && (args[0] == "up" || args[0] == "down" || args[0] == "status") ||
+		len(args) == 2 && args[0] == "down"
+	if !valid {
+		fmt.Fprintf(os.Stderr, migrateUsage, os.Args[0])
+		return 2
+	}
+	steps := 1
+	if len(args) == 2 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			fmt.Fprintf(os.Stderr, "steps must be a positive number, got %q\n", args[1])
+			return 2
+		}
+		steps = n
+	}
+
+	db, err := DB.Connect(cfg.Database, cfg.Server.Timezone)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to connect to database: %v\n", err)
+		return 1
+	}
+	runner, err := migrations.New(db)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load migrations: %v\n", err)
+		return 1
+	}
+
+	switch args[0] {
+	case "up":
+		count, err := runner.Up()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to migrate database: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Applied %d migration(s)\n", count)
+	case "down":
+		count, err := runner.Down(steps)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to roll back database: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Rolled back %d migration(s)\n", count)
+	case "status":
+		statuses, err := runner.Status()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read migration status: %v\n", err)
+			return 1
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS")
+		for _, status := range statuses {
+			state := "pending"
+			if status.AppliedAt != nil {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if status.Unknown {
+				state += " (not in this build)"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, state)
+		}
+		w.Flush()
+	}
+	return 0
+}